@@ -2,14 +2,39 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gameformush/goasm-vscode/internal/coverage"
 	"github.com/gameformush/goasm-vscode/internal/disasm"
+	lensmpb "github.com/gameformush/goasm-vscode/internal/proto"
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // The response format is simpler than initially implemented
@@ -18,16 +43,266 @@ import (
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	logger     *slog.Logger
+	// tlsConfig is used for https and wss connections, nil uses the defaults
+	tlsConfig *tls.Config
+	// encoding is requested for responses with the Accept header
+	encoding Encoding
+
+	// APIVersion selects the API paths and response schemas, 1 or 2
+	APIVersion int
 }
 
+// requestTimeout limits the requests of the client, except for uploads
+const requestTimeout = 30 * time.Second
+
 // NewClient creates a new client for the lensm HTTP server
+// It uses the highest API version supported by both sides
 func NewClient(baseURL string) *Client {
-	return &Client{
+	c := &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   requestTimeout,
+			Transport: tracingTransport{http.DefaultTransport},
 		},
+		logger:     slog.Default(),
+		encoding:   EncodingMsgpack,
+		APIVersion: 1,
+	}
+	c.negotiateVersion()
+	return c
+}
+
+// WithEncoding selects the encoding requested for responses, msgpack by default
+func (c *Client) WithEncoding(enc Encoding) *Client {
+	c.encoding = enc
+	return c
+}
+
+// get sends a GET request accepting the encoding of the client
+func (c *Client) get(rawURL string) (*http.Response, error) {
+	return c.send(http.MethodGet, rawURL, "", nil)
+}
+
+// send sends a request accepting the encoding of the client
+// Requests rejected with 406 are repeated accepting JSON
+func (c *Client) send(method, rawURL, contentType string, body []byte) (*http.Response, error) {
+	enc := c.encoding
+	for {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, rawURL, reader)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Accept", enc.ContentType())
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusNotAcceptable || enc == EncodingJSON {
+			return resp, err
+		}
+		resp.Body.Close()
+		enc = EncodingJSON
+	}
+}
+
+// readServerError decodes the ServerError of a failed response
+// The plain text body of older servers becomes the message
+func readServerError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	serverErr := &ServerError{}
+	if err := json.Unmarshal(body, serverErr); err != nil || serverErr.Message == "" {
+		serverErr = &ServerError{Message: strings.TrimSpace(string(body))}
+	}
+	if serverErr.Code == 0 {
+		serverErr.Code = resp.StatusCode
+	}
+	if serverErr.RequestID == "" {
+		serverErr.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return serverErr
+}
+
+// errorMessage returns the message of err for display,
+// with the request ID of server errors for support
+func errorMessage(err error) string {
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) && serverErr.RequestID != "" {
+		return fmt.Sprintf("%v (request ID %s)", err, serverErr.RequestID)
+	}
+	return err.Error()
+}
+
+// WithClientCert presents the certificate in certFile with the private key
+// in keyFile during the TLS handshake, for servers requiring client certificates
+func (c *Client) WithClientCert(certFile, keyFile string) *Client {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		c.logger.Error("error loading client certificate", "cert", certFile, "key", keyFile, "err", err)
+		return c
+	}
+	c.configureTLS(func(config *tls.Config) {
+		config.Certificates = []tls.Certificate{cert}
+	})
+	return c
+}
+
+// WithRootCA verifies the server certificate with the CA certificates in caFile
+// instead of the system roots
+func (c *Client) WithRootCA(caFile string) *Client {
+	pool, err := loadCertPool(caFile)
+	if err != nil {
+		c.logger.Error("error loading CA certificate", "ca", caFile, "err", err)
+		return c
+	}
+	c.configureTLS(func(config *tls.Config) {
+		config.RootCAs = pool
+	})
+	return c
+}
+
+// configureTLS updates the TLS config of the client and renegotiates the API version,
+// which fails when the server requires a client certificate that wasn't configured yet
+func (c *Client) configureTLS(update func(config *tls.Config)) {
+	if c.tlsConfig == nil {
+		c.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
 	}
+	update(c.tlsConfig)
+
+	// the transport adds h2 to NextProtos, which breaks the websocket handshake
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c.tlsConfig.Clone()
+	c.httpClient.Transport = tracingTransport{transport}
+	c.negotiateVersion()
+}
+
+// loadCertPool reads the PEM encoded certificates in file
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// negotiateVersion picks the API version using /api/version
+// Servers without the endpoint only support v1
+func (c *Client) negotiateVersion() {
+	resp, err := c.get(c.baseURL + "/api/version")
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	var result struct {
+		Versions []string `json:"versions"`
+	}
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
+		return
+	}
+	for _, version := range result.Versions {
+		if version == "v2" {
+			c.APIVersion = 2
+		}
+	}
+}
+
+// apiURL returns the URL of the endpoint for the selected API version
+func (c *Client) apiURL(endpoint string) string {
+	if c.APIVersion >= 2 {
+		return c.baseURL + "/api/v2" + endpoint
+	}
+	return c.baseURL + "/api" + endpoint
+}
+
+// listPageSize is the number of items requested per page from v2 list endpoints
+const listPageSize = 1000
+
+// getList retrieves all items stored under key from a list endpoint
+// With API v2 the items are fetched page by page
+func getList[T any](c *Client, endpoint string, params url.Values, key string) ([]T, error) {
+	var all []T
+	for {
+		if c.APIVersion >= 2 {
+			params.Set("offset", strconv.Itoa(len(all)))
+			params.Set("limit", strconv.Itoa(listPageSize))
+		}
+
+		resp, err := c.get(c.apiURL(endpoint) + "?" + params.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := readServerError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		enc := responseEncoding(resp)
+		result, err := enc.DecodeFields(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding response: %w", err)
+		}
+
+		var items []T
+		if err := enc.Unmarshal(result[key], &items); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", key, err)
+		}
+		all = append(all, items...)
+
+		if c.APIVersion < 2 {
+			return all, nil
+		}
+		var meta ListMeta
+		if err := enc.Unmarshal(result["meta"], &meta); err != nil {
+			return nil, fmt.Errorf("error decoding meta: %w", err)
+		}
+		if len(items) == 0 || len(all) >= meta.Total {
+			return all, nil
+		}
+	}
+}
+
+// tracingTransport records a span for each request and propagates
+// it to the server using the traceparent header
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracer.Start(req.Context(), req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("http.url", req.URL.String()),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		setSpanError(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	return resp, nil
 }
 
 // LoadFile loads a binary file for disassembly
@@ -46,7 +321,7 @@ func (c *Client) LoadFile(path string) error {
 	// Create a custom request to add headers
 	req, err := http.NewRequest(
 		"POST",
-		c.baseURL+"/api/files",
+		c.apiURL("/files"),
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
@@ -65,40 +340,190 @@ func (c *Client) LoadFile(path string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error (status %d): %s", resp.StatusCode, body)
+		return readServerError(resp)
 	}
 
 	return nil
 }
 
-// GetFunctions retrieves functions from a loaded file
-func (c *Client) GetFunctions(path string, filter string) ([]FunctionInfo, error) {
+// UploadBinaryAndLoad streams the binary read from r to the server, which loads it
+// The body is sent with chunked encoding while hashing it, so r is never buffered
+// in memory, and the hash is compared with the one computed by the server
+// opts are used for prefetching functions until the first Load
+func (c *Client) UploadBinaryAndLoad(ctx context.Context, r io.Reader, filename string, opts disasm.Options) (*NetworkFile, error) {
+	params := url.Values{}
+	params.Add("filename", filename)
+
+	hash := sha256.New()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.apiURL("/upload")+"?"+params.Encode(), io.TeeReader(r, hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Accept", c.encoding.ContentType())
+
+	// uploads may take longer than the timeout of the other requests, ctx limits them
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, readServerError(resp)
+	}
+
+	var result UploadResponse
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != result.SHA256 {
+		// close the corrupted file on the server
+		_ = (&NetworkFile{client: c, path: result.Path}).Close()
+		return nil, fmt.Errorf("upload corrupted: sent sha256 %s, server received %s", sum, result.SHA256)
+	}
+
+	file, err := openNetworkFile(c, FileInfo{Path: result.Path, BuildID: result.BuildID, GoModPath: result.GoModPath})
+	if err != nil {
+		return nil, err
+	}
+	file.prefetch.opts = opts
+	return file, nil
+}
+
+// GetFunctions retrieves functions from a loaded file, source restricts
+// them to the ones with code from matching source files
+func (c *Client) GetFunctions(path string, filter string, source string) ([]FunctionInfo, error) {
 	params := url.Values{}
 	params.Add("file", path)
 	if filter != "" {
 		params.Add("filter", filter)
 	}
+	if source != "" {
+		params.Add("source", source)
+	}
+
+	return getList[FunctionInfo](c, "/functions", params, "functions")
+}
 
-	resp, err := c.httpClient.Get(c.baseURL + "/api/functions?" + params.Encode())
+// UploadCoverage sends a `go test -coverprofile` output for a loaded file,
+// the server then marks the covered instructions of its functions
+func (c *Client) UploadCoverage(filePath string, coverProfile io.Reader) error {
+	body, err := io.ReadAll(coverProfile)
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
+		return fmt.Errorf("error reading coverage profile: %w", err)
+	}
+	params := url.Values{}
+	params.Add("file", filePath)
+
+	resp, err := c.send(http.MethodPost, c.apiURL("/coverage")+"?"+params.Encode(), "text/plain", body)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server error: %s", body)
+		return readServerError(resp)
 	}
+	return nil
+}
 
-	var result struct {
-		Functions []FunctionInfo `json:"functions"`
+// GetCoverage retrieves the line coverage uploaded for a loaded file
+func (c *Client) GetCoverage(filePath string) (*coverage.Profile, error) {
+	params := url.Values{}
+	params.Add("file", filePath)
+
+	resp, err := c.get(c.apiURL("/coverage") + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readServerError(resp)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+
+	var result CoverageResponse
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
+	lines := make(map[string]map[int]bool, len(result.Files))
+	for _, file := range result.Files {
+		covered := make(map[int]bool, len(file.Covered)+len(file.Uncovered))
+		for _, line := range file.Uncovered {
+			covered[line] = false
+		}
+		for _, line := range file.Covered {
+			covered[line] = true
+		}
+		lines[file.File] = covered
+	}
+	return coverage.FromLines(lines), nil
+}
+
+// GetSymbols retrieves the symbol table of a loaded file
+func (c *Client) GetSymbols(path string, symType string, exportedOnly bool) ([]SymbolInfo, error) {
+	params := url.Values{}
+	params.Add("file", path)
+	if symType != "" {
+		params.Add("type", symType)
+	}
+	if exportedOnly {
+		params.Add("exported", "true")
+	}
 
-	return result.Functions, nil
+	return getList[SymbolInfo](c, "/symbols", params, "symbols")
+}
+
+// GetPackageSizes retrieves the code size of each package, largest first,
+// and the size of all functions
+func (c *Client) GetPackageSizes(filePath string) ([]PackageSize, int64, error) {
+	params := url.Values{}
+	params.Add("file", filePath)
+
+	resp, err := c.get(c.apiURL("/packages/sizes") + "?" + params.Encode())
+	if err != nil {
+		return nil, 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, readServerError(resp)
+	}
+
+	var result SizeBreakdown
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %w", err)
+	}
+	return result.Packages, result.Total, nil
+}
+
+// ResolvePC retrieves the source position of a program counter
+func (c *Client) ResolvePC(filePath string, pc uint64) (*PCResolution, error) {
+	params := url.Values{}
+	params.Add("file", filePath)
+	params.Add("pc", fmt.Sprintf("%#x", pc))
+
+	resp, err := c.get(c.apiURL("/resolve") + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readServerError(resp)
+	}
+
+	var result PCResolution
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	return &result, nil
 }
 
 // GetFunctionCode retrieves the disassembled code for a specific function
@@ -112,29 +537,81 @@ func (c *Client) GetFunctionCode(path string, functionName string, context int)
 	// URL encode the function name
 	escapedName := url.PathEscape(functionName)
 
-	resp, err := c.httpClient.Get(c.baseURL + "/api/functions/" + escapedName + "?" + params.Encode())
+	resp, err := c.get(c.apiURL("/functions/"+escapedName) + "?" + params.Encode())
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server error: %s", body)
+		return nil, readServerError(resp)
 	}
 
 	var result CodeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return result.toCode(), nil
+}
+
+// GetFunctionsBulk retrieves the disassembled code of multiple functions with a single request
+// Functions that couldn't be disassembled are missing from the result
+func (c *Client) GetFunctionsBulk(path string, names []string, context int) (map[string]*disasm.Code, error) {
+	reqBody := struct {
+		File    string   `json:"file"`
+		Names   []string `json:"names"`
+		Context int      `json:"context,omitempty"`
+	}{
+		File:    path,
+		Names:   names,
+		Context: context,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.send(http.MethodPost, c.apiURL("/functions/bulk"), "application/json", jsonData)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readServerError(resp)
+	}
+
+	var result struct {
+		Results []BulkResult `json:"results"`
+	}
+	if err := responseEncoding(resp).Decode(resp.Body, &result); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	// Convert the response to a disasm.Code object
+	codes := make(map[string]*disasm.Code, len(result.Results))
+	for _, r := range result.Results {
+		if r.Code == nil {
+			c.logger.Warn("bulk disassembly failed", "func", r.Name, "err", r.Error)
+			continue
+		}
+		codes[r.Name] = r.Code.toCode()
+	}
+	return codes, nil
+}
+
+// toCode converts the response to a disasm.Code object
+func (result *CodeResponse) toCode() *disasm.Code {
 	code := &disasm.Code{
-		Name:    result.Name,
-		File:    result.File,
-		MaxJump: result.MaxJump,
-		Insts:   make([]disasm.Inst, len(result.Instructions)),
-		Source:  make([]disasm.Source, len(result.Sources)),
+		Name:        result.Name,
+		File:        result.File,
+		FrameSize:   result.FrameSize,
+		MaxJump:     result.MaxJump,
+		Arch:        result.Arch,
+		StackMapPCs: result.StackMapPCs,
+		Insts:       make([]disasm.Inst, len(result.Instructions)),
+		Source:      make([]disasm.Source, len(result.Sources)),
 	}
 
 	// Convert instructions
@@ -148,6 +625,7 @@ func (c *Client) GetFunctionCode(path string, functionName string, context int)
 			RefOffset: inst.RefOffset,
 			RefStack:  inst.RefStack,
 			Call:      inst.Call,
+			Bytes:     inst.Bytes,
 		}
 	}
 
@@ -183,22 +661,154 @@ func (c *Client) GetFunctionCode(path string, functionName string, context int)
 
 		code.Source[i] = source
 	}
+	code.BoundsCheckCount = disasm.CountBoundsChecks(code)
+	code.TailCallCount = disasm.CountTailCalls(code)
+	code.NilCheckCount = disasm.CountNilChecks(code)
+	code.NOPBytes = disasm.CountNOPBytes(code)
+	disasm.ComputeMetrics(code)
+
+	return code
+}
+
+// WSClient communicates with the lensm server over a single WebSocket
+// connection, which avoids the overhead of a request per function
+type WSClient struct {
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	nextID int
+	// broken is set when a request failed, the connection is closed then
+	broken bool
+}
+
+// DialWS connects to the /api/ws endpoint of the server at baseURL
+// tlsConfig is used for wss connections, nil uses the defaults
+func DialWS(baseURL string, tlsConfig *tls.Config) (*WSClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/ws"
+
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConfig
+	conn, _, err := dialer.Dial(u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting: %w", err)
+	}
+	return &WSClient{conn: conn}, nil
+}
+
+// errWSBroken is returned by the requests after a request failed
+var errWSBroken = errors.New("websocket connection closed after a failed request")
+
+// Close closes the connection
+func (c *WSClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broken {
+		return nil
+	}
+	_ = c.conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return c.conn.Close()
+}
+
+// roundTrip sends the request and waits for the matching response
+// The request fails after requestTimeout, which closes the connection
+// since a late response would be read by the next request
+func (c *WSClient) roundTrip(req WSMessage) (*WSMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.broken {
+		return nil, errWSBroken
+	}
+	fail := func(format string, err error) (*WSMessage, error) {
+		c.broken = true
+		_ = c.conn.Close()
+		return nil, fmt.Errorf(format, err)
+	}
 
-	return code, nil
+	deadline := time.Now().Add(requestTimeout)
+	_ = c.conn.SetWriteDeadline(deadline)
+	_ = c.conn.SetReadDeadline(deadline)
+
+	c.nextID++
+	req.ID = strconv.Itoa(c.nextID)
+	if err := c.conn.WriteJSON(&req); err != nil {
+		return fail("error sending request: %w", err)
+	}
+
+	for {
+		var resp WSMessage
+		if err := c.conn.ReadJSON(&resp); err != nil {
+			return fail("error reading response: %w", err)
+		}
+		if resp.ID != req.ID {
+			continue
+		}
+		if resp.Type == "error" {
+			return nil, &ServerError{Message: resp.Error}
+		}
+		return &resp, nil
+	}
+}
+
+// LoadFile loads a binary file for disassembly
+func (c *WSClient) LoadFile(path string) error {
+	_, err := c.roundTrip(WSMessage{Type: "load", Path: path})
+	return err
+}
+
+// GetFunctionCode retrieves the disassembled code for a specific function
+func (c *WSClient) GetFunctionCode(path string, functionName string, context int) (*disasm.Code, error) {
+	resp, err := c.roundTrip(WSMessage{Type: "disasm", File: path, Func: functionName, Context: &context})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code == nil {
+		return nil, fmt.Errorf("unexpected response %q", resp.Type)
+	}
+	return resp.Code.toCode(), nil
 }
 
 // NetworkFile implements the disasm.File interface for remote files
 type NetworkFile struct {
-	client  *Client
-	path    string
-	funcs   []disasm.Func
-	funcMap map[string]disasm.Func
+	client *Client
+	path   string
+
+	// mu guards the fields replaced when reconnecting
+	mu        sync.RWMutex
+	ws        *WSClient
+	buildID   string
+	goModPath string
+	funcs     []disasm.Func
+	funcMap   map[string]disasm.Func
+	// coverage is the line coverage stored on the server, see LoadCoverage
+	coverage *coverage.Profile
+
+	// prefetch caches the loaded functions and the ones loaded by Prefetch
+	prefetch *prefetcher
+
+	// reconnecting is set while the server is unreachable, see reconnect
+	reconnecting atomic.Bool
+	// OnReconnect is called after the file was loaded again on a restarted
+	// server and the function list was refreshed
+	OnReconnect func()
+	closed      chan struct{}
+	closeOnce   sync.Once
 }
 
 // NetworkFunc implements the disasm.Func interface for remote functions
 type NetworkFunc struct {
-	file *NetworkFile
-	name string
+	file        *NetworkFile
+	name        string
+	constraints []string
 }
 
 // Ensure interfaces are implemented
@@ -215,39 +825,77 @@ func NewNetworkFile(client *Client) (*NetworkFile, error) {
 		return nil, fmt.Errorf("no files available")
 	}
 
-	path := files[0] // TODO allow user to select file
+	return openNetworkFile(client, files[0]) // TODO allow user to select file
+}
 
+// openNetworkFile creates a NetworkFile for a file that's loaded on the server
+func openNetworkFile(client *Client, info FileInfo) (*NetworkFile, error) {
+	path := info.Path
 	file := &NetworkFile{
-		client:  client,
-		path:    path,
-		funcMap: make(map[string]disasm.Func),
+		client:    client,
+		path:      path,
+		buildID:   info.BuildID,
+		goModPath: info.GoModPath,
+		prefetch:  newPrefetcher(),
+		closed:    make(chan struct{}),
 	}
 
-	// Get all functions
-	functions, err := client.GetFunctions(path, "")
+	// Prefer a persistent connection for disassembling functions
+	file.ws = file.dialWS()
+
+	funcs, funcMap, err := file.loadFuncs()
 	if err != nil {
 		return nil, err
 	}
+	file.funcs, file.funcMap = funcs, funcMap
+
+	return file, nil
+}
 
-	// Create function objects
-	file.funcs = make([]disasm.Func, len(functions))
+// dialWS connects the WebSocket of the file, nil when it's unavailable
+func (f *NetworkFile) dialWS() *WSClient {
+	ws, err := DialWS(f.client.baseURL, f.client.tlsConfig)
+	if err != nil {
+		f.client.logger.Info("websocket unavailable, using HTTP", "err", err)
+		return nil
+	}
+	return ws
+}
+
+// loadFuncs gets the functions of the file from the server
+func (f *NetworkFile) loadFuncs() ([]disasm.Func, map[string]disasm.Func, error) {
+	functions, err := f.client.GetFunctions(f.path, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	funcs := make([]disasm.Func, len(functions))
+	funcMap := make(map[string]disasm.Func, len(functions))
 	for i, fn := range functions {
 		netFunc := &NetworkFunc{
-			file: file,
-			name: fn.Name,
+			file:        f,
+			name:        fn.Name,
+			constraints: fn.BuildConstraints,
 		}
-		file.funcs[i] = netFunc
-		file.funcMap[fn.Name] = netFunc
+		funcs[i] = netFunc
+		funcMap[fn.Name] = netFunc
 	}
-
-	return file, nil
+	return funcs, funcMap, nil
 }
 
 // Close implements disasm.File.Close
 func (f *NetworkFile) Close() error {
+	f.closeOnce.Do(func() { close(f.closed) })
+	f.mu.Lock()
+	if f.ws != nil {
+		_ = f.ws.Close()
+		f.ws = nil
+	}
+	f.mu.Unlock()
+
 	// Make a DELETE request to clean up resources on the server
 	encodedPath := url.PathEscape(f.path)
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/files/%s", f.client.baseURL, encodedPath), nil)
+	req, err := http.NewRequest(http.MethodDelete, f.client.apiURL("/files/"+encodedPath), nil)
 	if err != nil {
 		return err
 	}
@@ -259,59 +907,351 @@ func (f *NetworkFile) Close() error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error (%d): %s", resp.StatusCode, body)
+		return readServerError(resp)
 	}
 
 	return nil
 }
 
+// BuildID implements disasm.File.BuildID
+func (f *NetworkFile) BuildID() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.buildID
+}
+
+// GoModPath implements disasm.File.GoModPath
+func (f *NetworkFile) GoModPath() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.goModPath
+}
+
 // Funcs implements disasm.File.Funcs
 func (f *NetworkFile) Funcs() []disasm.Func {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	return f.funcs
 }
 
+// PackageFuncs implements disasm.File.PackageFuncs
+func (f *NetworkFile) PackageFuncs() map[string][]disasm.Func {
+	return disasm.GroupByPackage(f.Funcs())
+}
+
+// SymbolTable implements disasm.File.SymbolTable
+func (f *NetworkFile) SymbolTable() []disasm.Symbol {
+	symbols, err := f.client.GetSymbols(f.path, "", false)
+	if err != nil {
+		if needsReconnect(err) {
+			f.startReconnect(err)
+		}
+		// Log error but don't fail
+		f.client.logger.Error("error loading symbols", "path", f.path, "err", err)
+		return nil
+	}
+
+	table := make([]disasm.Symbol, len(symbols))
+	for i, sym := range symbols {
+		table[i] = disasm.Symbol{
+			Name:     sym.Name,
+			Type:     sym.Type,
+			Size:     sym.Size,
+			Address:  sym.Address,
+			Exported: sym.Exported,
+		}
+	}
+	return table
+}
+
+// LoadCoverage uploads the coverage profile at profilePath to the server,
+// which parses it, and keeps the resulting line coverage for Coverage
+func (f *NetworkFile) LoadCoverage(profilePath string) error {
+	in, err := os.Open(profilePath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := f.client.UploadCoverage(f.path, in); err != nil {
+		return err
+	}
+
+	profile, err := f.client.GetCoverage(f.path)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.coverage = profile
+	f.mu.Unlock()
+	return nil
+}
+
+// Coverage returns the line coverage loaded by LoadCoverage, nil before
+func (f *NetworkFile) Coverage() *coverage.Profile {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.coverage
+}
+
+// FuncsBySourceFile implements disasm.SourceFileFinder with the server
+// matching the source files, instead of loading every function
+func (f *NetworkFile) FuncsBySourceFile(pattern string) ([]disasm.Func, error) {
+	functions, err := f.client.GetFunctions(f.path, "", pattern)
+	if err != nil {
+		if needsReconnect(err) {
+			f.startReconnect(err)
+		}
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	funcs := make([]disasm.Func, 0, len(functions))
+	for _, fn := range functions {
+		if netFunc, ok := f.funcMap[fn.Name]; ok {
+			funcs = append(funcs, netFunc)
+		}
+	}
+	return funcs, nil
+}
+
+// PackageSizes returns the package sizes computed by the server,
+// NetworkFunc doesn't know the size of the functions
+func (f *NetworkFile) PackageSizes() ([]disasm.PackageSize, int64) {
+	packages, total, err := f.client.GetPackageSizes(f.path)
+	if err != nil {
+		if needsReconnect(err) {
+			f.startReconnect(err)
+		}
+		f.client.logger.Error("error loading package sizes", "path", f.path, "err", err)
+		return nil, 0
+	}
+	sizes := make([]disasm.PackageSize, len(packages))
+	for i, pkg := range packages {
+		sizes[i] = disasm.PackageSize{Name: pkg.Name, Size: pkg.ByteSize, Funcs: pkg.FunctionCount}
+	}
+	return sizes, total
+}
+
 // Name implements disasm.Func.Name
 func (f *NetworkFunc) Name() string {
 	return f.name
 }
 
+// BuildConstraints implements disasm.Func.BuildConstraints
+func (f *NetworkFunc) BuildConstraints() []string {
+	return f.constraints
+}
+
 // Load implements disasm.Func.Load
 func (f *NetworkFunc) Load(opt disasm.Options) *disasm.Code {
+	if code := f.file.prefetch.cached(f.name, opt); code != nil {
+		return code
+	}
+	if f.file.Reconnecting() {
+		return nil
+	}
+	code := f.load(opt)
+	if code != nil {
+		f.file.prefetch.add(f.name, opt, code)
+	}
+	return code
+}
+
+// load requests the code of the function from the server
+func (f *NetworkFunc) load(opt disasm.Options) *disasm.Code {
+	f.file.mu.RLock()
+	ws := f.file.ws
+	f.file.mu.RUnlock()
+	if ws != nil {
+		code, err := ws.GetFunctionCode(f.file.path, f.name, opt.Context)
+		if err == nil {
+			return code
+		}
+		// the server answered, HTTP would fail the same way
+		var serverErr *ServerError
+		if needsReconnect(err) {
+			f.file.startReconnect(err)
+			return nil
+		}
+		if errors.As(err, &serverErr) {
+			f.file.client.logger.Error("error loading function", "path", f.file.path, "func", f.name, "err", err)
+			return nil
+		}
+		// the connection is closed, the next requests use HTTP until reconnecting
+		f.file.client.logger.Warn("websocket request failed, using HTTP", "func", f.name, "err", err)
+		f.file.mu.Lock()
+		if f.file.ws == ws {
+			f.file.ws = nil
+		}
+		f.file.mu.Unlock()
+	}
+
 	code, err := f.file.client.GetFunctionCode(f.file.path, f.name, opt.Context)
 	if err != nil {
+		if needsReconnect(err) {
+			f.file.startReconnect(err)
+			return nil
+		}
 		// Log error but don't fail
-		fmt.Printf("Error loading function %s: %v\n", f.name, err)
+		var serverErr *ServerError
+		if errors.As(err, &serverErr) && serverErr.RequestID != "" {
+			f.file.client.logger.Error("error loading function", "path", f.file.path, "func", f.name, "err", err, "requestID", serverErr.RequestID)
+		} else {
+			f.file.client.logger.Error("error loading function", "path", f.file.path, "func", f.name, "err", err)
+		}
 		return nil
 	}
 	return code
 }
 
 // GetFiles retrieves a list of available binary files from the server
-func (c *Client) GetFiles() ([]string, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/api/files")
+func (c *Client) GetFiles() ([]FileInfo, error) {
+	return getList[FileInfo](c, "/files", url.Values{}, "files")
+}
+
+// LoadNetworkFile loads a file using the HTTP client
+func LoadNetworkFile(client *Client) (disasm.File, error) {
+	return NewNetworkFile(client)
+}
+
+// LoadNetworkFileAt asks the server to load the file at path and opens it
+func LoadNetworkFileAt(client *Client, path string) (disasm.File, error) {
+	if err := client.LoadFile(path); err != nil {
+		return nil, err
+	}
+	files, err := client.GetFiles()
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	info := FileInfo{Path: path}
+	for _, file := range files {
+		if file.Path == path {
+			info = file
+			break
+		}
+	}
+	return openNetworkFile(client, info)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server error (status %d): %s", resp.StatusCode, body)
+// GRPCClient calls the gRPC API of a server started with -grpc-addr
+type GRPCClient struct {
+	conn   *grpc.ClientConn
+	client lensmpb.LensmServiceClient
+}
+
+// NewGRPCClient creates a client for the gRPC API at addr (format: host:port)
+// tlsConfig is used for the connection, nil connects without TLS
+func NewGRPCClient(addr string, tlsConfig *tls.Config) (*GRPCClient, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting: %w", err)
 	}
+	return &GRPCClient{conn: conn, client: lensmpb.NewLensmServiceClient(conn)}, nil
+}
 
-	var result struct {
-		Files []string `json:"files"`
+// Close closes the connection
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// grpcClientError converts the status of a call rejected by the server
+// to a ServerError like the ones of the HTTP client
+func grpcClientError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	var code int
+	switch st.Code() {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.Internal:
+		code = http.StatusInternalServerError
+	default:
+		// e.g. Unavailable when the server can't be reached
+		return err
 	}
+	return &ServerError{Code: code, Message: st.Message()}
+}
 
-	return result.Files, nil
+// LoadFile loads a binary file for disassembly
+func (c *GRPCClient) LoadFile(ctx context.Context, path string) error {
+	_, err := c.client.LoadFile(ctx, &lensmpb.LoadFileRequest{Path: path})
+	return grpcClientError(err)
 }
 
-// LoadNetworkFile loads a file using the HTTP client
-func LoadNetworkFile(serverURL string) (disasm.File, error) {
-	client := NewClient(serverURL)
-	return NewNetworkFile(client)
+// GetFiles retrieves the files loaded by the server
+func (c *GRPCClient) GetFiles(ctx context.Context) ([]FileInfo, error) {
+	resp, err := c.client.ListFiles(ctx, &lensmpb.ListFilesRequest{})
+	if err != nil {
+		return nil, grpcClientError(err)
+	}
+	files := make([]FileInfo, len(resp.GetFiles()))
+	for i, file := range resp.GetFiles() {
+		files[i] = FileInfo{Path: file.GetPath(), BuildID: file.GetBuildId()}
+	}
+	return files, nil
+}
+
+// GetFunctions retrieves the functions of a loaded file matching filter,
+// with code from source files matching source
+func (c *GRPCClient) GetFunctions(ctx context.Context, path, filter, source string) ([]FunctionInfo, error) {
+	resp, err := c.client.ListFunctions(ctx, &lensmpb.ListFunctionsRequest{File: path, Filter: filter, Source: source})
+	if err != nil {
+		return nil, grpcClientError(err)
+	}
+	return functionInfosFromProto(resp.GetFunctions()), nil
+}
+
+// GetFunctionCode disassembles a function with context lines of source
+func (c *GRPCClient) GetFunctionCode(ctx context.Context, path, name string, context int) (*disasm.Code, error) {
+	msg, err := c.client.DisassembleFunction(ctx, &lensmpb.DisassembleFunctionRequest{
+		File:    path,
+		Name:    name,
+		Context: proto.Int32(int32(context)),
+	})
+	if err != nil {
+		return nil, grpcClientError(err)
+	}
+	return codeFromProto(msg).toCode(), nil
+}
+
+// WatchFunctions calls update with the functions of a loaded file, and again
+// whenever the server reloaded the changed binary, until ctx is done
+func (c *GRPCClient) WatchFunctions(ctx context.Context, path string, update func(buildID string, funcs []FunctionInfo)) error {
+	stream, err := c.client.StreamFunctionUpdates(ctx, &lensmpb.StreamFunctionUpdatesRequest{File: path})
+	if err != nil {
+		return grpcClientError(err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return grpcClientError(err)
+		}
+		update(msg.GetBuildId(), functionInfosFromProto(msg.GetFunctions()))
+	}
+}
+
+// functionInfosFromProto converts the function messages
+func functionInfosFromProto(msgs []*lensmpb.FunctionInfo) []FunctionInfo {
+	funcs := make([]FunctionInfo, len(msgs))
+	for i, fn := range msgs {
+		funcs[i] = FunctionInfo{
+			Name:             fn.GetName(),
+			Kind:             fn.GetKind(),
+			Package:          fn.GetPackage(),
+			BuildConstraints: fn.GetBuildConstraints(),
+		}
+	}
+	return funcs
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const (
+	// prefetchCacheSize is the number of functions a NetworkFile keeps
+	prefetchCacheSize = 128
+	// maxPrefetches limits the concurrent prefetch requests
+	maxPrefetches = 3
+)
+
+// prefetchKey identifies the code of a function loaded with specific options
+type prefetchKey struct {
+	name string
+	opts disasm.Options
+}
+
+// pendingLoad is a prefetch in progress, done is closed after code is set
+type pendingLoad struct {
+	done chan struct{}
+	code *disasm.Code
+}
+
+// prefetcher loads the functions of a NetworkFile in the background
+type prefetcher struct {
+	mu sync.Mutex
+	// opts are the options of the last Load, used for prefetching
+	opts    disasm.Options
+	cache   *lru.Cache[prefetchKey, *disasm.Code]
+	pending map[prefetchKey]*pendingLoad
+	slots   chan struct{}
+}
+
+func newPrefetcher() *prefetcher {
+	// New only fails for non-positive sizes
+	cache, _ := lru.New[prefetchKey, *disasm.Code](prefetchCacheSize)
+	return &prefetcher{
+		cache:   cache,
+		pending: make(map[prefetchKey]*pendingLoad),
+		slots:   make(chan struct{}, maxPrefetches),
+	}
+}
+
+// Prefetch loads the named functions in the background with the options
+// of the last Load, so that selecting them doesn't wait for the server
+func (f *NetworkFile) Prefetch(names []string) {
+	if f.Reconnecting() {
+		return
+	}
+	f.mu.RLock()
+	funcMap := f.funcMap
+	f.mu.RUnlock()
+
+	p := f.prefetch
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, name := range names {
+		fn, ok := funcMap[name].(*NetworkFunc)
+		if !ok {
+			continue
+		}
+		key := prefetchKey{name: name, opts: p.opts}
+		if _, ok := p.pending[key]; ok || p.cache.Contains(key) {
+			continue
+		}
+
+		load := &pendingLoad{done: make(chan struct{})}
+		p.pending[key] = load
+		go func() {
+			p.slots <- struct{}{}
+			// use HTTP, a prefetch on the WebSocket would delay the selected function
+			code, err := f.client.GetFunctionCode(f.path, fn.name, key.opts.Context)
+			<-p.slots
+			if err != nil {
+				f.client.logger.Debug("error prefetching function", "path", f.path, "func", fn.name, "err", err)
+			}
+
+			p.mu.Lock()
+			delete(p.pending, key)
+			if code != nil {
+				p.cache.Add(key, code)
+			}
+			load.code = code
+			p.mu.Unlock()
+			close(load.done)
+		}()
+	}
+}
+
+// cached returns the prefetched code of the function, waiting for a prefetch in progress
+func (p *prefetcher) cached(name string, opts disasm.Options) *disasm.Code {
+	key := prefetchKey{name: name, opts: opts}
+	p.mu.Lock()
+	p.opts = opts
+	if code, ok := p.cache.Get(key); ok {
+		p.mu.Unlock()
+		return code
+	}
+	load, ok := p.pending[key]
+	p.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	<-load.done
+	return load.code
+}
+
+// add caches code loaded without prefetching
+func (p *prefetcher) add(name string, opts disasm.Options, code *disasm.Code) {
+	p.cache.Add(prefetchKey{name: name, opts: opts}, code)
+}
+
+// purge drops the cached code, e.g. when the file may have changed on the server
+func (p *prefetcher) purge() {
+	p.cache.Purge()
+}
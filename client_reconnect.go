@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// reconnectMinDelay is the delay before the first reconnect attempt
+	reconnectMinDelay = 500 * time.Millisecond
+	// reconnectMaxDelay caps the backoff between reconnect attempts
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// needsReconnect reports whether the file has to be loaded on the server
+// again after err, which is the case when the connection is refused, e.g.
+// while the server is restarting, or when the server unloaded the file,
+// e.g. after it wasn't accessed within -file-ttl
+func needsReconnect(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	// the HTTP API answers "File not found", the WebSocket "file not found"
+	var serverErr *ServerError
+	return errors.As(err, &serverErr) && strings.EqualFold(serverErr.Message, "file not found")
+}
+
+// Reconnecting reports whether the server is unreachable and the file
+// is waiting to be loaded again
+func (f *NetworkFile) Reconnecting() bool {
+	return f.reconnecting.Load()
+}
+
+// startReconnect starts reconnecting in the background unless it's in progress
+func (f *NetworkFile) startReconnect(err error) {
+	if !f.reconnecting.CompareAndSwap(false, true) {
+		return
+	}
+	f.client.logger.Warn("file unavailable on the server, reconnecting", "path", f.path, "err", err)
+	go f.reconnect()
+}
+
+// reconnect retries with a jittered exponential backoff until the server
+// loads the file again, then refreshes the functions and calls OnReconnect
+func (f *NetworkFile) reconnect() {
+	delay := reconnectMinDelay
+	for attempt := 1; ; attempt++ {
+		// wait between half and all of the delay, so that clients of a
+		// restarted server don't all reconnect at once
+		wait := delay/2 + rand.N(delay/2+1)
+		select {
+		case <-f.closed:
+			return
+		case <-time.After(wait):
+		}
+
+		err := f.reload()
+		if err == nil {
+			f.client.logger.Info("reconnected", "path", f.path, "attempts", attempt)
+			break
+		}
+		f.client.logger.Debug("reconnect failed", "path", f.path, "attempt", attempt, "err", err)
+		delay = min(delay*2, reconnectMaxDelay)
+	}
+
+	select {
+	case <-f.closed:
+		return
+	default:
+	}
+
+	f.prefetch.purge()
+	f.reconnecting.Store(false)
+	if f.OnReconnect != nil {
+		f.OnReconnect()
+	}
+}
+
+// reload registers the file on the server again and refetches its
+// functions, which may have changed if the binary was rebuilt
+func (f *NetworkFile) reload() error {
+	if err := f.client.LoadFile(f.path); err != nil {
+		return err
+	}
+	files, err := f.client.GetFiles()
+	if err != nil {
+		return err
+	}
+	funcs, funcMap, err := f.loadFuncs()
+	if err != nil {
+		return err
+	}
+	ws := f.dialWS()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	select {
+	case <-f.closed:
+		// closed while reloading
+		if ws != nil {
+			_ = ws.Close()
+		}
+		return nil
+	default:
+	}
+	for _, info := range files {
+		if info.Path == f.path {
+			f.buildID, f.goModPath = info.BuildID, info.GoModPath
+		}
+	}
+	if f.ws != nil {
+		_ = f.ws.Close()
+	}
+	f.ws = ws
+	f.funcs, f.funcMap = funcs, funcMap
+	return nil
+}
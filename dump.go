@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/export"
+	"github.com/gameformush/goasm-vscode/internal/goobj"
+)
+
+// stringList is a flag that may be given multiple times.
+type stringList []string
+
+func (list *stringList) String() string { return strings.Join(*list, ",") }
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+// Pattern combines the patterns into a single regexp matching any of them.
+func (list stringList) Pattern() string {
+	if len(list) <= 1 {
+		return strings.Join(list, "")
+	}
+	groups := make([]string, len(list))
+	for i, pattern := range list {
+		groups[i] = "(?:" + pattern + ")"
+	}
+	return strings.Join(groups, "|")
+}
+
+// newFilterFunc returns a filter that accepts a func name when it matches
+// any of the patterns or belongs to any of the packages. Without patterns
+// and packages it accepts all funcs.
+func newFilterFunc(patterns, packages []string) (func(name string) bool, error) {
+	rxs := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		rx, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -filter %q: %w", pattern, err)
+		}
+		rxs = append(rxs, rx)
+	}
+	inPackage := map[string]bool{}
+	for _, pkg := range packages {
+		inPackage[pkg] = true
+	}
+
+	if len(rxs) == 0 && len(inPackage) == 0 {
+		return func(name string) bool { return true }, nil
+	}
+	return func(name string) bool {
+		if inPackage[disasm.PackageOf(name)] {
+			return true
+		}
+		for _, rx := range rxs {
+			if rx.MatchString(name) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// loadDumpFile loads the executable of -dump-asm and -benchmark-load.
+func loadDumpFile(path, dwarfPath string) (disasm.File, error) {
+	file, err := goobj.LoadWithDWARF(path, dwarfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// benchmarkLoad loads each func accepted by filter n times and writes
+// a table of the latencies to stdout. Files that cache the code are
+// cleared before each load, so that every load disassembles.
+func benchmarkLoad(path, dwarfPath string, filter func(name string) bool, n, context int) error {
+	if n <= 0 {
+		return fmt.Errorf("-benchmark-load must be positive")
+	}
+	file, err := loadDumpFile(path, dwarfPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	cache, _ := file.(interface{ ClearCache() })
+
+	var funcs []disasm.Func
+	for _, fn := range file.Funcs() {
+		if filter(fn.Name()) {
+			funcs = append(funcs, fn)
+		}
+	}
+	if len(funcs) == 0 {
+		return fmt.Errorf("no function matches the filter")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "func\tinsts\tmin\tavg\tp95\tmax")
+
+	opts := disasm.Options{Context: context}
+	durations := make([]time.Duration, n)
+	for _, fn := range funcs {
+		var code *disasm.Code
+		var total time.Duration
+		for i := range durations {
+			if cache != nil {
+				cache.ClearCache()
+			}
+			start := time.Now()
+			code = fn.Load(opts)
+			durations[i] = time.Since(start)
+			total += durations[i]
+		}
+		if code == nil {
+			return fmt.Errorf("failed to load %s", fn.Name())
+		}
+
+		slices.Sort(durations)
+		p95 := durations[min((n*95+99)/100, n)-1]
+		fmt.Fprintf(w, "%s\t%d\t%v\t%v\t%v\t%v\n", fn.Name(), len(code.Insts),
+			durations[0].Round(time.Microsecond), (total / time.Duration(n)).Round(time.Microsecond),
+			p95.Round(time.Microsecond), durations[n-1].Round(time.Microsecond))
+	}
+	return nil
+}
+
+// dumpAsm writes the disassembly of the funcs accepted by filter to stdout,
+// either as text, as one JSON document per func or like `go tool objdump`.
+// The svg output is the call graph between the funcs instead, starting from
+// root when it's set.
+func dumpAsm(path, dwarfPath string, filter func(name string) bool, output, root string, context int) error {
+	if output != "text" && output != "json" && output != "objdump" && output != "svg" {
+		return fmt.Errorf("unknown -output %q", output)
+	}
+	if root != "" && output != "svg" {
+		return fmt.Errorf("-root requires -output svg")
+	}
+
+	file, err := loadDumpFile(path, dwarfPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if output == "svg" {
+		graph, err := callGraph(file, filter, root)
+		if err != nil {
+			return err
+		}
+		return export.ExportCallGraphSVG(graph, w, export.SVGExportOptions{})
+	}
+
+	opts := disasm.Options{Context: context}
+	printed := false
+	for _, fn := range file.Funcs() {
+		if !filter(fn.Name()) {
+			continue
+		}
+		code := fn.Load(opts)
+		if code == nil {
+			continue
+		}
+		if output == "json" {
+			if err := export.ExportJSON(code, w, true); err != nil {
+				return err
+			}
+			continue
+		}
+		if output == "objdump" {
+			if printed {
+				fmt.Fprintln(w)
+			}
+			printed = true
+			if err := export.ExportObjdump(code, w); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Fprintf(w, "TEXT %s(SB) %s\n", code.Name, code.File)
+		for _, ix := range code.Insts {
+			if ix.Text == "" {
+				continue
+			}
+			fmt.Fprintf(w, "  %s:%d\t0x%x\t%s\n", ix.File, ix.Line, ix.PC, ix.Text)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// callGraph builds the call graph between the funcs accepted by filter.
+// When root is set, only the funcs reachable from it are included,
+// in breadth-first order so that the nearest funcs are kept by MaxNodes.
+func callGraph(file disasm.File, filter func(name string) bool, root string) (*export.CallGraphResponse, error) {
+	funcs := map[string]disasm.Func{}
+	var queue []string
+	for _, fn := range file.Funcs() {
+		if filter(fn.Name()) || fn.Name() == root {
+			funcs[fn.Name()] = fn
+			if root == "" {
+				queue = append(queue, fn.Name())
+			}
+		}
+	}
+	if root != "" {
+		if _, ok := funcs[root]; !ok {
+			return nil, fmt.Errorf("function %q not found", root)
+		}
+		queue = []string{root}
+	}
+	seen := map[string]bool{}
+	for _, name := range queue {
+		seen[name] = true
+	}
+
+	graph := &export.CallGraphResponse{Root: root}
+	for i := 0; i < len(queue); i++ {
+		name := queue[i]
+		graph.Nodes = append(graph.Nodes, export.NewCallGraphNode(name))
+		code := funcs[name].Load(disasm.Options{})
+		if code == nil {
+			continue
+		}
+		called := map[string]bool{}
+		for _, ix := range code.Insts {
+			if ix.Call == "" || called[ix.Call] {
+				continue
+			}
+			if _, ok := funcs[ix.Call]; !ok {
+				continue
+			}
+			called[ix.Call] = true
+			graph.Edges = append(graph.Edges, export.CallGraphEdge{Caller: name, Callee: ix.Call})
+			if !seen[ix.Call] {
+				seen[ix.Call] = true
+				queue = append(queue, ix.Call)
+			}
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/export"
+)
+
+func TestExportObjdumpMatchesGoTool(t *testing.T) {
+	path := buildBench(t)
+	file, err := loadDumpFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	compared := 0
+	for _, fn := range file.Funcs() {
+		if fn.Name() != "main.main" && fn.Name() != "main.small" {
+			continue
+		}
+		compared++
+		code := fn.Load(disasm.Options{})
+		if code == nil {
+			t.Fatalf("failed to load %s", fn.Name())
+		}
+		var got bytes.Buffer
+		if err := export.ExportObjdump(code, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command("go", "tool", "objdump", "-s", "^"+strings.ReplaceAll(fn.Name(), ".", `\.`)+"$", path).Output()
+		if err != nil {
+			t.Fatalf("go tool objdump: %v", err)
+		}
+		// objdump prints the trailing padding that the loader drops
+		gotLines := strings.Split(strings.TrimSpace(got.String()), "\n")
+		wantLines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		for len(wantLines) > len(gotLines) && isObjdumpPadding(wantLines[len(wantLines)-1]) {
+			wantLines = wantLines[:len(wantLines)-1]
+		}
+		if len(gotLines) != len(wantLines) {
+			t.Fatalf("%s: %d lines, go tool objdump prints %d\n%s", fn.Name(), len(gotLines), len(wantLines), out)
+		}
+		for i := range wantLines {
+			if gotLines[i] != wantLines[i] {
+				t.Errorf("%s line %d:\n got %q\nwant %q", fn.Name(), i+1, gotLines[i], wantLines[i])
+			}
+		}
+	}
+	if compared != 2 {
+		t.Fatalf("compared %d funcs, want main.main and main.small", compared)
+	}
+}
+
+// isObjdumpPadding reports whether line of go tool objdump is an INT3 or
+// an undecodable instruction of the padding after a func.
+func isObjdumpPadding(line string) bool {
+	fields := strings.Split(strings.TrimRight(line, "\t"), "\t")
+	text := fields[len(fields)-1]
+	return text == "INT3" || text == "?"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoding selects the format of API responses
+type Encoding int
+
+const (
+	EncodingJSON Encoding = iota
+	// EncodingMsgpack is faster to encode and decode for large functions
+	EncodingMsgpack
+)
+
+const msgpackContentType = "application/msgpack"
+
+// ContentType returns the media type of the encoding
+func (e Encoding) ContentType() string {
+	if e == EncodingMsgpack {
+		return msgpackContentType
+	}
+	return "application/json"
+}
+
+// Encode writes v to w, msgpack uses the json struct tags for the field names
+func (e Encoding) Encode(w io.Writer, v any) error {
+	if e == EncodingMsgpack {
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		return enc.Encode(v)
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads v from r
+func (e Encoding) Decode(r io.Reader, v any) error {
+	if e == EncodingMsgpack {
+		dec := msgpack.NewDecoder(r)
+		dec.SetCustomStructTag("json")
+		return dec.Decode(v)
+	}
+	return json.NewDecoder(r).Decode(v)
+}
+
+// DecodeFields reads an object from r without decoding the values of its fields,
+// they are decoded separately with Unmarshal
+func (e Encoding) DecodeFields(r io.Reader) (map[string][]byte, error) {
+	fields := map[string][]byte{}
+	if e == EncodingMsgpack {
+		var raw map[string]msgpack.RawMessage
+		if err := e.Decode(r, &raw); err != nil {
+			return nil, err
+		}
+		for key, value := range raw {
+			fields[key] = value
+		}
+		return fields, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := e.Decode(r, &raw); err != nil {
+		return nil, err
+	}
+	for key, value := range raw {
+		fields[key] = value
+	}
+	return fields, nil
+}
+
+// Unmarshal decodes a value returned by DecodeFields
+func (e Encoding) Unmarshal(data []byte, v any) error {
+	return e.Decode(bytes.NewReader(data), v)
+}
+
+// responseEncoding returns the encoding of a response by its Content-Type,
+// servers without msgpack support always respond with JSON
+func responseEncoding(resp *http.Response) Encoding {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == msgpackContentType || mediaType == "application/x-msgpack" {
+		return EncodingMsgpack
+	}
+	return EncodingJSON
+}
+
+// negotiateEncoding picks the response encoding from the Accept header of r,
+// ok is false when none of the accepted types is supported
+func negotiateEncoding(r *http.Request) (enc Encoding, ok bool) {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return EncodingJSON, true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case msgpackContentType, "application/x-msgpack":
+			return EncodingMsgpack, true
+		case "application/json", "application/*", "*/*":
+			return EncodingJSON, true
+		}
+	}
+	return EncodingJSON, false
+}
+
+// writeResponse encodes v with the encoding accepted by the client
+// Clients accepting neither JSON nor msgpack get 406
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	enc, ok := negotiateEncoding(r)
+	if !ok {
+		writeError(w, "Supported response types are application/json and "+msgpackContentType, http.StatusNotAcceptable)
+		return
+	}
+	w.Header().Set("Content-Type", enc.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	enc.Encode(w, v)
+}
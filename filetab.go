@@ -0,0 +1,811 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/coverage"
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/f32color"
+	"github.com/gameformush/goasm-vscode/internal/goobj"
+	"github.com/gameformush/goasm-vscode/internal/pgo"
+	"github.com/gameformush/goasm-vscode/internal/themes"
+)
+
+// FileView selects what is shown next to the function list.
+type FileView int
+
+const (
+	ViewCode FileView = iota
+	ViewSymbols
+	ViewItabs
+	ViewPackageSizes
+)
+
+// FileTab contains the state of a single open binary.
+type FileTab struct {
+	Windows *Windows
+	Theme   *material.Theme
+	Context int
+	// WarnFrame marks funcs with a larger stack frame, 0 disables.
+	WarnFrame int
+	// Coverage is the line coverage of the tests, nil disables.
+	Coverage *coverage.Profile
+	// PGO is the profile used for profile-guided optimization, nil disables.
+	PGO *pgo.Profile
+	// stats measures the funcs of File for the func list.
+	stats *funcStats
+	// Invalidate, when set, redraws the window of the tab.
+	Invalidate func()
+	// MaxComplexity highlights funcs with a larger cyclomatic complexity, 0 disables.
+	MaxComplexity int
+
+	// ViewMode arranges the source and the assembly of the code view.
+	ViewMode ViewMode
+	// ViewModeChanged is called when the view mode is changed in the code view.
+	ViewModeChanged func(ViewMode)
+	// ShowTimings shows the instruction timings in the code view.
+	ShowTimings bool
+	// ShowTimingsChanged is called when the timings are toggled in the code view.
+	ShowTimingsChanged func(bool)
+	// ShowHex shows the instruction encodings in the code view.
+	ShowHex bool
+	// ShowHexChanged is called when the encodings are toggled in the code view.
+	ShowHexChanged func(bool)
+	// Syntax colors the instruction categories in the code view.
+	Syntax themes.SyntaxTheme
+	// FuncListWidth is the width of the function list, 0 uses the default.
+	FuncListWidth unit.Dp
+	// FuncListWidthChanged is called when the splitter has been dragged.
+	FuncListWidthChanged func(unit.Dp)
+	funcListSplitter     Splitter
+
+	// Path of the binary, empty for the default file of the server.
+	Path string
+
+	LoadError error
+	// loading is set while the binary is being (re)loaded.
+	loading atomic.Bool
+	// done is closed when the tab is closed.
+	done chan struct{}
+	// lastFileHash is the SHA-256 of the last loaded binary with -watch-hash.
+	lastFileHash [32]byte
+
+	// Currently loaded executable.
+	File  disasm.File
+	Funcs *FilterList[disasm.Func]
+	// restoreFunc is the func of the previous session,
+	// selected when the file is loaded when it still exists.
+	restoreFunc string
+
+	// Active code view.
+	Code CodeUI
+	// directives are the compiler directives of the func in Code.
+	directives struct {
+		code *disasm.Code
+		list []goobj.Directive
+	}
+
+	// View selects between the code view and the symbol browser.
+	View     FileView
+	viewTabs [4]widget.Clickable
+
+	Symbols       *SymbolBrowserUI
+	symbolsLoaded bool
+
+	Itabs       *ItabBrowserUI
+	itabsLoaded bool
+
+	PackageSizes       *PackageSizesUI
+	packageSizesLoaded bool
+
+	// TestsOnly restricts Funcs to tests, benchmarks and fuzz targets.
+	// It's only shown for binaries built with `go test -c`.
+	TestsOnly widget.Bool
+	isTest    bool
+	// CurrentOSOnly hides funcs with build constraints excluding runtime.GOOS.
+	CurrentOSOnly widget.Bool
+
+	// Other FileTab elements.
+	OpenInNew widget.Clickable
+	ExportPNG widget.Clickable
+
+	// Tab bar buttons.
+	selectTab widget.Clickable
+	closeTab  widget.Clickable
+}
+
+// NewFileTab creates a tab for the binary at path.
+func NewFileTab(windows *Windows, theme *material.Theme, path string, context int) *FileTab {
+	tab := &FileTab{
+		Windows: windows,
+		Theme:   theme,
+		Context: context,
+		Path:    path,
+		done:    make(chan struct{}),
+	}
+	tab.Funcs = NewFilterList[disasm.Func](theme)
+	tab.Symbols = NewSymbolBrowser(theme)
+	tab.Itabs = NewItabBrowser(theme)
+	tab.PackageSizes = NewPackageSizes(theme)
+	tab.Funcs.Marker = func(fn disasm.Func) string {
+		if frame, ok := fn.(interface{ FrameSize() int }); ok && tab.WarnFrame > 0 && frame.FrameSize() > tab.WarnFrame {
+			return "⚠ "
+		}
+		if export, ok := fn.(interface{ IsPluginExport() bool }); ok && export.IsPluginExport() {
+			return "🔌 "
+		}
+		if tab.Coverage != nil {
+			if stat, ok := tab.funcStat(fn); ok && stat.uncovered {
+				return "🔴 "
+			}
+		}
+		if tab.isTest && disasm.KindOf(fn.Name()).IsTest() {
+			// the bundled Go fonts have no emoji
+			return "◊ "
+		}
+		return ""
+	}
+	tab.Funcs.Badges = func(fn disasm.Func) []Badge {
+		kind := disasm.KindOf(fn.Name())
+		badges := []Badge{{Label: kindBadge(kind), Color: kindColor(kind)}}
+		for _, c := range fn.BuildConstraints() {
+			badges = append(badges, Badge{Label: "[" + c + "]", Color: constraintColor})
+		}
+		if tab.MaxComplexity > 0 {
+			if stat, ok := tab.funcStat(fn); ok && stat.complexity > tab.MaxComplexity {
+				badges = append(badges, Badge{Label: fmt.Sprintf("CC %d", stat.complexity), Color: complexityColor})
+			}
+		}
+		return badges
+	}
+	tab.Funcs.Include = func(fn disasm.Func) bool {
+		if tab.TestsOnly.Value && !disasm.KindOf(fn.Name()).IsTest() {
+			return false
+		}
+		return !tab.CurrentOSOnly.Value || disasm.MatchesGOOS(fn.BuildConstraints(), runtime.GOOS)
+	}
+	tab.Funcs.PackageLabel = func(pkg string) string {
+		if pkg != "main" || tab.File == nil {
+			return pkg
+		}
+		if mod := tab.File.GoModPath(); mod != "" {
+			return "main (" + mod + ")"
+		}
+		return pkg
+	}
+	tab.Funcs.SourceFuncs = func(pattern string) ([]disasm.Func, error) {
+		if tab.File == nil {
+			return nil, nil
+		}
+		return disasm.FuncsBySourceFile(tab.File, pattern)
+	}
+	tab.Funcs.InstCount = func(fn disasm.Func) (int, bool) {
+		stat, ok := tab.funcStat(fn)
+		return stat.insts, ok
+	}
+	tab.Funcs.Complexity = func(fn disasm.Func) (int, bool) {
+		stat, ok := tab.funcStat(fn)
+		return stat.complexity, ok
+	}
+	tab.Funcs.Prefetch = func(fns []disasm.Func) {
+		prefetcher, ok := tab.File.(interface{ Prefetch(names []string) })
+		if !ok {
+			return
+		}
+		names := make([]string, len(fns))
+		for i, fn := range fns {
+			names[i] = fn.Name()
+		}
+		prefetcher.Prefetch(names)
+	}
+	return tab
+}
+
+// kindBadge returns the badge text of a func kind, empty for regular funcs and tests.
+func kindBadge(kind disasm.FuncKind) string {
+	switch kind {
+	case disasm.KindClosure, disasm.KindMethod, disasm.KindInit, disasm.KindWrapper, disasm.KindStub:
+		return kind.String()
+	}
+	return ""
+}
+
+// kindColor returns the badge color of a func kind.
+func kindColor(kind disasm.FuncKind) color.NRGBA {
+	switch kind {
+	case disasm.KindClosure:
+		return f32color.HSLA(0.75, 0.5, 0.55, 1)
+	case disasm.KindMethod:
+		return f32color.HSLA(0.58, 0.6, 0.5, 1)
+	case disasm.KindInit:
+		return f32color.HSLA(0.3, 0.5, 0.45, 1)
+	case disasm.KindWrapper:
+		return f32color.HSLA(0.08, 0.7, 0.5, 1)
+	case disasm.KindStub:
+		return f32color.HSLA(0, 0, 0.55, 1)
+	}
+	return f32color.HSLA(0, 0, 0.7, 1)
+}
+
+// complexityColor highlights funcs above the -max-complexity limit.
+var complexityColor = color.NRGBA{R: 0xD0, G: 0x50, B: 0x10, A: 0xFF}
+
+// constraintColor is the badge color of build constraints.
+var constraintColor = f32color.HSLA(0.5, 0.4, 0.45, 1)
+
+// Label returns the text shown in the tab bar.
+func (tab *FileTab) Label() string {
+	if tab.Path == "" {
+		return "server"
+	}
+	return filepath.Base(tab.Path)
+}
+
+// Close releases the loaded file and stops reloading it.
+func (tab *FileTab) Close() {
+	close(tab.done)
+	if tab.File != nil {
+		_ = tab.File.Close()
+		tab.File = nil
+	}
+}
+
+func (tab *FileTab) SetFile(file disasm.File) {
+	// a NetworkFile is set again after reconnecting
+	if tab.File != nil && tab.File != file {
+		_ = tab.File.Close()
+	}
+	tab.File = file
+	tab.LoadError = nil
+	tab.symbolsLoaded = false
+	tab.itabsLoaded = false
+	tab.packageSizesLoaded = false
+	tab.stats = nil
+	tab.isTest = false
+	if test, ok := file.(interface{ IsTest() bool }); ok {
+		tab.isTest = test.IsTest()
+	}
+	if !tab.isTest {
+		tab.TestsOnly.Value = false
+	}
+	if remote, ok := file.(interface{ Coverage() *coverage.Profile }); ok && remote.Coverage() != nil {
+		tab.Coverage = remote.Coverage()
+	}
+	tab.Funcs.SetPackages(slices.Sorted(maps.Keys(file.PackageFuncs())))
+	tab.Funcs.SetItems(file.Funcs())
+	if name := tab.restoreFunc; name != "" {
+		tab.restoreFunc = ""
+		for _, fn := range file.Funcs() {
+			if fn.Name() == name {
+				tab.openFunc(fn)
+				return
+			}
+		}
+	}
+	if tab.Funcs.Selected != "" {
+		for _, fn := range file.Funcs() {
+			if fn.Name() == tab.Funcs.Selected {
+				tab.Code.Code = fn.Load(tab.loadOptions())
+			}
+		}
+	}
+}
+
+// funcStat returns the measurements of fn, false while they are computed
+// in the background, Invalidate is called when they are available.
+func (tab *FileTab) funcStat(fn disasm.Func) (funcStat, bool) {
+	if tab.stats == nil || tab.stats.coverage != tab.Coverage {
+		tab.stats = newFuncStats(tab.loadOptions(), tab.Coverage, tab.Invalidate, tab.done)
+	}
+	return tab.stats.get(fn)
+}
+
+func (tab *FileTab) loadOptions() disasm.Options {
+	return disasm.Options{Context: tab.Context}
+}
+
+func (tab *FileTab) Layout(gtx layout.Context) layout.Dimensions {
+	for tab.OpenInNew.Clicked(gtx) {
+		tab.openInNew(gtx)
+	}
+	for tab.ExportPNG.Clicked(gtx) {
+		tab.exportPNG(gtx)
+	}
+	if tab.TestsOnly.Update(gtx) || tab.CurrentOSOnly.Update(gtx) {
+		tab.Funcs.updateFiltered()
+	}
+	for i := range tab.viewTabs {
+		for tab.viewTabs[i].Clicked(gtx) {
+			tab.View = FileView(i)
+		}
+	}
+	if tab.View == ViewSymbols && !tab.symbolsLoaded && tab.File != nil {
+		tab.Symbols.SetSymbols(tab.File.SymbolTable())
+		tab.symbolsLoaded = true
+	}
+	if tab.View == ViewItabs && !tab.itabsLoaded && tab.File != nil {
+		tab.Itabs.SetItabs(goobj.ParseItabs(tab.File))
+		tab.itabsLoaded = true
+	}
+	if tab.View == ViewPackageSizes && !tab.packageSizesLoaded && tab.File != nil {
+		tab.PackageSizes.SetFile(tab.File)
+		tab.packageSizesLoaded = true
+	}
+
+	if tab.Funcs.Selected == "" {
+		tab.Funcs.SelectIndex(0)
+	}
+
+	if !tab.Code.Loaded() || tab.Code.Name != tab.Funcs.Selected {
+		selected := tab.Funcs.SelectedItem
+		if selected != nil {
+			tab.Code.Code = selected.Load(tab.loadOptions())
+		}
+	}
+
+	return layout.Flex{
+		Axis: layout.Horizontal,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints = layout.Exact(image.Point{
+				X: tab.funcListWidth(gtx),
+				Y: gtx.Constraints.Max.Y,
+			})
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: gtx.Constraints.Max}.Op())
+					return material.CheckBox(tab.Theme, &tab.CurrentOSOnly, "GOOS="+runtime.GOOS+" only").Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !tab.isTest {
+						return layout.Dimensions{}
+					}
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: gtx.Constraints.Max}.Op())
+					return material.CheckBox(tab.Theme, &tab.TestsOnly, "Test functions only").Layout(gtx)
+				}),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min = gtx.Constraints.Max
+					return tab.Funcs.Layout(tab.Theme, gtx)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			tab.funcListSplitter.Color = splitterColor
+			current := unit.Dp(float32(tab.funcListWidth(gtx)) / gtx.Metric.PxPerDp)
+			dims, released := tab.funcListSplitter.Layout(gtx, &tab.FuncListWidth, current)
+			if released && tab.FuncListWidthChanged != nil {
+				tab.FuncListWidthChanged(tab.FuncListWidth)
+			}
+			return dims
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(tab.layoutViewTabs),
+				layout.Rigid(HorizontalLine{Height: 1, Color: splitterColor}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					switch tab.View {
+					case ViewSymbols:
+						return tab.Symbols.Layout(tab.Theme, gtx, tab.tryOpen)
+					case ViewItabs:
+						return tab.Itabs.Layout(tab.Theme, gtx, func(gtx layout.Context, name string) {
+							tab.tryOpen(gtx, name)
+							tab.View = ViewCode
+						})
+					case ViewPackageSizes:
+						return tab.PackageSizes.Layout(tab.Theme, gtx, func(pkg string) {
+							tab.Funcs.SetPackageFilter([]string{pkg})
+							tab.View = ViewCode
+						})
+					}
+					return tab.layoutCode(gtx)
+				}),
+			)
+		}),
+	)
+}
+
+// layoutViewTabs draws the buttons for switching between views.
+func (tab *FileTab) layoutViewTabs(gtx layout.Context) layout.Dimensions {
+	children := make([]layout.FlexChild, 0, len(tab.viewTabs))
+	for i, label := range [...]string{ViewCode: "Code", ViewSymbols: "Symbols", ViewItabs: "Itabs", ViewPackageSizes: "Package sizes"} {
+		view := FileView(i)
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &tab.viewTabs[view], func(gtx layout.Context) layout.Dimensions {
+				txt := material.Body1(tab.Theme, label)
+				if tab.View == view {
+					txt.Font.Weight = font.Bold
+				}
+				return layout.Inset{Top: 4, Left: 8, Right: 8, Bottom: 4}.Layout(gtx, txt.Layout)
+			})
+		}))
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+}
+
+// directiveColors are the colors of the directive badges in the code header.
+var directiveColors = map[goobj.DirectiveKind]color.NRGBA{
+	goobj.DirNosplit:  {R: 0xD0, G: 0x30, B: 0x30, A: 0xFF},
+	goobj.DirNoescape: {R: 0x30, G: 0x70, B: 0xE0, A: 0xFF},
+	goobj.DirLinkname: {R: 0x90, G: 0x40, B: 0xC0, A: 0xFF},
+	goobj.DirInline:   {R: 0xB0, G: 0x80, B: 0x50, A: 0xFF},
+}
+
+// layoutDirectives draws badges like [nosplit] for the compiler
+// directives of the func, parsed from its source file.
+func (tab *FileTab) layoutDirectives(gtx layout.Context) layout.Dimensions {
+	if tab.directives.code != tab.Code.Code {
+		tab.directives.code = tab.Code.Code
+		tab.directives.list = goobj.ParseDirectives(tab.Code.Code.File, tab.Code.Code.Name)
+	}
+	if len(tab.directives.list) == 0 {
+		return layout.Dimensions{}
+	}
+
+	inset := layout.Inset{Top: 4, Left: 4, Right: 4, Bottom: 2}
+	children := make([]layout.FlexChild, 0, len(tab.directives.list))
+	for _, directive := range tab.directives.list {
+		label := directive.Kind.String()
+		if directive.Kind == goobj.DirLinkname {
+			// the target of "//go:linkname local target"
+			if fields := strings.Fields(directive.Value); len(fields) == 2 {
+				label += " " + fields[1]
+			}
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			txt := material.Body2(tab.Theme, "["+label+"]")
+			txt.Color = directiveColors[directive.Kind]
+			return inset.Layout(gtx, txt.Layout)
+		}))
+	}
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx, children...)
+}
+
+// reconnectingColor is the background of the banner shown while
+// the server of a NetworkFile is unreachable.
+var reconnectingColor = color.NRGBA{R: 0xFF, G: 0xA5, B: 0x00, A: 0x60}
+
+// reconnecting reports whether the file is waiting for its server to restart.
+func (tab *FileTab) reconnecting() bool {
+	file, ok := tab.File.(interface{ Reconnecting() bool })
+	return ok && file.Reconnecting()
+}
+
+// layoutCode draws the header and the disassembly of the selected func.
+func (tab *FileTab) layoutCode(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !tab.reconnecting() {
+				return layout.Dimensions{}
+			}
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			macro := op.Record(gtx.Ops)
+			dims := layout.UniformInset(4).Layout(gtx, material.Body2(tab.Theme, "Reconnecting...").Layout)
+			call := macro.Stop()
+			paint.FillShape(gtx.Ops, reconnectingColor, clip.Rect{Max: dims.Size}.Op())
+			call.Add(gtx.Ops)
+			return dims
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if tab.reconnecting() {
+				return layout.Dimensions{}
+			}
+			if tab.LoadError != nil {
+				return material.Body1(tab.Theme, errorMessage(tab.LoadError)).Layout(gtx)
+			}
+			if !tab.Code.Loaded() {
+				return layout.Dimensions{}
+			}
+			txt := material.Body1(tab.Theme, tab.Code.Code.Name)
+			txt.TextSize *= 1.2
+
+			inset := layout.Inset{Top: 4, Left: 4, Right: 4, Bottom: 2}
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(tab.layoutDirectives),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					cc := tab.Code.Code.CyclomaticComplexity
+					if cc == 0 {
+						return layout.Dimensions{}
+					}
+					txt := material.Body2(tab.Theme, fmt.Sprintf("CC: %d", cc))
+					if tab.MaxComplexity > 0 && cc > tab.MaxComplexity {
+						txt.Color = complexityColor
+					}
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					count := tab.Code.Code.BoundsCheckCount
+					if count == 0 {
+						return layout.Dimensions{}
+					}
+					label := fmt.Sprintf("%d bounds checks", count)
+					if count == 1 {
+						label = "1 bounds check"
+					}
+					txt := material.Body2(tab.Theme, label)
+					txt.Color = boundsCheckColor
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					count := tab.Code.Code.TailCallCount
+					if count == 0 {
+						return layout.Dimensions{}
+					}
+					label := fmt.Sprintf("%d tail calls", count)
+					if count == 1 {
+						label = "1 tail call"
+					}
+					txt := material.Body2(tab.Theme, label)
+					txt.Color = tailCallColor
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					count := tab.Code.Code.NilCheckCount
+					if count == 0 {
+						return layout.Dimensions{}
+					}
+					label := fmt.Sprintf("%d nil checks", count)
+					if count == 1 {
+						label = "1 nil check"
+					}
+					txt := material.Body2(tab.Theme, label)
+					txt.Color = nilCheckColor
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					bytes := tab.Code.Code.NOPBytes
+					if bytes == 0 {
+						return layout.Dimensions{}
+					}
+					label := fmt.Sprintf("%d bytes NOPs", bytes)
+					if bytes == 1 {
+						label = "1 byte NOP"
+					}
+					txt := material.Body2(tab.Theme, label)
+					txt.Color = nopColor
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if tab.Coverage == nil {
+						return layout.Dimensions{}
+					}
+					percent, ok := tab.Coverage.Percent(tab.Code.Code)
+					if !ok {
+						return layout.Dimensions{}
+					}
+					txt := material.Body2(tab.Theme, fmt.Sprintf("%.1f%% covered", percent))
+					txt.Color = coveredColor
+					if percent == 0 {
+						txt.Color = uncoveredColor
+					}
+					return inset.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if tab.PGO == nil {
+						return layout.Dimensions{}
+					}
+					percent, ok := tab.PGO.Percent(tab.Code.Code)
+					if !ok {
+						return layout.Dimensions{}
+					}
+					txt := material.Body2(tab.Theme, fmt.Sprintf("%.1f%% of PGO samples", percent))
+					txt.Color = heatColor(min(percent/100, 1))
+					txt.Color.A = 0xFF
+					return inset.Layout(gtx, txt.Layout)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if tab.LoadError != nil || !tab.Code.Loaded() {
+				return layout.Dimensions{}
+			}
+			txt := material.Body1(tab.Theme, "file: "+tab.Code.Code.File)
+			txt.Font.Style = font.Italic
+
+			inset := layout.Inset{Top: 2, Left: 4, Right: 4, Bottom: 4}
+			return inset.Layout(gtx, txt.Layout)
+		}),
+		layout.Rigid(HorizontalLine{Height: 1, Color: splitterColor}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if tab.LoadError != nil {
+				return layout.Dimensions{}
+			}
+
+			gtx.Constraints = layout.Exact(gtx.Constraints.Max)
+			return layout.Stack{
+				Alignment: layout.SE,
+			}.Layout(gtx,
+				layout.Expanded(func(gtx layout.Context) layout.Dimensions {
+					return CodeUIStyle{
+						CodeUI: &tab.Code,
+
+						TryOpen: tab.tryOpen,
+
+						Theme:       tab.Theme,
+						TextHeight:  tab.Theme.TextSize,
+						LineHeight:  tab.Theme.TextSize * 1.2,
+						ShowMinimap: true,
+						WarnFrame:   tab.WarnFrame,
+						Coverage:    tab.Coverage,
+						PGO:         tab.PGO,
+						Syntax:      tab.Syntax,
+
+						ShowTimings:        tab.ShowTimings,
+						ShowTimingsChanged: tab.ShowTimingsChanged,
+						ShowHex:            tab.ShowHex,
+						ShowHexChanged:     tab.ShowHexChanged,
+						Arch:               tab.arch(),
+
+						ViewMode:        tab.ViewMode,
+						ViewModeChanged: tab.ViewModeChanged,
+					}.Layout(gtx)
+				}),
+				layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							button := material.IconButton(tab.Theme, &tab.ExportPNG, ExportPNGIcon, "Export as PNG")
+							button.Size = 16
+							button.Inset = layout.UniformInset(12)
+							return layout.UniformInset(2).Layout(gtx, button.Layout)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							button := material.IconButton(tab.Theme, &tab.OpenInNew, OpenInNewIcon, "Open in separate window")
+							button.Size = 16
+							button.Inset = layout.UniformInset(12)
+							return layout.UniformInset(2).Layout(gtx, button.Layout)
+						}),
+					)
+				}),
+			)
+		}),
+	)
+}
+
+// minFuncListWidth and minCodeWidth limit the width of the function list.
+const (
+	minFuncListWidth = unit.Dp(100)
+	minCodeWidth     = unit.Dp(200)
+)
+
+// funcListWidth returns the width of the function list in pixels,
+// the default depends on the text size.
+func (tab *FileTab) funcListWidth(gtx layout.Context) int {
+	if tab.FuncListWidth == 0 {
+		return gtx.Metric.Sp(10 * 20)
+	}
+	maxWidth := max(unit.Dp(float32(gtx.Constraints.Max.X)/gtx.Metric.PxPerDp)-minCodeWidth, minFuncListWidth)
+	return gtx.Dp(min(max(tab.FuncListWidth, minFuncListWidth), maxWidth))
+}
+
+// arch returns the GOARCH of the loaded file, empty when it's unknown.
+func (tab *FileTab) arch() string {
+	if arch, ok := tab.File.(interface{ GOARCH() string }); ok {
+		return arch.GOARCH()
+	}
+	return ""
+}
+
+func (tab *FileTab) tryOpen(gtx layout.Context, call string) {
+	var fn disasm.Func
+	for _, target := range tab.File.Funcs() {
+		if target.Name() == call {
+			fn = target
+			break
+		}
+	}
+	if fn == nil {
+		return
+	}
+	tab.openFunc(fn)
+}
+
+// openFunc selects fn in the function list and shows its code.
+func (tab *FileTab) openFunc(fn disasm.Func) {
+	load := fn.Load(tab.loadOptions())
+	tab.Funcs.Selected = fn.Name()
+	if load != nil {
+		tab.Funcs.Selected = load.Name
+	}
+	tab.Funcs.SelectedItem = fn
+	tab.Funcs.List.Selected = -1
+	for i, fil := range tab.Funcs.Filtered {
+		if fil == fn {
+			tab.Funcs.List.Selected = i
+			tab.Funcs.List.ScrollTo(i)
+			break
+		}
+	}
+
+	tab.Code.Code = load
+
+	if tab.Funcs.Selected == "" {
+		tab.Funcs.SelectIndex(0)
+	}
+
+	tab.Code.ResetScroll()
+}
+
+func (tab *FileTab) openInNew(gtx layout.Context) {
+	state := tab.Code
+	state.minimap = nil
+	style := CodeUIStyle{
+		Theme:  tab.Theme,
+		CodeUI: &state,
+
+		TextHeight:  tab.Theme.TextSize,
+		LineHeight:  tab.Theme.TextSize * 14 / 12,
+		ShowMinimap: true,
+		Coverage:    tab.Coverage,
+		PGO:         tab.PGO,
+		Syntax:      tab.Syntax,
+		ShowTimings: tab.ShowTimings,
+		ShowHex:     tab.ShowHex,
+		Arch:        tab.arch(),
+		ViewMode:    tab.ViewMode,
+	}
+
+	size := gtx.Constraints.Max
+	size.X = int(float32(size.X) / gtx.Metric.PxPerDp)
+	size.Y = int(float32(size.Y) / gtx.Metric.PxPerDp)
+	tab.Windows.Open(WindowConfig{Title: tab.Code.Name, Size: size}, WidgetWindow(style.Layout))
+}
+
+// exportPNG renders the code view and asks where to save the image.
+func (tab *FileTab) exportPNG(gtx layout.Context) {
+	if !tab.Code.Loaded() {
+		return
+	}
+	style := CodeUIStyle{
+		Theme:  tab.Theme,
+		CodeUI: &tab.Code,
+
+		TextHeight:  tab.Theme.TextSize,
+		LineHeight:  tab.Theme.TextSize * 1.2,
+		Coverage:    tab.Coverage,
+		PGO:         tab.PGO,
+		Syntax:      tab.Syntax,
+		ShowTimings: tab.ShowTimings,
+		ShowHex:     tab.ShowHex,
+		Arch:        tab.arch(),
+		ViewMode:    tab.ViewMode,
+	}
+	data, err := style.RenderToPNG(gtx.Metric.PxPerDp)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to export %s: %w", tab.Code.Name, err))
+		return
+	}
+
+	name := strings.NewReplacer("/", "_", "*", "", "(", "", ")", "").Replace(tab.Code.Name) + ".png"
+	go func() {
+		path, err := saveFileDialog("Export as PNG", name)
+		if err == nil && path != "" {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		if err != nil {
+			log.Println(fmt.Errorf("failed to export %s: %w", name, err))
+		}
+	}()
+}
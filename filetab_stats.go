@@ -0,0 +1,115 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/gameformush/goasm-vscode/internal/coverage"
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// funcStat contains the measurements of a func shown in the func list.
+type funcStat struct {
+	// insts is the number of instructions.
+	insts int
+	// complexity is the cyclomatic complexity.
+	complexity int
+	// uncovered is set when the coverage has data for the func without covered lines.
+	uncovered bool
+}
+
+// funcStats measures the funcs of a file on a background goroutine,
+// since loading a func disassembles it or requests it from the server.
+// A new funcStats is created when the file changes.
+type funcStats struct {
+	options  disasm.Options
+	coverage *coverage.Profile
+	// changed is called when new measurements are available.
+	changed func()
+	// done stops the measurements when it's closed.
+	done <-chan struct{}
+
+	mu      sync.Mutex
+	byName  map[string]funcStat
+	queued  map[string]bool
+	queue   []disasm.Func
+	running bool
+}
+
+func newFuncStats(options disasm.Options, coverage *coverage.Profile, changed func(), done <-chan struct{}) *funcStats {
+	return &funcStats{
+		options:  options,
+		coverage: coverage,
+		changed:  changed,
+		done:     done,
+		byName:   map[string]funcStat{},
+		queued:   map[string]bool{},
+	}
+}
+
+// get returns the measurements of fn, or queues fn and returns false
+// when it hasn't been measured yet.
+func (stats *funcStats) get(fn disasm.Func) (funcStat, bool) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	if stat, ok := stats.byName[fn.Name()]; ok {
+		return stat, true
+	}
+	if !stats.queued[fn.Name()] {
+		stats.queued[fn.Name()] = true
+		stats.queue = append(stats.queue, fn)
+		if !stats.running {
+			stats.running = true
+			go stats.run()
+		}
+	}
+	return funcStat{}, false
+}
+
+// run measures the queued funcs, the most recently queued first,
+// since those are the rows that are visible now.
+func (stats *funcStats) run() {
+	for {
+		select {
+		case <-stats.done:
+			return
+		default:
+		}
+
+		stats.mu.Lock()
+		if len(stats.queue) == 0 {
+			stats.running = false
+			stats.mu.Unlock()
+			return
+		}
+		fn := stats.queue[len(stats.queue)-1]
+		stats.queue = stats.queue[:len(stats.queue)-1]
+		stats.mu.Unlock()
+
+		stat := stats.measure(fn)
+
+		stats.mu.Lock()
+		stats.byName[fn.Name()] = stat
+		stats.mu.Unlock()
+		if stats.changed != nil {
+			stats.changed()
+		}
+	}
+}
+
+// measure loads fn, a func that can't be loaded counts as empty.
+func (stats *funcStats) measure(fn disasm.Func) funcStat {
+	code := fn.Load(stats.options)
+	if code == nil {
+		return funcStat{}
+	}
+	stat := funcStat{
+		insts:      len(code.Insts),
+		complexity: code.CyclomaticComplexity,
+	}
+	if stats.coverage != nil {
+		percent, ok := stats.coverage.Percent(code)
+		stat.uncovered = ok && percent == 0
+	}
+	return stat
+}
@@ -1,32 +1,72 @@
 package main
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"image"
 	"image/color"
+	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"gioui.org/app"
-	"gioui.org/font"
 	"gioui.org/io/event"
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"github.com/gameformush/goasm-vscode/internal/coverage"
+	"github.com/gameformush/goasm-vscode/internal/delve"
 	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/export"
 	"github.com/gameformush/goasm-vscode/internal/goobj"
-	"github.com/gameformush/goasm-vscode/internal/wasmobj"
+	"github.com/gameformush/goasm-vscode/internal/pgo"
+	"github.com/gameformush/goasm-vscode/internal/recent"
+	"github.com/gameformush/goasm-vscode/internal/session"
+	"github.com/gameformush/goasm-vscode/internal/settings"
+	"github.com/gameformush/goasm-vscode/internal/themes"
 )
 
-var workInProgressWASM bool
-
 type FileUIConfig struct {
-	Path      string
-	Watch     bool
-	Context   int
-	ServerURL string // URL of the HTTP server (if using client mode)
+	Path          string
+	Watch         bool
+	Context       int
+	Filter        string            // initial function filter of the first tab
+	WatchHash     bool              // compare file hashes when watching, for coarse or unreliable ModTime
+	ServerURL     string            // URL of the HTTP server (if using client mode)
+	ClientCert    string            // certificate presented to the server, requires ClientKey
+	ClientKey     string            // private key of ClientCert
+	ServerCA      string            // CA certificates verifying the server, empty uses the system roots
+	WarnFrame     int               // frame size in bytes that triggers a warning, 0 disables
+	MaxComplexity int               // cyclomatic complexity that highlights funcs, 0 disables
+	MaxFuncs      int               // maximum funcs listed after filtering, 0 is unlimited
+	Coverage      *coverage.Profile // line coverage shown in the code view, nil disables
+	CoveragePath  string            // coverage profile uploaded to the server in client mode instead of Coverage
+	PGO           *pgo.Profile      // CPU profile shown as a heatmap in the code view, nil disables
+	DWARF         string            // separate debug info of Path, empty uses an adjacent .dSYM
+	Delve         string            // pid or address of a Delve session opened instead of Path
+	TextSize      unit.Sp           // default text size, restored by resetting the font size
+}
+
+// delveTabPrefix starts the path of the tab of FileUIConfig.Delve.
+const delveTabPrefix = "delve:"
+
+// configDir returns the directory for storing lensm settings.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lensm"), nil
 }
 
 type FileUI struct {
@@ -35,93 +75,430 @@ type FileUI struct {
 
 	Config FileUIConfig
 
-	LoadError error
-
-	// Currently loaded executable.
-	File  disasm.File
-	Funcs *FilterList[disasm.Func]
-
-	// Active code view.
-	Code CodeUI
+	// Tabs contains one tab per open binary.
+	Tabs   []*FileTab
+	Active int
+	TabBar TabBar
+
+	// addingTab shows the editor for entering the path of a new tab.
+	addingTab bool
+	newPath   widget.Editor
+
+	// Settings are the persisted preferences.
+	Settings settings.Settings
+	// Syntax is the theme of Settings.SyntaxTheme.
+	Syntax themes.SyntaxTheme
+
+	// Recent lists the recently opened binaries, shown with Ctrl+R.
+	Recent       recent.RecentFiles
+	showRecent   bool
+	recentButton widget.Clickable
+	recentItems  []widget.Clickable
+
+	// selectedFuncs are the selected funcs of the binaries by session.PathKey,
+	// restored when a binary is opened again.
+	selectedFuncs map[string]string
+	// pinnedFuncs are the pinned funcs of the binaries by session.PathKey.
+	pinnedFuncs map[string][]string
+
+	// gotoLine is the Ctrl+G dialog.
+	gotoLine gotoLine
+
+	// textSize adjusts the text size of all views.
+	textSize textSizeControls
+	// windowState is saved when the window is closed.
+	windowState windowState
+
+	// dragging is set while files are dragged over the window.
+	dragging  bool
+	dropError error
+
+	window *app.Window
+	// title is the current window title, see updateTitle.
+	title  string
+	loaded chan tabLoad
+}
 
-	// Other FileUI elements.
-	OpenInNew widget.Clickable
+// tabLoad is the result of (re)loading the binary of a tab.
+type tabLoad struct {
+	tab  *FileTab
+	file disasm.File
+	err  error
 }
 
 func NewExeUI(windows *Windows, theme *material.Theme) *FileUI {
 	ui := &FileUI{}
 	ui.Windows = windows
 	ui.Theme = theme
-	ui.Funcs = NewFilterList[disasm.Func](theme)
+	ui.loaded = make(chan tabLoad)
+	ui.newPath.SingleLine = true
+	ui.newPath.Submit = true
 	return ui
 }
 
-func (ui *FileUI) Run(w *app.Window) error {
-	var ops op.Ops
+// ActiveTab returns the selected tab, or nil when no binaries are open.
+func (ui *FileUI) ActiveTab() *FileTab {
+	if ui.Active < 0 || ui.Active >= len(ui.Tabs) {
+		return nil
+	}
+	return ui.Tabs[ui.Active]
+}
 
-	exited := make(chan struct{})
-	defer close(exited)
+// OpenTab opens the binary at path in a new tab, or selects the tab
+// when it's already open. It must be called from the UI goroutine.
+func (ui *FileUI) OpenTab(path string) *FileTab {
+	if ui.Config.ServerURL == "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+	}
+	for i, tab := range ui.Tabs {
+		if tab.Path == path {
+			ui.Active = i
+			return tab
+		}
+	}
 
-	fileLoaded := make(chan disasm.File, 1)
-	fileLoadError := make(chan error, 1)
+	tab := NewFileTab(ui.Windows, ui.Theme, path, ui.Config.Context)
+	tab.WarnFrame = ui.Config.WarnFrame
+	tab.MaxComplexity = ui.Config.MaxComplexity
+	tab.Invalidate = ui.invalidate
+	tab.Funcs.MaxItems = ui.Config.MaxFuncs
+	if ui.Config.ServerURL == "" {
+		tab.restoreFunc = ui.selectedFuncs[session.PathKey(path)]
+		tab.Funcs.SetPinned(ui.pinnedFuncs[session.PathKey(path)])
+		tab.Funcs.PinnedChanged = func(pinned []string) {
+			if ui.pinnedFuncs == nil {
+				ui.pinnedFuncs = map[string][]string{}
+			}
+			if len(pinned) == 0 {
+				delete(ui.pinnedFuncs, session.PathKey(path))
+			} else {
+				ui.pinnedFuncs[session.PathKey(path)] = pinned
+			}
+			ui.saveSession()
+		}
+	}
+	tab.Coverage = ui.Config.Coverage
+	tab.PGO = ui.Config.PGO
+	sortField, _ := ParseSortField(ui.Settings.FuncSort)
+	sortOrder := Ascending
+	if ui.Settings.FuncSortDescending {
+		sortOrder = Descending
+	}
+	tab.Funcs.SetSort(sortField, sortOrder)
+	tab.ViewMode, _ = ParseViewMode(ui.Settings.CodeViewMode)
+	tab.ViewModeChanged = func(mode ViewMode) {
+		ui.Settings.CodeViewMode = mode.String()
+		ui.saveSettings()
+		for _, tab := range ui.Tabs {
+			tab.ViewMode = mode
+		}
+	}
+	tab.Syntax = ui.Syntax
+	tab.FuncListWidth = unit.Dp(ui.Settings.FuncListWidth)
+	tab.FuncListWidthChanged = ui.setFuncListWidth
+	tab.ShowTimings = ui.Settings.ShowTimings
+	tab.ShowTimingsChanged = func(show bool) {
+		ui.Settings.ShowTimings = show
+		ui.saveSettings()
+		for _, tab := range ui.Tabs {
+			tab.ShowTimings = show
+		}
+	}
+	tab.ShowHex = ui.Settings.ShowHex
+	tab.ShowHexChanged = func(show bool) {
+		ui.Settings.ShowHex = show
+		ui.saveSettings()
+		for _, tab := range ui.Tabs {
+			tab.ShowHex = show
+		}
+	}
+	tab.Funcs.SortChanged = func(field SortField, order SortOrder) {
+		ui.Settings.FuncSort = field.String()
+		ui.Settings.FuncSortDescending = order == Descending
+		ui.saveSettings()
+		for _, other := range ui.Tabs {
+			if other != tab {
+				other.Funcs.SetSort(field, order)
+			}
+		}
+	}
+	ui.Tabs = append(ui.Tabs, tab)
+	ui.Active = len(ui.Tabs) - 1
+	go ui.watch(tab)
+	ui.saveSession()
+	return tab
+}
+
+// CloseTab closes the tab at index i.
+func (ui *FileUI) CloseTab(i int) {
+	ui.Tabs[i].Close()
+	ui.Tabs = slices.Delete(ui.Tabs, i, i+1)
+	if ui.Active > i || ui.Active >= len(ui.Tabs) {
+		ui.Active--
+	}
+	if ui.Active < 0 && len(ui.Tabs) > 0 {
+		ui.Active = 0
+	}
+	ui.saveSession()
+}
 
-	loadFinished := func(exe disasm.File, err error) {
-		if err == nil {
-			select {
-			case <-fileLoaded:
-			default:
+// watch loads the binary of the tab and reloads it on changes
+// when watching is enabled.
+func (ui *FileUI) watch(tab *FileTab) {
+	finished := func(file disasm.File, err error) {
+		if network, ok := file.(*NetworkFile); ok {
+			// refresh the function list of the tab with the reconnected file
+			network.OnReconnect = func() {
+				select {
+				case ui.loaded <- tabLoad{tab: tab, file: network}:
+				case <-tab.done:
+				}
 			}
-			fileLoaded <- exe
+		}
+		tab.loading.Store(false)
+		select {
+		case ui.loaded <- tabLoad{tab: tab, file: file, err: err}:
+		case <-tab.done:
+			if file != nil {
+				_ = file.Close()
+			}
+		}
+	}
+
+	tab.loading.Store(true)
+	ui.invalidate()
+
+	// Attach to a live process, its funcs are read once
+	if target, ok := strings.CutPrefix(tab.Path, delveTabPrefix); ok {
+		finished(delve.Open(target))
+		return
+	}
+
+	// If using client mode, load the file from the server
+	if ui.Config.ServerURL != "" {
+		var file disasm.File
+		var err error
+		if tab.Path == "" {
+			file, err = LoadNetworkFile(ui.newClient())
 		} else {
-			select {
-			case <-fileLoadError:
-			default:
+			file, err = LoadNetworkFileAt(ui.newClient(), tab.Path)
+		}
+		if network, ok := file.(*NetworkFile); ok && ui.Config.CoveragePath != "" {
+			// the server parses the profile and marks the covered instructions
+			if err := network.LoadCoverage(ui.Config.CoveragePath); err != nil {
+				log.Println(fmt.Errorf("failed to load coverage on the server: %w", err))
 			}
-			fileLoadError <- err
 		}
+		finished(file, err)
+		return // No file watching in client mode (server handles it)
 	}
 
-	go func() {
-		// If using server mode, load the file from the server
-		if ui.Config.ServerURL != "" {
-			file, err := LoadNetworkFile(ui.Config.ServerURL)
-			loadFinished(file, err)
-			return // No file watching in client mode (server handles it)
+	// Otherwise, load the file locally
+	var lastModTime time.Time
+	var changed <-chan struct{}
+	var ticks <-chan time.Time
+	if ui.Config.Watch {
+		if watcher, err := watchFile(tab.Path); err == nil {
+			defer watcher.Close()
+			changed = watcher.changed
+		} else {
+			// poll when notifications are unavailable, e.g. on network drives
+			tick := time.NewTicker(100 * time.Millisecond)
+			defer tick.Stop()
+			ticks = tick.C
 		}
+	}
+	for {
+		func() {
+			stat, err := os.Stat(tab.Path)
+			if err != nil {
+				finished(nil, err)
+				return
+			}
+			if stat.ModTime().Equal(lastModTime) && !ui.Config.WatchHash {
+				return
+			}
+			lastModTime = stat.ModTime()
 
-		// Otherwise, load the file locally
-		var lastModTime time.Time
-		tick := time.NewTicker(100 * time.Millisecond)
-		defer tick.Stop()
-		for {
-			func() {
-				stat, err := os.Stat(ui.Config.Path)
+			if ui.Config.WatchHash {
+				hash, err := fileHash(tab.Path)
 				if err != nil {
-					loadFinished(nil, err)
+					finished(nil, err)
 					return
 				}
-				if stat.ModTime().Equal(lastModTime) {
+				// also skips builds that only touched the file
+				if hash == tab.lastFileHash {
 					return
 				}
-				lastModTime = stat.ModTime()
+				tab.lastFileHash = hash
+			}
 
-				if workInProgressWASM {
-					loadFinished(wasmobj.Load(ui.Config.Path))
-				} else {
-					loadFinished(goobj.Load(ui.Config.Path))
+			tab.loading.Store(true)
+			ui.invalidate()
+			if export.IsJSONFile(tab.Path) {
+				finished(export.LoadJSON(tab.Path))
+			} else {
+				dwarfPath := ""
+				if tab.Path == ui.Config.Path {
+					dwarfPath = ui.Config.DWARF
 				}
-			}()
-
-			if !ui.Config.Watch {
-				break
+				finished(goobj.LoadWithDWARF(tab.Path, dwarfPath))
 			}
+		}()
 
-			select {
-			case <-tick.C:
-			case <-exited:
-				return
+		if !ui.Config.Watch {
+			break
+		}
+
+		select {
+		case <-ticks:
+		case <-changed:
+		case <-tab.done:
+			return
+		}
+	}
+}
+
+// fileHash returns the SHA-256 of the file at path without reading it into memory.
+func fileHash(path string) ([32]byte, error) {
+	var sum [32]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return sum, err
+	}
+	hash.Sum(sum[:0])
+	return sum, nil
+}
+
+func (ui *FileUI) invalidate() {
+	if ui.window != nil {
+		ui.window.Invalidate()
+	}
+}
+
+// restoreSession opens the tabs of the previous session followed by
+// the configured path, which becomes the active tab.
+func (ui *FileUI) restoreSession() {
+	if ui.Settings.TextSize != 0 {
+		ui.Theme.TextSize = clampTextSize(unit.Sp(ui.Settings.TextSize))
+	}
+	ui.Syntax = themes.Default()
+	if name := ui.Settings.SyntaxTheme; name != "" {
+		if syntax, err := themes.LoadSyntaxTheme(name); err == nil {
+			ui.Syntax = syntax
+		} else {
+			log.Println(fmt.Errorf("failed to load syntax theme: %w", err))
+		}
+	}
+
+	if ui.Config.ServerURL == "" {
+		if dir, err := configDir(); err == nil {
+			if err := ui.Recent.Load(dir); err != nil {
+				log.Println(fmt.Errorf("failed to load recent files: %w", err))
+			}
+			if s, err := session.Load(dir); err == nil {
+				ui.selectedFuncs = s.SelectedFuncs
+				ui.pinnedFuncs = s.PinnedFuncs
+				for _, path := range s.OpenFiles {
+					if _, err := os.Stat(path); err == nil {
+						ui.OpenTab(path)
+					}
+				}
+			} else {
+				log.Println(fmt.Errorf("failed to load session: %w", err))
 			}
 		}
+	}
+
+	path := ui.Config.Path
+	if ui.Config.Delve != "" {
+		path = delveTabPrefix + ui.Config.Delve
+	}
+	tab := ui.OpenTab(path)
+	tab.Funcs.SetFilter(ui.Config.Filter)
+}
+
+// newClient creates a client for the server, with the configured certificates.
+func (ui *FileUI) newClient() *Client {
+	client := NewClient(ui.Config.ServerURL)
+	if ui.Config.ServerCA != "" {
+		client.WithRootCA(ui.Config.ServerCA)
+	}
+	if ui.Config.ClientCert != "" {
+		client.WithClientCert(ui.Config.ClientCert, ui.Config.ClientKey)
+	}
+	return client
+}
+
+// saveSession stores the open tabs, only local files are persisted.
+func (ui *FileUI) saveSession() {
+	if ui.Config.ServerURL != "" {
+		return
+	}
+	dir, err := configDir()
+	if err != nil {
+		return
+	}
+
+	if ui.selectedFuncs == nil {
+		ui.selectedFuncs = map[string]string{}
+	}
+	s := &session.Session{SelectedFuncs: ui.selectedFuncs, PinnedFuncs: ui.pinnedFuncs}
+	for _, tab := range ui.Tabs {
+		if strings.HasPrefix(tab.Path, delveTabPrefix) {
+			continue
+		}
+		s.OpenFiles = append(s.OpenFiles, tab.Path)
+		if tab.Funcs.Selected != "" {
+			ui.selectedFuncs[session.PathKey(tab.Path)] = tab.Funcs.Selected
+		}
+	}
+	if err := s.Save(dir); err != nil {
+		log.Println(fmt.Errorf("failed to save session: %w", err))
+	}
+}
+
+// saveSettings stores the preferences.
+func (ui *FileUI) saveSettings() {
+	dir, err := configDir()
+	if err != nil {
+		return
+	}
+	if err := ui.Settings.Save(dir); err != nil {
+		log.Println(fmt.Errorf("failed to save settings: %w", err))
+	}
+}
+
+// addRecent remembers a successfully loaded local binary.
+func (ui *FileUI) addRecent(path string) {
+	if ui.Config.ServerURL != "" || strings.HasPrefix(path, delveTabPrefix) {
+		return
+	}
+	ui.Recent.Add(path)
+	if dir, err := configDir(); err == nil {
+		if err := ui.Recent.Save(dir); err != nil {
+			log.Println(fmt.Errorf("failed to save recent files: %w", err))
+		}
+	}
+}
+
+func (ui *FileUI) Run(w *app.Window) error {
+	var ops op.Ops
+
+	ui.window = w
+	ui.restoreSession()
+	defer func() {
+		for _, tab := range ui.Tabs {
+			tab.Close()
+		}
 	}()
 
 	events := make(chan event.Event)
@@ -140,20 +517,38 @@ func (ui *FileUI) Run(w *app.Window) error {
 
 	for {
 		select {
-		case err := <-fileLoadError:
-			ui.LoadError = err
-			w.Invalidate()
-		case file := <-fileLoaded:
-			ui.SetFile(file)
+		case load := <-ui.loaded:
+			if !slices.Contains(ui.Tabs, load.tab) {
+				// the tab was closed while loading
+				if load.file != nil {
+					_ = load.file.Close()
+				}
+				continue
+			}
+			if load.err != nil {
+				load.tab.LoadError = load.err
+			} else {
+				if network, ok := load.file.(*NetworkFile); ok && load.tab.Path == "" {
+					load.tab.Path = network.path
+				}
+				load.tab.SetFile(load.file)
+				ui.addRecent(load.tab.Path)
+			}
 			w.Invalidate()
 		case e := <-events:
 			switch e := e.(type) {
+			case app.ConfigEvent:
+				ui.windowState.mode = e.Config.Mode
+
 			case app.FrameEvent:
+				ui.updateWindow(e)
 				gtx := app.NewContext(&ops, e)
 				ui.Layout(gtx)
 				e.Frame(gtx.Ops)
 
 			case app.DestroyEvent:
+				ui.saveWindow()
+				ui.saveSession()
 				acks <- struct{}{}
 				return e.Err
 			}
@@ -162,38 +557,60 @@ func (ui *FileUI) Run(w *app.Window) error {
 	}
 }
 
-func (ui *FileUI) SetFile(file disasm.File) {
-	if ui.File != nil {
-		_ = ui.File.Close()
+func (ui *FileUI) Layout(gtx layout.Context) {
+	for i := 0; i < len(ui.Tabs); i++ {
+		tab := ui.Tabs[i]
+		for tab.selectTab.Clicked(gtx) {
+			ui.Active = i
+		}
+		if tab.closeTab.Clicked(gtx) {
+			ui.CloseTab(i)
+			i--
+		}
 	}
-	ui.File = file
-	ui.Funcs.SetItems(file.Funcs())
-	if ui.Funcs.Selected != "" {
-		for _, fn := range file.Funcs() {
-			if fn.Name() == ui.Funcs.Selected {
-				ui.Code.Code = fn.Load(ui.loadOptions())
-			}
+	for ui.TabBar.Add.Clicked(gtx) {
+		ui.addingTab = !ui.addingTab
+		if ui.addingTab {
+			gtx.Execute(key.FocusCmd{Tag: &ui.newPath})
+		}
+	}
+	for {
+		ev, ok := ui.newPath.Update(gtx)
+		if !ok {
+			break
+		}
+		if submit, ok := ev.(widget.SubmitEvent); ok && submit.Text != "" {
+			ui.OpenTab(submit.Text)
+			ui.newPath.SetText("")
+			ui.addingTab = false
 		}
 	}
-}
 
-func (ui *FileUI) loadOptions() disasm.Options {
-	return disasm.Options{Context: ui.Config.Context}
-}
+	ui.updateDrop(gtx)
+	ui.updateTitle()
+	ui.updateTextSize(gtx)
+	ui.updateGotoLine(gtx)
 
-func (ui *FileUI) Layout(gtx layout.Context) {
-	for ui.OpenInNew.Clicked(gtx) {
-		ui.openInNew(gtx)
+	for {
+		ev, ok := gtx.Event(key.Filter{Name: "R", Required: key.ModShortcut})
+		if !ok {
+			break
+		}
+		if ev, ok := ev.(key.Event); ok && ev.State == key.Press {
+			ui.showRecent = !ui.showRecent
+		}
 	}
-
-	if ui.Funcs.Selected == "" {
-		ui.Funcs.SelectIndex(0)
+	for ui.recentButton.Clicked(gtx) {
+		ui.showRecent = !ui.showRecent
 	}
-
-	if !ui.Code.Loaded() || ui.Code.Name != ui.Funcs.Selected {
-		selected := ui.Funcs.SelectedItem
-		if selected != nil {
-			ui.Code.Code = selected.Load(ui.loadOptions())
+	if len(ui.recentItems) < len(ui.Recent.Entries) {
+		ui.recentItems = make([]widget.Clickable, len(ui.Recent.Entries))
+	}
+	for i, entry := range ui.Recent.Entries {
+		for ui.recentItems[i].Clicked(gtx) {
+			ui.Config.Path = entry.Path
+			ui.OpenTab(entry.Path)
+			ui.showRecent = false
 		}
 	}
 
@@ -203,121 +620,138 @@ func (ui *FileUI) Layout(gtx layout.Context) {
 		bgColor = ui.Theme.Bg // Use theme background for dark mode
 	}
 	paint.Fill(gtx.Ops, bgColor)
+	event.Op(gtx.Ops, ui)
+
+	var toolbarHeight int
 
-	layout.Flex{
-		Axis: layout.Horizontal,
-	}.Layout(gtx,
+	layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			gtx.Constraints = layout.Exact(image.Point{
-				X: gtx.Metric.Sp(10 * 20),
-				Y: gtx.Constraints.Max.Y,
-			})
-			return ui.Funcs.Layout(ui.Theme, gtx)
-		}),
-		layout.Rigid(VerticalLine{Width: 1, Color: splitterColor}.Layout),
-		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					if ui.LoadError != nil {
-						return material.Body1(ui.Theme, ui.LoadError.Error()).Layout(gtx)
-					}
-					if !ui.Code.Loaded() {
-						return layout.Dimensions{}
+			dims := layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(ui.layoutTabBar),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					if !ui.addingTab {
+						return layout.Dimensions{Size: gtx.Constraints.Min}
 					}
-					txt := material.Body1(ui.Theme, ui.Code.Code.Name)
-					txt.TextSize *= 1.2
-
-					inset := layout.Inset{Top: 4, Left: 4, Right: 4, Bottom: 2}
-					return inset.Layout(gtx, txt.Layout)
+					return FocusBorder(ui.Theme, gtx.Focused(&ui.newPath)).Layout(gtx,
+						material.Editor(ui.Theme, &ui.newPath, "Path to binary or exported .json assembly, press Enter to open").Layout)
 				}),
+				layout.Rigid(ui.layoutTextSize),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					if ui.LoadError != nil || !ui.Code.Loaded() {
+					if ui.Config.ServerURL != "" {
 						return layout.Dimensions{}
 					}
-					txt := material.Body1(ui.Theme, "file: "+ui.Code.Code.File)
-					txt.Font.Style = font.Italic
-
-					inset := layout.Inset{Top: 2, Left: 4, Right: 4, Bottom: 4}
-					return inset.Layout(gtx, txt.Layout)
-				}),
-				layout.Rigid(HorizontalLine{Height: 1, Color: splitterColor}.Layout),
-				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-					if ui.LoadError != nil {
-						return layout.Dimensions{}
-					}
-
-					gtx.Constraints = layout.Exact(gtx.Constraints.Max)
-					return layout.Stack{
-						Alignment: layout.SE,
-					}.Layout(gtx,
-						layout.Expanded(func(gtx layout.Context) layout.Dimensions {
-							return CodeUIStyle{
-								CodeUI: &ui.Code,
-
-								TryOpen: ui.tryOpen,
-
-								Theme:      ui.Theme,
-								TextHeight: ui.Theme.TextSize,
-								LineHeight: ui.Theme.TextSize * 1.2,
-							}.Layout(gtx)
-						}),
-						layout.Stacked(func(gtx layout.Context) layout.Dimensions {
-							button := material.IconButton(ui.Theme, &ui.OpenInNew, OpenInNewIcon, "Open in separate window")
-							button.Size = 16
-							button.Inset = layout.UniformInset(12)
-							return layout.UniformInset(2).Layout(gtx, button.Layout)
-						}),
-					)
+					return material.Clickable(gtx, &ui.recentButton, func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Top: 4, Left: 8, Right: 8, Bottom: 4}.Layout(gtx,
+							material.Body1(ui.Theme, "Recent ▾").Layout)
+					})
 				}),
 			)
+			toolbarHeight = dims.Size.Y
+			return dims
+		}),
+		layout.Rigid(HorizontalLine{Height: 1, Color: splitterColor}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if ui.dropError == nil {
+				return layout.Dimensions{}
+			}
+			return layout.UniformInset(4).Layout(gtx, material.Body1(ui.Theme, ui.dropError.Error()).Layout)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			tab := ui.ActiveTab()
+			if tab == nil {
+				return layout.Center.Layout(gtx, material.Body1(ui.Theme, "Open a binary with +").Layout)
+			}
+			return tab.Layout(gtx)
 		}),
 	)
-}
 
-func (ui *FileUI) tryOpen(gtx layout.Context, call string) {
-	var fn disasm.Func
-	for _, target := range ui.File.Funcs() {
-		if target.Name() == call {
-			fn = target
-			break
-		}
+	if ui.showRecent {
+		ui.layoutRecent(gtx, toolbarHeight)
 	}
-	if fn == nil {
-		return
+	if ui.gotoLine.visible {
+		ui.layoutGotoLine(gtx, toolbarHeight)
 	}
-
-	load := fn.Load(ui.loadOptions())
-	ui.Funcs.Selected = load.Name
-	ui.Funcs.SelectedItem = fn
-	ui.Funcs.List.Selected = -1
-	for i, fil := range ui.Funcs.Filtered {
-		if fil == fn {
-			ui.Funcs.List.Selected = i
-			break
-		}
+	if ui.dragging {
+		ui.layoutDropTarget(gtx)
 	}
+}
 
-	ui.Code.Code = load
-
-	if ui.Funcs.Selected == "" {
-		ui.Funcs.SelectIndex(0)
+// layoutRecent draws the recent files dropdown in the top right corner.
+func (ui *FileUI) layoutRecent(gtx layout.Context, top int) {
+	width := min(gtx.Dp(480), gtx.Constraints.Max.X)
+	gtx.Constraints = layout.Constraints{
+		Min: image.Pt(width, 0),
+		Max: image.Pt(width, gtx.Constraints.Max.Y-top),
 	}
 
-	ui.Code.ResetScroll()
+	macro := op.Record(gtx.Ops)
+	dims := layout.UniformInset(4).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if len(ui.Recent.Entries) == 0 {
+			return material.Body1(ui.Theme, "No recent files").Layout(gtx)
+		}
+		children := make([]layout.FlexChild, 0, len(ui.Recent.Entries))
+		for i, entry := range ui.Recent.Entries {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return material.Clickable(gtx, &ui.recentItems[i], func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: 2, Left: 4, Right: 4, Bottom: 2}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(material.Body1(ui.Theme, entry.Path).Layout),
+							layout.Rigid(material.Caption(ui.Theme, entry.Opened.Format("2006-01-02 15:04")).Layout),
+						)
+					})
+				})
+			}))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+	call := macro.Stop()
+
+	defer op.Offset(image.Pt(gtx.Constraints.Max.X-width, top)).Push(gtx.Ops).Pop()
+	paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: dims.Size}.Op())
+	call.Add(gtx.Ops)
 }
 
-func (ui *FileUI) openInNew(gtx layout.Context) {
-	state := ui.Code
-	style := CodeUIStyle{
-		Theme:  ui.Theme,
-		CodeUI: &state,
+// updateTitle shows the active binary, its build ID and the loaded slice
+// of universal binaries in the window title.
+func (ui *FileUI) updateTitle() {
+	title := "lensm"
+	if ui.Active < len(ui.Tabs) {
+		tab := ui.Tabs[ui.Active]
+		title += " - " + tab.Label()
+		if tab.File != nil {
+			if id := tab.File.BuildID(); id != "" {
+				title += " (" + shortBuildID(id) + ")"
+			}
+			if sliced, ok := tab.File.(disasm.SlicedFile); ok && len(sliced.Slices()) > 1 {
+				title += " [" + tab.arch() + "]"
+			}
+		}
+	}
+	if title != ui.title && ui.window != nil {
+		ui.title = title
+		ui.window.Option(app.Title(title))
+	}
+}
 
-		TextHeight: ui.Theme.TextSize,
-		LineHeight: ui.Theme.TextSize * 14 / 12,
+// shortBuildID truncates a build ID to 8 characters, like a short commit hash.
+func shortBuildID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
 	}
+	return id
+}
 
-	size := gtx.Constraints.Max
-	size.X = int(float32(size.X) / gtx.Metric.PxPerDp)
-	size.Y = int(float32(size.Y) / gtx.Metric.PxPerDp)
-	ui.Windows.Open(ui.Code.Name, size, WidgetWindow(style.Layout))
+// layoutTabBar draws a tab for each open binary.
+func (ui *FileUI) layoutTabBar(gtx layout.Context) layout.Dimensions {
+	tabs := make([]Tab, len(ui.Tabs))
+	for i, tab := range ui.Tabs {
+		tabs[i] = Tab{
+			Label:  tab.Label(),
+			Dirty:  tab.loading.Load(),
+			Select: &tab.selectTab,
+			Close:  &tab.closeTab,
+		}
+	}
+	return ui.TabBar.Layout(ui.Theme, gtx, tabs, ui.Active)
 }
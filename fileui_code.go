@@ -1,10 +1,13 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"image"
 	"image/color"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"gioui.org/f32"
@@ -19,8 +22,12 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 
+	"github.com/gameformush/goasm-vscode/internal/coverage"
 	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/disasm/timing"
 	"github.com/gameformush/goasm-vscode/internal/f32color"
+	"github.com/gameformush/goasm-vscode/internal/pgo"
+	"github.com/gameformush/goasm-vscode/internal/themes"
 )
 
 type CodeUI struct {
@@ -37,8 +44,125 @@ type CodeUI struct {
 		gesture gesture.Scroll
 		bar     widget.Scrollbar
 	}
+	histogram struct {
+		toggle  widget.Clickable
+		visible bool
+
+		code   *disasm.Code
+		counts []disasm.MnemonicCount
+	}
+	loops struct {
+		toggle  widget.Clickable
+		visible bool
+
+		code   *disasm.Code
+		info   []disasm.LoopInfo
+		depths []int
+	}
+	bounds struct {
+		code   *disasm.Code
+		checks map[int]bool
+	}
+	tailCalls struct {
+		code  *disasm.Code
+		lines []int
+	}
+	nilChecks struct {
+		code  *disasm.Code
+		lines []int
+	}
+	race struct {
+		code         *disasm.Code
+		instrumented bool
+		lines        []int
+	}
+	goroutines struct {
+		code  *disasm.Code
+		lines []int
+	}
+	// alignment caches the instructions of code aligned by the linker,
+	// see disasm.AlignmentAnnotations.
+	alignment struct {
+		code  *disasm.Code
+		insts map[int]int
+	}
+	// heat caches the PGO weights of the instructions of code.
+	heat struct {
+		code    *disasm.Code
+		profile *pgo.Profile
+		weights []float64
+	}
+	safepoints struct {
+		toggle  widget.Clickable
+		visible bool
+
+		code  *disasm.Code
+		lines []int
+	}
+	defers struct {
+		toggle  widget.Clickable
+		visible bool
+
+		code  *disasm.Code
+		sites []deferLines
+	}
+	timings struct {
+		toggle widget.Clickable
+
+		code         *disasm.Code
+		table        *timing.Table
+		labels       []string
+		criticalPath float64
+	}
+	hex struct {
+		toggle widget.Clickable
+	}
+	interleaved struct {
+		code     *disasm.Code
+		rows     []interleavedRow
+		instRows []int
+	}
+	// nops folds the groups of NOPs of code, the folded code is laid out
+	// instead of code and headers are its rows of the groups.
+	nops struct {
+		code     *disasm.Code
+		expanded map[uint64]bool
+		folded   *disasm.Code
+		headers  map[int]disasm.NOPGroup
+	}
+	// wrap caches the instructions of code wrapped at width characters,
+	// see CodeUIStyle.InstructionMaxWidth.
+	wrap struct {
+		code  *disasm.Code
+		width int
+		hex   bool
+		// lines are the lines of each instruction, nil when it fits.
+		lines [][]string
+		// rows are the first row of each instruction followed by
+		// the number of rows, nil when no instruction is wrapped.
+		rows []int
+	}
+	viewMode widget.Clickable
+	minimap  *minimapCache
+	// scrollTo is the source line of a pending ScrollToSource.
+	scrollTo struct {
+		code *disasm.Code
+		file string
+		line int
+	}
+	// frameWarning is the dismissed stack frame warning.
+	frameWarning struct {
+		dismiss   widget.Clickable
+		dismissed *disasm.Code
+	}
 
 	mousePosition f32.Point
+	// hoveredInstIndex is the instruction under the mouse, -1 when none.
+	hoveredInstIndex int
+	// hoveredSourceLine is the source line under the mouse or of the
+	// hovered instruction in hoveredSourceFile, -1 when none.
+	hoveredSourceLine int
+	hoveredSourceFile string
 }
 
 func (ui *CodeUI) Loaded() bool {
@@ -50,6 +174,43 @@ func (ui *CodeUI) ResetScroll() {
 	ui.src.scroll = 100000
 }
 
+// ScrollToSource centers the first instruction of the code compiled
+// from line of the source file, when the code is laid out next.
+func (ui *CodeUI) ScrollToSource(file string, line int) {
+	ui.scrollTo.code = ui.Code
+	ui.scrollTo.file = file
+	ui.scrollTo.line = line
+}
+
+// applyScrollTo scrolls to the instruction of a pending ScrollToSource.
+func (ui CodeUIStyle) applyScrollTo(gtx layout.Context) {
+	if ui.scrollTo.code == nil {
+		return
+	}
+	if ui.scrollTo.code != ui.nops.code {
+		ui.scrollTo.code = nil
+		return
+	}
+	// the rows of the interleaved view are built by its layout
+	if ui.ViewMode == ViewModeInterleaved && ui.interleaved.code != ui.Code {
+		gtx.Execute(op.InvalidateCmd{})
+		return
+	}
+	ui.scrollTo.code = nil
+
+	row := disasm.FindInstByLine(ui.Code, ui.scrollTo.file, ui.scrollTo.line)
+	if row < 0 {
+		return
+	}
+	if ui.ViewMode == ViewModeInterleaved && InRange(row, len(ui.interleaved.instRows)) {
+		row = ui.interleaved.instRows[row]
+	} else {
+		row = ui.instRow(row)
+	}
+	ui.asm.anim.Stop()
+	ui.asm.scroll = float32(gtx.Constraints.Max.Y/2 - row*gtx.Metric.Sp(ui.LineHeight))
+}
+
 type CodeUIStyle struct {
 	*CodeUI
 
@@ -58,14 +219,854 @@ type CodeUIStyle struct {
 
 	TextHeight unit.Sp
 	LineHeight unit.Sp
+	// InstructionMaxWidth wraps the instructions wider than it to continuation
+	// lines indented by the mnemonic, 0 doesn't wrap.
+	InstructionMaxWidth unit.Dp
+
+	// HistogramWidth is the width of the mnemonic histogram panel.
+	HistogramWidth unit.Dp
+
+	// ShowMinimap shows an overview of the instructions on the right side.
+	ShowMinimap  bool
+	MinimapWidth unit.Dp
+
+	// WarnFrame shows a warning banner for larger stack frames, 0 disables.
+	WarnFrame int
+	// Coverage marks the covered source lines, nil disables.
+	Coverage *coverage.Profile
+	// PGO tints the instructions by their weight in the profile, nil disables.
+	PGO *pgo.Profile
+	// Syntax colors the instruction categories.
+	Syntax themes.SyntaxTheme
+
+	// ShowTimings annotates the instructions with their latency and throughput
+	// and shows the critical path, see timing.Table.
+	ShowTimings bool
+	// ShowTimingsChanged is called when the toolbar toggle is clicked,
+	// the toggle is hidden when it's nil.
+	ShowTimingsChanged func(bool)
+	// ShowHex prefixes the instructions with their encoding, see hexColumn.
+	ShowHex bool
+	// ShowHexChanged is called when the toolbar toggle is clicked,
+	// the toggle is hidden when it's nil.
+	ShowHexChanged func(bool)
+	// Arch is the GOARCH of the code, which selects the timing table.
+	Arch string
+
+	// ViewMode arranges the source and the assembly.
+	ViewMode ViewMode
+	// ViewModeChanged is called when the toolbar toggle is clicked,
+	// the toggle is hidden when it's nil.
+	ViewModeChanged func(ViewMode)
 }
 
+// histogramTopN is the number of mnemonics shown in the histogram.
+const histogramTopN = 10
+
 func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	gtx.Constraints = layout.Exact(gtx.Constraints.Max)
 	if ui.Code == nil {
 		return layout.Dimensions{Size: gtx.Constraints.Max}
 	}
+	code := ui.Code
+	ui.Code = ui.foldNOPs(code)
+	defer func() { ui.Code = code }()
+
+	for ui.histogram.toggle.Clicked(gtx) {
+		ui.histogram.visible = !ui.histogram.visible
+	}
+	for ui.loops.toggle.Clicked(gtx) {
+		ui.loops.visible = !ui.loops.visible
+	}
+	if ui.loops.visible && ui.loops.code != ui.Code {
+		ui.loops.code = ui.Code
+		ui.loops.info = disasm.DetectLoops(ui.Code)
+		ui.loops.depths = disasm.LoopDepths(ui.loops.info, len(ui.Code.Insts))
+	}
+	for ui.viewMode.Clicked(gtx) {
+		if ui.ViewModeChanged != nil {
+			ui.ViewModeChanged(ui.ViewMode.Next())
+		}
+	}
+	for ui.safepoints.toggle.Clicked(gtx) {
+		ui.safepoints.visible = !ui.safepoints.visible
+	}
+	if ui.safepoints.visible && ui.safepoints.code != ui.Code {
+		ui.safepoints.code = ui.Code
+		ui.safepoints.lines = safepointLines(ui.Code)
+	}
+	for ui.defers.toggle.Clicked(gtx) {
+		ui.defers.visible = !ui.defers.visible
+	}
+	for ui.timings.toggle.Clicked(gtx) {
+		if ui.ShowTimingsChanged != nil {
+			ui.ShowTimingsChanged(!ui.ShowTimings)
+		}
+	}
+	if ui.ShowTimings && ui.timings.code != ui.Code {
+		ui.updateTimings()
+	}
+	for ui.hex.toggle.Clicked(gtx) {
+		if ui.ShowHexChanged != nil {
+			ui.ShowHexChanged(!ui.ShowHex)
+		}
+	}
+	if ui.defers.visible && ui.defers.code != ui.Code {
+		ui.defers.code = ui.Code
+		ui.defers.sites = deferSiteLines(ui.Code)
+	}
+	ui.updateWrap(gtx)
+
+	size := gtx.Constraints.Max
+	panelWidth := 0
+	if ui.histogram.visible {
+		width := ui.HistogramWidth
+		if width <= 0 {
+			width = 160
+		}
+		panelWidth = gtx.Dp(width)
+	}
+	minimapWidth := 0
+	if ui.ShowMinimap {
+		width := ui.MinimapWidth
+		if width <= 0 {
+			width = 60
+		}
+		minimapWidth = gtx.Dp(width)
+	}
+	codeWidth := size.X - panelWidth - minimapWidth
+
+	if minimapWidth > 0 {
+		stack := op.Offset(image.Pt(codeWidth, 0)).Push(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(minimapWidth, size.Y))
+		ui.layoutMinimap(gtx, size.Y)
+		stack.Pop()
+	}
+
+	if ui.race.code != ui.Code {
+		ui.race.code = ui.Code
+		ui.race.instrumented = disasm.IsRaceInstrumented(ui.Code)
+		ui.race.lines = raceLines(ui.Code)
+	}
+	if ui.goroutines.code != ui.Code {
+		ui.goroutines.code = ui.Code
+		ui.goroutines.lines = goroutineLines(ui.Code)
+	}
+	if ui.alignment.code != ui.Code {
+		ui.alignment.code = ui.Code
+		ui.alignment.insts = disasm.AlignmentAnnotations(ui.Code)
+	}
+	if ui.PGO != nil && (ui.heat.code != ui.Code || ui.heat.profile != ui.PGO) {
+		ui.heat.code, ui.heat.profile = ui.Code, ui.PGO
+		ui.heat.weights = ui.PGO.InstWeights(ui.Code)
+	}
+
+	bannerHeight := 0
+	banner := func(background color.NRGBA, w layout.Widget) {
+		macro := op.Record(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(codeWidth, 0))
+		gtx.Constraints.Max.Y = size.Y
+		dims := w(gtx)
+		call := macro.Stop()
+
+		stack := op.Offset(image.Pt(0, bannerHeight)).Push(gtx.Ops)
+		paint.FillShape(gtx.Ops, background, clip.Rect{Max: dims.Size}.Op())
+		call.Add(gtx.Ops)
+		stack.Pop()
+		bannerHeight += dims.Size.Y
+	}
+	if ui.showFrameWarning(gtx) {
+		banner(frameWarningColor, ui.layoutFrameWarning)
+	}
+	if ui.ShowTimings && ui.timings.table != nil {
+		banner(secondaryBackground, func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			msg := fmt.Sprintf("Critical path: %s cycles (%s, L: latency, T: reciprocal throughput)",
+				formatCycles(ui.timings.criticalPath), ui.timings.table.Model)
+			return layout.UniformInset(4).Layout(gtx, material.Body2(ui.Theme, msg).Layout)
+		})
+	}
+	if ui.race.instrumented {
+		banner(raceBannerColor, func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			msg := fmt.Sprintf("Race detector enabled, %d instrumented accesses", len(ui.race.lines))
+			return layout.UniformInset(4).Layout(gtx, material.Body2(ui.Theme, msg).Layout)
+		})
+	}
+
+	{
+		stack := op.Offset(image.Pt(0, bannerHeight)).Push(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(codeWidth, size.Y-bannerHeight))
+		ui.applyScrollTo(gtx)
+		if ui.ViewMode == ViewModeInterleaved {
+			ui.layoutInterleaved(gtx)
+		} else {
+			ui.layoutCode(gtx)
+		}
+		stack.Pop()
+	}
+
+	if panelWidth > 0 {
+		stack := op.Offset(image.Pt(size.X-panelWidth, 0)).Push(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(panelWidth, size.Y))
+		ui.layoutHistogram(gtx)
+		stack.Pop()
+	}
 
+	{
+		label := "mnemonics ◂"
+		if ui.histogram.visible {
+			label = "mnemonics ▸"
+		}
+		loopsLabel := "Show loops"
+		if ui.loops.visible {
+			loopsLabel = "Hide loops"
+		}
+		safepointsLabel := "Show safepoints"
+		if ui.safepoints.visible {
+			safepointsLabel = "Hide safepoints"
+		}
+		defersLabel := "Show defer chains"
+		if ui.defers.visible {
+			defersLabel = "Hide defer chains"
+		}
+		toggle := func(click *widget.Clickable, label string) layout.FlexChild {
+			return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Clickable(gtx, click, func(gtx layout.Context) layout.Dimensions {
+					txt := material.Body2(ui.Theme, label)
+					txt.TextSize = ui.Theme.TextSize * 8 / 10
+					return layout.UniformInset(4).Layout(gtx, txt.Layout)
+				})
+			})
+		}
+
+		macro := op.Record(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints.Min = image.Point{}
+		children := []layout.FlexChild{}
+		if ui.ViewModeChanged != nil {
+			children = append(children, toggle(&ui.viewMode, "View: "+ui.ViewMode.String()+" ▸"))
+		}
+		if ui.ShowTimingsChanged != nil {
+			timingsLabel := "Show timings"
+			if ui.ShowTimings {
+				timingsLabel = "Hide timings"
+			}
+			children = append(children, toggle(&ui.timings.toggle, timingsLabel))
+		}
+		if ui.ShowHexChanged != nil {
+			hexLabel := "Show hex"
+			if ui.ShowHex {
+				hexLabel = "Hide hex"
+			}
+			children = append(children, toggle(&ui.hex.toggle, hexLabel))
+		}
+		children = append(children,
+			toggle(&ui.defers.toggle, defersLabel),
+			toggle(&ui.safepoints.toggle, safepointsLabel),
+			toggle(&ui.loops.toggle, loopsLabel),
+			toggle(&ui.histogram.toggle, label),
+		)
+		dims := layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+		call := macro.Stop()
+
+		stack := op.Offset(image.Pt(codeWidth-dims.Size.X, bannerHeight)).Push(gtx.Ops)
+		paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: dims.Size}.Op())
+		call.Add(gtx.Ops)
+		stack.Pop()
+	}
+
+	return layout.Dimensions{Size: size}
+}
+
+// layoutLoops tints the instructions inside loops, deeper loops are darker,
+// and annotates the loop headers.
+func (ui CodeUIStyle) layoutLoops(gtx layout.Context, asm Bounds, lineHeight int, textColor color.NRGBA) {
+	for i, depth := range ui.loops.depths {
+		if depth == 0 {
+			continue
+		}
+		top := ui.instRow(i)*lineHeight + int(ui.asm.scroll)
+		height := ui.instRowCount(i) * lineHeight
+		if top+height < 0 || top > gtx.Constraints.Max.Y {
+			continue
+		}
+		tint := f32color.HSLA(0.6, 0.6, 0.5, min(0.06*float32(depth), 0.3))
+		paint.FillShape(gtx.Ops, tint, clip.Rect{
+			Min: image.Pt(int(asm.Min), top),
+			Max: image.Pt(int(asm.Max), top+height),
+		}.Op())
+	}
+
+	annotation := textColor
+	annotation.A = 0x90
+	width := lineHeight * 4
+	for _, loop := range ui.loops.info {
+		SourceLine{
+			TopLeft:    image.Pt(int(asm.Max)-width, ui.instRow(loop.Header)*lineHeight+int(ui.asm.scroll)),
+			Width:      width,
+			Text:       "↺ loop",
+			TextHeight: ui.TextHeight,
+			Italic:     true,
+			Color:      annotation,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// alignmentColor marks the instructions aligned by the linker.
+var alignmentColor = color.NRGBA{R: 0x40, G: 0x90, B: 0x40, A: 0xFF}
+
+// layoutAlignment draws a line above the instructions aligned by the linker,
+// annotated with the alignment left of the loop annotation.
+func (ui CodeUIStyle) layoutAlignment(gtx layout.Context, asm Bounds, lineHeight int) {
+	faded := alignmentColor
+	faded.A = 0x80
+	width := lineHeight * 5
+	for i, alignment := range ui.alignment.insts {
+		top := ui.instRow(i)*lineHeight + int(ui.asm.scroll)
+		if top+lineHeight < 0 || top > gtx.Constraints.Max.Y {
+			continue
+		}
+		paint.FillShape(gtx.Ops, faded, clip.Rect{
+			Min: image.Pt(int(asm.Min), top),
+			Max: image.Pt(int(asm.Max), top+gtx.Dp(1)),
+		}.Op())
+		SourceLine{
+			TopLeft:    image.Pt(int(asm.Max)-lineHeight*4-width, top),
+			Width:      width,
+			Text:       fmt.Sprintf("▲ align %d", alignment),
+			TextHeight: ui.TextHeight * 8 / 10,
+			Color:      alignmentColor,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// heatColor returns the background of an instruction with the PGO weight,
+// from blue for rarely sampled to yellow and red for the hottest.
+func heatColor(weight float64) color.NRGBA {
+	return f32color.HSLA(0.66*float32(1-weight), 0.9, 0.5, 0.15+0.25*float32(weight))
+}
+
+// layoutHeat draws the PGO weights of the instructions as a heatmap,
+// instructions that weren't sampled aren't tinted.
+func (ui CodeUIStyle) layoutHeat(gtx layout.Context, asm Bounds, lineHeight int) {
+	for i, weight := range ui.heat.weights {
+		if weight == 0 {
+			continue
+		}
+		top := ui.instRow(i)*lineHeight + int(ui.asm.scroll)
+		height := ui.instRowCount(i) * lineHeight
+		if top+height < 0 || top > gtx.Constraints.Max.Y {
+			continue
+		}
+		paint.FillShape(gtx.Ops, heatColor(weight), clip.Rect{
+			Min: image.Pt(int(asm.Min), top),
+			Max: image.Pt(int(asm.Max), top+height),
+		}.Op())
+	}
+}
+
+// nopColor and paddingColor are the text colors of folded NOPs,
+// paddingColor for the NOPs aligning the next func.
+var (
+	nopColor     = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}
+	paddingColor = color.NRGBA{R: 0xB0, G: 0x80, B: 0x50, A: 0xFF}
+)
+
+// foldNOPs returns code with its groups of NOPs folded,
+// the folded code is cached until a group is toggled.
+func (ui *CodeUI) foldNOPs(code *disasm.Code) *disasm.Code {
+	if ui.nops.code != code {
+		ui.nops.code = code
+		ui.nops.expanded = map[uint64]bool{}
+		ui.nops.folded = nil
+	}
+	if ui.nops.folded == nil {
+		ui.nops.folded, ui.nops.headers = disasm.FoldNOPs(code, func(group disasm.NOPGroup) bool {
+			return ui.nops.expanded[code.Insts[group.Start].PC]
+		})
+	}
+	return ui.nops.folded
+}
+
+// toggleNOPs expands or collapses the NOPs of the header row.
+func (ui *CodeUI) toggleNOPs(row int) {
+	group, ok := ui.nops.headers[row]
+	if !ok {
+		return
+	}
+	pc := ui.nops.code.Insts[group.Start].PC
+	ui.nops.expanded[pc] = !ui.nops.expanded[pc]
+	ui.nops.folded = nil
+}
+
+// instText returns the text of the instruction row, prefixed with
+// the encoding when ShowHex is set.
+func (ui CodeUIStyle) instText(ix disasm.Inst) string {
+	if !ui.ShowHex || ix.Text == "" {
+		return ix.Text
+	}
+	return hexColumn(ix.Bytes, ui.Arch) + "  " + ix.Text
+}
+
+// hexColumn formats the encoding as space separated hex bytes, padded
+// to the longest instruction shown for arch: 8 bytes for x86 and 4 for
+// the fixed width architectures. Longer encodings are shown in full.
+func hexColumn(encoding []byte, arch string) string {
+	width := 4
+	switch arch {
+	case "", "amd64", "386":
+		width = 8
+	}
+	var b strings.Builder
+	for i, c := range encoding {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%02X", c)
+	}
+	if pad := width*3 - 1 - b.Len(); pad > 0 {
+		b.WriteString(strings.Repeat(" ", pad))
+	}
+	return b.String()
+}
+
+// instColor returns the text color of the instruction row.
+func (ui CodeUIStyle) instColor(row int, textColor color.NRGBA) color.NRGBA {
+	group, ok := ui.nops.headers[row]
+	switch {
+	case !ok:
+		return textColor
+	case group.Padding:
+		return paddingColor
+	default:
+		return nopColor
+	}
+}
+
+// hoverColor is the background of the instructions and the source
+// line related to the hovered line.
+var hoverColor = color.NRGBA{R: 0xFF, G: 0xD0, B: 0x40, A: 0x50}
+
+// isHoveredLine reports whether line of file is the hovered source line.
+func (ui *CodeUI) isHoveredLine(file string, line int) bool {
+	return ui.hoveredSourceLine >= 0 && line == ui.hoveredSourceLine && file == ui.hoveredSourceFile
+}
+
+// sourceLineAt returns the source line drawn at y in the source panel,
+// -1 when y isn't on a line of a block.
+func (ui *CodeUI) sourceLineAt(sources []disasm.Source, y, lineHeight int) (file string, line int) {
+	top := int(ui.src.scroll)
+	for i, src := range sources {
+		if i > 0 {
+			top += lineHeight
+		}
+		// the file name
+		top += lineHeight
+		for i, block := range src.Blocks {
+			if i > 0 {
+				top += lineHeight
+			}
+			height := len(block.Lines) * lineHeight
+			if top <= y && y < top+height {
+				return src.File, block.From + (y-top)/lineHeight
+			}
+			top += height
+		}
+	}
+	return "", -1
+}
+
+// boundsCheckColor highlights bounds checks.
+var boundsCheckColor = color.NRGBA{R: 0xE0, G: 0x80, B: 0x00, A: 0xFF}
+
+// layoutBoundsChecks marks the bounds check instructions in the gutter.
+func (ui CodeUIStyle) layoutBoundsChecks(gtx layout.Context, gutter Bounds, lineHeight int) {
+	if ui.bounds.code != ui.Code {
+		ui.bounds.code = ui.Code
+		ui.bounds.checks = disasm.AnnotateBoundsChecks(ui.Code)
+	}
+	for i := range ui.bounds.checks {
+		SourceLine{
+			TopLeft:    image.Pt(int(gutter.Min)+lineHeight/4, ui.instRow(i)*lineHeight+int(ui.asm.scroll)),
+			Text:       "[BC]",
+			TextHeight: ui.TextHeight,
+			Color:      boundsCheckColor,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// tailCallColor highlights tail calls.
+var tailCallColor = color.NRGBA{R: 0x90, G: 0x40, B: 0xC0, A: 0xFF}
+
+// layoutTailCalls marks the tail calls in the gutter.
+func (ui CodeUIStyle) layoutTailCalls(gtx layout.Context, gutter Bounds, lineHeight int) {
+	if ui.tailCalls.code != ui.Code {
+		ui.tailCalls.code = ui.Code
+		ui.tailCalls.lines = disasm.DetectTailCalls(ui.Code)
+	}
+	for _, i := range ui.tailCalls.lines {
+		SourceLine{
+			TopLeft:    image.Pt(int(gutter.Min)+lineHeight/4, ui.instRow(i)*lineHeight+int(ui.asm.scroll)),
+			Text:       "[TC]",
+			TextHeight: ui.TextHeight,
+			Color:      tailCallColor,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// nilCheckColor highlights nil checks.
+var nilCheckColor = color.NRGBA{R: 0x00, G: 0x90, B: 0x90, A: 0xFF}
+
+// layoutNilChecks marks the nil check instructions in the gutter.
+func (ui CodeUIStyle) layoutNilChecks(gtx layout.Context, gutter Bounds, lineHeight int) {
+	if ui.nilChecks.code != ui.Code {
+		ui.nilChecks.code = ui.Code
+		ui.nilChecks.lines = disasm.DetectNilChecks(ui.Code)
+	}
+	for _, i := range ui.nilChecks.lines {
+		SourceLine{
+			TopLeft:    image.Pt(int(gutter.Min)+lineHeight/4, ui.instRow(i)*lineHeight+int(ui.asm.scroll)),
+			Text:       "[N]",
+			TextHeight: ui.TextHeight,
+			Color:      nilCheckColor,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// coveredColor and uncoveredColor mark the coverage of source lines.
+var (
+	coveredColor   = color.NRGBA{R: 0x20, G: 0xA0, B: 0x40, A: 0xFF}
+	uncoveredColor = color.NRGBA{R: 0xD0, G: 0x30, B: 0x30, A: 0xFF}
+)
+
+// safepointColor marks the safepoints.
+var safepointColor = color.NRGBA{R: 0x30, G: 0x70, B: 0xE0, A: 0xFF}
+
+// safepointLines returns the instruction indexes of the safepoints.
+func safepointLines(code *disasm.Code) []int {
+	safepoints := map[uint64]bool{}
+	for _, pc := range disasm.SafepointPCs(code) {
+		safepoints[pc] = true
+	}
+	var lines []int
+	for i, ix := range code.Insts {
+		if ix.Text != "" && safepoints[ix.PC] {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+// layoutSafepoints draws a dot in the gutter for each safepoint.
+func (ui CodeUIStyle) layoutSafepoints(gtx layout.Context, gutter Bounds, lineHeight int) {
+	radius := max(lineHeight/5, 2)
+	for _, i := range ui.safepoints.lines {
+		center := image.Pt(int(gutter.Max)-lineHeight/2, ui.instRow(i)*lineHeight+int(ui.asm.scroll)+lineHeight/2)
+		paint.FillShape(gtx.Ops, safepointColor, clip.Ellipse{
+			Min: center.Sub(image.Pt(radius, radius)),
+			Max: center.Add(image.Pt(radius, radius)),
+		}.Op(gtx.Ops))
+	}
+}
+
+// deferColor marks the defer calls and their connecting lines.
+var deferColor = color.NRGBA{R: 0x90, G: 0x40, B: 0xC0, A: 0xFF}
+
+// deferLines are the instruction indexes of a disasm.DeferSite.
+type deferLines struct {
+	push    int
+	returns []int
+}
+
+// deferSiteLines returns the instruction indexes of the defer sites.
+func deferSiteLines(code *disasm.Code) []deferLines {
+	index := map[uint64]int{}
+	for i, ix := range code.Insts {
+		if ix.Text != "" {
+			index[ix.PC] = i
+		}
+	}
+	var sites []deferLines
+	for _, site := range disasm.DetectDefers(code) {
+		lines := deferLines{push: index[site.PushPC]}
+		for _, pc := range site.ReturnPCs {
+			lines.returns = append(lines.returns, index[pc])
+		}
+		sites = append(sites, lines)
+	}
+	return sites
+}
+
+// layoutDefers annotates the defer calls in the gutter and connects
+// each push with the returns that run it, like the jump lines.
+func (ui CodeUIStyle) layoutDefers(gtx layout.Context, gutter Bounds, lineHeight int) {
+	annotated := map[int]bool{}
+	annotate := func(i int, text string) {
+		if annotated[i] {
+			return
+		}
+		annotated[i] = true
+		SourceLine{
+			TopLeft:    image.Pt(int(gutter.Min)+lineHeight/4, ui.instRow(i)*lineHeight+int(ui.asm.scroll)),
+			Text:       text,
+			TextHeight: ui.TextHeight,
+			Color:      deferColor,
+		}.Layout(ui.Theme, gtx)
+	}
+
+	start := gutter.Min + float32(lineHeight*5/2)
+	step := float32(lineHeight / 3)
+	for k, site := range ui.defers.sites {
+		annotate(site.push, "[D↓]")
+		x := start + step*float32(k+1)
+		mid := func(i int) float32 {
+			return float32(ui.instRow(i)*lineHeight+lineHeight/2) + ui.asm.scroll
+		}
+		for _, ret := range site.returns {
+			annotate(ret, "[D↑]")
+
+			var path clip.Path
+			path.Begin(gtx.Ops)
+			path.MoveTo(f32.Pt(start, mid(site.push)))
+			path.LineTo(f32.Pt(x, mid(site.push)))
+			path.LineTo(f32.Pt(x, mid(ret)))
+			path.LineTo(f32.Pt(start, mid(ret)))
+			// draw arrow
+			path.Line(f32.Pt(step, -step/2))
+			path.Move(f32.Pt(-step, step/2))
+			path.Line(f32.Pt(step, step/2))
+
+			faded := deferColor
+			faded.A = 0xB0
+			paint.FillShape(gtx.Ops, faded, clip.Stroke{Path: path.End(), Width: float32(gtx.Dp(1))}.Op())
+		}
+	}
+}
+
+// raceColor marks the race detector calls and raceBannerColor
+// is the background of the race detector banner.
+var (
+	raceColor       = color.NRGBA{R: 0x00, G: 0x90, B: 0x90, A: 0xFF}
+	raceBannerColor = color.NRGBA{R: 0x00, G: 0xA0, B: 0xC0, A: 0x40}
+)
+
+// raceLines returns the instruction indexes of the race detector calls.
+func raceLines(code *disasm.Code) []int {
+	accesses := map[uint64]bool{}
+	for _, pc := range disasm.RaceAccessPCs(code) {
+		accesses[pc] = true
+	}
+	var lines []int
+	for i, ix := range code.Insts {
+		if ix.Text != "" && accesses[ix.PC] {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+// layoutRaceAccesses marks the race detector calls in the gutter.
+func (ui CodeUIStyle) layoutRaceAccesses(gtx layout.Context, gutter Bounds, lineHeight int) {
+	for _, i := range ui.race.lines {
+		SourceLine{
+			TopLeft:    image.Pt(int(gutter.Min)+lineHeight/4, ui.instRow(i)*lineHeight+int(ui.asm.scroll)),
+			Text:       "[R]",
+			TextHeight: ui.TextHeight,
+			Color:      raceColor,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// foreignSourceColor tints the source lines of cgo code,
+// which are also prefixed by langPrefix.
+var foreignSourceColor = color.NRGBA{R: 0xE0, G: 0x90, B: 0x20, A: 0x20}
+
+// langPrefix returns the prefix of the source lines in lang,
+// "" for Go and assembly.
+func langPrefix(lang disasm.SourceLang) string {
+	switch lang {
+	case disasm.LangC:
+		return "C ┃ "
+	case disasm.LangCpp:
+		return "C++ ┃ "
+	default:
+		return ""
+	}
+}
+
+// goroutineColor is the arrow pointing at the PCs of the goroutines.
+var goroutineColor = color.NRGBA{R: 0xE0, G: 0x20, B: 0x20, A: 0xFF}
+
+// goroutineLines returns the instruction indexes of disasm.Code.GoroutinePCs.
+func goroutineLines(code *disasm.Code) []int {
+	if len(code.GoroutinePCs) == 0 {
+		return nil
+	}
+	stopped := map[uint64]bool{}
+	for _, pc := range code.GoroutinePCs {
+		stopped[pc] = true
+	}
+	var lines []int
+	for i, ix := range code.Insts {
+		if ix.Text != "" && stopped[ix.PC] {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+// layoutGoroutinePCs draws an arrow in the pad/2 between the jump lines
+// and the text of the instructions where goroutines are stopped.
+func (ui CodeUIStyle) layoutGoroutinePCs(gtx layout.Context, asm Bounds, pad, lineHeight int) {
+	left, right := asm.Min, asm.Min+float32(pad)/2
+	head := asm.Min + float32(pad)/4
+	shaft, half := float32(lineHeight)/12, float32(lineHeight)/4
+	for _, i := range ui.goroutines.lines {
+		mid := float32(ui.instRow(i)*lineHeight+lineHeight/2) + ui.asm.scroll
+		var path clip.Path
+		path.Begin(gtx.Ops)
+		path.MoveTo(f32.Pt(left, mid-shaft))
+		path.LineTo(f32.Pt(head, mid-shaft))
+		path.LineTo(f32.Pt(head, mid-half))
+		path.LineTo(f32.Pt(right, mid))
+		path.LineTo(f32.Pt(head, mid+half))
+		path.LineTo(f32.Pt(head, mid+shaft))
+		path.LineTo(f32.Pt(left, mid+shaft))
+		path.Close()
+		paint.FillShape(gtx.Ops, goroutineColor, clip.Outline{Path: path.End()}.Op())
+	}
+}
+
+// timingColor is the color of the instruction timings.
+var timingColor = color.NRGBA{R: 0x70, G: 0x70, B: 0x70, A: 0xFF}
+
+// updateTimings looks up the timings of the instructions in the table of Arch.
+func (ui CodeUIStyle) updateTimings() {
+	ui.timings.code = ui.Code
+	ui.timings.labels = nil
+	ui.timings.table, _ = timing.ForArch(cmp.Or(ui.Arch, "amd64"))
+	if ui.timings.table == nil {
+		return
+	}
+	ui.timings.criticalPath = ui.timings.table.CriticalPath(ui.Code.Insts)
+	ui.timings.labels = make([]string, len(ui.Code.Insts))
+	for i, ix := range ui.Code.Insts {
+		if t, ok := ui.timings.table.Lookup(ix.Text); ok && ix.Text != "" {
+			ui.timings.labels[i] = "L:" + formatCycles(t.Latency) + " T:" + formatCycles(t.Throughput)
+		}
+	}
+}
+
+// formatCycles formats a number of cycles without trailing zeros, e.g. 0.25.
+func formatCycles(cycles float64) string {
+	return strconv.FormatFloat(cycles, 'f', -1, 64)
+}
+
+// layoutTimings shows the latency and reciprocal throughput of each instruction in the gutter.
+func (ui CodeUIStyle) layoutTimings(gtx layout.Context, gutter Bounds, lineHeight int) {
+	for i, label := range ui.timings.labels {
+		top := ui.instRow(i)*lineHeight + int(ui.asm.scroll)
+		if label == "" || top+lineHeight < 0 || top > gtx.Constraints.Max.Y {
+			continue
+		}
+		SourceLine{
+			TopLeft:    image.Pt(int(gutter.Min)+lineHeight*5/2, top),
+			Width:      int(gutter.Max-gutter.Min) - lineHeight*3,
+			Text:       label,
+			TextHeight: ui.TextHeight * 9 / 10,
+			Color:      timingColor,
+		}.Layout(ui.Theme, gtx)
+	}
+}
+
+// frameWarningColor is the background of the stack frame warning.
+var frameWarningColor = color.NRGBA{R: 0xFF, G: 0xA5, B: 0x00, A: 0x60}
+
+// showFrameWarning reports whether the stack frame warning is visible.
+func (ui CodeUIStyle) showFrameWarning(gtx layout.Context) bool {
+	for ui.frameWarning.dismiss.Clicked(gtx) {
+		ui.frameWarning.dismissed = ui.nops.code
+	}
+	return ui.WarnFrame > 0 && ui.Code.FrameSize > ui.WarnFrame && ui.frameWarning.dismissed != ui.nops.code
+}
+
+// layoutFrameWarning draws the stack frame warning with a dismiss button.
+func (ui CodeUIStyle) layoutFrameWarning(gtx layout.Context) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			msg := fmt.Sprintf("⚠ Stack frame is %d bytes, larger than %d bytes", ui.Code.FrameSize, ui.WarnFrame)
+			return layout.UniformInset(4).Layout(gtx, material.Body2(ui.Theme, msg).Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &ui.frameWarning.dismiss, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(4).Layout(gtx, material.Body2(ui.Theme, "×").Layout)
+			})
+		}),
+	)
+}
+
+// layoutHistogram draws the most frequent mnemonics as horizontal bars.
+func (ui CodeUIStyle) layoutHistogram(gtx layout.Context) {
+	if ui.histogram.code != ui.nops.code {
+		ui.histogram.code = ui.nops.code
+		ui.histogram.counts = disasm.CountMnemonics(ui.nops.code.Insts)
+	}
+
+	size := gtx.Constraints.Max
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	paint.Fill(gtx.Ops, secondaryBackground)
+	paint.FillShape(gtx.Ops, splitterColor, clip.Rect{Max: image.Pt(gtx.Dp(1), size.Y)}.Op())
+
+	textColor := f32color.Black
+	if isDarkMode {
+		textColor = ui.Theme.Fg
+	}
+
+	lineHeight := gtx.Metric.Sp(ui.LineHeight)
+	pad := lineHeight / 2
+	labelWidth := (size.X - 2*pad) * 2 / 5
+	barWidth := size.X - 2*pad - labelWidth
+
+	counts := ui.histogram.counts
+	if len(counts) > histogramTopN {
+		counts = counts[:histogramTopN]
+	}
+
+	top := lineHeight * 2
+	for _, mc := range counts {
+		SourceLine{
+			TopLeft:    image.Pt(pad, top),
+			Width:      labelWidth,
+			Text:       mc.Mnemonic,
+			TextHeight: ui.TextHeight,
+			Color:      textColor,
+		}.Layout(ui.Theme, gtx)
+
+		width := barWidth * mc.Count / ui.histogram.counts[0].Count
+		paint.FillShape(gtx.Ops, f32color.HSLA(0.6, 0.6, 0.6, 0.6), clip.Rect{
+			Min: image.Pt(pad+labelWidth, top+lineHeight/6),
+			Max: image.Pt(pad+labelWidth+width, top+lineHeight*5/6),
+		}.Op())
+		SourceLine{
+			TopLeft:    image.Pt(pad+labelWidth+pad/2, top),
+			Text:       strconv.Itoa(mc.Count),
+			TextHeight: ui.TextHeight,
+			Color:      textColor,
+		}.Layout(ui.Theme, gtx)
+
+		top += lineHeight
+	}
+}
+
+// layoutCode draws the source and the disassembly side by side,
+// or only one of them depending on ViewMode.
+func (ui CodeUIStyle) layoutCode(gtx layout.Context) layout.Dimensions {
 	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
 
 	mouseClicked := false
@@ -74,7 +1075,7 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	for {
 		ev, ok := gtx.Event(pointer.Filter{
 			Target: ui.Code,
-			Kinds:  pointer.Move | pointer.Press,
+			Kinds:  pointer.Move | pointer.Press | pointer.Leave,
 		})
 		if !ok {
 			break
@@ -85,6 +1086,8 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 				ui.mousePosition = ev.Position
 			case pointer.Press:
 				mouseClicked = true
+			case pointer.Leave:
+				ui.mousePosition = f32.Pt(-1, -1)
 			}
 		}
 	}
@@ -97,22 +1100,58 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	jumpStep := lineHeight / 2
 	jumpWidth := jumpStep * ui.Code.MaxJump
 	gutterWidth := lineHeight * 8
+	showAsm := ui.ViewMode != ViewModeSourceOnly
+	showSource := ui.ViewMode != ViewModeAssemblyOnly
+	if !showAsm {
+		jumpWidth = 0
+	}
 	blocksWidth := gtx.Constraints.Max.X - gutterWidth - jumpWidth - 4*pad - pad/2
 
+	asmWidth, sourceWidth := blocksWidth*3/10, blocksWidth*7/10
+	switch {
+	case !showSource:
+		asmWidth, sourceWidth = blocksWidth, 0
+	case !showAsm:
+		asmWidth, sourceWidth, gutterWidth = 0, blocksWidth+gutterWidth, 0
+	}
+
 	jump := BoundsWidth(pad, jumpWidth)
-	asm := BoundsWidth(int(jump.Max)+pad/2, blocksWidth*3/10)
+	asm := BoundsWidth(int(jump.Max)+pad/2, asmWidth)
 	gutter := BoundsWidth(int(asm.Max)+pad, gutterWidth)
-	source := BoundsWidth(int(gutter.Max)+pad, blocksWidth*7/10)
+	source := BoundsWidth(int(gutter.Max)+pad, sourceWidth)
+
+	insts, sources, related := ui.Code.Insts, ui.Code.Source, ui.Code.Source
+	if !showAsm {
+		insts, related = nil, nil
+	}
+	if !showSource {
+		sources, related = nil, nil
+	}
 
 	// draw gutter - use appropriate color based on theme
 	gutterColor := f32color.Gray8(0xE8) // light theme default
 	if isDarkMode {
 		gutterColor = f32color.Gray8(0x28) // dark theme
 	}
-	paint.FillShape(gtx.Ops, gutterColor, clip.Rect{
-		Min: image.Pt(int(gutter.Min), 0),
-		Max: image.Pt(int(gutter.Max), gtx.Constraints.Max.Y),
-	}.Op())
+	if showAsm {
+		paint.FillShape(gtx.Ops, gutterColor, clip.Rect{
+			Min: image.Pt(int(gutter.Min), 0),
+			Max: image.Pt(int(gutter.Max), gtx.Constraints.Max.Y),
+		}.Op())
+		ui.layoutBoundsChecks(gtx, gutter, lineHeight)
+		ui.layoutTailCalls(gtx, gutter, lineHeight)
+		ui.layoutNilChecks(gtx, gutter, lineHeight)
+		if ui.safepoints.visible {
+			ui.layoutSafepoints(gtx, gutter, lineHeight)
+		}
+		if ui.defers.visible {
+			ui.layoutDefers(gtx, gutter, lineHeight)
+		}
+		ui.layoutRaceAccesses(gtx, gutter, lineHeight)
+		if ui.ShowTimings {
+			ui.layoutTimings(gtx, gutter, lineHeight)
+		}
+	}
 
 	if scroll, ok := ui.asm.anim.Update(gtx); ok {
 		ui.asm.scroll = scroll
@@ -123,11 +1162,27 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	mouseInSource := source.Contains(mousePosition.X)
 	highlightAsmIndex := -1
 	if mouseInAsm {
-		highlightAsmIndex = int(mousePosition.Y-ui.asm.scroll) / lineHeight
+		highlightAsmIndex = ui.instAtRow(int(mousePosition.Y-ui.asm.scroll) / lineHeight)
 	}
 	var highlightRanges []disasm.LineRange
 
-	if InRange(highlightAsmIndex, len(ui.Code.Insts)) {
+	ui.hoveredInstIndex, ui.hoveredSourceLine, ui.hoveredSourceFile = -1, -1, ""
+	if InRange(highlightAsmIndex, len(insts)) && insts[highlightAsmIndex].Text != "" {
+		ix := &insts[highlightAsmIndex]
+		ui.hoveredInstIndex = highlightAsmIndex
+		ui.hoveredSourceFile, ui.hoveredSourceLine = ix.File, ix.Line
+	} else if mouseInSource {
+		ui.hoveredSourceFile, ui.hoveredSourceLine = ui.sourceLineAt(sources, int(mousePosition.Y), lineHeight)
+	}
+
+	if _, ok := ui.nops.headers[highlightAsmIndex]; ok && showAsm {
+		pointer.CursorPointer.Add(gtx.Ops)
+		if mouseClicked {
+			ui.toggleNOPs(highlightAsmIndex)
+			gtx.Execute(op.InvalidateCmd{})
+		}
+	}
+	if InRange(highlightAsmIndex, len(insts)) {
 		ix := &ui.Code.Insts[highlightAsmIndex]
 		if ui.TryOpen != nil && ix.Call != "" {
 			pointer.CursorPointer.Add(gtx.Ops)
@@ -140,7 +1195,7 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 			if mouseClicked {
 				// TODO: smooth scroll
 				// highlightAsmIndex -= ix.RefOffset
-				ui.asm.anim.Start(gtx, ui.asm.scroll, ui.asm.scroll-float32(ix.RefOffset*lineHeight), 150*time.Millisecond)
+				ui.asm.anim.Start(gtx, ui.asm.scroll, ui.asm.scroll-float32((ui.instRow(highlightAsmIndex+ix.RefOffset)-ui.instRow(highlightAsmIndex))*lineHeight), 150*time.Millisecond)
 			}
 		}
 	}
@@ -149,7 +1204,7 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	top := int(ui.src.scroll)
 	var highlightPath *clip.PathSpec
 	var highlightColor color.NRGBA
-	for i, src := range ui.Code.Source {
+	for i, src := range related {
 		if i > 0 {
 			top += lineHeight
 		}
@@ -176,8 +1231,10 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 					p.LineTo(f32.Pt(gutter.Max, float32(top)))
 					pin := float32(top)
 					for i, r := range ranges {
+						from := float32(ui.instRow(r.From)*lineHeight) + ui.asm.scroll
+						to := float32(ui.instRow(r.To)*lineHeight) + ui.asm.scroll
 						if mouseInAsm {
-							if float32(r.From*lineHeight)+ui.asm.scroll <= mousePosition.Y && mousePosition.Y < float32(r.To*lineHeight)+ui.asm.scroll {
+							if from <= mousePosition.Y && mousePosition.Y < to {
 								highlight = true
 								highlightRanges = ranges
 							}
@@ -185,14 +1242,14 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 						const S = 0.1
 						p.CubeTo(
 							f32.Pt(gutter.Lerp(0.5-S), pin),
-							f32.Pt(gutter.Lerp(0.5+S), float32(r.From*lineHeight)+ui.asm.scroll),
-							f32.Pt(gutter.Min, float32(r.From*lineHeight)+ui.asm.scroll))
-						p.LineTo(f32.Pt(asm.Min, float32(r.From*lineHeight)+ui.asm.scroll))
-						p.LineTo(f32.Pt(asm.Min, float32(r.To*lineHeight)+ui.asm.scroll))
-						p.LineTo(f32.Pt(gutter.Min, float32(r.To*lineHeight)+ui.asm.scroll))
+							f32.Pt(gutter.Lerp(0.5+S), from),
+							f32.Pt(gutter.Min, from))
+						p.LineTo(f32.Pt(asm.Min, from))
+						p.LineTo(f32.Pt(asm.Min, to))
+						p.LineTo(f32.Pt(gutter.Min, to))
 						pin = float32(top) + float32(lineHeight)*float32(i+1)/float32(len(ranges))
 						p.CubeTo(
-							f32.Pt(gutter.Lerp(0.5+S), float32(r.To*lineHeight)+ui.asm.scroll),
+							f32.Pt(gutter.Lerp(0.5+S), to),
 							f32.Pt(gutter.Lerp(0.5-S), pin),
 							f32.Pt(gutter.Max, pin))
 					}
@@ -232,29 +1289,51 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 		Max: image.Pt(int(gutter.Min), gtx.Constraints.Max.Y),
 	}.Push(gtx.Ops)
 
-	for i, ix := range ui.Code.Insts {
-		SourceLine{
-			TopLeft:    image.Pt(int(asm.Min)+pad/2, i*lineHeight+int(ui.asm.scroll)),
-			Text:       ix.Text,
-			TextHeight: ui.TextHeight,
-			Italic:     ix.Call != "",
-			Bold:       highlightAsmIndex == i,
-			Color:      textColor,
-		}.Layout(ui.Theme, gtx)
+	if ui.loops.visible && showAsm {
+		ui.layoutLoops(gtx, asm, lineHeight, textColor)
+	}
+	if ui.PGO != nil && showAsm {
+		ui.layoutHeat(gtx, asm, lineHeight)
+	}
+	if showAsm {
+		ui.layoutAlignment(gtx, asm, lineHeight)
+	}
 
-		// jump line
+	for i, ix := range insts {
+		top := ui.instRow(i) * lineHeight
+		if ui.isHoveredLine(ix.File, ix.Line) && ix.Text != "" {
+			y := top + int(ui.asm.scroll)
+			paint.FillShape(gtx.Ops, hoverColor, clip.Rect{
+				Min: image.Pt(int(asm.Min), y),
+				Max: image.Pt(int(asm.Max), y+ui.instRowCount(i)*lineHeight),
+			}.Op())
+		}
+		for k, line := range ui.instLines(i, ix) {
+			SourceLine{
+				TopLeft:    image.Pt(int(asm.Min)+pad/2, top+k*lineHeight+int(ui.asm.scroll)),
+				Text:       line,
+				TextHeight: ui.TextHeight,
+				Italic:     ix.Call != "",
+				Bold:       highlightAsmIndex == i,
+				Color:      ui.instColor(i, textColor),
+			}.Layout(ui.Theme, gtx)
+		}
+
+		// jump line, from the first row of the instruction to the first
+		// row of the target
 		if ix.RefOffset != 0 {
 			lineWidth := gtx.Metric.Dp(1)
 			align := float32(lineWidth%2) / 2
 			stack := op.Affine(f32.Affine2D{}.Offset(
-				f32.Pt(jump.Max+align, float32(i*lineHeight)+align+ui.asm.scroll))).Push(gtx.Ops)
+				f32.Pt(jump.Max+align, float32(top)+align+ui.asm.scroll))).Push(gtx.Ops)
 
+			target := ui.instRow(i+ix.RefOffset)*lineHeight - top
 			var path clip.Path
 			path.Begin(gtx.Ops)
 			path.MoveTo(f32.Pt(float32(pad/2), float32(lineHeight*2/3)))
 			path.LineTo(f32.Pt(float32(-jumpStep*ix.RefStack), float32(lineHeight*2/3)))
-			path.LineTo(f32.Pt(float32(-jumpStep*ix.RefStack), float32(lineHeight/3+ix.RefOffset*lineHeight)))
-			path.LineTo(f32.Pt(float32(-jumpStep/2), float32(lineHeight/3+ix.RefOffset*lineHeight)))
+			path.LineTo(f32.Pt(float32(-jumpStep*ix.RefStack), float32(lineHeight/3+target)))
+			path.LineTo(f32.Pt(float32(-jumpStep/2), float32(lineHeight/3+target)))
 			// draw arrow
 			path.Line(f32.Pt(0, float32(lineHeight/4)))
 			path.Line(f32.Pt(float32(lineHeight/3), float32(-lineHeight/4)))
@@ -277,19 +1356,35 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	}
 	asmClip.Pop()
 
+	if showAsm {
+		ui.layoutGoroutinePCs(gtx, asm, pad, lineHeight)
+	}
+
 	// source
 	sourceClip := clip.Rect{
 		Min: image.Pt(int(source.Min), 0),
 		Max: image.Pt(int(source.Max), gtx.Constraints.Max.Y),
 	}.Push(gtx.Ops)
 
+	// coverage border
+	sourceText := int(source.Min)
+	borderWidth := gtx.Dp(3)
+	if ui.Coverage != nil {
+		sourceText += 2 * borderWidth
+	}
+
 	top = int(ui.src.scroll)
-	for i, src := range ui.Code.Source {
+	for i, src := range sources {
 		if i > 0 {
 			top += lineHeight
 		}
+		var covered map[int]bool
+		if ui.Coverage != nil {
+			covered = ui.Coverage.FileLines(src.File)
+		}
+		prefix := langPrefix(disasm.DetectLanguage(src.File))
 		SourceLine{
-			TopLeft:    image.Pt(int(source.Min), top),
+			TopLeft:    image.Pt(sourceText, top),
 			Text:       src.File,
 			TextHeight: ui.TextHeight,
 			Bold:       highlightAsmIndex == i,
@@ -302,9 +1397,31 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 			}
 			for off, line := range block.Lines {
 				highlight := mouseInSource && float32(top) <= mousePosition.Y && mousePosition.Y < float32(top+lineHeight)
+				if prefix != "" {
+					paint.FillShape(gtx.Ops, foreignSourceColor, clip.Rect{
+						Min: image.Pt(int(source.Min), top),
+						Max: image.Pt(int(source.Max), top+lineHeight),
+					}.Op())
+				}
+				if ui.isHoveredLine(src.File, block.From+off) {
+					paint.FillShape(gtx.Ops, hoverColor, clip.Rect{
+						Min: image.Pt(int(source.Min), top),
+						Max: image.Pt(int(source.Max), top+lineHeight),
+					}.Op())
+				}
+				if c, ok := covered[block.From+off]; ok {
+					borderColor := uncoveredColor
+					if c {
+						borderColor = coveredColor
+					}
+					paint.FillShape(gtx.Ops, borderColor, clip.Rect{
+						Min: image.Pt(int(source.Min), top),
+						Max: image.Pt(int(source.Min)+borderWidth, top+lineHeight),
+					}.Op())
+				}
 				SourceLine{
-					TopLeft:    image.Pt(int(source.Min), top),
-					Text:       fmt.Sprintf("%-4d %s", block.From+off, line),
+					TopLeft:    image.Pt(sourceText, top),
+					Text:       fmt.Sprintf("%s%-4d %s", prefix, block.From+off, line),
 					TextHeight: ui.TextHeight,
 					Bold:       highlight,
 					Color:      textColor,
@@ -316,7 +1433,7 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 	sourceClip.Pop()
 	sourceContentHeight := top - int(ui.src.scroll)
 
-	{
+	if showAsm {
 		stack := clip.Rect{
 			Min: image.Pt(int(jump.Min)-pad, 0),
 			Max: image.Pt(int(asm.Max), gtx.Constraints.Max.Y),
@@ -325,7 +1442,7 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 		// overflow := gtx.Constraints.Max.Y / 3
 		overflow := lineHeight
 		contentTop := float32(-overflow)
-		contentBot := float32(len(ui.Code.Insts)*lineHeight + overflow)
+		contentBot := float32(ui.instRow(len(ui.Code.Insts))*lineHeight + overflow)
 		viewTop := -ui.asm.scroll
 		viewBot := -ui.asm.scroll + float32(gtx.Constraints.Max.Y)
 
@@ -366,7 +1483,7 @@ func (ui CodeUIStyle) Layout(gtx layout.Context) layout.Dimensions {
 		stack.Pop()
 	}
 
-	{
+	if showSource {
 		stack := clip.Rect{
 			Min: image.Pt(int(source.Min), 0),
 			Max: image.Pt(int(source.Max)+pad, gtx.Constraints.Max.Y),
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"gioui.org/io/event"
+	"gioui.org/io/transfer"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/export"
+)
+
+// dropTypes are the MIME types accepted when dropping files on the window.
+var dropTypes = [...]string{"text/uri-list", "application/octet-stream"}
+
+// binaryMagics are the headers of the supported executable formats.
+var binaryMagics = [][]byte{
+	[]byte("\x7fELF"),
+	{0xfe, 0xed, 0xfa, 0xce}, {0xce, 0xfa, 0xed, 0xfe}, // Mach-O 32-bit
+	{0xfe, 0xed, 0xfa, 0xcf}, {0xcf, 0xfa, 0xed, 0xfe}, // Mach-O 64-bit
+	[]byte("MZ"),             // PE
+	[]byte("\x00asm"),        // WebAssembly
+	[]byte("!<arch>\n"),      // Go object archive
+	[]byte("go object "),     // Go object file
+	{0x00, 0x00, 0x01, 0xeb}, // Plan 9 amd64
+}
+
+// updateDrop handles the files dropped on the window.
+//
+// Dropping is only delivered on platforms where Gio forwards external
+// drag and drop transfers, elsewhere the events never arrive.
+func (ui *FileUI) updateDrop(gtx layout.Context) {
+	if ui.Config.ServerURL != "" {
+		return
+	}
+
+	filters := make([]event.Filter, 0, len(dropTypes))
+	for _, typ := range dropTypes {
+		filters = append(filters, transfer.TargetFilter{Target: ui, Type: typ})
+	}
+	for {
+		ev, ok := gtx.Event(filters...)
+		if !ok {
+			break
+		}
+		switch ev := ev.(type) {
+		case transfer.InitiateEvent:
+			ui.dragging = true
+		case transfer.CancelEvent:
+			ui.dragging = false
+		case transfer.DataEvent:
+			ui.dragging = false
+			paths, err := readDrop(ev)
+			ui.dropError = err
+			if err != nil {
+				log.Println(fmt.Errorf("failed to read dropped files: %w", err))
+			}
+			for _, path := range paths {
+				if err := checkBinary(path); err != nil {
+					ui.dropError = err
+					log.Println(err)
+					continue
+				}
+				ui.Config.Path = path
+				ui.OpenTab(path)
+			}
+		}
+	}
+}
+
+// readDrop returns the paths of the files in a drop payload.
+// Raw binary data is stored in a temporary file.
+func readDrop(ev transfer.DataEvent) ([]string, error) {
+	data := ev.Open()
+	defer data.Close()
+
+	if ev.Type == "application/octet-stream" {
+		f, err := os.CreateTemp("", "lensm-drop-*")
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if _, err := io.Copy(f, data); err != nil {
+			_ = os.Remove(f.Name())
+			return nil, err
+		}
+		return []string{f.Name()}, nil
+	}
+
+	return parseURIList(data)
+}
+
+// parseURIList parses a text/uri-list as described in RFC 2483,
+// only local file URIs are supported.
+func parseURIList(r io.Reader) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		uri, err := url.Parse(line)
+		if err != nil {
+			return paths, err
+		}
+		switch uri.Scheme {
+		case "":
+			paths = append(paths, line)
+		case "file":
+			paths = append(paths, uri.Path)
+		default:
+			return paths, fmt.Errorf("unsupported uri %q", line)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// checkBinary verifies that path is a readable file in a known format.
+func checkBinary(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if export.IsJSONFile(path) {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, 16)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	header = header[:n]
+	for _, magic := range binaryMagics {
+		if bytes.HasPrefix(header, magic) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: unknown binary format", path)
+}
+
+// layoutDropTarget highlights the window while files are dragged over it.
+func (ui *FileUI) layoutDropTarget(gtx layout.Context) layout.Dimensions {
+	size := gtx.Constraints.Max
+	highlight := ui.Theme.ContrastBg
+	highlight.A = 0x30
+	paint.FillShape(gtx.Ops, highlight, clip.Rect{Max: size}.Op())
+
+	gtx.Constraints = layout.Exact(size)
+	return widget.Border{
+		Color:        ui.Theme.ContrastBg,
+		CornerRadius: unit.Dp(4),
+		Width:        unit.Dp(2),
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Center.Layout(gtx, material.H6(ui.Theme, "Drop binaries to open").Layout)
+	})
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"gioui.org/f32"
+	"gioui.org/font"
+	"gioui.org/gpu/headless"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// exportWidth is the width of the exported code view.
+const exportWidth = 1200
+
+// maxExportHeight limits the exported image to a reasonable texture size.
+const maxExportHeight = 16384
+
+// RenderToPNG renders the whole code view, including the function name
+// header, at the given scale using the current theme.
+func (ui CodeUIStyle) RenderToPNG(scale float32) ([]byte, error) {
+	if ui.Code == nil {
+		return nil, errors.New("no code loaded")
+	}
+	if scale <= 0 {
+		scale = 1
+	}
+
+	// Render a copy to keep the scroll state of the view.
+	state := *ui.CodeUI
+	state.asm.scroll = 0
+	state.src.scroll = 0
+	state.mousePosition = f32.Pt(-1, -1)
+	state.histogram.visible = false
+	state.minimap = nil
+	ui.CodeUI = &state
+
+	metric := unit.Metric{PxPerDp: scale, PxPerSp: scale}
+	lineHeight := metric.Sp(ui.LineHeight)
+	headerHeight := lineHeight * 3
+	lines := codeLines(ui.Code.Insts, ui.Code.Source)
+	if ui.ViewMode == ViewModeInterleaved {
+		rows, _ := interleave(ui.Code)
+		lines = len(rows)
+	}
+	size := image.Pt(metric.Dp(exportWidth), headerHeight+lines*lineHeight+lineHeight)
+	size.Y = min(size.Y, maxExportHeight)
+
+	win, err := headless.NewWindow(size.X, size.Y)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create renderer: %w", err)
+	}
+	defer win.Release()
+
+	ops := new(op.Ops)
+	gtx := layout.Context{
+		Ops:         ops,
+		Metric:      metric,
+		Constraints: layout.Exact(size),
+	}
+
+	bgColor := color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	if isDarkMode {
+		bgColor = ui.Theme.Bg
+	}
+	paint.Fill(gtx.Ops, bgColor)
+
+	layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.Y = headerHeight
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txt := material.Body1(ui.Theme, ui.Code.Name)
+					txt.TextSize *= 1.2
+					return layout.Inset{Top: 4, Left: 4, Right: 4, Bottom: 2}.Layout(gtx, txt.Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					txt := material.Body1(ui.Theme, "file: "+ui.Code.File)
+					txt.Font.Style = font.Italic
+					return layout.Inset{Top: 2, Left: 4, Right: 4, Bottom: 4}.Layout(gtx, txt.Layout)
+				}),
+			)
+		}),
+		layout.Rigid(HorizontalLine{Height: 1, Color: splitterColor}.Layout),
+		layout.Flexed(1, ui.Layout),
+	)
+
+	if err := win.Frame(ops); err != nil {
+		return nil, fmt.Errorf("failed to render: %w", err)
+	}
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	if err := win.Screenshot(img); err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// codeLines returns the number of lines needed to show the whole code.
+func codeLines(insts []disasm.Inst, sources []disasm.Source) int {
+	lines := 0
+	for i, src := range sources {
+		if i > 0 {
+			lines++
+		}
+		lines++
+		for i, block := range src.Blocks {
+			if i > 0 {
+				lines++
+			}
+			lines += len(block.Lines)
+		}
+	}
+	return max(lines, len(insts))
+}
+
+// saveFileDialog asks the user for a path to save a file to.
+// It returns an empty path when the dialog was cancelled.
+func saveFileDialog(title, name string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("POSIX path of (choose file name with prompt %q default name %q)", title, name)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$d = New-Object System.Windows.Forms.SaveFileDialog
+$d.Title = '%s'
+$d.FileName = '%s'
+$d.Filter = 'PNG image (*.png)|*.png'
+if ($d.ShowDialog() -eq 'OK') { $d.FileName }`, escapePowerShell(title), escapePowerShell(name))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		cmd = exec.Command("zenity", "--file-selection", "--save", "--confirm-overwrite",
+			"--title="+title, "--filename="+name)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// the dialog was cancelled
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func escapePowerShell(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
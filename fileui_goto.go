@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// maxGotoSuggestions is the number of source files suggested while typing.
+const maxGotoSuggestions = 8
+
+// lineFinder is implemented by files that can find funcs by source line.
+type lineFinder interface {
+	FindFuncByLine(file string, line int) []disasm.Func
+}
+
+// gotoLine is the Ctrl+G dialog, which opens the func compiled
+// from a source file and line.
+type gotoLine struct {
+	visible bool
+
+	file widget.Editor
+	line widget.Editor
+	open widget.Clickable
+
+	// suggestions are the source files of the current code matching file.
+	suggestions []string
+	suggest     []widget.Clickable
+
+	// results are the funcs containing the line, shown as a picker
+	// when there's more than one.
+	results []disasm.Func
+	pick    []widget.Clickable
+	target  struct {
+		file string
+		line int
+	}
+
+	err string
+}
+
+// toggleGotoLine shows or hides the dialog.
+func (ui *FileUI) toggleGotoLine(gtx layout.Context) {
+	ui.gotoLine.visible = !ui.gotoLine.visible
+	ui.gotoLine.results = nil
+	ui.gotoLine.err = ""
+	if ui.gotoLine.visible {
+		ui.gotoLine.file.SingleLine = true
+		ui.gotoLine.file.Submit = true
+		ui.gotoLine.line.SingleLine = true
+		ui.gotoLine.line.Submit = true
+		ui.gotoLine.line.Filter = "0123456789"
+		gtx.Execute(key.FocusCmd{Tag: &ui.gotoLine.file})
+	}
+}
+
+// updateGotoLine handles Ctrl+G and the input of the dialog.
+func (ui *FileUI) updateGotoLine(gtx layout.Context) {
+	for {
+		ev, ok := gtx.Event(key.Filter{Name: "G", Required: key.ModShortcut})
+		if !ok {
+			break
+		}
+		if ev, ok := ev.(key.Event); ok && ev.State == key.Press {
+			ui.toggleGotoLine(gtx)
+		}
+	}
+	dialog := &ui.gotoLine
+	if !dialog.visible {
+		return
+	}
+	for {
+		ev, ok := gtx.Event(key.Filter{Focus: &dialog.file, Name: key.NameEscape}, key.Filter{Focus: &dialog.line, Name: key.NameEscape})
+		if !ok {
+			break
+		}
+		if ev, ok := ev.(key.Event); ok && ev.State == key.Press {
+			dialog.visible = false
+			return
+		}
+	}
+
+	submit := false
+	for {
+		ev, ok := dialog.file.Update(gtx)
+		if !ok {
+			break
+		}
+		switch ev.(type) {
+		case widget.ChangeEvent:
+			ui.updateGotoSuggestions()
+		case widget.SubmitEvent:
+			gtx.Execute(key.FocusCmd{Tag: &dialog.line})
+		}
+	}
+	for {
+		ev, ok := dialog.line.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.SubmitEvent); ok {
+			submit = true
+		}
+	}
+	for dialog.open.Clicked(gtx) {
+		submit = true
+	}
+	for i := range dialog.suggestions {
+		for dialog.suggest[i].Clicked(gtx) {
+			dialog.file.SetText(dialog.suggestions[i])
+			dialog.file.SetCaret(dialog.file.Len(), dialog.file.Len())
+			dialog.suggestions = nil
+			gtx.Execute(key.FocusCmd{Tag: &dialog.line})
+		}
+	}
+	for i, fn := range dialog.results {
+		for dialog.pick[i].Clicked(gtx) {
+			ui.openLine(fn, dialog.target.file, dialog.target.line)
+		}
+	}
+	if submit {
+		ui.findLine()
+	}
+}
+
+// updateGotoSuggestions lists the source files of the current code
+// that contain the text of the file input.
+func (ui *FileUI) updateGotoSuggestions() {
+	dialog := &ui.gotoLine
+	dialog.suggestions = dialog.suggestions[:0]
+	query := strings.ToLower(strings.TrimSpace(dialog.file.Text()))
+	tab := ui.ActiveTab()
+	if query == "" || tab == nil || !tab.Code.Loaded() {
+		return
+	}
+
+	var files []string
+	for _, source := range tab.Code.Source {
+		files = append(files, source.File)
+	}
+	for _, ix := range tab.Code.Insts {
+		files = append(files, ix.File)
+	}
+	slices.Sort(files)
+	for _, file := range slices.Compact(files) {
+		if file != "" && file != query && strings.Contains(strings.ToLower(file), query) {
+			dialog.suggestions = append(dialog.suggestions, file)
+			if len(dialog.suggestions) == maxGotoSuggestions {
+				break
+			}
+		}
+	}
+	if len(dialog.suggest) < len(dialog.suggestions) {
+		dialog.suggest = make([]widget.Clickable, len(dialog.suggestions))
+	}
+}
+
+// findLine looks up the funcs containing the line of the dialog. A single
+// func is opened directly, otherwise the funcs are shown in a picker.
+func (ui *FileUI) findLine() {
+	dialog := &ui.gotoLine
+	dialog.results, dialog.err = nil, ""
+
+	file := strings.TrimSpace(dialog.file.Text())
+	line, err := strconv.Atoi(strings.TrimSpace(dialog.line.Text()))
+	if file == "" || err != nil || line <= 0 {
+		dialog.err = "Enter a source file and a line number"
+		return
+	}
+	tab := ui.ActiveTab()
+	if tab == nil || tab.File == nil {
+		dialog.err = "No binary is loaded"
+		return
+	}
+	finder, ok := tab.File.(lineFinder)
+	if !ok {
+		dialog.err = "Finding functions by line isn't supported for this file"
+		return
+	}
+
+	results := finder.FindFuncByLine(file, line)
+	switch len(results) {
+	case 0:
+		dialog.err = fmt.Sprintf("No function contains %s:%d", file, line)
+	case 1:
+		ui.openLine(results[0], file, line)
+	default:
+		dialog.results = results
+		dialog.target.file, dialog.target.line = file, line
+		if len(dialog.pick) < len(results) {
+			dialog.pick = make([]widget.Clickable, len(results))
+		}
+	}
+}
+
+// openLine shows the code of fn in the active tab scrolled to file:line
+// and closes the dialog.
+func (ui *FileUI) openLine(fn disasm.Func, file string, line int) {
+	tab := ui.ActiveTab()
+	if tab == nil {
+		return
+	}
+	tab.openFunc(fn)
+	tab.Code.ScrollToSource(file, line)
+	ui.gotoLine.visible = false
+	ui.gotoLine.results = nil
+	ui.invalidate()
+}
+
+// layoutGotoLine draws the dialog as a floating panel at the top of the window.
+func (ui *FileUI) layoutGotoLine(gtx layout.Context, top int) layout.Dimensions {
+	dialog := &ui.gotoLine
+	width := min(gtx.Dp(560), gtx.Constraints.Max.X)
+
+	panel := func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = image.Pt(width, 0)
+		gtx.Constraints.Max.X = width
+
+		input := func(editor *widget.Editor, hint string) layout.Widget {
+			return func(gtx layout.Context) layout.Dimensions {
+				return FocusBorder(ui.Theme, gtx.Focused(editor)).Layout(gtx, material.Editor(ui.Theme, editor, hint).Layout)
+			}
+		}
+		item := func(click *widget.Clickable, label string) layout.FlexChild {
+			return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return material.Clickable(gtx, click, func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: 2, Left: 4, Right: 4, Bottom: 2}.Layout(gtx, material.Body2(ui.Theme, label).Layout)
+				})
+			})
+		}
+
+		children := []layout.FlexChild{
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(3, input(&dialog.file, "Source file")),
+					layout.Rigid(layout.Spacer{Width: 4}.Layout),
+					layout.Flexed(1, input(&dialog.line, "Line")),
+					layout.Rigid(layout.Spacer{Width: 4}.Layout),
+					layout.Rigid(material.Button(ui.Theme, &dialog.open, "Go").Layout),
+				)
+			}),
+		}
+		for i, file := range dialog.suggestions {
+			children = append(children, item(&dialog.suggest[i], file))
+		}
+		if len(dialog.results) > 0 {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: 4, Bottom: 2}.Layout(gtx,
+					material.Caption(ui.Theme, fmt.Sprintf("%d functions contain the line, it's inlined:", len(dialog.results))).Layout)
+			}))
+			for i, fn := range dialog.results {
+				children = append(children, item(&dialog.pick[i], fn.Name()))
+			}
+		}
+		if dialog.err != "" {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: 4}.Layout(gtx, material.Body2(ui.Theme, dialog.err).Layout)
+			}))
+		}
+
+		macro := op.Record(gtx.Ops)
+		dims := layout.UniformInset(8).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+		call := macro.Stop()
+		paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: dims.Size}.Op())
+		call.Add(gtx.Ops)
+		return dims
+	}
+
+	gtx.Constraints.Min = gtx.Constraints.Max
+	return layout.Stack{Alignment: layout.N}.Layout(gtx,
+		layout.Stacked(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(float32(top) / gtx.Metric.PxPerDp)}.Layout(gtx, panel)
+		}),
+	)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// minimapTextWidth is the instruction length that fills the minimap width.
+const minimapTextWidth = 48
+
+// minimapCache contains the recorded bars of the minimap,
+// which only change with the code or the panel size.
+type minimapCache struct {
+	code *disasm.Code
+	size image.Point
+	ops  op.Ops
+	call op.CallOp
+}
+
+// layoutMinimap draws an overview of the instructions with the visible part
+// highlighted. Clicking or dragging scrolls the code view.
+func (ui CodeUIStyle) layoutMinimap(gtx layout.Context, viewHeight int) {
+	if ui.minimap == nil {
+		ui.minimap = &minimapCache{}
+	}
+	cache := ui.minimap
+
+	size := gtx.Constraints.Max
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	paint.Fill(gtx.Ops, secondaryBackground)
+	paint.FillShape(gtx.Ops, splitterColor, clip.Rect{Max: image.Pt(gtx.Dp(1), size.Y)}.Op())
+
+	count := len(ui.Code.Insts)
+	if count == 0 {
+		return
+	}
+	lineHeight := gtx.Metric.Sp(ui.LineHeight)
+	rowHeight := min(float32(gtx.Dp(1)), float32(size.Y)/float32(count))
+
+	event.Op(gtx.Ops, cache)
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target: cache,
+			Kinds:  pointer.Press | pointer.Drag,
+		})
+		if !ok {
+			break
+		}
+		if ev, ok := ev.(pointer.Event); ok {
+			line := int(ev.Position.Y / rowHeight)
+			if ui.ViewMode == ViewModeInterleaved && InRange(line, len(ui.interleaved.instRows)) {
+				line = ui.interleaved.instRows[line]
+			} else {
+				line = ui.instRow(line)
+			}
+			ui.asm.anim.Stop()
+			ui.asm.scroll = float32(viewHeight/2 - line*lineHeight)
+			gtx.Execute(op.InvalidateCmd{})
+		}
+	}
+
+	if cache.code != ui.Code || cache.size != size {
+		cache.code = ui.Code
+		cache.size = size
+		cache.ops.Reset()
+
+		pad := gtx.Dp(4)
+		barHeight := max(int(rowHeight), 1)
+		macro := op.Record(&cache.ops)
+		for i, ix := range ui.Code.Insts {
+			width := (size.X - 2*pad) * min(len(ix.Text), minimapTextWidth) / minimapTextWidth
+			top := int(float32(i) * rowHeight)
+			paint.FillShape(&cache.ops, ui.Syntax.Color(disasm.ClassifyArch(ui.Arch, ix.Text)), clip.Rect{
+				Min: image.Pt(pad, top),
+				Max: image.Pt(pad+width, top+barHeight),
+			}.Op())
+		}
+		cache.call = macro.Stop()
+	}
+	cache.call.Add(gtx.Ops)
+
+	// viewport indicator
+	first := -ui.asm.scroll / float32(lineHeight)
+	visible := float32(viewHeight) / float32(lineHeight)
+	if ui.wrapRows() != nil {
+		// the minimap has a row per instruction
+		last := ui.instAtRow(int(first + visible))
+		first = float32(ui.instAtRow(int(first)))
+		visible = float32(last) - first
+	}
+	viewport := clip.Rect{
+		Min: image.Pt(0, int(first*rowHeight)),
+		Max: image.Pt(size.X, int((first+visible)*rowHeight)),
+	}
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x40}, viewport.Op())
+	paint.FillShape(gtx.Ops, splitterColor, clip.Stroke{Path: viewport.Path(), Width: float32(gtx.Dp(1))}.Op())
+}
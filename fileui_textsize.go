@@ -0,0 +1,98 @@
+package main
+
+import (
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// minTextSize and maxTextSize limit the text size adjustment.
+const (
+	minTextSize = unit.Sp(8)
+	maxTextSize = unit.Sp(32)
+)
+
+// textSizeControls are the toolbar buttons adjusting the text size.
+type textSizeControls struct {
+	smaller widget.Clickable
+	larger  widget.Clickable
+	reset   widget.Clickable
+}
+
+// clampTextSize limits size to [minTextSize, maxTextSize].
+func clampTextSize(size unit.Sp) unit.Sp {
+	return min(max(size, minTextSize), maxTextSize)
+}
+
+// setTextSize changes the text size of all views and remembers it,
+// a size of 0 returns to the configured default.
+func (ui *FileUI) setTextSize(size unit.Sp) {
+	if size == 0 {
+		ui.Theme.TextSize = clampTextSize(ui.Config.TextSize)
+		ui.Settings.TextSize = 0
+	} else {
+		ui.Theme.TextSize = clampTextSize(size)
+		ui.Settings.TextSize = float32(ui.Theme.TextSize)
+	}
+	ui.saveSettings()
+	ui.invalidate()
+}
+
+// updateTextSize handles the buttons and the Ctrl +, Ctrl - and Ctrl 0 shortcuts.
+func (ui *FileUI) updateTextSize(gtx layout.Context) {
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Name: "+", Required: key.ModShortcut, Optional: key.ModShift},
+			key.Filter{Name: "=", Required: key.ModShortcut, Optional: key.ModShift},
+			key.Filter{Name: "-", Required: key.ModShortcut},
+			key.Filter{Name: "0", Required: key.ModShortcut},
+		)
+		if !ok {
+			break
+		}
+		press, ok := ev.(key.Event)
+		if !ok || press.State != key.Press {
+			continue
+		}
+		switch press.Name {
+		case "+", "=":
+			ui.setTextSize(ui.Theme.TextSize + 1)
+		case "-":
+			ui.setTextSize(ui.Theme.TextSize - 1)
+		case "0":
+			ui.setTextSize(0)
+		}
+	}
+	for ui.textSize.smaller.Clicked(gtx) {
+		ui.setTextSize(ui.Theme.TextSize - 1)
+	}
+	for ui.textSize.larger.Clicked(gtx) {
+		ui.setTextSize(ui.Theme.TextSize + 1)
+	}
+	for ui.textSize.reset.Clicked(gtx) {
+		ui.setTextSize(0)
+	}
+}
+
+// layoutTextSize draws the toolbar buttons adjusting the text size,
+// the reset button is only shown when the size differs from the default.
+func (ui *FileUI) layoutTextSize(gtx layout.Context) layout.Dimensions {
+	button := func(click *widget.Clickable, label string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, click, func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: 4, Left: 6, Right: 6, Bottom: 4}.Layout(gtx,
+					material.Body1(ui.Theme, label).Layout)
+			})
+		})
+	}
+	children := []layout.FlexChild{
+		button(&ui.textSize.smaller, "A−"),
+		button(&ui.textSize.larger, "A+"),
+	}
+	if ui.Theme.TextSize != clampTextSize(ui.Config.TextSize) {
+		children = append(children, button(&ui.textSize.reset, "Reset font size"))
+	}
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gioui.org/f32"
+	"gioui.org/gesture"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/f32color"
+)
+
+// ViewMode selects how the code view arranges the source and the assembly.
+type ViewMode int
+
+const (
+	// ViewModeDefault shows the source and the assembly side by side.
+	ViewModeDefault ViewMode = iota
+	// ViewModeInterleaved shows each source line directly before
+	// its instructions, like `objdump -S`.
+	ViewModeInterleaved
+	// ViewModeAssemblyOnly hides the source.
+	ViewModeAssemblyOnly
+	// ViewModeSourceOnly hides the assembly.
+	ViewModeSourceOnly
+)
+
+// String returns the name of the mode, as stored in the settings.
+func (mode ViewMode) String() string {
+	switch mode {
+	case ViewModeInterleaved:
+		return "interleaved"
+	case ViewModeAssemblyOnly:
+		return "assembly"
+	case ViewModeSourceOnly:
+		return "source"
+	default:
+		return "side by side"
+	}
+}
+
+// ParseViewMode parses the result of ViewMode.String.
+func ParseViewMode(s string) (ViewMode, bool) {
+	for mode := ViewModeDefault; mode <= ViewModeSourceOnly; mode++ {
+		if mode.String() == s {
+			return mode, true
+		}
+	}
+	return ViewModeDefault, false
+}
+
+// Next returns the mode that follows in the toolbar toggle.
+func (mode ViewMode) Next() ViewMode {
+	return (mode + 1) % (ViewModeSourceOnly + 1)
+}
+
+// interleavedRow is a single row of the interleaved view.
+type interleavedRow struct {
+	// inst is the index of the instruction, -1 for source lines.
+	inst int
+	text string
+	// foreign is set for the source lines of cgo code, see langPrefix.
+	foreign bool
+}
+
+// interleave orders the instructions after their source lines and
+// returns the rows and the row index of each instruction.
+func interleave(code *disasm.Code) (rows []interleavedRow, instRows []int) {
+	type fileLine struct {
+		file string
+		line int
+	}
+	lines := map[fileLine]string{}
+	for _, src := range code.Source {
+		for _, block := range src.Blocks {
+			for off, text := range block.Lines {
+				lines[fileLine{src.File, block.From + off}] = text
+			}
+		}
+	}
+
+	instRows = make([]int, len(code.Insts))
+	var prev fileLine
+	for i, ix := range code.Insts {
+		if at := (fileLine{ix.File, ix.Line}); ix.Text != "" && at != prev {
+			prev = at
+			text, ok := lines[at]
+			if !ok {
+				text = fmt.Sprintf("%s:%d", filepath.Base(ix.File), ix.Line)
+			}
+			prefix := langPrefix(disasm.DetectLanguage(ix.File))
+			rows = append(rows, interleavedRow{
+				inst:    -1,
+				text:    fmt.Sprintf("%s%-4d %s", prefix, ix.Line, strings.TrimRight(text, " \t")),
+				foreign: prefix != "",
+			})
+		}
+		instRows[i] = len(rows)
+		rows = append(rows, interleavedRow{inst: i, text: ix.Text})
+	}
+	return rows, instRows
+}
+
+// layoutInterleaved draws the source lines as dimmer headers
+// followed by the instructions compiled from them.
+func (ui CodeUIStyle) layoutInterleaved(gtx layout.Context) layout.Dimensions {
+	defer clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops).Pop()
+
+	if ui.interleaved.code != ui.Code {
+		ui.interleaved.code = ui.Code
+		ui.interleaved.rows, ui.interleaved.instRows = interleave(ui.Code)
+	}
+	rows, instRows := ui.interleaved.rows, ui.interleaved.instRows
+
+	mouseClicked := false
+	event.Op(gtx.Ops, ui.Code)
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target: ui.Code,
+			Kinds:  pointer.Move | pointer.Press,
+		})
+		if !ok {
+			break
+		}
+		if ev, ok := ev.(pointer.Event); ok {
+			switch ev.Kind {
+			case pointer.Move:
+				ui.mousePosition = ev.Position
+			case pointer.Press:
+				mouseClicked = true
+			}
+		}
+	}
+
+	// The layout has the following sections:
+	// pad | Jump | pad/2 | Rows | pad
+
+	lineHeight := gtx.Metric.Sp(ui.LineHeight)
+	pad := lineHeight
+	jumpStep := lineHeight / 2
+	jump := BoundsWidth(pad, jumpStep*ui.Code.MaxJump)
+	text := BoundsWidth(int(jump.Max)+pad/2, gtx.Constraints.Max.X-int(jump.Max)-pad/2-pad)
+
+	if scroll, ok := ui.asm.anim.Update(gtx); ok {
+		ui.asm.scroll = scroll
+	}
+
+	highlightRow := -1
+	if text.Contains(ui.mousePosition.X) {
+		highlightRow = int(ui.mousePosition.Y-ui.asm.scroll) / lineHeight
+	}
+	if InRange(highlightRow, len(rows)) && rows[highlightRow].inst >= 0 {
+		if _, ok := ui.nops.headers[rows[highlightRow].inst]; ok {
+			pointer.CursorPointer.Add(gtx.Ops)
+			if mouseClicked {
+				ui.toggleNOPs(rows[highlightRow].inst)
+				gtx.Execute(op.InvalidateCmd{})
+			}
+		}
+		ix := &ui.Code.Insts[rows[highlightRow].inst]
+		if ui.TryOpen != nil && ix.Call != "" {
+			pointer.CursorPointer.Add(gtx.Ops)
+			if mouseClicked {
+				ui.TryOpen(gtx, ix.Call)
+			}
+		}
+		if ix.Call == "" && ix.RefOffset != 0 {
+			pointer.CursorPointer.Add(gtx.Ops)
+			if mouseClicked {
+				target := instRows[rows[highlightRow].inst+ix.RefOffset]
+				ui.asm.anim.Start(gtx, ui.asm.scroll, ui.asm.scroll-float32((target-highlightRow)*lineHeight), 150*time.Millisecond)
+			}
+		}
+	}
+
+	textColor := f32color.Black
+	if isDarkMode {
+		textColor = ui.Theme.Fg
+	}
+	sourceColor := textColor
+	sourceColor.A = 0x90
+
+	for r, row := range rows {
+		top := r*lineHeight + int(ui.asm.scroll)
+		if top+lineHeight < 0 || top > gtx.Constraints.Max.Y {
+			continue
+		}
+		if row.inst < 0 {
+			background := clip.Rect{
+				Min: image.Pt(int(text.Min), top),
+				Max: image.Pt(int(text.Max), top+lineHeight),
+			}
+			paint.FillShape(gtx.Ops, secondaryBackground, background.Op())
+			if row.foreign {
+				paint.FillShape(gtx.Ops, foreignSourceColor, background.Op())
+			}
+			SourceLine{
+				TopLeft:    image.Pt(int(text.Min)+pad/2, top),
+				Text:       row.text,
+				TextHeight: ui.TextHeight,
+				Italic:     true,
+				Color:      sourceColor,
+			}.Layout(ui.Theme, gtx)
+			continue
+		}
+		SourceLine{
+			TopLeft:    image.Pt(int(text.Min)+pad*2, top),
+			Text:       ui.instText(ui.Code.Insts[row.inst]),
+			TextHeight: ui.TextHeight,
+			Italic:     ui.Code.Insts[row.inst].Call != "",
+			Bold:       highlightRow == r,
+			Color:      ui.instColor(row.inst, textColor),
+		}.Layout(ui.Theme, gtx)
+	}
+
+	// jump lines
+	for i, ix := range ui.Code.Insts {
+		if ix.RefOffset == 0 || !InRange(i+ix.RefOffset, len(instRows)) {
+			continue
+		}
+		row, target := instRows[i], instRows[i+ix.RefOffset]
+
+		lineWidth := gtx.Metric.Dp(1)
+		align := float32(lineWidth%2) / 2
+		stack := op.Affine(f32.Affine2D{}.Offset(
+			f32.Pt(jump.Max+align, float32(row*lineHeight)+align+ui.asm.scroll))).Push(gtx.Ops)
+
+		var path clip.Path
+		path.Begin(gtx.Ops)
+		path.MoveTo(f32.Pt(float32(pad/2), float32(lineHeight*2/3)))
+		path.LineTo(f32.Pt(float32(-jumpStep*ix.RefStack), float32(lineHeight*2/3)))
+		path.LineTo(f32.Pt(float32(-jumpStep*ix.RefStack), float32(lineHeight/3+(target-row)*lineHeight)))
+		path.LineTo(f32.Pt(float32(-jumpStep/2), float32(lineHeight/3+(target-row)*lineHeight)))
+		// draw arrow
+		path.Line(f32.Pt(0, float32(lineHeight/4)))
+		path.Line(f32.Pt(float32(lineHeight/3), float32(-lineHeight/4)))
+		path.Line(f32.Pt(float32(-lineHeight/3), float32(-lineHeight/4)))
+		path.Line(f32.Pt(0, float32(lineHeight/4)))
+
+		width := float32(lineWidth)
+		alpha := float32(0.7)
+		if highlightRow == row || highlightRow == target {
+			width *= 3
+			alpha = 1
+		}
+		jumpColor := f32color.HSLA(float32(math.Mod(float64(ix.PC)*math.Phi, 1)), 0.8, 0.4, alpha)
+		paint.FillShape(gtx.Ops, jumpColor, clip.Stroke{Path: path.End(), Width: width}.Op())
+
+		stack.Pop()
+	}
+
+	{
+		stack := clip.Rect{
+			Min: image.Pt(int(jump.Min)-pad, 0),
+			Max: image.Pt(int(text.Max), gtx.Constraints.Max.Y),
+		}.Push(gtx.Ops)
+
+		overflow := lineHeight
+		contentTop := float32(-overflow)
+		contentBot := float32(len(rows)*lineHeight + overflow)
+		viewTop := -ui.asm.scroll
+		viewBot := -ui.asm.scroll + float32(gtx.Constraints.Max.Y)
+
+		{
+			stack := op.Offset(image.Pt(int(jump.Min)-pad, 0)).Push(gtx.Ops)
+			gtx := gtx
+			gtx.Constraints = layout.Exact(image.Pt(pad, gtx.Constraints.Max.Y))
+			material.Scrollbar(ui.Theme, &ui.asm.bar).Layout(gtx, layout.Vertical,
+				(viewTop-contentTop)/(contentBot-contentTop),
+				(viewBot-contentTop)/(contentBot-contentTop),
+			)
+			stack.Pop()
+		}
+
+		if distance := ui.asm.bar.ScrollDistance(); distance != 0 {
+			ui.asm.scroll -= distance * (contentBot - contentTop)
+		}
+		if distance := ui.asm.gesture.Update(gtx.Metric, gtx.Source, gtx.Now, gesture.Vertical,
+			pointer.ScrollRange{},
+			pointer.ScrollRange{Min: -1000, Max: 1000},
+		); distance != 0 {
+			ui.asm.scroll -= float32(distance)
+		}
+
+		if -ui.asm.scroll < contentTop {
+			ui.asm.scroll = -contentTop
+			ui.asm.anim.Stop()
+		}
+		if -ui.asm.scroll+float32(gtx.Constraints.Max.Y) > contentBot {
+			if contentBot < float32(gtx.Constraints.Max.Y) {
+				ui.asm.scroll = -contentTop
+			} else {
+				ui.asm.scroll = float32(gtx.Constraints.Max.Y) - contentBot
+			}
+			ui.asm.anim.Stop()
+		}
+		stack.Pop()
+	}
+
+	return layout.Dimensions{Size: gtx.Constraints.Max}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"gioui.org/app"
+	"gioui.org/unit"
+
+	"github.com/gameformush/goasm-vscode/internal/settings"
+)
+
+// defaultWindowSize and minWindowSize are the sizes of the main window in dp.
+var (
+	defaultWindowSize = image.Pt(1400, 900)
+	minWindowSize     = image.Pt(640, 400)
+)
+
+// windowState tracks the geometry of the main window, see saveWindow.
+type windowState struct {
+	mode app.WindowMode
+	// size is the last size in dp while the window wasn't maximized or fullscreen.
+	size image.Point
+}
+
+// LoadSettings reads the persisted preferences.
+func (ui *FileUI) LoadSettings() {
+	dir, err := configDir()
+	if err != nil {
+		return
+	}
+	if s, err := settings.Load(dir); err == nil {
+		ui.Settings = *s
+	} else {
+		log.Println(fmt.Errorf("failed to load settings: %w", err))
+	}
+}
+
+// WindowConfig returns the main window with the geometry it had when it was
+// last closed. Gio can't position windows, so the window manager places it.
+func (ui *FileUI) WindowConfig() WindowConfig {
+	config := WindowConfig{
+		Title:   "lensm",
+		Size:    defaultWindowSize,
+		MinSize: minWindowSize,
+	}
+	if saved := ui.Settings.Window; saved != nil {
+		if saved.Width > 0 && saved.Height > 0 {
+			config.Size = image.Pt(saved.Width, saved.Height)
+		}
+		config.Maximized = saved.Maximized
+	}
+	return config
+}
+
+// updateWindow records the geometry of the main window.
+func (ui *FileUI) updateWindow(e app.FrameEvent) {
+	if ui.windowState.mode != app.Windowed {
+		return
+	}
+	ui.windowState.size = image.Pt(
+		int(float32(e.Size.X)/e.Metric.PxPerDp+0.5),
+		int(float32(e.Size.Y)/e.Metric.PxPerDp+0.5),
+	)
+}
+
+// saveWindow persists the geometry of the main window and the width of the function list.
+func (ui *FileUI) saveWindow() {
+	state := &settings.Window{Maximized: ui.windowState.mode == app.Maximized}
+	if previous := ui.Settings.Window; previous != nil {
+		state.Width, state.Height = previous.Width, previous.Height
+	}
+	if size := ui.windowState.size; size.X > 0 && size.Y > 0 {
+		state.Width, state.Height = size.X, size.Y
+	}
+	ui.Settings.Window = state
+	ui.saveSettings()
+}
+
+// setFuncListWidth changes the width of the function list of all tabs.
+func (ui *FileUI) setFuncListWidth(width unit.Dp) {
+	ui.Settings.FuncListWidth = float32(width)
+	ui.saveSettings()
+	for _, tab := range ui.Tabs {
+		tab.FuncListWidth = width
+	}
+}
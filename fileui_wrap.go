@@ -0,0 +1,144 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"gioui.org/layout"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// updateWrap wraps the instructions wider than InstructionMaxWidth,
+// the interleaved view doesn't wrap.
+func (ui CodeUIStyle) updateWrap(gtx layout.Context) {
+	width := 0
+	if ui.InstructionMaxWidth > 0 && ui.ViewMode != ViewModeInterleaved {
+		width = max(gtx.Dp(ui.InstructionMaxWidth)/monoCharWidth(ui.Theme, gtx, ui.TextHeight), 1)
+	}
+	if ui.wrap.code == ui.Code && ui.wrap.width == width && ui.wrap.hex == ui.ShowHex {
+		return
+	}
+	ui.wrap.code, ui.wrap.width, ui.wrap.hex = ui.Code, width, ui.ShowHex
+	ui.wrap.lines, ui.wrap.rows = nil, nil
+	if width == 0 {
+		return
+	}
+
+	lines := make([][]string, len(ui.Code.Insts))
+	rows := make([]int, len(ui.Code.Insts)+1)
+	wrapped := false
+	for i, ix := range ui.Code.Insts {
+		text := ui.instText(ix)
+		// the operands are aligned after the mnemonic, and the encoding
+		// when it's shown
+		indent := len(text) - len(strings.TrimLeft(ix.Text, " \t")) + len(disasm.Mnemonic(ix.Text)) + 1
+		lines[i] = wrapInst(text, width, indent)
+		rows[i+1] = rows[i] + max(len(lines[i]), 1)
+		wrapped = wrapped || lines[i] != nil
+	}
+	if wrapped {
+		ui.wrap.lines, ui.wrap.rows = lines, rows
+	}
+}
+
+// wrapInst splits the instruction text into lines of at most width
+// characters, preferably after the commas between the operands. The
+// continuation lines are indented by indent, the width of the mnemonic.
+// It returns nil when text fits.
+func wrapInst(text string, width, indent int) []string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return nil
+	}
+	if indent >= width/2 {
+		// too narrow for aligning the operands
+		indent = 0
+	}
+
+	var lines []string
+	prefix := ""
+	for len(runes) > 0 {
+		avail := width - len(prefix)
+		if len(runes) <= avail {
+			lines = append(lines, prefix+string(runes))
+			break
+		}
+		// a space right after the line is dropped, so it can break there
+		cut := lastBreak(runes[:avail], ',')
+		if cut <= 0 {
+			cut = lastBreak(runes[:avail+1], ' ')
+		}
+		if cut <= 0 || prefix == "" && cut <= indent {
+			// don't leave the mnemonic alone on the first line
+			cut = avail
+		}
+		lines = append(lines, prefix+strings.TrimRight(string(runes[:cut]), " "))
+		for cut < len(runes) && runes[cut] == ' ' {
+			cut++
+		}
+		runes = runes[cut:]
+		prefix = strings.Repeat(" ", indent)
+	}
+	return lines
+}
+
+// lastBreak returns the index after the last sep in runes,
+// 0 when there's none.
+func lastBreak(runes []rune, sep rune) int {
+	for k := len(runes); k > 1; k-- {
+		if runes[k-1] == sep {
+			return k
+		}
+	}
+	return 0
+}
+
+// wrapRows returns the first row of each instruction of Code followed
+// by the number of rows, nil when no instruction is wrapped.
+func (ui *CodeUI) wrapRows() []int {
+	if ui.wrap.code != ui.Code {
+		return nil
+	}
+	return ui.wrap.rows
+}
+
+// instRow returns the first row of the instruction i, the rows of
+// len(Insts) is the number of rows.
+func (ui *CodeUI) instRow(i int) int {
+	rows := ui.wrapRows()
+	if !InRange(i, len(rows)) {
+		return i
+	}
+	return rows[i]
+}
+
+// instRowCount returns the number of rows of the instruction i.
+func (ui *CodeUI) instRowCount(i int) int {
+	return ui.instRow(i+1) - ui.instRow(i)
+}
+
+// instAtRow returns the instruction drawn at row, which is out of range
+// for rows outside of the code.
+func (ui *CodeUI) instAtRow(row int) int {
+	rows := ui.wrapRows()
+	if rows == nil || row < 0 {
+		return row
+	}
+	if total := rows[len(rows)-1]; row >= total {
+		return len(rows) - 1 + row - total
+	}
+	i, found := slices.BinarySearch(rows, row)
+	if !found {
+		i--
+	}
+	return i
+}
+
+// instLines returns the lines drawn for the instruction i.
+func (ui CodeUIStyle) instLines(i int, ix disasm.Inst) []string {
+	if ui.wrapRows() != nil && ui.wrap.lines[i] != nil {
+		return ui.wrap.lines[i]
+	}
+	return []string{ui.instText(ix)}
+}
@@ -2,15 +2,21 @@ package main
 
 import (
 	"fmt"
+	"image"
 	"regexp"
+	"slices"
+	"strings"
 
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
 )
 
 type FilterListItem interface {
@@ -23,17 +29,80 @@ type FilterList[T FilterListItem] struct {
 	Filter      widget.Editor
 	FilterError string
 	Filtered    []T
+	// MaxItems caps Filtered after sorting, 0 is unlimited.
+	// Matched is the number of items before the cap.
+	MaxItems int
+	Matched  int
 
 	Selected     string
 	SelectedItem T
 
+	// Marker, when set, returns a prefix that is drawn before the item name.
+	Marker func(item T) string
+	// Badges, when set, returns labels that are drawn on a colored
+	// background after the item name. Empty labels are not drawn.
+	Badges func(item T) []Badge
+	// Include, when set, additionally restricts which items are listed.
+	Include func(item T) bool
+	// SourceFuncs, when set, shows the source file filter and returns
+	// the items compiled from a source file matching the pattern.
+	SourceFuncs func(pattern string) ([]T, error)
+	Source      widget.Editor
+	// sourcePattern is the pattern sourceNames were found for.
+	sourcePattern string
+	sourceNames   map[string]bool
+	// Prefetch, when set, is called with the items next to the selection,
+	// nearest first, whenever the selection changes.
+	Prefetch func(items []T)
+
+	// SortField and SortOrder define the order of Filtered.
+	SortField SortField
+	SortOrder SortOrder
+	// SortChanged, when set, is called after the sort is changed from the UI.
+	SortChanged func(field SortField, order SortOrder)
+	// InstCount, when set, counts the instructions of an item
+	// for SortByInstructionCount, false when the count isn't known yet.
+	InstCount  func(item T) (int, bool)
+	instCounts map[string]int
+	// Complexity, when set, returns the cyclomatic complexity of an item
+	// for SortByComplexity, false when it isn't known yet.
+	Complexity   func(item T) (int, bool)
+	complexities map[string]int
+	sort         struct {
+		toggle widget.Clickable
+		open   bool
+		fields [SortByComplexity + 1]widget.Clickable
+	}
+
+	// PinnedChanged, when set, is called after the pinned items are
+	// changed from the UI.
+	PinnedChanged func(pinned []string)
+	pin           pinState
+
+	// PackageLabel, when set, returns the text of a package filter chip,
+	// e.g. to qualify the main package with its module path.
+	PackageLabel func(pkg string) string
+
+	// packages restricts the list to these packages, when non-empty.
+	packages     map[string]bool
+	packageChips []packageChip
+	chipList     layout.List
+
 	List SelectList
 }
 
+// packageChip is a toggle in the package filter row.
+type packageChip struct {
+	name  string
+	click widget.Clickable
+}
+
 // NewFilterList creates a new list with the specified theme.
 func NewFilterList[T FilterListItem](theme *material.Theme) *FilterList[T] {
 	ui := &FilterList[T]{}
 	ui.Filter.SingleLine = true
+	ui.Source.SingleLine = true
+	ui.pin.hovered = -1
 	ui.List = NewVerticalSelectList(unit.Dp(theme.TextSize) + 4)
 	return ui
 }
@@ -44,23 +113,100 @@ func (ui *FilterList[T]) SelectIndex(index int) {
 		return
 	}
 
+	changed := ui.Selected != ui.Filtered[index].Name()
 	ui.List.Selected = index
 	ui.Selected = ui.Filtered[index].Name()
 	ui.SelectedItem = ui.Filtered[index]
+	if changed {
+		ui.List.ScrollTo(index)
+	}
+	if changed && ui.Prefetch != nil {
+		ui.Prefetch(ui.adjacent(index))
+	}
+}
+
+// prefetchAdjacent is the number of items prefetched on each side of the selection.
+const prefetchAdjacent = 2
+
+// adjacent returns the filtered items around index, nearest first.
+func (ui *FilterList[T]) adjacent(index int) []T {
+	items := make([]T, 0, 2*prefetchAdjacent)
+	for distance := 1; distance <= prefetchAdjacent; distance++ {
+		if next := index + distance; InRange(next, len(ui.Filtered)) {
+			items = append(items, ui.Filtered[next])
+		}
+		if previous := index - distance; InRange(previous, len(ui.Filtered)) {
+			items = append(items, ui.Filtered[previous])
+		}
+	}
+	return items
 }
 
 // SetItems updates the full list.
 func (ui *FilterList[T]) SetItems(all []T) {
 	ui.All = all
+	ui.instCounts = nil
+	ui.complexities = nil
+	ui.sourceNames = nil
 	ui.updateFiltered()
 }
 
-// SetFilter sets the filter.
+// SetFilter sets the filter and updates Filtered and the selected index.
+// The caller is responsible for invalidating the window.
 func (ui *FilterList[T]) SetFilter(filter string) {
 	ui.Filter.SetText(filter)
 	ui.updateFiltered()
 }
 
+// GetFilter returns the filter, e.g. for storing it in the settings.
+func (ui *FilterList[T]) GetFilter() string {
+	return ui.Filter.Text()
+}
+
+// RestoreState sets the filter and the selected item together, so that
+// the selection is looked up in the newly filtered items.
+// An empty selected clears the selection.
+func (ui *FilterList[T]) RestoreState(filter, selected string) {
+	var zero T
+	ui.Selected = selected
+	ui.SelectedItem = zero
+	ui.Filter.SetText(filter)
+	ui.updateFiltered()
+	if ui.List.Selected >= 0 {
+		ui.List.ScrollTo(ui.List.Selected)
+	}
+}
+
+// SetPackages sets the packages shown in the package filter row.
+// An empty list hides the row.
+func (ui *FilterList[T]) SetPackages(pkgs []string) {
+	ui.packageChips = make([]packageChip, len(pkgs))
+	for i, pkg := range pkgs {
+		ui.packageChips[i].name = pkg
+	}
+}
+
+// SetPackageFilter restricts the list to items from pkgs,
+// in addition to the filter. An empty list includes every package.
+func (ui *FilterList[T]) SetPackageFilter(pkgs []string) {
+	ui.packages = make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		ui.packages[pkg] = true
+	}
+	ui.updateFiltered()
+}
+
+// PackageFilter returns the packages the list is restricted to.
+func (ui *FilterList[T]) PackageFilter() []string {
+	pkgs := make([]string, 0, len(ui.packages))
+	for _, chip := range ui.packageChips {
+		if ui.packages[chip.name] {
+			pkgs = append(pkgs, chip.name)
+		}
+	}
+	return pkgs
+}
+
 // updateFiltered updates the filtered list from the unfiltered content.
 func (ui *FilterList[T]) updateFiltered() {
 	defer func() {
@@ -81,13 +227,59 @@ func (ui *FilterList[T]) updateFiltered() {
 		ui.FilterError = err.Error()
 		return
 	}
+	sourceNames, err := ui.matchSource()
+	if err != nil {
+		ui.FilterError = err.Error()
+		return
+	}
 
 	ui.Filtered = ui.Filtered[:0]
+	var pinned []T
 	for _, item := range ui.All {
-		if rx.MatchString(item.Name()) {
+		if ui.IsPinned(item.Name()) {
+			pinned = append(pinned, item)
+			continue
+		}
+		if len(ui.packages) > 0 && !ui.packages[disasm.PackageOf(item.Name())] {
+			continue
+		}
+		if sourceNames != nil && !sourceNames[item.Name()] {
+			continue
+		}
+		if rx.MatchString(item.Name()) && (ui.Include == nil || ui.Include(item)) {
 			ui.Filtered = append(ui.Filtered, item)
 		}
 	}
+	ui.sortFiltered()
+	ui.Filtered = slices.Insert(ui.Filtered, 0, ui.sortPinned(pinned)...)
+	ui.Matched = len(ui.Filtered)
+	if ui.MaxItems > 0 && len(ui.Filtered) > ui.MaxItems {
+		clear(ui.Filtered[ui.MaxItems:])
+		ui.Filtered = ui.Filtered[:ui.MaxItems]
+	}
+}
+
+// matchSource returns the names of the items matching the source file
+// filter, nil when there's no filter. The names are cached by pattern,
+// since finding them may disassemble every item.
+func (ui *FilterList[T]) matchSource() (map[string]bool, error) {
+	pattern := strings.TrimSpace(ui.Source.Text())
+	if ui.SourceFuncs == nil || pattern == "" {
+		return nil, nil
+	}
+	if ui.sourceNames != nil && ui.sourcePattern == pattern {
+		return ui.sourceNames, nil
+	}
+	items, err := ui.SourceFuncs(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("source file: %w", err)
+	}
+	ui.sourcePattern = pattern
+	ui.sourceNames = make(map[string]bool, len(items))
+	for _, item := range items {
+		ui.sourceNames[item.Name()] = true
+	}
+	return ui.sourceNames, nil
 }
 
 // Layout draws the list.
@@ -106,19 +298,59 @@ func (ui *FilterList[T]) Layout(th *material.Theme, gtx layout.Context) layout.D
 			changed = true
 		}
 	}
+	for {
+		ev, ok := ui.Source.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.ChangeEvent); ok {
+			changed = true
+		}
+	}
+
+	for i := range ui.packageChips {
+		chip := &ui.packageChips[i]
+		for chip.click.Clicked(gtx) {
+			if ui.packages == nil {
+				ui.packages = map[string]bool{}
+			}
+			if ui.packages[chip.name] {
+				delete(ui.packages, chip.name)
+			} else {
+				ui.packages[chip.name] = true
+			}
+			changed = true
+		}
+	}
 
 	if changed {
 		ui.updateFiltered()
 		gtx.Execute(op.InvalidateCmd{})
 	}
+	ui.updateSort(gtx)
+	ui.updatePins(gtx)
+	ui.countVisible(gtx)
 
 	return layout.Flex{
 		Axis: layout.Vertical,
 	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return ui.layoutSort(th, gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return ui.layoutPackages(th, gtx)
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return FocusBorder(th, gtx.Focused(&ui.Filter)).Layout(gtx,
 				material.Editor(th, &ui.Filter, "Filter (regexp)").Layout)
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if ui.SourceFuncs == nil {
+				return layout.Dimensions{}
+			}
+			return FocusBorder(th, gtx.Focused(&ui.Source)).Layout(gtx,
+				material.Editor(th, &ui.Source, "Source file (substring or glob)").Layout)
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			if ui.FilterError == "" {
 				return layout.Dimensions{}
@@ -126,10 +358,30 @@ func (ui *FilterList[T]) Layout(th *material.Theme, gtx layout.Context) layout.D
 			return material.Body1(th, ui.FilterError).Layout(gtx)
 		}),
 		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
-			return ui.List.Layout(th, gtx, len(ui.Filtered),
-				StringListItem(th, &ui.List, func(index int) string {
-					return ui.Filtered[index].Name()
-				}))
+			name := func(index int) string {
+				item := ui.Filtered[index]
+				if ui.Marker != nil {
+					return ui.Marker(item) + item.Name()
+				}
+				return item.Name()
+			}
+			element := StringListItem(th, &ui.List, name)
+			if ui.Badges != nil {
+				element = BadgeListItem(th, &ui.List, name, func(index int) []Badge {
+					return ui.Badges(ui.Filtered[index])
+				})
+			}
+			dims := ui.List.Layout(th, gtx, len(ui.Filtered), ui.pinListItem(th, element))
+			ui.layoutPinArea(gtx, dims)
+			return dims
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if ui.Matched <= len(ui.Filtered) {
+				return layout.Dimensions{}
+			}
+			body := material.Body2(th, fmt.Sprintf("Showing first %d of %d functions — refine your filter", len(ui.Filtered), ui.Matched))
+			body.Alignment = text.Middle
+			return layout.UniformInset(4).Layout(gtx, body.Layout)
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			body := material.Body1(th, fmt.Sprintf("%d / %d", len(ui.Filtered), len(ui.All)))
@@ -138,3 +390,44 @@ func (ui *FilterList[T]) Layout(th *material.Theme, gtx layout.Context) layout.D
 		}),
 	)
 }
+
+// layoutPackages draws the package filter as a scrollable row of chips.
+func (ui *FilterList[T]) layoutPackages(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	if len(ui.packageChips) == 0 {
+		return layout.Dimensions{}
+	}
+	ui.chipList.Axis = layout.Horizontal
+	return ui.chipList.Layout(gtx, len(ui.packageChips), func(gtx layout.Context, index int) layout.Dimensions {
+		chip := &ui.packageChips[index]
+		selected := ui.packages[chip.name]
+		return layout.UniformInset(2).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &chip.click, func(gtx layout.Context) layout.Dimensions {
+				macro := op.Record(gtx.Ops)
+				name := chip.name
+				if ui.PackageLabel != nil {
+					name = ui.PackageLabel(name)
+				}
+				if name == "" {
+					name = "(none)"
+				}
+				txt := material.Body2(th, name)
+				txt.TextSize *= 0.9
+				if selected {
+					txt.Color = th.ContrastFg
+				}
+				dims := layout.Inset{Top: 2, Left: 6, Right: 6, Bottom: 2}.Layout(gtx, txt.Layout)
+				call := macro.Stop()
+
+				bg := splitterColor
+				bg.A = 0x40
+				if selected {
+					bg = th.ContrastBg
+				}
+				radius := dims.Size.Y / 2
+				paint.FillShape(gtx.Ops, bg, clip.UniformRRect(image.Rectangle{Max: dims.Size}, radius).Op(gtx.Ops))
+				call.Add(gtx.Ops)
+				return dims
+			})
+		})
+	})
+}
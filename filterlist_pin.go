@@ -0,0 +1,184 @@
+package main
+
+import (
+	"image"
+	"slices"
+
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/text"
+	"gioui.org/widget/material"
+)
+
+// pinState tracks the pinned items of FilterList and the pointer
+// in the pin column, where items are pinned and dragged to reorder.
+type pinState struct {
+	// names are the pinned items in the order they're listed.
+	names []string
+
+	// hovered is the row under the pointer in the pin column, -1 for none.
+	hovered int
+	// pressed is the item pressed in the pin column, empty when
+	// there's no press. moved is set once it was dragged to another row.
+	pressed string
+	moved   bool
+}
+
+// pinBorder is the width of the focus border around the list, see FocusBorder.
+const pinBorder = 2
+
+// SetPinned sets the pinned items, which are listed first in the given order.
+func (ui *FilterList[T]) SetPinned(names []string) {
+	ui.pin.names = slices.Clone(names)
+	ui.updateFiltered()
+}
+
+// Pinned returns the pinned items in the order they're listed.
+func (ui *FilterList[T]) Pinned() []string {
+	return slices.Clone(ui.pin.names)
+}
+
+// IsPinned returns whether the item is pinned.
+func (ui *FilterList[T]) IsPinned(name string) bool {
+	return slices.Contains(ui.pin.names, name)
+}
+
+// TogglePin pins the item after the other pinned items, or unpins it.
+func (ui *FilterList[T]) TogglePin(name string) {
+	if i := slices.Index(ui.pin.names, name); i >= 0 {
+		ui.pin.names = slices.Delete(ui.pin.names, i, i+1)
+	} else {
+		ui.pin.names = append(ui.pin.names, name)
+	}
+	ui.updateFiltered()
+}
+
+// sortPinned orders the pinned items by pin order.
+func (ui *FilterList[T]) sortPinned(items []T) []T {
+	slices.SortFunc(items, func(a, b T) int {
+		return slices.Index(ui.pin.names, a.Name()) - slices.Index(ui.pin.names, b.Name())
+	})
+	return items
+}
+
+// movePin moves the pinned item name to the position of the pinned item target.
+func (ui *FilterList[T]) movePin(name, target string) {
+	from, to := slices.Index(ui.pin.names, name), slices.Index(ui.pin.names, target)
+	if from < 0 || to < 0 || from == to {
+		return
+	}
+	ui.pin.names = slices.Delete(ui.pin.names, from, from+1)
+	ui.pin.names = slices.Insert(ui.pin.names, to, name)
+	ui.updateFiltered()
+}
+
+// pinRow returns the row of the list at y in the pin column.
+func (ui *FilterList[T]) pinRow(gtx layout.Context, y float32) int {
+	itemHeight := gtx.Dp(ui.List.ItemHeight)
+	if itemHeight <= 0 {
+		return -1
+	}
+	pos := ui.List.List.Position
+	offset := pos.First*itemHeight + pos.Offset + int(y) - gtx.Dp(pinBorder)
+	if offset < 0 {
+		return -1
+	}
+	return offset / itemHeight
+}
+
+// updatePins handles the clicks and drags in the pin column. A click toggles
+// the pin of the item, dragging a pinned item moves it among the pinned items.
+func (ui *FilterList[T]) updatePins(gtx layout.Context) {
+	changed := false
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target: &ui.pin,
+			Kinds:  pointer.Press | pointer.Drag | pointer.Release | pointer.Move | pointer.Leave | pointer.Cancel,
+		})
+		if !ok {
+			break
+		}
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+		row := ui.pinRow(gtx, e.Position.Y)
+		switch e.Kind {
+		case pointer.Move:
+			ui.pin.hovered = row
+		case pointer.Leave:
+			ui.pin.hovered = -1
+		case pointer.Press:
+			ui.pin.pressed, ui.pin.moved = "", false
+			if InRange(row, len(ui.Filtered)) {
+				ui.pin.pressed = ui.Filtered[row].Name()
+			}
+		case pointer.Drag:
+			if ui.pin.pressed == "" || !ui.IsPinned(ui.pin.pressed) {
+				break
+			}
+			row = max(min(row, len(ui.pin.names)-1, len(ui.Filtered)-1), 0)
+			if target := ui.Filtered[row].Name(); target != ui.pin.pressed && ui.IsPinned(target) {
+				ui.movePin(ui.pin.pressed, target)
+				ui.pin.moved = true
+				changed = true
+			}
+			ui.pin.hovered = row
+		case pointer.Release:
+			if ui.pin.pressed != "" && !ui.pin.moved {
+				ui.TogglePin(ui.pin.pressed)
+				changed = true
+			}
+			ui.pin.pressed = ""
+		case pointer.Cancel:
+			ui.pin.pressed = ""
+			ui.pin.hovered = -1
+		}
+		gtx.Execute(op.InvalidateCmd{})
+	}
+	if changed && ui.PinnedChanged != nil {
+		ui.PinnedChanged(ui.Pinned())
+	}
+}
+
+// pinListItem draws the pin column before element. Pinned items always
+// show the pin, the other items only while the pointer is over it.
+func (ui *FilterList[T]) pinListItem(th *material.Theme, element layout.ListElement) layout.ListElement {
+	return func(gtx layout.Context, index int) layout.Dimensions {
+		size := gtx.Constraints.Max
+		width := size.Y
+
+		pinned := ui.IsPinned(ui.Filtered[index].Name())
+		if pinned || ui.pin.hovered == index {
+			label := material.Body2(th, "📌")
+			label.Alignment = text.Middle
+			label.MaxLines = 1
+			label.TextSize = th.TextSize * 7 / 10
+			if !pinned {
+				label.Color.A /= 3
+			}
+			pinGtx := gtx
+			pinGtx.Constraints = layout.Exact(image.Pt(width, size.Y))
+			layout.Center.Layout(pinGtx, label.Layout)
+		}
+
+		stack := op.Offset(image.Pt(width, 0)).Push(gtx.Ops)
+		gtx.Constraints = layout.Exact(image.Pt(max(size.X-width, 0), size.Y))
+		element(gtx, index)
+		stack.Pop()
+
+		return layout.Dimensions{Size: size}
+	}
+}
+
+// layoutPinArea registers the pin column of the list drawn with dims
+// for the pointer, above the list so that it doesn't select the item.
+func (ui *FilterList[T]) layoutPinArea(gtx layout.Context, dims layout.Dimensions) {
+	width := gtx.Dp(ui.List.ItemHeight) + gtx.Dp(pinBorder)
+	defer clip.Rect{Max: image.Pt(min(width, dims.Size.X), dims.Size.Y)}.Push(gtx.Ops).Pop()
+	pointer.CursorPointer.Add(gtx.Ops)
+	event.Op(gtx.Ops, &ui.pin)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"slices"
+	"sort"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/widget/material"
+)
+
+// SortField selects how FilterList orders the items.
+type SortField int
+
+const (
+	// SortByName keeps the order of the source, which lists items
+	// alphabetically ignoring case.
+	SortByName SortField = iota
+	// SortBySize orders by the size in bytes, for items with a `Size() int64` method.
+	SortBySize
+	// SortByInstructionCount orders by the number of instructions,
+	// which are counted lazily for the visible items.
+	SortByInstructionCount
+	// SortByComplexity orders by the cyclomatic complexity,
+	// which is computed lazily for the visible items.
+	SortByComplexity
+)
+
+// String returns the name of the field, as stored in the settings.
+func (field SortField) String() string {
+	switch field {
+	case SortBySize:
+		return "size"
+	case SortByInstructionCount:
+		return "instructions"
+	case SortByComplexity:
+		return "complexity"
+	default:
+		return "name"
+	}
+}
+
+// ParseSortField parses the result of SortField.String.
+func ParseSortField(s string) (SortField, bool) {
+	for field := SortByName; field <= SortByComplexity; field++ {
+		if field.String() == s {
+			return field, true
+		}
+	}
+	return SortByName, false
+}
+
+// SortOrder is the direction of sorting.
+type SortOrder int
+
+const (
+	Ascending SortOrder = iota
+	Descending
+)
+
+// SetSort changes the order of the items.
+func (ui *FilterList[T]) SetSort(field SortField, order SortOrder) {
+	ui.SortField = field
+	ui.SortOrder = order
+	ui.updateFiltered()
+}
+
+// sortFiltered orders Filtered by SortField and SortOrder.
+// Items with an unknown size or count are kept at the end.
+func (ui *FilterList[T]) sortFiltered() {
+	less := func(a, b int64) bool {
+		if a < 0 || b < 0 {
+			return a >= 0 && b < 0
+		}
+		if ui.SortOrder == Descending {
+			return a > b
+		}
+		return a < b
+	}
+
+	switch ui.SortField {
+	case SortByName:
+		if ui.SortOrder == Descending {
+			slices.Reverse(ui.Filtered)
+		}
+	case SortBySize:
+		sort.SliceStable(ui.Filtered, func(i, k int) bool {
+			return less(itemSize(ui.Filtered[i]), itemSize(ui.Filtered[k]))
+		})
+	case SortByInstructionCount:
+		sort.SliceStable(ui.Filtered, func(i, k int) bool {
+			return less(measured(ui.instCounts, ui.Filtered[i]), measured(ui.instCounts, ui.Filtered[k]))
+		})
+	case SortByComplexity:
+		sort.SliceStable(ui.Filtered, func(i, k int) bool {
+			return less(measured(ui.complexities, ui.Filtered[i]), measured(ui.complexities, ui.Filtered[k]))
+		})
+	}
+}
+
+// itemSize returns the size of item, or -1 when it's unknown.
+func itemSize(item any) int64 {
+	if sized, ok := item.(interface{ Size() int64 }); ok {
+		return sized.Size()
+	}
+	return -1
+}
+
+// measured returns the cached value of item, or -1 when it's unknown.
+func measured[T FilterListItem](cache map[string]int, item T) int64 {
+	if value, ok := cache[item.Name()]; ok {
+		return int64(value)
+	}
+	return -1
+}
+
+// countVisible requests the values of the visible items for the lazily
+// computed sort fields and resorts the list when new values are available.
+// The values may be measured in the background, the window is redrawn then.
+func (ui *FilterList[T]) countVisible(gtx layout.Context) {
+	var measure func(item T) (int, bool)
+	var cache *map[string]int
+	switch ui.SortField {
+	case SortByInstructionCount:
+		measure, cache = ui.InstCount, &ui.instCounts
+	case SortByComplexity:
+		measure, cache = ui.Complexity, &ui.complexities
+	}
+	if measure == nil {
+		return
+	}
+	if *cache == nil {
+		*cache = map[string]int{}
+	}
+
+	pos := ui.List.List.Position
+	first := max(pos.First, 0)
+	last := min(first+max(pos.Count, 1)+1, len(ui.Filtered))
+	counted := false
+	for _, item := range ui.Filtered[first:last] {
+		if _, ok := (*cache)[item.Name()]; ok {
+			continue
+		}
+		if value, ok := measure(item); ok {
+			(*cache)[item.Name()] = value
+			counted = true
+		}
+	}
+	if counted {
+		ui.updateFiltered()
+		gtx.Execute(op.InvalidateCmd{})
+	}
+}
+
+// updateSort handles the clicks of the sort dropdown.
+func (ui *FilterList[T]) updateSort(gtx layout.Context) {
+	for ui.sort.toggle.Clicked(gtx) {
+		ui.sort.open = !ui.sort.open
+	}
+	for i := range ui.sort.fields {
+		for ui.sort.fields[i].Clicked(gtx) {
+			field := SortField(i)
+			order := ui.SortOrder
+			if field == ui.SortField {
+				order = 1 - order
+			}
+			ui.SetSort(field, order)
+			ui.sort.open = false
+			if ui.SortChanged != nil {
+				ui.SortChanged(field, order)
+			}
+		}
+	}
+}
+
+// layoutSort draws the sort dropdown.
+func (ui *FilterList[T]) layoutSort(th *material.Theme, gtx layout.Context) layout.Dimensions {
+	arrow := "↑"
+	if ui.SortOrder == Descending {
+		arrow = "↓"
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &ui.sort.toggle, func(gtx layout.Context) layout.Dimensions {
+				txt := material.Body2(th, "Sort: "+ui.SortField.String()+" "+arrow+" ▾")
+				txt.TextSize *= 0.9
+				return layout.Inset{Top: 2, Left: 4, Right: 4, Bottom: 2}.Layout(gtx, txt.Layout)
+			})
+		}),
+	}
+	if ui.sort.open {
+		for i := range ui.sort.fields {
+			field := SortField(i)
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Clickable(gtx, &ui.sort.fields[i], func(gtx layout.Context) layout.Dimensions {
+					txt := material.Body2(th, field.String())
+					txt.TextSize *= 0.9
+					if field == ui.SortField {
+						txt.Font.Weight = font.Bold
+					}
+					return layout.Inset{Top: 2, Left: 16, Right: 4, Bottom: 2}.Layout(gtx, txt.Layout)
+				})
+			}))
+		}
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
@@ -10,3 +10,9 @@ var OpenInNewIcon = func() *widget.Icon {
 	icon, _ := widget.NewIcon(icons.ActionOpenInNew)
 	return icon
 }()
+
+// ExportPNGIcon is used for exporting Code as an image.
+var ExportPNGIcon = func() *widget.Icon {
+	icon, _ := widget.NewIcon(icons.ImagePhoto)
+	return icon
+}()
@@ -0,0 +1,152 @@
+// Package coverage maps Go coverage profiles to source lines.
+package coverage
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/cover"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// Profile contains the line coverage of a `go test -coverprofile` output.
+type Profile struct {
+	// Lines maps the file names of the profile, which are import paths,
+	// to the covered state of each line.
+	Lines map[string]map[int]bool
+
+	mu sync.Mutex
+	// resolved caches the lines of the file names found in binaries.
+	resolved map[string]map[int]bool
+}
+
+// Load parses the coverage profile at path.
+func Load(path string) (*Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse parses a coverage profile, e.g. uploaded to the server.
+func Parse(r io.Reader) (*Profile, error) {
+	profiles, err := cover.ParseProfilesFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := map[string]map[int]bool{}
+	for _, profile := range profiles {
+		covered := lines[profile.FileName]
+		if covered == nil {
+			covered = map[int]bool{}
+			lines[profile.FileName] = covered
+		}
+		for _, block := range profile.Blocks {
+			for line := block.StartLine; line <= block.EndLine; line++ {
+				covered[line] = covered[line] || block.Count > 0
+			}
+		}
+	}
+	return FromLines(lines), nil
+}
+
+// FromLines creates a profile from the coverage of each line by file name,
+// e.g. received from a server that parsed the profile.
+func FromLines(lines map[string]map[int]bool) *Profile {
+	return &Profile{
+		Lines:    lines,
+		resolved: map[string]map[int]bool{},
+	}
+}
+
+// SplitLines returns the sorted covered and uncovered lines of file.
+func (p *Profile) SplitLines(file string) (covered, uncovered []int) {
+	for line, c := range p.Lines[file] {
+		if c {
+			covered = append(covered, line)
+		} else {
+			uncovered = append(uncovered, line)
+		}
+	}
+	slices.Sort(covered)
+	slices.Sort(uncovered)
+	return covered, uncovered
+}
+
+// FileLines returns the coverage of file, which is usually an absolute path.
+// It returns nil when the profile doesn't contain the file.
+func (p *Profile) FileLines(file string) map[int]bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if lines, ok := p.resolved[file]; ok {
+		return lines
+	}
+	lines := p.Lines[file]
+	if lines == nil {
+		// The profile uses import paths, which usually end with the
+		// directory of the package, pick the longest matching suffix.
+		best := 1
+		for name, covered := range p.Lines {
+			if n := commonSuffix(file, name); n > best {
+				best, lines = n, covered
+			}
+		}
+	}
+	p.resolved[file] = lines
+	return lines
+}
+
+// commonSuffix returns the number of equal trailing path elements.
+func commonSuffix(a, b string) int {
+	as := strings.Split(strings.ReplaceAll(a, "\\", "/"), "/")
+	bs := strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[len(as)-1-n] == bs[len(bs)-1-n] {
+		n++
+	}
+	return n
+}
+
+// Line returns whether the line in file is covered,
+// ok is false when the line has no coverage data.
+func (p *Profile) Line(file string, line int) (covered, ok bool) {
+	covered, ok = p.FileLines(file)[line]
+	return covered, ok
+}
+
+// Percent returns the percentage of covered lines of the code,
+// only the lines of the function's own file are included.
+// ok is false when none of the lines have coverage data.
+func (p *Profile) Percent(code *disasm.Code) (percent float64, ok bool) {
+	lines := p.FileLines(code.File)
+	if lines == nil {
+		return 0, false
+	}
+
+	seen := map[int]bool{}
+	total, covered := 0, 0
+	for _, ix := range code.Insts {
+		if ix.File != code.File || seen[ix.Line] {
+			continue
+		}
+		seen[ix.Line] = true
+		if c, ok := lines[ix.Line]; ok {
+			total++
+			if c {
+				covered++
+			}
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(covered) / float64(total), true
+}
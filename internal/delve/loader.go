@@ -0,0 +1,314 @@
+// Package delve implements disasm.File for a live process by connecting
+// to a Delve debug session with its JSON-RPC API.
+//
+// The instructions are read from the memory of the process, and the
+// code is annotated with the PCs where its goroutines are stopped.
+package delve
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/goobj"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// Command is the dlv executable that is used for attaching to a process.
+var Command = "dlv"
+
+// dialTimeout limits connecting to the Delve API server.
+const dialTimeout = 5 * time.Second
+
+// listeningPrefix starts the line printed by a headless dlv with its address.
+const listeningPrefix = "API server listening at: "
+
+// scope evaluates the locations in the selected goroutine.
+var scope = api.EvalScope{GoroutineID: -1}
+
+var _ disasm.File = (*File)(nil)
+var _ disasm.Func = (*Func)(nil)
+
+// File is a process stopped by Delve.
+type File struct {
+	client *rpc2.RPCClient
+	// cmd is the dlv started by Attach, nil when connected to a session.
+	cmd     *exec.Cmd
+	funcs   []disasm.Func
+	buildID string
+}
+
+// Open attaches to the process when target is a pid, otherwise it connects
+// to the headless Delve server listening at target (format: host:port).
+func Open(target string) (*File, error) {
+	if pid, err := strconv.Atoi(target); err == nil {
+		return Attach(pid)
+	}
+	return Connect(target)
+}
+
+// Connect connects to a headless Delve server, e.g. started with
+// `dlv attach <pid> --headless --listen host:port`. A running process is
+// halted, since the goroutines can only be read while it's stopped.
+// Close disconnects without continuing the process, a dlv without
+// --accept-multiclient then exits and detaches from it.
+func Connect(addr string) (*File, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to delve: %w", err)
+	}
+	return newFile(rpc2.NewClientFromConn(conn), nil)
+}
+
+// Attach stops the process with pid by attaching Command to it.
+// Close detaches, which lets the process continue.
+func Attach(pid int) (*File, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(Command, "attach", strconv.Itoa(pid),
+		"--headless", "--api-version=2", "--listen=127.0.0.1:0")
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", Command, err)
+	}
+	// fail stops dlv and adds the reason it printed, e.g. for unknown pids
+	fail := func(err error) (*File, error) {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("%s attach failed: %w: %s", Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	addr, err := listenAddr(stdout)
+	if err != nil {
+		return fail(err)
+	}
+	// keep dlv from blocking on a full pipe
+	go func() { _, _ = io.Copy(io.Discard, stdout) }()
+
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return fail(fmt.Errorf("connecting to delve: %w", err))
+	}
+	file, err := newFile(rpc2.NewClientFromConn(conn), cmd)
+	if err != nil {
+		return fail(err)
+	}
+	return file, nil
+}
+
+// listenAddr reads the address of the API server from the output of dlv.
+func listenAddr(stdout io.Reader) (string, error) {
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if addr, ok := strings.CutPrefix(scanner.Text(), listeningPrefix); ok {
+			return strings.TrimSpace(addr), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("exited before listening")
+}
+
+// newFile loads the funcs of the process, closing client on failure.
+func newFile(client *rpc2.RPCClient, cmd *exec.Cmd) (*File, error) {
+	file := &File{client: client, cmd: cmd}
+	if err := file.load(); err != nil {
+		if cmd != nil {
+			_ = client.Detach(false)
+		} else {
+			_ = client.Disconnect(false)
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// load halts the process and lists its funcs.
+func (file *File) load() error {
+	state, err := file.client.GetStateNonBlocking()
+	if err != nil {
+		return fmt.Errorf("delve state: %w", err)
+	}
+	if state.Exited {
+		return errors.New("the process has exited")
+	}
+	if state.Running {
+		if _, err := file.client.Halt(); err != nil {
+			return fmt.Errorf("halting the process: %w", err)
+		}
+	}
+
+	names, err := file.client.ListFunctions("", 0)
+	if err != nil {
+		return fmt.Errorf("listing functions: %w", err)
+	}
+	sort.Slice(names, func(i, k int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[k])
+	})
+	file.funcs = make([]disasm.Func, len(names))
+	for i, name := range names {
+		file.funcs[i] = &Func{file: file, name: name}
+	}
+	file.buildID = file.client.BuildID()
+	return nil
+}
+
+func (file *File) Funcs() []disasm.Func { return file.funcs }
+
+// PackageFuncs groups the funcs by package path.
+func (file *File) PackageFuncs() map[string][]disasm.Func {
+	return disasm.GroupByPackage(file.funcs)
+}
+
+// BuildID returns the build ID of the executable of the process.
+func (file *File) BuildID() string { return file.buildID }
+
+// GoModPath returns "", Delve doesn't provide the build info.
+func (file *File) GoModPath() string { return "" }
+
+// SymbolTable lists the funcs, Delve doesn't provide their addresses
+// without disassembling them.
+func (file *File) SymbolTable() []disasm.Symbol {
+	table := make([]disasm.Symbol, 0, len(file.funcs))
+	for _, fn := range file.funcs {
+		table = append(table, disasm.Symbol{
+			Name:     fn.Name(),
+			Type:     "T",
+			Exported: disasm.IsExported(fn.Name()),
+		})
+	}
+	return table
+}
+
+// Close detaches from a process stopped by Attach,
+// or disconnects from the session of Connect.
+func (file *File) Close() error {
+	if file.cmd == nil {
+		return file.client.Disconnect(false)
+	}
+	err := file.client.Detach(false)
+	if waitErr := file.cmd.Wait(); err == nil {
+		err = waitErr
+	}
+	return err
+}
+
+// goroutinePCs returns the PCs where the goroutines are stopped, and where
+// the ones stopped in the runtime were called from user code.
+func (file *File) goroutinePCs() ([]uint64, error) {
+	var pcs []uint64
+	for start := 0; start >= 0; {
+		goroutines, next, err := file.client.ListGoroutines(start, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range goroutines {
+			pcs = append(pcs, g.CurrentLoc.PC)
+			if g.UserCurrentLoc.PC != g.CurrentLoc.PC {
+				pcs = append(pcs, g.UserCurrentLoc.PC)
+			}
+		}
+		start = next
+	}
+	return pcs, nil
+}
+
+// Func is a func of the process.
+type Func struct {
+	file *File
+	name string
+}
+
+func (fn *Func) Name() string { return fn.name }
+
+// BuildConstraints returns nil, the source file is only known after Load.
+func (fn *Func) BuildConstraints() []string { return nil }
+
+// Load disassembles the func from the memory of the process.
+// It returns nil when Delve fails, e.g. after the process exited.
+func (fn *Func) Load(opts disasm.Options) *disasm.Code {
+	code, err := fn.file.disassemble(fn.name, opts)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return nil
+	}
+	return code
+}
+
+// unknownFile is the file of the locations without line information.
+const unknownFile = "?"
+
+// rxTarget matches the absolute address of the jumps without DestLoc,
+// e.g. "JNE 0x4a1b2c".
+var rxTarget = regexp.MustCompile(`\s0x([\da-fA-F]+)$`)
+
+func (file *File) disassemble(name string, opts disasm.Options) (*disasm.Code, error) {
+	locs, _, err := file.client.FindLocation(scope, name, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("finding %s: %w", name, err)
+	}
+	if len(locs) == 0 {
+		return nil, fmt.Errorf("finding %s: no location", name)
+	}
+	insts, err := file.client.DisassemblePC(scope, locs[0].PC, api.GoFlavour)
+	if err != nil {
+		return nil, fmt.Errorf("disassembling %s: %w", name, err)
+	}
+	pcs, err := file.goroutinePCs()
+	if err != nil {
+		return nil, fmt.Errorf("listing goroutines: %w", err)
+	}
+	if len(insts) == 0 {
+		return &disasm.Code{Name: name}, nil
+	}
+
+	start, end := insts[0].Loc.PC, insts[len(insts)-1].Loc.PC
+	instructions := make([]disasm.Inst, 0, len(insts))
+	for _, inst := range insts {
+		ix := disasm.Inst{
+			PC:    inst.Loc.PC,
+			Text:  inst.Text,
+			Bytes: inst.Bytes,
+		}
+		if inst.Loc.File != unknownFile {
+			ix.File, ix.Line = inst.Loc.File, inst.Loc.Line
+		}
+		switch dest := inst.DestLoc; {
+		case dest != nil && dest.Function != nil && dest.Function.Name() != name:
+			ix.Call = dest.Function.Name()
+		case dest != nil:
+			ix.RefPC = dest.PC
+		default:
+			if match := rxTarget.FindStringSubmatch(inst.Text); match != nil {
+				if target, err := strconv.ParseUint(match[1], 16, 64); err == nil && start <= target && target <= end {
+					ix.RefPC = target
+				}
+			}
+		}
+		instructions = append(instructions, ix)
+	}
+
+	code := &disasm.Code{
+		Name:         name,
+		File:         instructions[0].File,
+		GoroutinePCs: pcs,
+	}
+	goobj.BuildCode(code, instructions, opts)
+	return code, nil
+}
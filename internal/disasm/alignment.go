@@ -0,0 +1,31 @@
+package disasm
+
+// Alignments the linker uses for the entries of funcs and the headers
+// of loops, in bytes.
+const (
+	FuncAlignment = 32
+	LoopAlignment = 16
+)
+
+// AlignmentAnnotations maps the indices of the instructions that start at
+// an alignment boundary to the alignment in bytes: FuncAlignment for the
+// entry of the func and LoopAlignment for the loop headers, see DetectLoops.
+// Headers that aren't at a boundary weren't aligned and aren't annotated.
+func AlignmentAnnotations(code *Code) map[int]int {
+	annotations := map[int]int{}
+	for _, loop := range DetectLoops(code) {
+		if code.Insts[loop.Header].PC%LoopAlignment == 0 {
+			annotations[loop.Header] = LoopAlignment
+		}
+	}
+	for i, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		if ix.PC%FuncAlignment == 0 {
+			annotations[i] = FuncAlignment
+		}
+		break
+	}
+	return annotations
+}
@@ -0,0 +1,87 @@
+package disasm
+
+import "strings"
+
+// boundsPanics are the prefixes of the runtime functions called when
+// an index or slice expression is out of range.
+var boundsPanics = []string{
+	"runtime.panicIndex",
+	"runtime.panicSlice",
+	"runtime.panicBounds",
+	"runtime.goPanicIndex",
+	"runtime.goPanicSlice",
+}
+
+// maxBoundsSetup is the number of instructions allowed between the
+// target of a bounds check branch and the call to the panic function.
+const maxBoundsSetup = 4
+
+// AnnotateBoundsChecks finds the instructions of bounds checks, a compare
+// followed by a branch to a runtime bounds panic. The result contains the
+// indices of both the compare and the branch into code.Insts.
+func AnnotateBoundsChecks(code *Code) map[int]bool {
+	checks := map[int]bool{}
+	for i, ix := range code.Insts {
+		if ix.Call != "" || ix.RefOffset == 0 {
+			continue
+		}
+		if ClassifyArch(code.Arch, ix.Text) != CategoryBranch || !callsPanic(code.Insts, i+ix.RefOffset, boundsPanics) {
+			continue
+		}
+		checks[i] = true
+		if prev := previousInst(code.Insts, i); prev >= 0 && isCompare(code.Insts[prev].Text) {
+			checks[prev] = true
+		}
+	}
+	return checks
+}
+
+// CountBoundsChecks returns the number of bounds check branches in code.
+func CountBoundsChecks(code *Code) int {
+	count := 0
+	for i := range AnnotateBoundsChecks(code) {
+		if ClassifyArch(code.Arch, code.Insts[i].Text) == CategoryBranch {
+			count++
+		}
+	}
+	return count
+}
+
+// callsPanic reports whether the instructions starting at target only set up
+// the arguments of a call to a func with any of the panic prefixes.
+func callsPanic(insts []Inst, target int, panics []string) bool {
+	for i := target; i >= 0 && i < len(insts) && i < target+maxBoundsSetup; i++ {
+		ix := insts[i]
+		if ix.Call != "" {
+			for _, prefix := range panics {
+				if strings.HasPrefix(ix.Call, prefix) {
+					return true
+				}
+			}
+			return false
+		}
+		if ix.Text != "" && !isMove(strings.ToUpper(Mnemonic(ix.Text))) {
+			return false
+		}
+	}
+	return false
+}
+
+// previousInst returns the index of the instruction before i,
+// skipping empty separator lines.
+func previousInst(insts []Inst, i int) int {
+	for i--; i >= 0; i-- {
+		if insts[i].Text != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// isCompare reports whether text is a compare instruction.
+func isCompare(text string) bool {
+	mnemonic := strings.ToUpper(Mnemonic(text))
+	return strings.HasPrefix(mnemonic, "CMP") ||
+		strings.HasPrefix(mnemonic, "TEST") ||
+		strings.HasPrefix(mnemonic, "CMN")
+}
@@ -0,0 +1,238 @@
+package disasm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// InstCategory is a coarse classification of an instruction.
+type InstCategory int
+
+const (
+	CategoryOther InstCategory = iota
+	CategoryBranch
+	CategoryCall
+	CategoryReturn
+	CategoryLoad
+	CategoryStore
+	CategoryArithmetic
+	CategoryVector
+)
+
+// String returns the lowercase name of the category.
+func (cat InstCategory) String() string {
+	switch cat {
+	case CategoryBranch:
+		return "branch"
+	case CategoryCall:
+		return "call"
+	case CategoryReturn:
+		return "return"
+	case CategoryLoad:
+		return "load"
+	case CategoryStore:
+		return "store"
+	case CategoryArithmetic:
+		return "arithmetic"
+	case CategoryVector:
+		return "vector"
+	default:
+		return "other"
+	}
+}
+
+// arm64Branches are the arm64 branch mnemonics that can't be
+// distinguished from other instructions by prefix, e.g. BIC.
+var arm64Branches = map[string]bool{
+	"B": true, "BR": true, "BEQ": true, "BNE": true, "BCS": true, "BCC": true,
+	"BHS": true, "BLO": true, "BMI": true, "BPL": true, "BVS": true, "BVC": true,
+	"BHI": true, "BLS": true, "BGE": true, "BLT": true, "BGT": true, "BLE": true,
+	"BAL": true, "CBZ": true, "CBNZ": true, "TBZ": true, "TBNZ": true,
+	// WebAssembly
+	"BR_IF": true, "BR_TABLE": true,
+	// RISC-V
+	"BLTU": true, "BGEU": true, "BGTU": true, "BLEU": true, "BEQZ": true,
+	"BNEZ": true, "BLTZ": true, "BGEZ": true, "BLEZ": true, "BGTZ": true,
+}
+
+// riscv64Loads and riscv64Stores are the RISC-V memory mnemonics in Go
+// and GNU syntax that aren't moves, e.g. "SD" and "LR.W".
+var riscv64Loads = map[string]bool{
+	"LB": true, "LBU": true, "LH": true, "LHU": true, "LW": true, "LWU": true,
+	"FLW": true, "FLD": true, "LRW": true, "LRD": true, "LR.W": true, "LR.D": true,
+}
+
+var riscv64Stores = map[string]bool{
+	"SB": true, "SH": true, "SW": true, "SD": true, "FSW": true, "FSD": true,
+	"SCW": true, "SCD": true, "SC.W": true, "SC.D": true,
+}
+
+// riscv64Scalar are the RISC-V integer mnemonics, whose X registers
+// must not be mistaken for x86 vector registers.
+var riscv64Scalar = map[string]bool{
+	"MOV": true, "MOVW": true, "MOVWU": true, "MOVH": true, "MOVHU": true, "MOVB": true, "MOVBU": true,
+	"ADD": true, "ADDI": true, "ADDW": true, "ADDIW": true, "SUB": true, "SUBW": true, "NEG": true, "NEGW": true,
+	"AND": true, "ANDI": true, "OR": true, "ORI": true, "XOR": true, "XORI": true, "NOT": true,
+	"SLL": true, "SLLI": true, "SLLW": true, "SLLIW": true, "SRL": true, "SRLI": true, "SRLW": true, "SRLIW": true,
+	"SRA": true, "SRAI": true, "SRAW": true, "SRAIW": true, "SLT": true, "SLTI": true, "SLTU": true, "SLTIU": true,
+	"SEQZ": true, "SNEZ": true, "LUI": true, "AUIPC": true, "MUL": true, "MULH": true, "MULHU": true,
+	"MULHSU": true, "MULW": true, "DIV": true, "DIVU": true, "DIVW": true, "DIVUW": true, "REM": true,
+	"REMU": true, "REMW": true, "REMUW": true, "CPOP": true, "CLZ": true, "CTZ": true,
+}
+
+// arithmeticPrefixes are the integer arithmetic and logic mnemonics.
+var arithmeticPrefixes = []string{
+	"ADD", "SUB", "MUL", "IMUL", "DIV", "IDIV", "UDIV", "SDIV", "INC", "DEC", "NEG",
+	"AND", "OR", "XOR", "EOR", "NOT", "BIC", "SHL", "SHR", "SAR", "ROL", "ROR",
+	"LSL", "LSR", "ASR", "LEA", "CMP", "CMN", "TEST", "TST", "ADC", "SBB", "MADD",
+	"MSUB", "POPCNT", "BSF", "BSR", "TZCNT", "LZCNT", "CLZ", "REM", "CLO",
+	"SLL", "SRL", "SRA", "SLT", "SEQZ", "SNEZ", "LUI", "AUIPC", "CPOP", "CTZ",
+}
+
+// rxVectorRegister matches the x86 vector registers in Go syntax.
+var rxVectorRegister = regexp.MustCompile(`\b[XYZ]\d+\b`)
+
+// ClassifyArch derives the category of an instruction of the architecture
+// arch, a GOARCH value. Unknown and empty archs use the heuristics of Classify.
+func ClassifyArch(arch, text string) InstCategory {
+	switch arch {
+	case "arm64":
+		return classifyARM64(text)
+	default:
+		return Classify(text)
+	}
+}
+
+// Classify derives the category of an instruction from its text,
+// e.g. "CALL runtime.morestack(SB)" is a CategoryCall. It recognizes
+// the mnemonics of every architecture, see ClassifyArch when it's known.
+func Classify(text string) InstCategory {
+	text = strings.TrimSpace(text)
+	mnemonic := strings.ToUpper(Mnemonic(text))
+	args := strings.TrimSpace(text[len(mnemonic):])
+
+	// RISC-V compressed instructions behave like their expanded forms,
+	// e.g. "C.LW" is a "LW".
+	mnemonic = strings.TrimPrefix(mnemonic, "C.")
+	switch {
+	case riscv64Loads[mnemonic]:
+		return CategoryLoad
+	case riscv64Stores[mnemonic]:
+		return CategoryStore
+	case (mnemonic == "JAL" || mnemonic == "JALR") && !strings.HasPrefix(args, "X0,") && !strings.HasPrefix(args, "zero,"):
+		// links the return address, unlike "JAL X0, target"
+		return CategoryCall
+	}
+
+	// WebAssembly uses "type.op", e.g. "i32.load".
+	if dot := strings.IndexByte(mnemonic, '.'); dot > 0 && !strings.HasPrefix(mnemonic, "B.") {
+		switch op := mnemonic[dot+1:]; {
+		case strings.HasPrefix(op, "LOAD"):
+			return CategoryLoad
+		case strings.HasPrefix(op, "STORE"):
+			return CategoryStore
+		default:
+			mnemonic = op
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(mnemonic, "RET") || mnemonic == "ERET":
+		return CategoryReturn
+	case strings.HasPrefix(mnemonic, "CALL") || mnemonic == "BL" || mnemonic == "BLR":
+		return CategoryCall
+	case strings.HasPrefix(mnemonic, "J") || strings.HasPrefix(mnemonic, "B.") || arm64Branches[mnemonic]:
+		return CategoryBranch
+	case !riscv64Scalar[mnemonic] && isVector(mnemonic, args):
+		return CategoryVector
+	}
+
+	if src, dst, ok := strings.Cut(args, ","); ok && isMove(mnemonic) {
+		switch {
+		case strings.Contains(dst, "("):
+			return CategoryStore
+		case strings.Contains(src, "("):
+			return CategoryLoad
+		}
+	}
+	switch {
+	case strings.HasPrefix(mnemonic, "LD"):
+		return CategoryLoad
+	case strings.HasPrefix(mnemonic, "ST"):
+		return CategoryStore
+	}
+
+	for _, prefix := range arithmeticPrefixes {
+		if strings.HasPrefix(mnemonic, prefix) {
+			return CategoryArithmetic
+		}
+	}
+	return CategoryOther
+}
+
+// isMove reports whether the mnemonic copies data between its operands.
+func isMove(mnemonic string) bool {
+	return strings.HasPrefix(mnemonic, "MOV") ||
+		strings.HasPrefix(mnemonic, "XCHG") ||
+		strings.HasPrefix(mnemonic, "FMOV")
+}
+
+// isVector reports whether the instruction operates on vector registers.
+func isVector(mnemonic, args string) bool {
+	switch {
+	case strings.HasPrefix(mnemonic, "PUSH"), strings.HasPrefix(mnemonic, "POP"),
+		strings.HasPrefix(mnemonic, "PAUSE"), strings.HasPrefix(mnemonic, "PREFETCH"),
+		strings.HasPrefix(mnemonic, "PRFM"), strings.HasPrefix(mnemonic, "PC"):
+		return false
+	case strings.HasPrefix(mnemonic, "V"), strings.HasPrefix(mnemonic, "P"):
+		return true
+	}
+	return rxVectorRegister.MatchString(args)
+}
+
+// arm64Vector matches the arm64 SIMD registers with an arrangement,
+// e.g. "V0.B16" in Go syntax and "v0.16b" in GNU syntax.
+var arm64Vector = regexp.MustCompile(`(?i)\bV\d+\.\w+`)
+
+// classifyARM64 classifies arm64 instructions in Go and GNU syntax.
+// Go syntax calls BL "CALL" and B "JMP", and writes most loads and stores
+// as moves with a memory operand, e.g. "MOVD 8(R0), R1" for LDR.
+func classifyARM64(text string) InstCategory {
+	text = strings.TrimSpace(text)
+	mnemonic := strings.ToUpper(Mnemonic(text))
+	args := strings.TrimSpace(text[len(mnemonic):])
+
+	switch {
+	case mnemonic == "RET" || mnemonic == "ERET" || strings.HasPrefix(mnemonic, "RETA"):
+		return CategoryReturn
+	case mnemonic == "BL" || mnemonic == "BLR" || mnemonic == "CALL":
+		return CategoryCall
+	case mnemonic == "JMP" || strings.HasPrefix(mnemonic, "B.") || arm64Branches[mnemonic]:
+		return CategoryBranch
+	}
+
+	if src, dst, ok := strings.Cut(args, ","); ok && isMove(mnemonic) {
+		switch {
+		case strings.Contains(dst, "("):
+			return CategoryStore
+		case strings.Contains(src, "("):
+			return CategoryLoad
+		}
+	}
+	// Go syntax prefixes the pairs of float registers with F, e.g. "FSTPD"
+	switch {
+	case strings.HasPrefix(mnemonic, "LD"), strings.HasPrefix(mnemonic, "FLDP"):
+		return CategoryLoad
+	case strings.HasPrefix(mnemonic, "ST"), strings.HasPrefix(mnemonic, "FSTP"):
+		return CategoryStore
+	case strings.HasPrefix(mnemonic, "F"), strings.HasPrefix(mnemonic, "V"), arm64Vector.MatchString(args):
+		return CategoryVector
+	}
+
+	for _, prefix := range arithmeticPrefixes {
+		if strings.HasPrefix(mnemonic, prefix) {
+			return CategoryArithmetic
+		}
+	}
+	return CategoryOther
+}
@@ -1,11 +1,39 @@
 package disasm
 
 // Code combines the disassembly and the source code mapping.
+//
+// Code loaded by Func.Load is read-only, since loaders return the same
+// code to every caller. Decorations, e.g. the coverage of the lines, are
+// kept next to the code instead of being written into it.
 type Code struct {
 	// Name is the name of the code block, e.g. function or method name.
 	Name string
 	// File is where the code is located.
 	File string
+	// Arch is the GOARCH of the instructions, empty when unknown,
+	// see ClassifyArch.
+	Arch string
+	// FrameSize is the size of the stack frame in bytes, 0 when unknown.
+	FrameSize int
+	// BoundsCheckCount is the number of bounds checks, see AnnotateBoundsChecks.
+	BoundsCheckCount int
+	// TailCallCount is the number of tail calls, see DetectTailCalls.
+	TailCallCount int
+	// NilCheckCount is the number of nil checks, see DetectNilChecks.
+	NilCheckCount int
+	// NOPBytes is the number of bytes taken by NOPs, see CountNOPBytes.
+	NOPBytes int
+	// InstructionCount, CallCount and CyclomaticComplexity summarize
+	// the instructions, see ComputeMetrics.
+	InstructionCount     int
+	CallCount            int
+	CyclomaticComplexity int
+	// StackMapPCs are the PCs where a stack map of a call site starts,
+	// nil when the binary doesn't provide them, see SafepointPCs.
+	StackMapPCs []uint64
+	// GoroutinePCs are the PCs where the goroutines of a live process
+	// are stopped, nil for binaries, see internal/delve.
+	GoroutinePCs []uint64
 
 	// Insts is the slice of a all instructions in the code.
 	Insts []Inst
@@ -28,6 +56,8 @@ type Inst struct {
 	File string
 	// Line is the line in the file where this instruction was compiled from.
 	Line int
+	// Bytes is the encoding of this instruction, nil when unknown.
+	Bytes []byte
 
 	// RefPC is a reference to another program counter, e.g. a call.
 	RefPC uint64
@@ -42,6 +72,15 @@ type Inst struct {
 	Call string
 }
 
+// Location is the source position of a program counter.
+type Location struct {
+	// File and Line are where the instruction was compiled from.
+	File string
+	Line int
+	// Func is the name of the func containing the instruction.
+	Func string
+}
+
 // Source represents code from a single file.
 type Source struct {
 	// File is the file name for the source code.
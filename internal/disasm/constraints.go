@@ -0,0 +1,152 @@
+package disasm
+
+import (
+	"bufio"
+	"go/build/constraint"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// knownOS and knownArch are the GOOS and GOARCH values
+// that are recognized in file names and build tags.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+		"js": true, "linux": true, "nacl": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "arm": true, "arm64": true,
+		"loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "ppc64": true, "ppc64le": true, "riscv64": true,
+		"s390x": true, "wasm": true,
+	}
+)
+
+// ConstraintCache reads and caches the build constraints of source files.
+// It's safe for concurrent use.
+type ConstraintCache struct {
+	mu     sync.Mutex
+	byFile map[string][]string
+}
+
+// Get returns the build constraints of the source file at path, see ReadBuildConstraints.
+func (cache *ConstraintCache) Get(path string) []string {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if constraints, ok := cache.byFile[path]; ok {
+		return constraints
+	}
+	if cache.byFile == nil {
+		cache.byFile = map[string][]string{}
+	}
+	constraints := ReadBuildConstraints(path)
+	cache.byFile[path] = constraints
+	return constraints
+}
+
+// ReadBuildConstraints returns the constraints implied by the file name,
+// e.g. "linux" for "file_linux.go", followed by the `//go:build` expression.
+// It returns nil for unconstrained, unreadable and non-Go files.
+func ReadBuildConstraints(path string) []string {
+	if filepath.Ext(path) != ".go" {
+		return nil
+	}
+	constraints := fileNameConstraints(filepath.Base(path))
+
+	f, err := os.Open(path)
+	if err != nil {
+		return constraints
+	}
+	defer f.Close()
+
+	// build constraints must appear before the package clause
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if !constraint.IsGoBuild(line) {
+			continue
+		}
+		if expr, err := constraint.Parse(line); err == nil {
+			constraints = append(constraints, expr.String())
+		}
+		break
+	}
+	return constraints
+}
+
+// fileNameConstraints returns the GOOS and GOARCH of name_GOOS_GOARCH.go,
+// name_GOOS.go and name_GOARCH.go files.
+func fileNameConstraints(name string) []string {
+	name = strings.TrimSuffix(name, ".go")
+	name = strings.TrimSuffix(name, "_test")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil
+	}
+	last := parts[len(parts)-1]
+	if len(parts) >= 3 && knownOS[parts[len(parts)-2]] && knownArch[last] {
+		return []string{parts[len(parts)-2], last}
+	}
+	if knownOS[last] || knownArch[last] {
+		return []string{last}
+	}
+	return nil
+}
+
+// MatchesGOOS reports whether constraints may be satisfied on goos.
+// Tags other than GOOS values, such as the architecture or cgo,
+// may have any value.
+func MatchesGOOS(constraints []string, goos string) bool {
+	for _, line := range constraints {
+		expr, err := constraint.Parse("//go:build " + line)
+		if err != nil {
+			continue
+		}
+		if !satisfiable(expr, goos) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxFreeTags limits the tags that satisfiable tries all values for.
+const maxFreeTags = 8
+
+// satisfiable tries all values of the tags that aren't GOOS values.
+func satisfiable(expr constraint.Expr, goos string) bool {
+	free := map[string]int{}
+	expr.Eval(func(tag string) bool {
+		if !knownOS[tag] && tag != "unix" {
+			if _, ok := free[tag]; !ok {
+				free[tag] = len(free)
+			}
+		}
+		return false
+	})
+	if len(free) > maxFreeTags {
+		return true
+	}
+	for values := 0; values < 1<<len(free); values++ {
+		ok := expr.Eval(func(tag string) bool {
+			if bit, ok := free[tag]; ok {
+				return values&(1<<bit) != 0
+			}
+			if tag == "unix" {
+				return goos != "windows" && goos != "plan9" && goos != "js" && goos != "wasip1"
+			}
+			return tag == goos
+		})
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package disasm
+
+import "strings"
+
+// DeferSite is a call registering a deferred func
+// and the calls that may run it when the func returns.
+type DeferSite struct {
+	// PushPC is the PC of the runtime.deferproc call.
+	PushPC uint64
+	// ReturnPCs are the PCs of the runtime.deferreturn calls.
+	ReturnPCs []uint64
+}
+
+// DetectDefers finds the defers that are registered with the runtime.
+//
+// The deferred calls run in any of the runtime.deferreturn calls of the func,
+// so every site references all of them. Open-coded defers don't call
+// runtime.deferproc and aren't reported.
+func DetectDefers(code *Code) []DeferSite {
+	var pushes, returns []uint64
+	for _, ix := range code.Insts {
+		switch {
+		case ix.Call == "runtime.deferreturn":
+			returns = append(returns, ix.PC)
+		// also matches runtime.deferprocStack and runtime.deferprocat
+		case strings.HasPrefix(ix.Call, "runtime.deferproc"):
+			pushes = append(pushes, ix.PC)
+		}
+	}
+
+	sites := make([]DeferSite, len(pushes))
+	for i, pc := range pushes {
+		sites[i] = DeferSite{PushPC: pc, ReturnPCs: returns}
+	}
+	return sites
+}
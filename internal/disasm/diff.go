@@ -0,0 +1,94 @@
+package disasm
+
+// DefaultSimilarityThreshold is the similarity above which a removed and
+// an added func are considered to be the same func with a new name.
+const DefaultSimilarityThreshold = 0.8
+
+// ComputeSimilarity compares the mnemonic sequences of a and b, ignoring
+// the operands, and returns 1 minus their normalized edit distance.
+// Identical sequences result in 1 and completely different ones in 0.
+func ComputeSimilarity(a, b *Code) float64 {
+	as, bs := mnemonics(a), mnemonics(b)
+	longest := max(len(as), len(bs))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(as, bs))/float64(longest)
+}
+
+// mnemonics returns the mnemonics of the instructions, skipping separators.
+func mnemonics(code *Code) []string {
+	if code == nil {
+		return nil
+	}
+	seq := make([]string, 0, len(code.Insts))
+	for _, ix := range code.Insts {
+		if m := Mnemonic(ix.Text); m != "" {
+			seq = append(seq, m)
+		}
+	}
+	return seq
+}
+
+// editDistance returns the Levenshtein distance of a and b.
+func editDistance(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for k := range prev {
+		prev[k] = k
+	}
+	for i := range a {
+		cur[0] = i + 1
+		for k := range b {
+			cost := 1
+			if a[i] == b[k] {
+				cost = 0
+			}
+			cur[k+1] = min(prev[k+1]+1, cur[k]+1, prev[k]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Rename is a removed func that matches an added func.
+type Rename struct {
+	From, To   string
+	Similarity float64
+}
+
+// DetectRenames pairs each removed func with the most similar added func,
+// when their similarity is above threshold. Each added func is used once.
+func DetectRenames(removed, added []Func, opts Options, threshold float64) []Rename {
+	addedCode := make([]*Code, len(added))
+	for i, fn := range added {
+		addedCode[i] = fn.Load(opts)
+	}
+
+	used := make([]bool, len(added))
+	var renames []Rename
+	for _, fn := range removed {
+		code := fn.Load(opts)
+		if code == nil || len(code.Insts) == 0 {
+			continue
+		}
+		best, bestSimilarity := -1, threshold
+		for i, other := range addedCode {
+			if used[i] || other == nil || len(other.Insts) == 0 {
+				continue
+			}
+			if similarity := ComputeSimilarity(code, other); similarity > bestSimilarity {
+				best, bestSimilarity = i, similarity
+			}
+		}
+		if best >= 0 {
+			used[best] = true
+			renames = append(renames, Rename{
+				From:       fn.Name(),
+				To:         added[best].Name(),
+				Similarity: bestSimilarity,
+			})
+		}
+	}
+	return renames
+}
@@ -6,6 +6,23 @@ type File interface {
 	Close() error
 	// Funcs enumerates all the visualizable code blocks.
 	Funcs() []Func
+	// PackageFuncs groups Funcs by package path, see PackageOf.
+	PackageFuncs() map[string][]Func
+	// SymbolTable lists all symbols in the file, similar to `nm`.
+	SymbolTable() []Symbol
+	// BuildID returns the build ID embedded by the Go toolchain,
+	// or "" when the file has none.
+	BuildID() string
+	// GoModPath returns the path of the main module of the binary,
+	// e.g. "github.com/user/project", or "" when it's unknown.
+	GoModPath() string
+}
+
+// SlicedFile is implemented by the files that contain code for several
+// architectures, e.g. universal Mach-O binaries.
+type SlicedFile interface {
+	// Slices returns the GOARCH of each architecture, nil when there's one.
+	Slices() []string
 }
 
 // Func represents a function or method that can be independently rendered.
@@ -13,7 +30,12 @@ type Func interface {
 	// Name is the name of the func.
 	Name() string
 	// Load loads the source code and disassembles it.
+	// The code may be cached and shared by every caller with the same
+	// options, so it must not be modified.
 	Load(opt Options) *Code
+	// BuildConstraints returns the build constraints of the source file
+	// of the func, nil when it's unconstrained or unknown.
+	BuildConstraints() []string
 }
 
 // Options defines configuration for loading the func.
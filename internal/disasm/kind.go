@@ -0,0 +1,143 @@
+package disasm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FuncKind classifies a func based on its name.
+type FuncKind int
+
+const (
+	// KindFunc is a regular function or method.
+	KindFunc FuncKind = iota
+	// KindTest is a `TestXxx` function from a `_test.go` file.
+	KindTest
+	// KindBenchmark is a `BenchmarkXxx` function from a `_test.go` file.
+	KindBenchmark
+	// KindFuzz is a `FuzzXxx` function from a `_test.go` file.
+	KindFuzz
+	// KindClosure is a function literal, e.g. "pkg.F.func1".
+	KindClosure
+	// KindMethod is a method, e.g. "pkg.(*T).M" or "pkg.T.M".
+	KindMethod
+	// KindInit is a package initializer, e.g. "pkg.init.0".
+	KindInit
+	// KindWrapper is a compiler generated wrapper, e.g. a method value
+	// "pkg.T.M-fm" or the wrapper of a go or defer statement.
+	KindWrapper
+	// KindStub is a compiler generated helper, e.g. the equality
+	// function of a type "type:.eq.pkg.T".
+	KindStub
+)
+
+// String returns the lowercase name of the kind, as used in the API.
+func (kind FuncKind) String() string {
+	switch kind {
+	case KindTest:
+		return "test"
+	case KindBenchmark:
+		return "benchmark"
+	case KindFuzz:
+		return "fuzz"
+	case KindClosure:
+		return "closure"
+	case KindMethod:
+		return "method"
+	case KindInit:
+		return "init"
+	case KindWrapper:
+		return "wrapper"
+	case KindStub:
+		return "stub"
+	default:
+		return "func"
+	}
+}
+
+// ParseFuncKind parses the result of FuncKind.String.
+func ParseFuncKind(s string) (FuncKind, bool) {
+	for kind := KindFunc; kind <= KindStub; kind++ {
+		if kind.String() == s {
+			return kind, true
+		}
+	}
+	return KindFunc, false
+}
+
+// IsTest reports whether the kind is a test, benchmark or fuzz target.
+func (kind FuncKind) IsTest() bool {
+	return kind == KindTest || kind == KindBenchmark || kind == KindFuzz
+}
+
+var (
+	rxClosure = regexp.MustCompile(`\.func\d+(\.\d+)*$`)
+	rxWrapper = regexp.MustCompile(`(-fm|\.(gowrap|deferwrap)\d+)$`)
+	rxInit    = regexp.MustCompile(`^init(\.\d+)?$`)
+)
+
+// stubPrefixes are the prefixes of compiler generated helpers.
+var stubPrefixes = []string{"type:.", "type..", "go:"}
+
+// KindOf derives the kind from the symbol name, e.g. "pkg.TestFoo" is a KindTest.
+func KindOf(name string) FuncKind {
+	// Assembly uses a middle dot as the package separator.
+	name = strings.ReplaceAll(name, "·", ".")
+	for _, prefix := range stubPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return KindStub
+		}
+	}
+	switch {
+	case rxWrapper.MatchString(name):
+		return KindWrapper
+	case rxClosure.MatchString(name):
+		return KindClosure
+	}
+
+	// Strip the package path and the type arguments.
+	if slash := strings.LastIndexByte(name, '/'); slash >= 0 {
+		name = name[slash+1:]
+	}
+	if bracket := strings.IndexByte(name, '['); bracket >= 0 {
+		name = name[:bracket]
+	}
+	dot := strings.IndexByte(name, '.')
+	if dot < 0 {
+		return KindFunc
+	}
+	name = name[dot+1:]
+	switch {
+	case rxInit.MatchString(name):
+		return KindInit
+	case strings.ContainsAny(name, ".()*"):
+		return KindMethod
+	}
+
+	switch {
+	case name == "TestMain":
+		return KindFunc
+	case isTestName(name, "Test"):
+		return KindTest
+	case isTestName(name, "Benchmark"):
+		return KindBenchmark
+	case isTestName(name, "Fuzz"):
+		return KindFuzz
+	}
+	return KindFunc
+}
+
+// isTestName follows the rules of `go test`: the prefix must not be
+// followed by a lowercase letter.
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
@@ -0,0 +1,61 @@
+package disasm
+
+import "path/filepath"
+
+// SourceLang is the language of a source file, cgo binaries
+// contain code compiled from C and C++ besides Go.
+type SourceLang int
+
+const (
+	// LangUnknown is a file with an unrecognized extension, e.g. "<autogenerated>".
+	LangUnknown SourceLang = iota
+	// LangGo is a ".go" file.
+	LangGo
+	// LangC is a C source or header file.
+	LangC
+	// LangCpp is a C++ source or header file.
+	LangCpp
+	// LangAsm is an assembly file, e.g. the ".s" files of the runtime.
+	LangAsm
+)
+
+// String returns the lowercase name of the language, as used in the API,
+// or "" for LangUnknown.
+func (lang SourceLang) String() string {
+	switch lang {
+	case LangGo:
+		return "go"
+	case LangC:
+		return "c"
+	case LangCpp:
+		return "cpp"
+	case LangAsm:
+		return "asm"
+	default:
+		return ""
+	}
+}
+
+// langByExt maps the file extensions to their languages, the case matters
+// since ".C" is C++ while ".S" is preprocessed assembly.
+var langByExt = map[string]SourceLang{
+	".go":  LangGo,
+	".c":   LangC,
+	".h":   LangC,
+	".C":   LangCpp,
+	".cc":  LangCpp,
+	".cpp": LangCpp,
+	".cxx": LangCpp,
+	".c++": LangCpp,
+	".hh":  LangCpp,
+	".hpp": LangCpp,
+	".hxx": LangCpp,
+	".s":   LangAsm,
+	".S":   LangAsm,
+	".asm": LangAsm,
+}
+
+// DetectLanguage returns the language of the source file based on its extension.
+func DetectLanguage(filename string) SourceLang {
+	return langByExt[filepath.Ext(filename)]
+}
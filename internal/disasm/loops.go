@@ -0,0 +1,70 @@
+package disasm
+
+import "sort"
+
+// LoopInfo describes a loop found in the instructions of a Code.
+// All values are indices into Code.Insts.
+type LoopInfo struct {
+	// Header is the first instruction of the loop, the target of the back edge.
+	Header int
+	// Body lists the instructions of the loop, including the header
+	// and the branch that jumps back.
+	Body []int
+	// IsInnermost is set when the loop doesn't contain other loops.
+	IsInnermost bool
+}
+
+// End returns the index of the last instruction in the loop.
+func (loop LoopInfo) End() int { return loop.Body[len(loop.Body)-1] }
+
+// DetectLoops finds loops from back edges, which are branches to a lower PC.
+// Back edges to the same header are merged into a single loop.
+func DetectLoops(code *Code) []LoopInfo {
+	ends := map[int]int{}
+	for i, ix := range code.Insts {
+		if ix.Call != "" || ix.RefOffset >= 0 {
+			continue
+		}
+		header := i + ix.RefOffset
+		if header < 0 {
+			continue
+		}
+		ends[header] = max(ends[header], i)
+	}
+
+	loops := make([]LoopInfo, 0, len(ends))
+	for header, end := range ends {
+		loop := LoopInfo{Header: header, Body: make([]int, 0, end-header+1)}
+		for i := header; i <= end; i++ {
+			loop.Body = append(loop.Body, i)
+		}
+		loops = append(loops, loop)
+	}
+	sort.Slice(loops, func(i, k int) bool {
+		return loops[i].Header < loops[k].Header
+	})
+
+	for i := range loops {
+		loops[i].IsInnermost = true
+		for k := range loops {
+			if i != k && loops[i].Header <= loops[k].Header && loops[k].End() <= loops[i].End() {
+				loops[i].IsInnermost = false
+				break
+			}
+		}
+	}
+	return loops
+}
+
+// LoopDepths returns the number of loops containing each of n instructions.
+func LoopDepths(loops []LoopInfo, n int) []int {
+	depths := make([]int, n)
+	for _, loop := range loops {
+		for _, i := range loop.Body {
+			if i < n {
+				depths[i]++
+			}
+		}
+	}
+	return depths
+}
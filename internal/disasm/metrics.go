@@ -0,0 +1,25 @@
+package disasm
+
+// ComputeMetrics sets the instruction count, call count and
+// cyclomatic complexity of code from its instructions.
+//
+// The complexity is approximated as the number of back edges plus one,
+// so a func without loops has a complexity of 1.
+func ComputeMetrics(code *Code) {
+	code.InstructionCount = 0
+	code.CallCount = 0
+	backEdges := 0
+	for _, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		code.InstructionCount++
+		switch {
+		case ix.Call != "":
+			code.CallCount++
+		case ix.RefOffset < 0:
+			backEdges++
+		}
+	}
+	code.CyclomaticComplexity = backEdges + 1
+}
@@ -0,0 +1,43 @@
+package disasm
+
+import (
+	"sort"
+	"strings"
+)
+
+// Mnemonic returns the first word of the instruction text, e.g. "MOVQ".
+func Mnemonic(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// MnemonicCount is the number of occurrences of a mnemonic.
+type MnemonicCount struct {
+	Mnemonic string
+	Count    int
+}
+
+// CountMnemonics counts the mnemonics in insts, most frequent first.
+func CountMnemonics(insts []Inst) []MnemonicCount {
+	counts := map[string]int{}
+	for _, ix := range insts {
+		if m := Mnemonic(ix.Text); m != "" {
+			counts[m]++
+		}
+	}
+
+	sorted := make([]MnemonicCount, 0, len(counts))
+	for m, n := range counts {
+		sorted = append(sorted, MnemonicCount{Mnemonic: m, Count: n})
+	}
+	sort.Slice(sorted, func(i, k int) bool {
+		if sorted[i].Count == sorted[k].Count {
+			return sorted[i].Mnemonic < sorted[k].Mnemonic
+		}
+		return sorted[i].Count > sorted[k].Count
+	})
+	return sorted
+}
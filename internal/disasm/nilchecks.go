@@ -0,0 +1,62 @@
+package disasm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// nilPanics are the prefixes of the runtime functions called when
+// a nil pointer is dereferenced.
+var nilPanics = []string{
+	"runtime.panicnil",
+	"runtime.panicmem",
+}
+
+// rxNilProbe matches the amd64 load the compiler inserts to fault
+// on a nil pointer, e.g. "TESTB AL, 0(AX)".
+var rxNilProbe = regexp.MustCompile(`^TESTB\s+AL,\s+(?:0)?\(\w+\)$`)
+
+// rxTestSelf matches a test of a register against itself, e.g. "TESTQ AX, AX".
+var rxTestSelf = regexp.MustCompile(`^TESTQ\s+(\w+),\s+(\w+)$`)
+
+// DetectNilChecks finds the nil checks the compiler couldn't remove,
+// using amd64 heuristics. A nil check is either a TESTQ of a register
+// against itself followed by a JEQ to a nil panic, in which case both
+// indices into code.Insts are included, or a probing TESTB load that
+// faults on nil.
+func DetectNilChecks(code *Code) []int {
+	checks, _ := findNilChecks(code)
+	return checks
+}
+
+// CountNilChecks returns the number of nil checks in code.
+func CountNilChecks(code *Code) int {
+	_, count := findNilChecks(code)
+	return count
+}
+
+// findNilChecks returns the instructions of the nil checks and their number.
+func findNilChecks(code *Code) (checks []int, count int) {
+	for i, ix := range code.Insts {
+		if rxNilProbe.MatchString(ix.Text) {
+			checks = append(checks, i)
+			count++
+			continue
+		}
+		if strings.ToUpper(Mnemonic(ix.Text)) != "JEQ" || ix.RefOffset == 0 {
+			continue
+		}
+		if !callsPanic(code.Insts, i+ix.RefOffset, nilPanics) {
+			continue
+		}
+		prev := previousInst(code.Insts, i)
+		if prev < 0 {
+			continue
+		}
+		if match := rxTestSelf.FindStringSubmatch(code.Insts[prev].Text); len(match) > 0 && match[1] == match[2] {
+			checks = append(checks, prev, i)
+			count++
+		}
+	}
+	return checks, count
+}
@@ -0,0 +1,184 @@
+package disasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinFoldedNOPs is the smallest group of NOPs folded by FoldNOPs.
+const MinFoldedNOPs = 2
+
+// nopPrefixes are the x86 prefixes GNU objdump prints before long NOPs,
+// e.g. "data16 cs nopw 0x0(%rax,%rax,1)".
+var nopPrefixes = map[string]bool{
+	"DATA16": true, "CS": true, "DS": true, "REP": true,
+}
+
+// NOPGroup is a run of consecutive NOP instructions.
+type NOPGroup struct {
+	// Start is the index into Code.Insts of the first NOP.
+	Start int
+	// Count is the number of NOPs.
+	Count int
+	// Bytes is the size of the NOPs, see DetectNOPs.
+	Bytes int
+	// Padding is set when the NOPs are at the end of the code,
+	// i.e. they align the start of the next func.
+	Padding bool
+}
+
+// End returns the index after the last NOP of the group.
+func (group NOPGroup) End() int { return group.Start + group.Count }
+
+// IsNOP reports whether the instruction text is a NOP, in Go or GNU syntax.
+func IsNOP(text string) bool {
+	fields := strings.Fields(strings.ToUpper(text))
+	for len(fields) > 1 && nopPrefixes[fields[0]] {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.TrimPrefix(fields[0], "C.") {
+	case "NOP", "NOOP", "NOPL", "NOPW", "NOPQ", "FNOP":
+		return true
+	case "XCHG":
+		// "xchg %ax,%ax" is the 2 byte NOP
+		return strings.ReplaceAll(strings.Join(fields[1:], ""), "%", "") == "AX,AX"
+	}
+	return false
+}
+
+// DetectNOPs groups the consecutive NOPs of code. Empty separator lines
+// end a group.
+//
+// The size of a NOP is the distance to the next instruction, the size
+// of a NOP at the very end of the code is unknown and isn't counted.
+func DetectNOPs(code *Code) []NOPGroup {
+	var groups []NOPGroup
+	for i := 0; i < len(code.Insts); i++ {
+		if !IsNOP(code.Insts[i].Text) {
+			continue
+		}
+		group := NOPGroup{Start: i}
+		for i < len(code.Insts) && IsNOP(code.Insts[i].Text) {
+			if next := nextInst(code.Insts, i); next >= 0 && code.Insts[next].PC > code.Insts[i].PC {
+				group.Bytes += int(code.Insts[next].PC - code.Insts[i].PC)
+			}
+			group.Count++
+			i++
+		}
+		group.Padding = nextInst(code.Insts, group.End()-1) < 0
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// CountNOPBytes returns the number of bytes taken by NOPs in code.
+func CountNOPBytes(code *Code) int {
+	total := 0
+	for _, group := range DetectNOPs(code) {
+		total += group.Bytes
+	}
+	return total
+}
+
+// FoldNOPs returns a copy of code where each group of at least MinFoldedNOPs
+// NOPs is preceded by a header row "NOP × 8 ▸", and the NOPs themselves are
+// removed unless expanded returns true for the group. The jumps and the
+// source relations are updated to the new rows.
+//
+// headers maps the header rows to their groups. When there's nothing
+// to fold, code itself is returned.
+func FoldNOPs(code *Code, expanded func(NOPGroup) bool) (folded *Code, headers map[int]NOPGroup) {
+	var groups []NOPGroup
+	for _, group := range DetectNOPs(code) {
+		if group.Count >= MinFoldedNOPs {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		return code, nil
+	}
+
+	folded = &Code{}
+	*folded = *code
+	folded.Insts = make([]Inst, 0, len(code.Insts)+len(groups))
+	headers = map[int]NOPGroup{}
+
+	// rows maps the instructions of code to the rows of folded,
+	// the NOPs of a collapsed group map to its header
+	rows := make([]int, len(code.Insts))
+	next := 0
+	for i := 0; i < len(code.Insts); i++ {
+		if next < len(groups) && groups[next].Start == i {
+			group := groups[next]
+			next++
+
+			first := code.Insts[i]
+			label := fmt.Sprintf("NOP × %d", group.Count)
+			if group.Padding {
+				label = "padding " + label
+			}
+			open := expanded(group)
+			if open {
+				label += " ▾"
+			} else {
+				label += " ▸"
+			}
+			headers[len(folded.Insts)] = group
+			folded.Insts = append(folded.Insts, Inst{
+				PC:   first.PC,
+				Text: label,
+				File: first.File,
+				Line: first.Line,
+			})
+			if !open {
+				for k := group.Start; k < group.End(); k++ {
+					rows[k] = len(folded.Insts) - 1
+				}
+				i = group.End() - 1
+				continue
+			}
+		}
+		rows[i] = len(folded.Insts)
+		folded.Insts = append(folded.Insts, code.Insts[i])
+	}
+
+	for i, ix := range code.Insts {
+		if ix.RefOffset != 0 && i+ix.RefOffset >= 0 && i+ix.RefOffset < len(rows) {
+			folded.Insts[rows[i]].RefOffset = rows[i+ix.RefOffset] - rows[i]
+		}
+	}
+
+	folded.Source = make([]Source, len(code.Source))
+	for i, src := range code.Source {
+		blocks := make([]SourceBlock, len(src.Blocks))
+		for k, block := range src.Blocks {
+			related := make([][]LineRange, len(block.Related))
+			for line, ranges := range block.Related {
+				for _, r := range ranges {
+					if r.From < r.To && r.To <= len(rows) {
+						r = LineRange{From: rows[r.From], To: rows[r.To-1] + 1}
+					}
+					related[line] = append(related[line], r)
+				}
+			}
+			block.Related = related
+			blocks[k] = block
+		}
+		folded.Source[i] = Source{File: src.File, Blocks: blocks}
+	}
+	return folded, headers
+}
+
+// nextInst returns the index of the instruction after i,
+// skipping empty separator lines, or -1 when there's none.
+func nextInst(insts []Inst, i int) int {
+	for k := i + 1; k < len(insts); k++ {
+		if insts[k].Text != "" {
+			return k
+		}
+	}
+	return -1
+}
@@ -0,0 +1,68 @@
+package disasm
+
+import (
+	"sort"
+	"strings"
+)
+
+// PackageOf returns the package path of a symbol name,
+// e.g. "net/http.(*Client).Do" is in "net/http".
+// It returns "" for names without a package, such as "go:buildid".
+func PackageOf(name string) string {
+	// Type arguments may contain other package paths.
+	if bracket := strings.IndexByte(name, '['); bracket >= 0 {
+		name = name[:bracket]
+	}
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	pkg := name[:slash+1+dot]
+	if strings.ContainsAny(pkg, ":()*[] ") {
+		return ""
+	}
+	return pkg
+}
+
+// GroupByPackage groups funcs by their package path.
+func GroupByPackage(funcs []Func) map[string][]Func {
+	packages := map[string][]Func{}
+	for _, fn := range funcs {
+		pkg := PackageOf(fn.Name())
+		packages[pkg] = append(packages[pkg], fn)
+	}
+	return packages
+}
+
+// PackageSize is the total code size of the funcs in a package.
+type PackageSize struct {
+	Name string
+	// Size is the sum of the func sizes in bytes.
+	Size int64
+	// Funcs is the number of funcs.
+	Funcs int
+}
+
+// PackageSizes sums the sizes of the funcs of file per package, for funcs
+// with a `Size() int64` method. The result is ordered by decreasing size,
+// total is the size of all funcs.
+func PackageSizes(file File) (sizes []PackageSize, total int64) {
+	for name, funcs := range file.PackageFuncs() {
+		pkg := PackageSize{Name: name, Funcs: len(funcs)}
+		for _, fn := range funcs {
+			if sized, ok := fn.(interface{ Size() int64 }); ok {
+				pkg.Size += sized.Size()
+			}
+		}
+		total += pkg.Size
+		sizes = append(sizes, pkg)
+	}
+	sort.Slice(sizes, func(i, k int) bool {
+		if sizes[i].Size != sizes[k].Size {
+			return sizes[i].Size > sizes[k].Size
+		}
+		return sizes[i].Name < sizes[k].Name
+	})
+	return sizes, total
+}
@@ -0,0 +1,45 @@
+package disasm
+
+import "strings"
+
+// raceAccessCalls are the calls the compiler inserts
+// before memory accesses when building with -race.
+var raceAccessCalls = []string{
+	"runtime.raceread",
+	"runtime.racewrite",
+	"runtime.racereadrange",
+	"runtime.racewriterange",
+}
+
+// isRaceAccess reports whether call checks a memory access for races.
+func isRaceAccess(call string) bool {
+	call = strings.TrimSuffix(call, ".abi0")
+	for _, target := range raceAccessCalls {
+		if call == target {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRaceInstrumented reports whether code was compiled with -race.
+func IsRaceInstrumented(code *Code) bool {
+	for _, ix := range code.Insts {
+		if ix.Call == "runtime.racefuncenter" || isRaceAccess(ix.Call) {
+			return true
+		}
+	}
+	return false
+}
+
+// RaceAccessPCs returns the PCs of the race detector calls
+// that precede the instrumented memory accesses.
+func RaceAccessPCs(code *Code) []uint64 {
+	var pcs []uint64
+	for _, ix := range code.Insts {
+		if isRaceAccess(ix.Call) {
+			pcs = append(pcs, ix.PC)
+		}
+	}
+	return pcs
+}
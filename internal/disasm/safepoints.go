@@ -0,0 +1,45 @@
+package disasm
+
+// maxSafepointPadding is the number of instructions to look ahead
+// for the call of a stack map.
+const maxSafepointPadding = 4
+
+// SafepointPCs returns the PCs of the instructions where the garbage collector
+// can stop the goroutine synchronously, which are the call sites with a stack map.
+// Without stack maps in code every call is assumed to be a safepoint.
+// Asynchronous preemption, which can happen between most other instructions,
+// isn't included.
+func SafepointPCs(code *Code) []uint64 {
+	var pcs []uint64
+	if code.StackMapPCs == nil {
+		for _, ix := range code.Insts {
+			if ClassifyArch(code.Arch, ix.Text) == CategoryCall {
+				pcs = append(pcs, ix.PC)
+			}
+		}
+		return pcs
+	}
+
+	// Insts may contain separators, which don't have a PC.
+	index := make(map[uint64]int, len(code.Insts))
+	for i, ix := range code.Insts {
+		if ix.Text != "" {
+			index[ix.PC] = i
+		}
+	}
+	for _, start := range code.StackMapPCs {
+		i, ok := index[start]
+		if !ok {
+			continue
+		}
+		// The stack map may start at alignment padding before the call.
+		for k := i; k < len(code.Insts) && k < i+maxSafepointPadding; k++ {
+			if ClassifyArch(code.Arch, code.Insts[k].Text) == CategoryCall {
+				i = k
+				break
+			}
+		}
+		pcs = append(pcs, code.Insts[i].PC)
+	}
+	return pcs
+}
@@ -0,0 +1,117 @@
+package disasm
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SourceFileMatches reports whether the source file name matches query,
+// which is either the whole path or its trailing path elements,
+// e.g. "/src/net/http/server.go" matches "http/server.go".
+func SourceFileMatches(name, query string) bool {
+	name, query = filepath.ToSlash(name), filepath.ToSlash(query)
+	if query == "" {
+		return false
+	}
+	return name == query || strings.HasSuffix(name, "/"+strings.TrimPrefix(query, "/"))
+}
+
+// FindInstByLine returns the index of the first instruction in code
+// compiled from line of the source file, -1 when there's none.
+func FindInstByLine(code *Code, file string, line int) int {
+	for i, ix := range code.Insts {
+		if ix.Text != "" && ix.Line == line && SourceFileMatches(ix.File, file) {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindFuncByLine returns the func of file with code compiled from line of
+// the source file path, and the index of the first such instruction in the
+// code loaded with opts. A func defined in path is preferred over the funcs
+// the line was inlined into. It returns nil and -1 when there's none.
+func FindFuncByLine(file File, path string, line int, opts Options) (Func, *Code, int) {
+	funcs, err := FuncsBySourceFile(file, filepath.Base(path))
+	if err != nil {
+		return nil, nil, -1
+	}
+	var found Func
+	var foundCode *Code
+	foundIndex := -1
+	for _, fn := range funcs {
+		code := fn.Load(opts)
+		if code == nil {
+			continue
+		}
+		index := FindInstByLine(code, path, line)
+		if index < 0 {
+			continue
+		}
+		if SourceFileMatches(code.File, path) {
+			return fn, code, index
+		}
+		if found == nil {
+			found, foundCode, foundIndex = fn, code, index
+		}
+	}
+	return found, foundCode, foundIndex
+}
+
+// SourcePatternMatches reports whether the source file name matches pattern.
+// A pattern with any of "*?[" is a glob, see path.Match, that is matched
+// against as many trailing path elements of name as it has, e.g. "*_test.go"
+// or "net/http/*.go". Otherwise it matches when it's a substring of name,
+// e.g. "pkg/server".
+func SourcePatternMatches(name, pattern string) bool {
+	name, pattern = filepath.ToSlash(name), filepath.ToSlash(pattern)
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.Contains(name, pattern)
+	}
+	elems := strings.Split(name, "/")
+	n := strings.Count(pattern, "/") + 1
+	if n > len(elems) {
+		return false
+	}
+	match, _ := path.Match(pattern, strings.Join(elems[len(elems)-n:], "/"))
+	return match
+}
+
+// CheckSourcePattern returns path.ErrBadPattern when pattern is a malformed glob.
+func CheckSourcePattern(pattern string) error {
+	_, err := path.Match(filepath.ToSlash(pattern), "")
+	return err
+}
+
+// SourceFileFinder is implemented by the files that find the funcs compiled
+// from a source file without disassembling them, see FuncsBySourceFile.
+type SourceFileFinder interface {
+	FuncsBySourceFile(pattern string) ([]Func, error)
+}
+
+// FuncsBySourceFile returns the funcs of file with an instruction compiled
+// from a source file matching pattern, see SourcePatternMatches. The funcs
+// of files that don't implement SourceFileFinder are disassembled.
+func FuncsBySourceFile(file File, pattern string) ([]Func, error) {
+	if err := CheckSourcePattern(pattern); err != nil {
+		return nil, err
+	}
+	if finder, ok := file.(SourceFileFinder); ok {
+		return finder.FuncsBySourceFile(pattern)
+	}
+	var found []Func
+	for _, fn := range file.Funcs() {
+		code := fn.Load(Options{})
+		if code == nil {
+			continue
+		}
+		for _, ix := range code.Insts {
+			if ix.Text != "" && SourcePatternMatches(ix.File, pattern) {
+				found = append(found, fn)
+				break
+			}
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,40 @@
+package disasm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Symbol is an entry in the symbol table.
+type Symbol struct {
+	// Name is the full symbol name, e.g. "main.(*T).Foo".
+	Name string
+	// Type is the `nm` code, e.g. "T" for text and "D" for data.
+	Type string
+	// Size is the size of the symbol in bytes.
+	Size int64
+	// Address is the virtual address of the symbol.
+	Address uint64
+	// Exported is true when the identifier is exported from its package.
+	Exported bool
+}
+
+// IsFunc returns whether the symbol refers to code.
+func (sym *Symbol) IsFunc() bool {
+	return sym.Type == "T" || sym.Type == "t"
+}
+
+// IsExported checks whether the last identifier in a symbol name is exported,
+// e.g. "pkg.Foo" and "pkg.(*T).Foo" are exported, "pkg.Foo.func1" is not.
+func IsExported(name string) bool {
+	if slash := strings.LastIndexByte(name, '/'); slash >= 0 {
+		name = name[slash+1:]
+	}
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[dot+1:])
+	return unicode.IsUpper(r)
+}
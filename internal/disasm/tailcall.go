@@ -0,0 +1,64 @@
+package disasm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rxJumpSymbol matches an unconditional jump to a symbol,
+// e.g. "JMP runtime.gcWriteBarrier(SB)".
+var rxJumpSymbol = regexp.MustCompile(`^(?:JMP|B)\s+([\w\d\/\.\(\)\*]+)\(SB\)$`)
+
+// DetectTailCalls returns the indices into code.Insts of the tail calls,
+// the unconditional jumps whose target is outside of the func.
+//
+// Tail calls don't appear in stack traces, since the callee reuses
+// the frame of the caller. The jump back to the start of the func
+// after growing the stack isn't a tail call.
+func DetectTailCalls(code *Code) []int {
+	start, end, ok := pcRange(code.Insts)
+	if !ok {
+		return nil
+	}
+
+	var calls []int
+	for i, ix := range code.Insts {
+		mnemonic := strings.ToUpper(Mnemonic(ix.Text))
+		if mnemonic != "JMP" && mnemonic != "B" {
+			continue
+		}
+		if match := rxJumpSymbol.FindStringSubmatch(ix.Text); len(match) > 0 {
+			if match[1] != code.Name {
+				calls = append(calls, i)
+			}
+			continue
+		}
+		if ix.RefPC != 0 && (ix.RefPC < start || ix.RefPC > end) {
+			calls = append(calls, i)
+		}
+	}
+	return calls
+}
+
+// CountTailCalls returns the number of tail calls in code.
+func CountTailCalls(code *Code) int {
+	return len(DetectTailCalls(code))
+}
+
+// pcRange returns the PCs of the first and the last instruction,
+// skipping empty separator lines.
+func pcRange(insts []Inst) (start, end uint64, ok bool) {
+	for _, ix := range insts {
+		if ix.Text == "" {
+			continue
+		}
+		if !ok || ix.PC < start {
+			start = ix.PC
+		}
+		if !ok || ix.PC > end {
+			end = ix.PC
+		}
+		ok = true
+	}
+	return start, end, ok
+}
@@ -0,0 +1,12 @@
+package timing
+
+import _ "embed"
+
+//go:embed amd64.csv
+var amd64CSV string
+
+// amd64 is the table of the x86-64 instructions on Intel Skylake.
+var amd64 = mustParse("amd64", "Intel Skylake", amd64CSV)
+
+// amd64LoadLatency is the latency of a load hitting the L1 cache.
+const amd64LoadLatency = 4
@@ -0,0 +1,277 @@
+// Package timing estimates the latency and throughput of instructions
+// from per architecture timing tables.
+package timing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// Timing is the cost of an instruction in clock cycles.
+type Timing struct {
+	// Latency is the delay until the result is available to dependent instructions.
+	Latency float64
+	// Throughput is the reciprocal throughput, the cycles between independent
+	// instructions of the same kind.
+	Throughput float64
+}
+
+// Table contains the timings of the instructions of an architecture.
+type Table struct {
+	// Arch is the GOARCH of the instructions.
+	Arch string
+	// Model is the microarchitecture the timings were measured on.
+	Model string
+
+	entries     map[string]entry
+	loadLatency float64
+}
+
+type entry struct {
+	Timing
+	// memory is set when the timing includes the memory access.
+	memory bool
+}
+
+// ForArch returns the timing table of arch, ok is false when there's none.
+func ForArch(arch string) (table *Table, ok bool) {
+	switch arch {
+	case "amd64":
+		return amd64, true
+	}
+	return nil, false
+}
+
+func mustParse(arch, model, data string) *Table {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("timing table %s: %v", arch, err))
+	}
+
+	table := &Table{Arch: arch, Model: model, entries: map[string]entry{}, loadLatency: amd64LoadLatency}
+	for _, record := range records[1:] {
+		latency, err1 := strconv.ParseFloat(record[1], 64)
+		throughput, err2 := strconv.ParseFloat(record[2], 64)
+		memory, err3 := strconv.ParseBool(record[3])
+		if err1 != nil || err2 != nil || err3 != nil {
+			panic(fmt.Sprintf("timing table %s: invalid entry %v", arch, record))
+		}
+		table.entries[record[0]] = entry{Timing: Timing{Latency: latency, Throughput: throughput}, memory: memory}
+	}
+	return table
+}
+
+// Lookup returns the timing of the instruction text, e.g. "ADDQ 0x8(SP), AX".
+// Reading a memory operand adds the latency of a load hitting the L1 cache.
+// Instructions with the LOCK prefix cost the same as a locked XADD.
+func (table *Table) Lookup(text string) (Timing, bool) {
+	mnemonic, operands, locked := split(text)
+	if locked {
+		mnemonic = "LOCK"
+	}
+	e, ok := table.entries[mnemonic]
+	if !ok {
+		return Timing{}, false
+	}
+	if !e.memory && loads(mnemonic, operands) {
+		e.Latency += table.loadLatency
+	}
+	return e.Timing, true
+}
+
+// CriticalPath returns the length in cycles of the longest chain of dependent
+// instructions, following the registers and flags in instruction order.
+// It's a lower bound: branches, memory dependencies and execution ports are ignored,
+// and instructions missing from the table don't add latency.
+func (table *Table) CriticalPath(insts []disasm.Inst) float64 {
+	ready := map[string]float64{}
+	longest := 0.0
+	for _, ix := range insts {
+		if ix.Text == "" {
+			continue
+		}
+		timing, _ := table.Lookup(ix.Text)
+		mnemonic, operands, _ := split(ix.Text)
+		sources, dests := dependencies(mnemonic, operands)
+
+		start := 0.0
+		for _, reg := range sources {
+			start = max(start, ready[reg])
+		}
+		done := start + timing.Latency
+		for _, reg := range dests {
+			ready[reg] = done
+		}
+		longest = max(longest, done)
+	}
+	return longest
+}
+
+// split returns the mnemonic and the operands of an instruction,
+// locked is set when it has the LOCK prefix.
+func split(text string) (mnemonic string, operands []string, locked bool) {
+	mnemonic = disasm.Mnemonic(text)
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), mnemonic))
+	if mnemonic == "LOCK" {
+		locked = true
+		mnemonic = disasm.Mnemonic(rest)
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, mnemonic))
+	}
+	if rest == "" {
+		return mnemonic, nil, locked
+	}
+	operands = strings.Split(rest, ",")
+	for i := range operands {
+		operands[i] = strings.TrimSpace(operands[i])
+	}
+	return mnemonic, operands, locked
+}
+
+// isMemory reports whether the operand is a memory reference, e.g. "0x8(SP)" or "FS:0xfffffff8".
+func isMemory(operand string) bool {
+	return strings.Contains(operand, "(") || strings.Contains(operand, ":")
+}
+
+// loads reports whether the instruction reads memory. The destination is the last
+// operand, so it's only read by instructions other than moves and LEA.
+func loads(mnemonic string, operands []string) bool {
+	if strings.HasPrefix(mnemonic, "LEA") {
+		return false
+	}
+	for i, operand := range operands {
+		if !isMemory(operand) {
+			continue
+		}
+		if i == len(operands)-1 && writesOnly(mnemonic) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// writesOnly reports whether the instruction overwrites its destination without reading it.
+func writesOnly(mnemonic string) bool {
+	for _, prefix := range []string{"MOV", "VMOV", "LEA", "SET", "POP", "CVT", "BSF", "BSR", "POPCNT", "TZCNT", "LZCNT", "PMOVMSKB"} {
+		if strings.HasPrefix(mnemonic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readsFlags and writesFlags list the mnemonic prefixes that use the flags register.
+var (
+	readsFlags  = []string{"J", "SET", "CMOV", "ADC", "SBB", "RCL", "RCR"}
+	writesFlags = []string{"ADD", "SUB", "AND", "OR", "XOR", "CMP", "TEST", "INC", "DEC", "NEG", "SHL", "SHR", "SAR", "ROL", "ROR", "BT", "ADC", "SBB", "IMUL", "MUL", "DIV", "IDIV", "BSF", "BSR", "POPCNT", "TZCNT", "LZCNT", "UCOMIS", "COMIS", "PTEST", "XADD", "CMPXCHG"}
+)
+
+// isVector reports whether the mnemonic is a floating point or vector instruction,
+// e.g. ADDSD, which doesn't write the flags unlike ADDQ.
+func isVector(mnemonic string) bool {
+	for _, suffix := range []string{"SD", "SS", "PD", "PS"} {
+		if strings.HasSuffix(mnemonic, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compares lists the instructions that only set the flags.
+var compares = []string{"CMP", "TEST", "BT", "UCOMIS", "COMIS", "PTEST"}
+
+func hasPrefix(mnemonic string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(mnemonic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// rxRegister matches the register names in Go assembly syntax.
+var rxRegister = regexp.MustCompile(`\b(?:[A-D][XLH]|SI|DI|SP|BP|SIB|DIB|SPB|BPB|R\d+[BWL]?|[XYZ]\d+)\b`)
+
+// register returns the name of the full register, e.g. AX for AL and X0 for Y0.
+func register(name string) string {
+	switch {
+	case len(name) == 2 && name[0] >= 'A' && name[0] <= 'D' && strings.ContainsRune("XLH", rune(name[1])):
+		return name[:1] + "X"
+	case name == "SIB", name == "DIB", name == "SPB", name == "BPB":
+		return name[:2]
+	case name[0] == 'R':
+		return strings.TrimRight(name, "BWL")
+	case name[0] == 'Y' || name[0] == 'Z':
+		return "X" + name[1:]
+	}
+	return name
+}
+
+func registers(operand string) []string {
+	names := rxRegister.FindAllString(operand, -1)
+	for i, name := range names {
+		names[i] = register(name)
+	}
+	return names
+}
+
+// dependencies returns the registers, including the "FLAGS" pseudo register,
+// read and written by the instruction.
+func dependencies(mnemonic string, operands []string) (sources, dests []string) {
+	if hasPrefix(mnemonic, readsFlags) && mnemonic != "JMP" {
+		sources = append(sources, "FLAGS")
+	}
+	if hasPrefix(mnemonic, writesFlags) && (hasPrefix(mnemonic, compares) || !isVector(mnemonic)) {
+		dests = append(dests, "FLAGS")
+	}
+
+	switch {
+	case strings.HasPrefix(mnemonic, "MUL") || strings.HasPrefix(mnemonic, "DIV") || strings.HasPrefix(mnemonic, "IDIV"):
+		sources = append(sources, "AX", "DX")
+		for _, operand := range operands {
+			sources = append(sources, registers(operand)...)
+		}
+		return sources, append(dests, "AX", "DX")
+	case strings.HasPrefix(mnemonic, "XADD") || strings.HasPrefix(mnemonic, "XCHG"):
+		// the source register receives the previous value
+		if len(operands) == 2 && !isMemory(operands[0]) {
+			dests = append(dests, registers(operands[0])...)
+		}
+	case mnemonic == "CQO" || mnemonic == "CDQ":
+		sources = append(sources, "AX")
+		dests = append(dests, "DX")
+	case strings.HasPrefix(mnemonic, "PUSH") || strings.HasPrefix(mnemonic, "POP"):
+		sources = append(sources, "SP")
+		dests = append(dests, "SP")
+	}
+
+	// zeroing idioms don't depend on the previous value
+	if len(operands) == 2 && operands[0] == operands[1] && (strings.HasPrefix(mnemonic, "XOR") || mnemonic == "PXOR" || strings.HasPrefix(mnemonic, "SUB")) {
+		return nil, append(dests, registers(operands[1])...)
+	}
+
+	for i, operand := range operands {
+		regs := registers(operand)
+		last := i == len(operands)-1
+		switch {
+		case !last || isMemory(operand):
+			// sources and address registers
+			sources = append(sources, regs...)
+		case hasPrefix(mnemonic, compares):
+			sources = append(sources, regs...)
+		case writesOnly(mnemonic) && len(operands) > 1:
+			dests = append(dests, regs...)
+		default:
+			sources = append(sources, regs...)
+			dests = append(dests, regs...)
+		}
+	}
+	return sources, dests
+}
@@ -0,0 +1,233 @@
+// Package export writes disassembled code in portable formats.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// SchemaVersion is the version of the JSON document. It changes when
+// fields are removed or change their meaning, adding fields keeps it.
+const SchemaVersion = "1"
+
+// Document is the JSON representation of a disasm.Code. It's a superset of
+// the code response of the HTTP API and contains the source line contents.
+type Document struct {
+	SchemaVersion string `json:"schemaVersion"`
+
+	Name             string        `json:"name"`
+	File             string        `json:"file"`
+	FrameSize        int           `json:"frameSize,omitempty"`
+	BoundsCheckCount int           `json:"boundsCheckCount,omitempty"`
+	InstructionCount int           `json:"instructionCount"`
+	CallCount        int           `json:"callCount"`
+	Complexity       int           `json:"cyclomaticComplexity"`
+	MaxJump          int           `json:"maxJump"`
+	Instructions     []Instruction `json:"instructions"`
+	Sources          []Source      `json:"sources"`
+	StackMapPCs      []uint64      `json:"stackMapPcs,omitempty"`
+	Defers           []DeferSite   `json:"defers,omitempty"`
+}
+
+// Instruction is a single instruction, see disasm.Inst.
+type Instruction struct {
+	PC        uint64 `json:"pc"`
+	Text      string `json:"text"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	RefPC     uint64 `json:"refPc"`
+	RefOffset int    `json:"refOffset"`
+	RefStack  int    `json:"refStack"`
+	Call      string `json:"call"`
+
+	LoopHeader bool `json:"loopHeader,omitempty"`
+	LoopDepth  int  `json:"loopDepth,omitempty"`
+}
+
+// DeferSite is a deferred call, see disasm.DeferSite.
+type DeferSite struct {
+	PushPC    uint64   `json:"pushPc"`
+	ReturnPCs []uint64 `json:"returnPcs"`
+}
+
+// Source is the code from a single file, see disasm.Source.
+type Source struct {
+	File string `json:"file"`
+	// Lang is the language of File, see disasm.SourceLang.
+	Lang   string        `json:"lang,omitempty"`
+	Blocks []SourceBlock `json:"blocks"`
+}
+
+// SourceBlock is a range of source lines with their content.
+type SourceBlock struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Lines   []string      `json:"lines"`
+	Related [][]LineRange `json:"related"`
+}
+
+// LineRange is a range of instruction indexes.
+type LineRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// NewDocument converts code to its JSON representation.
+func NewDocument(code *disasm.Code) *Document {
+	doc := &Document{
+		SchemaVersion:    SchemaVersion,
+		Name:             code.Name,
+		File:             code.File,
+		FrameSize:        code.FrameSize,
+		BoundsCheckCount: code.BoundsCheckCount,
+		InstructionCount: code.InstructionCount,
+		CallCount:        code.CallCount,
+		Complexity:       code.CyclomaticComplexity,
+		MaxJump:          code.MaxJump,
+		Instructions:     make([]Instruction, len(code.Insts)),
+		Sources:          make([]Source, len(code.Source)),
+		StackMapPCs:      code.StackMapPCs,
+	}
+
+	loops := disasm.DetectLoops(code)
+	depths := disasm.LoopDepths(loops, len(code.Insts))
+	for i, ix := range code.Insts {
+		doc.Instructions[i] = Instruction{
+			PC:        ix.PC,
+			Text:      ix.Text,
+			File:      ix.File,
+			Line:      ix.Line,
+			RefPC:     ix.RefPC,
+			RefOffset: ix.RefOffset,
+			RefStack:  ix.RefStack,
+			Call:      ix.Call,
+			LoopDepth: depths[i],
+		}
+	}
+	for _, loop := range loops {
+		doc.Instructions[loop.Header].LoopHeader = true
+	}
+	for _, site := range disasm.DetectDefers(code) {
+		doc.Defers = append(doc.Defers, DeferSite{PushPC: site.PushPC, ReturnPCs: site.ReturnPCs})
+	}
+
+	for i, src := range code.Source {
+		source := Source{
+			File:   src.File,
+			Lang:   disasm.DetectLanguage(src.File).String(),
+			Blocks: make([]SourceBlock, len(src.Blocks)),
+		}
+		for k, block := range src.Blocks {
+			related := make([][]LineRange, len(block.Related))
+			for line, ranges := range block.Related {
+				related[line] = make([]LineRange, len(ranges))
+				for r, rng := range ranges {
+					related[line][r] = LineRange{From: rng.From, To: rng.To}
+				}
+			}
+			source.Blocks[k] = SourceBlock{
+				From:    block.From,
+				To:      block.To,
+				Lines:   block.Lines,
+				Related: related,
+			}
+		}
+		doc.Sources[i] = source
+	}
+	return doc
+}
+
+// Code converts the document back to a disasm.Code.
+func (doc *Document) Code() *disasm.Code {
+	code := &disasm.Code{
+		Name:                 doc.Name,
+		File:                 doc.File,
+		FrameSize:            doc.FrameSize,
+		BoundsCheckCount:     doc.BoundsCheckCount,
+		InstructionCount:     doc.InstructionCount,
+		CallCount:            doc.CallCount,
+		CyclomaticComplexity: doc.Complexity,
+		MaxJump:              doc.MaxJump,
+		Insts:                make([]disasm.Inst, len(doc.Instructions)),
+		Source:               make([]disasm.Source, len(doc.Sources)),
+		StackMapPCs:          doc.StackMapPCs,
+	}
+	for i, ix := range doc.Instructions {
+		code.Insts[i] = disasm.Inst{
+			PC:        ix.PC,
+			Text:      ix.Text,
+			File:      ix.File,
+			Line:      ix.Line,
+			RefPC:     ix.RefPC,
+			RefOffset: ix.RefOffset,
+			RefStack:  ix.RefStack,
+			Call:      ix.Call,
+		}
+	}
+	for i, src := range doc.Sources {
+		source := disasm.Source{
+			File:   src.File,
+			Blocks: make([]disasm.SourceBlock, len(src.Blocks)),
+		}
+		for k, block := range src.Blocks {
+			related := make([][]disasm.LineRange, len(block.Related))
+			for line, ranges := range block.Related {
+				related[line] = make([]disasm.LineRange, len(ranges))
+				for r, rng := range ranges {
+					related[line][r] = disasm.LineRange{From: rng.From, To: rng.To}
+				}
+			}
+			source.Blocks[k] = disasm.SourceBlock{
+				LineRange: disasm.LineRange{From: block.From, To: block.To},
+				Lines:     block.Lines,
+				Related:   related,
+			}
+		}
+		code.Source[i] = source
+	}
+	return code
+}
+
+// ExportJSON writes code as a Document to w, indented when pretty is set.
+func ExportJSON(code *disasm.Code, w io.Writer, pretty bool) error {
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(NewDocument(code))
+}
+
+// ImportJSON reads a document written by ExportJSON.
+func ImportJSON(r io.Reader) (*disasm.Code, error) {
+	return decodeDocument(json.NewDecoder(r))
+}
+
+// ImportJSONAll reads the consecutive documents written by ExportJSON,
+// e.g. the output of -dump-asm -output json.
+func ImportJSONAll(r io.Reader) ([]*disasm.Code, error) {
+	dec := json.NewDecoder(r)
+	var codes []*disasm.Code
+	for dec.More() {
+		code, err := decodeDocument(dec)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", len(codes)+1, err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// decodeDocument reads the next document from dec.
+func decodeDocument(dec *json.Decoder) (*disasm.Code, error) {
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %q", doc.SchemaVersion)
+	}
+	return doc.Code(), nil
+}
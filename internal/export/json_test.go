@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	code := &disasm.Code{
+		Name:                 "main.add",
+		File:                 "/src/main.go",
+		FrameSize:            24,
+		InstructionCount:     4,
+		CallCount:            1,
+		CyclomaticComplexity: 2,
+		MaxJump:              1,
+		Insts: []disasm.Inst{
+			{PC: 0x401000, Text: "CMPQ SP, 0x10(R14)", File: "/src/main.go", Line: 3},
+			{PC: 0x401004, Text: "JBE 0x401010", File: "/src/main.go", Line: 3, RefPC: 0x401010, RefOffset: 3, RefStack: 1},
+			{PC: 0x401006, Text: "CALL main.sub(SB)", File: "/src/main.go", Line: 4, RefPC: 0x402000, Call: "main.sub"},
+			{PC: 0x401010, Text: "RET", File: "/src/main.go", Line: 5},
+		},
+		Source: []disasm.Source{{
+			File: "/src/main.go",
+			Blocks: []disasm.SourceBlock{{
+				LineRange: disasm.LineRange{From: 3, To: 6},
+				Lines:     []string{"func add() int {", "\treturn sub()", "}"},
+				Related:   [][]disasm.LineRange{{{From: 0, To: 2}}, {{From: 2, To: 3}}, {{From: 3, To: 4}}},
+			}},
+		}},
+	}
+
+	for _, pretty := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := ExportJSON(code, &buf, pretty); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ImportJSON(&buf)
+		if err != nil {
+			t.Fatalf("pretty %v: %v", pretty, err)
+		}
+
+		if len(got.Insts) != len(code.Insts) {
+			t.Fatalf("pretty %v: %d instructions, want %d", pretty, len(got.Insts), len(code.Insts))
+		}
+		for i, ix := range code.Insts {
+			if got.Insts[i].PC != ix.PC || got.Insts[i].Text != ix.Text {
+				t.Errorf("pretty %v: instruction %d = %#x %q, want %#x %q", pretty, i, got.Insts[i].PC, got.Insts[i].Text, ix.PC, ix.Text)
+			}
+		}
+		if !reflect.DeepEqual(got.Insts, code.Insts) {
+			t.Errorf("pretty %v: instructions = %+v, want %+v", pretty, got.Insts, code.Insts)
+		}
+		if !reflect.DeepEqual(got.Source, code.Source) {
+			t.Errorf("pretty %v: sources = %+v, want %+v", pretty, got.Source, code.Source)
+		}
+		if got.Name != code.Name || got.FrameSize != code.FrameSize || got.CyclomaticComplexity != code.CyclomaticComplexity {
+			t.Errorf("pretty %v: got %s frame %d complexity %d, want %s frame %d complexity %d", pretty,
+				got.Name, got.FrameSize, got.CyclomaticComplexity, code.Name, code.FrameSize, code.CyclomaticComplexity)
+		}
+	}
+}
+
+func TestImportJSONRejectsSchemaVersion(t *testing.T) {
+	_, err := ImportJSON(bytes.NewReader([]byte(`{"schemaVersion":"0","name":"main.main"}`)))
+	if err == nil {
+		t.Error("document of an unknown schema version was imported")
+	}
+}
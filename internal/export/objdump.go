@@ -0,0 +1,60 @@
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// ExportObjdump writes the code in the format of `go tool objdump -s`,
+// so that scripts written for it can process the output:
+//
+//	TEXT main.main(SB) /path/main.go
+//	  main.go:7		0x1400a5200		493b6610		CMPQ SP, 0x10(R14)
+//
+// The instructions are the ones of the code, so the trailing padding
+// that the loaders drop isn't printed. Instructions without Bytes have
+// an empty encoding column. Write an empty line between funcs as objdump.
+func ExportObjdump(code *disasm.Code, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "TEXT %s(SB) %s\n", code.Name, code.File); err != nil {
+		return err
+	}
+
+	// the same alignment as cmd/internal/objfile
+	tw := tabwriter.NewWriter(w, 18, 8, 1, '\t', tabwriter.StripEscape)
+	for _, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		fmt.Fprintf(tw, "  %s:%d\t%#x\t%s\t%s\t\n", baseName(ix.File), ix.Line, ix.PC, objdumpEncoding(ix.Bytes, code.Arch), ix.Text)
+	}
+	return tw.Flush()
+}
+
+// objdumpEncoding formats the encoding as bytes for x86 and as 32-bit words
+// for the fixed width architectures, as objdump does.
+func objdumpEncoding(encoding []byte, arch string) string {
+	if len(encoding)%4 != 0 || arch == "386" || arch == "amd64" {
+		return fmt.Sprintf("%x", encoding)
+	}
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	switch arch {
+	case "mips", "mips64", "ppc64", "s390x":
+		byteOrder = binary.BigEndian
+	}
+	words := make([]string, 0, len(encoding)/4)
+	for i := 0; i < len(encoding); i += 4 {
+		words = append(words, fmt.Sprintf("%08x", byteOrder.Uint32(encoding[i:])))
+	}
+	return strings.Join(words, " ")
+}
+
+// baseName returns the last element of a slash or backslash separated path.
+func baseName(path string) string {
+	path = path[strings.LastIndex(path, "/")+1:]
+	return path[strings.LastIndex(path, `\`)+1:]
+}
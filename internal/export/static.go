@@ -0,0 +1,112 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+var _ disasm.File = (*StaticFile)(nil)
+var _ disasm.Func = (*StaticFunc)(nil)
+
+// StaticFile contains code that was disassembled earlier, e.g. imported
+// from JSON, for viewing it without the binary.
+type StaticFile struct {
+	funcs       []disasm.Func
+	constraints disasm.ConstraintCache
+}
+
+// NewStaticFile creates a file with the funcs of codes, in their order.
+func NewStaticFile(codes []*disasm.Code) *StaticFile {
+	file := &StaticFile{funcs: make([]disasm.Func, len(codes))}
+	for i, code := range codes {
+		file.funcs[i] = &StaticFunc{file: file, code: code}
+	}
+	return file
+}
+
+// IsJSONFile reports whether path is named like a file written by ExportJSON.
+func IsJSONFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// LoadJSON reads the documents of the file at path, see ImportJSONAll.
+func LoadJSON(path string) (*StaticFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	codes, err := ImportJSONAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("%s: no functions", path)
+	}
+	return NewStaticFile(codes), nil
+}
+
+func (file *StaticFile) Funcs() []disasm.Func { return file.funcs }
+
+// PackageFuncs groups the funcs by package path.
+func (file *StaticFile) PackageFuncs() map[string][]disasm.Func {
+	return disasm.GroupByPackage(file.funcs)
+}
+
+func (file *StaticFile) Close() error { return nil }
+
+// BuildID returns "", the documents don't include the build ID.
+func (file *StaticFile) BuildID() string { return "" }
+
+// GoModPath returns "", the documents don't include the build info.
+func (file *StaticFile) GoModPath() string { return "" }
+
+// SymbolTable lists the funcs with the address range of their instructions.
+func (file *StaticFile) SymbolTable() []disasm.Symbol {
+	table := make([]disasm.Symbol, 0, len(file.funcs))
+	for _, fn := range file.funcs {
+		fn := fn.(*StaticFunc)
+		sym := disasm.Symbol{
+			Name:     fn.code.Name,
+			Type:     "T",
+			Exported: disasm.IsExported(fn.code.Name),
+		}
+		for _, ix := range fn.code.Insts {
+			if ix.Text == "" {
+				continue
+			}
+			if sym.Address == 0 {
+				sym.Address = ix.PC
+			}
+			sym.Size = int64(ix.PC - sym.Address)
+		}
+		table = append(table, sym)
+	}
+	return table
+}
+
+// StaticFunc is a func of a StaticFile.
+type StaticFunc struct {
+	file *StaticFile
+	code *disasm.Code
+}
+
+func (fn *StaticFunc) Name() string { return fn.code.Name }
+
+// BuildConstraints returns the build constraints of the source file,
+// when it's readable on this machine.
+func (fn *StaticFunc) BuildConstraints() []string {
+	if fn.code.File == "" {
+		return nil
+	}
+	return fn.file.constraints.Get(fn.code.File)
+}
+
+// Load returns the code as it was loaded, the options are ignored
+// since the source context was fixed when exporting.
+func (fn *StaticFunc) Load(opts disasm.Options) *disasm.Code { return fn.code }
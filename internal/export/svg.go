@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// DotCommand is the Graphviz executable used to render SVGs.
+var DotCommand = "dot"
+
+// CallGraphResponse is a call graph between funcs.
+type CallGraphResponse struct {
+	// Root is the func the graph was started from, empty for whole files.
+	Root  string          `json:"root,omitempty"`
+	Nodes []CallGraphNode `json:"nodes"`
+	Edges []CallGraphEdge `json:"edges"`
+}
+
+// CallGraphNode is a func in the call graph.
+type CallGraphNode struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+}
+
+// CallGraphEdge is a call from Caller to Callee.
+type CallGraphEdge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+}
+
+// SVGExportOptions controls the layout of an exported call graph.
+type SVGExportOptions struct {
+	// MaxNodes limits the number of funcs, the first nodes of the graph are kept.
+	// Defaults to 200.
+	MaxNodes int
+	// RankDir is the direction of the calls, "LR" (default) or "TB".
+	RankDir string
+	// FontSize of the labels in points, defaults to 10.
+	FontSize float64
+}
+
+// ExportCallGraphSVG renders graph as SVG with Graphviz,
+// funcs are colored and clustered by package.
+func ExportCallGraphSVG(graph *CallGraphResponse, w io.Writer, opts SVGExportOptions) error {
+	var dot bytes.Buffer
+	if err := WriteCallGraphDOT(graph, &dot, opts); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(DotCommand, "-Tsvg")
+	cmd.Stdin = &dot
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s -Tsvg failed: %w: %s", DotCommand, err, msg)
+		}
+		return fmt.Errorf("%s -Tsvg failed: %w", DotCommand, err)
+	}
+	return nil
+}
+
+// WriteCallGraphDOT writes graph in the Graphviz DOT format used by ExportCallGraphSVG.
+func WriteCallGraphDOT(graph *CallGraphResponse, w io.Writer, opts SVGExportOptions) error {
+	if opts.MaxNodes <= 0 {
+		opts.MaxNodes = 200
+	}
+	switch opts.RankDir {
+	case "":
+		opts.RankDir = "LR"
+	case "LR", "TB":
+	default:
+		return fmt.Errorf("unknown rank direction %q", opts.RankDir)
+	}
+	if opts.FontSize <= 0 {
+		opts.FontSize = 10
+	}
+
+	nodes := graph.Nodes
+	if len(nodes) > opts.MaxNodes {
+		nodes = nodes[:opts.MaxNodes]
+	}
+	ids := make(map[string]string, len(nodes))
+	byPackage := map[string][]string{}
+	for i, node := range nodes {
+		ids[node.Name] = "n" + strconv.Itoa(i)
+		byPackage[node.Package] = append(byPackage[node.Package], node.Name)
+	}
+	packages := make([]string, 0, len(byPackage))
+	for pkg := range byPackage {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph callgraph {\n")
+	fmt.Fprintf(&b, "\trankdir=%s;\n", opts.RankDir)
+	fmt.Fprintf(&b, "\tnode [shape=box, style=\"rounded,filled\", fontname=\"monospace\", fontsize=%g];\n", opts.FontSize)
+	fmt.Fprintf(&b, "\tedge [color=\"#606060\"];\n")
+	for i, pkg := range packages {
+		// funcs without a package, e.g. assembly trampolines, aren't clustered
+		indent := "\t"
+		if pkg != "" {
+			indent = "\t\t"
+			fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n", i)
+			fmt.Fprintf(&b, "\t\tlabel=%s;\n\t\tstyle=dashed;\n\t\tcolor=\"#a0a0a0\";\n", dotQuote(pkg))
+		}
+		color := packageColor(pkg)
+		for _, name := range byPackage[pkg] {
+			label := strings.TrimPrefix(name, pkg+".")
+			attrs := fmt.Sprintf("label=%s, tooltip=%s, fillcolor=%q", dotQuote(label), dotQuote(name), color)
+			if name == graph.Root {
+				attrs += ", penwidth=2"
+			}
+			fmt.Fprintf(&b, "%s%s [%s];\n", indent, ids[name], attrs)
+		}
+		if pkg != "" {
+			fmt.Fprintf(&b, "\t}\n")
+		}
+	}
+	for _, edge := range graph.Edges {
+		caller, ok := ids[edge.Caller]
+		if !ok {
+			continue
+		}
+		callee, ok := ids[edge.Callee]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s -> %s;\n", caller, callee)
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// NewCallGraphNode returns the node of the func name.
+func NewCallGraphNode(name string) CallGraphNode {
+	return CallGraphNode{Name: name, Package: disasm.PackageOf(name)}
+}
+
+// packageColor returns a light color that's stable for pkg.
+func packageColor(pkg string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(pkg))
+	hue := float64(h.Sum32()%360) / 360
+	return fmt.Sprintf("%.3f 0.250 0.950", hue)
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -6,3 +6,13 @@ func (d *Disasm) Syms() []objfile.Sym { return d.syms }
 func (d *Disasm) TextStart() uint64   { return d.textStart }
 func (d *Disasm) TextEnd() uint64     { return d.textEnd }
 func (d *Disasm) PCLN() objfile.Liner { return d.pcln }
+
+// InstBytes returns the encoding of the instruction of size bytes at pc,
+// nil when it's outside the text section.
+func (d *Disasm) InstBytes(pc, size uint64) []byte {
+	if pc < d.textStart || pc+size > d.textEnd || size == 0 {
+		return nil
+	}
+	i := pc - d.textStart
+	return d.text[i : i+size : i+size]
+}
@@ -0,0 +1,36 @@
+package objfile
+
+import (
+	"debug/macho"
+	"debug/pe"
+	"os"
+)
+
+// PCLNTab returns the start of the text section and the raw pclntab
+// of the first entry.
+func (f *File) PCLNTab() (textStart uint64, pclntab []byte, err error) {
+	textStart, _, pclntab, err = f.entries[0].raw.pcln()
+	return textStart, pclntab, err
+}
+
+// OpenMacho returns the file for the Mach-O file m read from r,
+// e.g. a slice of a universal binary. Close closes r.
+func OpenMacho(r *os.File, m *macho.File) *File {
+	return &File{r: r, entries: []*Entry{{raw: &machoFile{m}}}}
+}
+
+// OpenPE returns the file for the PE file p read from r, whose pclntab
+// is loaded by pcln instead of the PE parser. Close closes r.
+func OpenPE(r *os.File, p *pe.File, pcln func() (textStart uint64, symtab, pclntab []byte, err error)) *File {
+	return &File{r: r, entries: []*Entry{{raw: &peTables{peFile: &peFile{p}, tables: pcln}}}}
+}
+
+// peTables is a PE file with a custom pclntab lookup.
+type peTables struct {
+	*peFile
+	tables func() (textStart uint64, symtab, pclntab []byte, err error)
+}
+
+func (f *peTables) pcln() (textStart uint64, symtab, pclntab []byte, err error) {
+	return f.tables()
+}
@@ -26,6 +26,7 @@ func main() {
 	}
 
 	must0(os.WriteFile("src/disasm/expose.go", must(os.ReadFile("expose.go_")), 0644))
+	must0(os.WriteFile("src/objfile/expose.go", must(os.ReadFile("expose_objfile.go_")), 0644))
 	must0(os.Remove("src/abi/abi_test.s"))
 }
 
@@ -0,0 +1,113 @@
+package goobj
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Addr2LineCommand is the addr2line executable used for resolving the source
+// of instructions without pclntab line information, e.g. in stripped
+// binaries with separate DWARF. Empty disables the fallback.
+var Addr2LineCommand = ""
+
+// Addr2LineResult is the source position of a pc reported by addr2line.
+type Addr2LineResult struct {
+	Func string
+	File string
+	Line int
+}
+
+// Addr2LineResolver resolves pcs of an executable by running addr2line.
+type Addr2LineResolver struct {
+	command string
+	path    string
+
+	mu    sync.Mutex
+	cache map[uint64]Addr2LineResult
+	// err is the first failure of the command, after which it isn't run again
+	err error
+}
+
+// NewAddr2LineResolver creates a resolver for the executable at path.
+func NewAddr2LineResolver(command, path string) *Addr2LineResolver {
+	return &Addr2LineResolver{
+		command: command,
+		path:    path,
+		cache:   make(map[uint64]Addr2LineResult),
+	}
+}
+
+// Resolve returns the source positions of pcs. Pcs addr2line doesn't
+// know are missing from the result.
+func (r *Addr2LineResolver) Resolve(pcs []uint64) (map[uint64]Addr2LineResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var missing []uint64
+	for _, pc := range pcs {
+		if _, ok := r.cache[pc]; !ok {
+			missing = append(missing, pc)
+		}
+	}
+	if len(missing) > 0 && r.err == nil {
+		r.err = r.run(missing)
+	}
+
+	results := make(map[uint64]Addr2LineResult, len(pcs))
+	for _, pc := range pcs {
+		if result, ok := r.cache[pc]; ok && result.File != "" {
+			results[pc] = result
+		}
+	}
+	return results, r.err
+}
+
+// run resolves pcs with a single addr2line invocation and caches the results,
+// unknown pcs are cached as empty results.
+func (r *Addr2LineResolver) run(pcs []uint64) error {
+	args := []string{"-f", "-e", r.path}
+	for _, pc := range pcs {
+		args = append(args, "0x"+strconv.FormatUint(pc, 16))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(r.command, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", r.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// every pc is printed as two lines, "func" and "file:line"
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for _, pc := range pcs {
+		var result Addr2LineResult
+		if scanner.Scan() && scanner.Text() != "??" {
+			result.Func = scanner.Text()
+		}
+		if scanner.Scan() {
+			result.File, result.Line = parseAddr2LinePosition(scanner.Text())
+		}
+		r.cache[pc] = result
+	}
+	return nil
+}
+
+// parseAddr2LinePosition parses "file:line" or "file:line (discriminator N)",
+// file is empty for unknown positions such as "??:0".
+func parseAddr2LinePosition(s string) (file string, line int) {
+	if i := strings.Index(s, " ("); i >= 0 {
+		s = s[:i]
+	}
+	colon := strings.LastIndexByte(s, ':')
+	if colon < 0 || s[:colon] == "??" {
+		return "", 0
+	}
+	line, _ = strconv.Atoi(s[colon+1:])
+	return s[:colon], line
+}
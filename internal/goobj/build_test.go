@@ -0,0 +1,51 @@
+package goobj
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildProgram writes files to a new module and builds it with the build
+// flags, it returns the path of the output. The test is skipped without
+// a go toolchain.
+func buildProgram(t *testing.T, files map[string]string, flags ...string) string {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go toolchain to build the test binary")
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/test\n"
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "test.out")
+	build := exec.Command(goTool, append(append([]string{"build", "-o", out}, flags...), ".")...)
+	build.Dir = dir
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build test binary: %v\n%s", err, output)
+	}
+	return out
+}
+
+// requireCgo skips the test when cgo is disabled or the C compiler is missing.
+func requireCgo(t *testing.T) {
+	t.Helper()
+	out, err := exec.Command("go", "env", "CGO_ENABLED", "CC").Output()
+	if err != nil {
+		t.Skip("no go toolchain to build the test binary")
+	}
+	env := strings.Fields(string(out))
+	if len(env) < 2 || env[0] != "1" {
+		t.Skip("cgo is disabled")
+	}
+	if _, err := exec.LookPath(env[1]); err != nil {
+		t.Skipf("no C compiler %s", env[1])
+	}
+}
@@ -0,0 +1,121 @@
+package goobj
+
+import (
+	"bytes"
+	"debug/elf"
+	"io"
+	"os"
+	"strconv"
+)
+
+var (
+	// buildIDPrefix starts the go:buildid symbol at the beginning of the text
+	buildIDPrefix = []byte("\xff Go build ID: \"")
+	buildIDSuffix = []byte("\"\n \xff")
+	// elfGoNote is the name of the ELF note holding the build ID
+	elfGoNote = []byte("Go\x00\x00")
+)
+
+// readBuildID returns the build ID that the Go linker embedded in the executable at path,
+// or "" when there's none.
+func readBuildID(path string) string {
+	if id := elfBuildID(path); id != "" {
+		return id
+	}
+	if id := machoBuildID(path); id != "" {
+		return id
+	}
+	return scanBuildID(path)
+}
+
+// elfBuildID reads the build ID from the .note.go.buildid note
+// or the .go.buildid section.
+func elfBuildID(path string) string {
+	f, err := elf.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	if section := f.Section(".note.go.buildid"); section != nil {
+		data, err := section.Data()
+		if err != nil || len(data) < 16 {
+			return ""
+		}
+		nameSize := f.ByteOrder.Uint32(data[0:])
+		descSize := f.ByteOrder.Uint32(data[4:])
+		if nameSize != 4 || !bytes.Equal(data[12:16], elfGoNote) || uint64(descSize) > uint64(len(data)-16) {
+			return ""
+		}
+		return string(data[16 : 16+descSize])
+	}
+	if section := f.Section(".go.buildid"); section != nil {
+		data, err := section.Data()
+		if err != nil {
+			return ""
+		}
+		return parseBuildID(data)
+	}
+	return ""
+}
+
+// machoBuildID reads the build ID from the __text,__go_buildid section.
+func machoBuildID(path string) string {
+	f, closer, err := openMacho(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = closer.Close() }()
+
+	for _, section := range f.Sections {
+		if section.Seg != "__TEXT" && section.Seg != "__text" || section.Name != "__go_buildid" {
+			continue
+		}
+		data, err := section.Data()
+		if err != nil {
+			return ""
+		}
+		return parseBuildID(data)
+	}
+	// the linker usually puts go:buildid at the start of __text instead
+	if section := f.Section("__text"); section != nil {
+		data := make([]byte, 1024)
+		n, _ := section.ReadAt(data, 0)
+		return parseBuildID(data[:n])
+	}
+	return ""
+}
+
+// scanBuildID looks for the go:buildid symbol in the first 32KB of the file,
+// as `go tool buildid` does for formats without a dedicated section.
+func scanBuildID(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, 32*1024))
+	if err != nil {
+		return ""
+	}
+	return parseBuildID(data)
+}
+
+// parseBuildID extracts the quoted build ID from the go:buildid symbol in data.
+func parseBuildID(data []byte) string {
+	start := bytes.Index(data, buildIDPrefix)
+	if start < 0 {
+		return ""
+	}
+	rest := data[start+len(buildIDPrefix)-1:]
+	end := bytes.Index(rest, buildIDSuffix)
+	if end < 0 {
+		return ""
+	}
+	id, err := strconv.Unquote(string(rest[:end+1]))
+	if err != nil {
+		return ""
+	}
+	return id
+}
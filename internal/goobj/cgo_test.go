@@ -0,0 +1,83 @@
+package goobj
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// TestLoadCgo checks that the source blocks of a cgo binary contain the Go
+// and the C sources, with the C comments and preprocessor lines unchanged.
+func TestLoadCgo(t *testing.T) {
+	requireCgo(t)
+	exe := buildProgram(t, map[string]string{
+		"main.go": `package main
+
+// int add(int a, int b);
+import "C"
+
+func main() {
+	println(C.add(1, 2))
+}
+`,
+		"add.c": `//go:noinline is a C comment here
+#if 1
+int add(int a, int b) {
+	return a + b;
+}
+#endif
+`,
+	})
+
+	// the C funcs have only DWARF line tables
+	file, err := LoadWithDWARF(exe, exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	sources := map[string]struct {
+		file string
+		lang disasm.SourceLang
+		line string
+	}{
+		"main.main": {"main.go", disasm.LangGo, `import "C"`},
+		"add":       {"add.c", disasm.LangC, "#if 1"},
+	}
+	for _, fn := range file.Funcs() {
+		want, ok := sources[fn.Name()]
+		if !ok {
+			continue
+		}
+		code := fn.Load(disasm.Options{Context: 3})
+		if code == nil {
+			t.Fatalf("failed to load %s", fn.Name())
+		}
+		if len(code.Source) != 1 {
+			t.Fatalf("%s has %d source files, want 1", fn.Name(), len(code.Source))
+		}
+		src := code.Source[0]
+		if filepath.Base(src.File) != want.file {
+			t.Errorf("%s source file = %s, want %s", fn.Name(), src.File, want.file)
+		}
+		if lang := disasm.DetectLanguage(src.File); lang != want.lang {
+			t.Errorf("%s language = %v, want %v", fn.Name(), lang, want.lang)
+		}
+		var lines []string
+		for _, block := range src.Blocks {
+			lines = append(lines, block.Lines...)
+		}
+		if !slices.Contains(lines, want.line) {
+			t.Errorf("%s source lines %q don't contain %q", fn.Name(), lines, want.line)
+		}
+		if directives := ParseDirectives(src.File, fn.Name()); len(directives) != 0 {
+			t.Errorf("%s directives = %v, want none", fn.Name(), directives)
+		}
+		delete(sources, fn.Name())
+	}
+	for name := range sources {
+		t.Errorf("%s not found", name)
+	}
+}
@@ -14,20 +14,31 @@ import (
 
 var rxRefAbs = regexp.MustCompile(`\s0x[\da-fA-F]+$`)
 var rxRefRel = regexp.MustCompile(`\s-?\d+\(PC\)$`)
-var rxCall = regexp.MustCompile(`^CALL\s+([\w\d\/\.\(\)\*]+)\(SB\)`)
+
+// rxCall also matches riscv64 JAL, which links X1 for calls and X5 for
+// runtime.morestack, e.g. "JAL X5, runtime.morestack_noctxt.abi0(SB)".
+var rxCall = regexp.MustCompile(`^(?:CALL|JAL\s+X\d+,)\s+([\w\d\/\.\(\)\*]+)\(SB\)`)
 
 // Disassemble disassembles the specified symbol.
-func Disassemble(dis *godisasm.Disasm, sym *Function, opts disasm.Options) (*disasm.Code, error) {
-	neededLines := make(map[string]*disasm.LineSet)
+// A panic of the decoder on malformed code is returned as an error.
+func Disassemble(dis *godisasm.Disasm, sym *Function, opts disasm.Options) (code *disasm.Code, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			code, err = nil, fmt.Errorf("failed to disassemble %s: %v", sym.Name(), p)
+		}
+	}()
 
 	file, _, _ := dis.PCLN().PCToLine(sym.sym.Addr)
-	needRefPCs := map[uint64]struct{}{}
 
-	code := &disasm.Code{
-		Name: sym.Name(),
-		File: file,
+	code = &disasm.Code{
+		Name:        sym.Name(),
+		File:        file,
+		Arch:        sym.obj.GOARCH(),
+		FrameSize:   sym.FrameSize(),
+		StackMapPCs: sym.StackMapPCs(),
 	}
 	var instructions []disasm.Inst
+	var unresolved []uint64
 	dis.Decode(sym.sym.Addr, sym.sym.Addr+uint64(sym.sym.Size), sym.sym.Relocs, false,
 		func(pc, size uint64, file string, line int, text string) {
 			// TODO: find a better way to calculate the jump target
@@ -48,27 +59,96 @@ func Disassemble(dis *godisasm.Disasm, sym *Function, opts disasm.Options) (*dis
 				call = match[1]
 			}
 
-			if refPC != 0 {
-				needRefPCs[refPC] = struct{}{}
-			}
 			instructions = append(instructions, disasm.Inst{
 				PC:    pc,
 				Text:  text,
 				File:  file,
 				Line:  line,
+				Bytes: dis.InstBytes(pc, size),
 				Call:  call,
 				RefPC: refPC,
 			})
+			if file == "" {
+				unresolved = append(unresolved, pc)
+			}
+		})
 
-			if file != "" && file != "<autogenerated>" {
-				lineset, ok := neededLines[file]
-				if !ok {
-					lineset = &disasm.LineSet{}
-					neededLines[file] = lineset
+	// stripped binaries have no line table, use the separate DWARF
+	// or ask addr2line for it
+	if len(unresolved) > 0 {
+		if table, err := sym.obj.dwarfLines(); table != nil {
+			unresolved = unresolved[:0]
+			for i := range instructions {
+				ix := &instructions[i]
+				if ix.File != "" {
+					continue
+				}
+				if file, line, ok := table.lookup(ix.PC); ok {
+					ix.File, ix.Line = file, line
+				} else {
+					unresolved = append(unresolved, ix.PC)
 				}
-				lineset.Add(line)
+			}
+		} else if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	if len(unresolved) > 0 && sym.obj.addr2line != nil {
+		resolved, err := sym.obj.addr2line.Resolve(unresolved)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+		}
+		for i := range instructions {
+			ix := &instructions[i]
+			if pos, ok := resolved[ix.PC]; ok && ix.File == "" {
+				ix.File, ix.Line = pos.File, pos.Line
+			}
+		}
+	}
+	if code.File == "" && len(instructions) > 0 {
+		code.File = instructions[0].File
+	}
+	if sym.obj.GOARCH() == "riscv64" {
+		fixCompressedRefs(instructions)
+	}
+
+	BuildCode(code, instructions, opts)
+	return code, nil
+}
+
+// Calls returns the names of the funcs called by fn, decoded from its
+// instructions without loading the sources or caching the code.
+func (fn *Function) Calls() []string {
+	var calls []string
+	fn.obj.disasm.Decode(fn.sym.Addr, fn.sym.Addr+uint64(fn.sym.Size), fn.sym.Relocs, false,
+		func(pc, size uint64, file string, line int, text string) {
+			if match := rxCall.FindStringSubmatch(text); len(match) > 0 {
+				calls = append(calls, match[1])
 			}
 		})
+	return calls
+}
+
+// BuildCode adds the instructions to code with an empty line before each
+// jump target, lays out the jump lines, computes the metrics, and loads the
+// sources of the instructions with opts.Context lines of context.
+func BuildCode(code *disasm.Code, instructions []disasm.Inst, opts disasm.Options) {
+	neededLines := make(map[string]*disasm.LineSet)
+	needRefPCs := map[uint64]struct{}{}
+	for _, ix := range instructions {
+		if ix.RefPC != 0 {
+			needRefPCs[ix.RefPC] = struct{}{}
+		}
+		if ix.File == "" || ix.File == "<autogenerated>" {
+			continue
+		}
+		lineset, ok := neededLines[ix.File]
+		if !ok {
+			lineset = &disasm.LineSet{}
+			neededLines[ix.File] = lineset
+		}
+		lineset.Add(ix.Line)
+	}
 
 	pcToIndex := map[uint64]int{}
 	for _, ix := range instructions {
@@ -159,6 +239,11 @@ func Disassemble(dis *godisasm.Disasm, sym *Function, opts disasm.Options) (*dis
 			code.Insts[len(code.Insts)-1].Text == "?") {
 		code.Insts = code.Insts[:len(code.Insts)-1]
 	}
+	code.BoundsCheckCount = disasm.CountBoundsChecks(code)
+	code.TailCallCount = disasm.CountTailCalls(code)
+	code.NilCheckCount = disasm.CountNilChecks(code)
+	code.NOPBytes = disasm.CountNOPBytes(code)
+	disasm.ComputeMetrics(code)
 
 	// load sources
 	code.Source = LoadSources(neededLines, code.File, opts.Context)
@@ -191,8 +276,29 @@ func Disassemble(dis *godisasm.Disasm, sym *Function, opts disasm.Options) (*dis
 			}
 		}
 	}
+}
 
-	return code, nil
+// fixCompressedRefs corrects the jump targets of riscv64 compressed branches.
+// The disassembler prints their offsets as N(PC) where N is the offset in bytes
+// divided by 4, which is 2 bytes short when the offset isn't a multiple of 4.
+func fixCompressedRefs(instructions []disasm.Inst) {
+	starts := map[uint64]bool{}
+	for _, ix := range instructions {
+		starts[ix.PC] = true
+	}
+	for i := range instructions {
+		ix := &instructions[i]
+		if ix.RefPC == 0 || starts[ix.RefPC] {
+			continue
+		}
+		target := ix.RefPC + 2
+		if ix.RefPC < ix.PC {
+			target = ix.RefPC - 2
+		}
+		if starts[target] {
+			ix.RefPC = target
+		}
+	}
 }
 
 var rxEnvVariable = regexp.MustCompile(`\$[a-zA-Z_]+[a-zA-Z0-9_]+\b`)
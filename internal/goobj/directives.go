@@ -0,0 +1,224 @@
+package goobj
+
+import (
+	"go/scanner"
+	"go/token"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// DirectiveKind is a compiler directive that changes the code generated for a func.
+type DirectiveKind int
+
+const (
+	// DirNosplit is //go:nosplit, the func doesn't check for stack overflow.
+	DirNosplit DirectiveKind = iota
+	// DirNoescape is //go:noescape, the pointer arguments of the
+	// func without body don't escape.
+	DirNoescape
+	// DirLinkname is //go:linkname, the func is linked to another symbol.
+	DirLinkname
+	// DirInline is //go:noinline, the func isn't inlined into its callers.
+	DirInline
+)
+
+// String returns the name of the directive without the "go:" prefix.
+func (kind DirectiveKind) String() string {
+	switch kind {
+	case DirNosplit:
+		return "nosplit"
+	case DirNoescape:
+		return "noescape"
+	case DirLinkname:
+		return "linkname"
+	case DirInline:
+		return "noinline"
+	default:
+		return "unknown"
+	}
+}
+
+// directiveKinds maps the directive names to their kinds.
+var directiveKinds = map[string]DirectiveKind{
+	"go:nosplit":  DirNosplit,
+	"go:noescape": DirNoescape,
+	"go:linkname": DirLinkname,
+	"go:noinline": DirInline,
+}
+
+// Directive is a compiler directive of a func.
+type Directive struct {
+	Kind DirectiveKind
+	// Value are the arguments of the directive, e.g. the local and the
+	// target name of //go:linkname.
+	Value string
+}
+
+// rxClosure matches the names of closures and go/defer wrappers,
+// which can't have directives.
+var rxClosure = regexp.MustCompile(`\.(?:func|gowrap|deferwrap)\d+`)
+
+// ParseDirectives returns the compiler directives of the func funcName,
+// e.g. "main.(*T).M", declared in the Go source file sourceFile.
+//
+// The directives are the //go: comments right before the func declaration
+// and the //go:linkname directives for the func anywhere in the file.
+// It returns nil when the file can't be read or the func isn't declared in it,
+// and for the C files of cgo funcs, whose comments and preprocessor
+// directives aren't Go.
+func ParseDirectives(sourceFile string, funcName string) []Directive {
+	if disasm.DetectLanguage(sourceFile) != disasm.LangGo {
+		return nil
+	}
+	recv, name, ok := splitFuncName(funcName)
+	if !ok {
+		return nil
+	}
+	src, err := os.ReadFile(replaceEnvironmentVariables(sourceFile))
+	if err != nil {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile(sourceFile, -1, len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var directives, linknames []Directive
+	found := false
+	// group are the line comments on consecutive lines before the current token
+	var group []string
+	groupEnd := 0
+	depth := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		line := file.Line(pos)
+		switch tok {
+		case token.COMMENT:
+			if len(group) == 0 || line != groupEnd+1 {
+				group = group[:0]
+			}
+			group = append(group, lit)
+			groupEnd = line
+			if directive, ok := parseDirective(lit); ok && directive.Kind == DirLinkname && recv == "" {
+				if fields := strings.Fields(directive.Value); len(fields) > 0 && fields[0] == name {
+					linknames = append(linknames, directive)
+				}
+			}
+			continue
+		case token.SEMICOLON:
+			if lit == "\n" {
+				// automatically inserted at the end of the line
+				continue
+			}
+		case token.LBRACE, token.LPAREN, token.LBRACK:
+			depth++
+		case token.RBRACE, token.RPAREN, token.RBRACK:
+			depth--
+		case token.FUNC:
+			if depth == 0 && !found {
+				declRecv, declName := scanFuncDecl(&s)
+				if declRecv == recv && declName == name {
+					found = true
+					if len(group) > 0 && groupEnd == line-1 {
+						for _, comment := range group {
+							if directive, ok := parseDirective(comment); ok && directive.Kind != DirLinkname {
+								directives = append(directives, directive)
+							}
+						}
+					}
+				}
+			}
+		}
+		group = group[:0]
+	}
+	if !found {
+		return nil
+	}
+	return append(directives, linknames...)
+}
+
+// parseDirective parses a //go: comment of a known directive.
+func parseDirective(comment string) (Directive, bool) {
+	text, ok := strings.CutPrefix(comment, "//")
+	if !ok {
+		return Directive{}, false
+	}
+	name, value, _ := strings.Cut(text, " ")
+	kind, ok := directiveKinds[name]
+	if !ok {
+		return Directive{}, false
+	}
+	return Directive{Kind: kind, Value: strings.TrimSpace(value)}, true
+}
+
+// scanFuncDecl scans the receiver type and the name of the func declaration
+// after the func keyword. Both are empty for func literals.
+func scanFuncDecl(s *scanner.Scanner) (recv, name string) {
+	_, tok, lit := s.Scan()
+	if tok == token.LPAREN {
+		// the receiver type is the last identifier outside of the type parameters,
+		// e.g. T for "(t *T[K])"
+		depth := 1
+		for depth > 0 {
+			_, tok, lit := s.Scan()
+			switch tok {
+			case token.EOF:
+				return "", ""
+			case token.LPAREN, token.LBRACK:
+				depth++
+			case token.RPAREN, token.RBRACK:
+				depth--
+			case token.IDENT:
+				if depth == 1 {
+					recv = lit
+				}
+			}
+		}
+		_, tok, lit = s.Scan()
+	}
+	if tok != token.IDENT {
+		return "", ""
+	}
+	return recv, lit
+}
+
+// splitFuncName returns the receiver type and the name of a func symbol,
+// ok is false for closures and other funcs that aren't declared in the source.
+func splitFuncName(funcName string) (recv, name string, ok bool) {
+	// drop the type arguments of generic funcs
+	var b strings.Builder
+	depth := 0
+	for _, r := range funcName {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	funcName = strings.TrimSuffix(b.String(), ".abi0")
+
+	pkg := disasm.PackageOf(funcName)
+	if pkg == "" || rxClosure.MatchString(funcName) || strings.Contains(funcName, "-") {
+		return "", "", false
+	}
+	rest := funcName[len(pkg)+1:]
+	parts := strings.Split(rest, ".")
+	switch len(parts) {
+	case 1:
+		return "", parts[0], true
+	case 2:
+		recv = strings.TrimSuffix(strings.TrimPrefix(parts[0], "(*"), ")")
+		return recv, parts[1], true
+	}
+	return "", "", false
+}
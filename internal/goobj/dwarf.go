@@ -0,0 +1,164 @@
+package goobj
+
+import (
+	"context"
+	"debug/dwarf"
+	"debug/elf"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// LoadWithDWARF loads the executable at exePath like Load, and reads the
+// line tables from the separate debug info at dwarfPath, e.g. a `-dbg`
+// package or a `.dSYM` bundle. The line tables are used for instructions
+// without pclntab line information.
+//
+// An empty dwarfPath only uses a `.dSYM` bundle next to the executable.
+func LoadWithDWARF(exePath, dwarfPath string) (disasm.File, error) {
+	file, err := LoadContext(context.Background(), exePath)
+	if err != nil || dwarfPath == "" {
+		return file, err
+	}
+
+	obj, ok := file.(*File)
+	if !ok {
+		_ = file.Close()
+		return nil, fmt.Errorf("separate debug info is only supported for Go executables")
+	}
+	obj.dwarfPath = dwarfPath
+	if _, err := obj.dwarfLines(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to load debug info %s: %w", dwarfPath, err)
+	}
+	return file, nil
+}
+
+// dSYMPath returns the DWARF file of the `.dSYM` bundle next to the
+// executable at path, or "" when there is none.
+func dSYMPath(path string) string {
+	candidate := filepath.Join(path+".dSYM", "Contents", "Resources", "DWARF", filepath.Base(path))
+	if _, err := os.Stat(candidate); err != nil {
+		return ""
+	}
+	return candidate
+}
+
+// dwarfLines returns the line table of the separate debug info,
+// nil when there is none.
+func (file *File) dwarfLines() (*lineTable, error) {
+	file.dwarfOnce.Do(func() {
+		if file.dwarfPath != "" {
+			file.dwarf, file.dwarfErr = loadLineTable(file.dwarfPath)
+		}
+	})
+	return file.dwarf, file.dwarfErr
+}
+
+// lineTable maps pcs to source positions.
+type lineTable struct {
+	// rows are sorted by address, a row applies
+	// up to the address of the next row
+	rows []lineRow
+}
+
+type lineRow struct {
+	addr uint64
+	file string
+	line int
+	// end marks the first address after a sequence
+	end bool
+}
+
+// lookup returns the source position of pc.
+func (table *lineTable) lookup(pc uint64) (file string, line int, ok bool) {
+	i := sort.Search(len(table.rows), func(i int) bool { return table.rows[i].addr > pc }) - 1
+	if i < 0 || table.rows[i].end || table.rows[i].file == "" {
+		return "", 0, false
+	}
+	return table.rows[i].file, table.rows[i].line, true
+}
+
+// loadLineTable reads .debug_info and .debug_line from the ELF,
+// Mach-O or PE file at path.
+func loadLineTable(path string) (*lineTable, error) {
+	data, err := openDWARF(path)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &lineTable{}
+	reader := data.Reader()
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			reader.SkipChildren()
+			continue
+		}
+		lines, err := data.LineReader(entry)
+		if err != nil {
+			return nil, err
+		}
+		if lines == nil {
+			continue
+		}
+		var row dwarf.LineEntry
+		for {
+			if err := lines.Next(&row); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			r := lineRow{addr: row.Address, line: row.Line, end: row.EndSequence}
+			if row.File != nil {
+				r.file = row.File.Name
+			}
+			table.rows = append(table.rows, r)
+		}
+	}
+
+	// sequence ends sort before rows starting at the same address
+	sort.SliceStable(table.rows, func(i, k int) bool {
+		if table.rows[i].addr != table.rows[k].addr {
+			return table.rows[i].addr < table.rows[k].addr
+		}
+		return table.rows[i].end && !table.rows[k].end
+	})
+	if len(table.rows) == 0 {
+		return nil, errors.New("no line information")
+	}
+	return table, nil
+}
+
+// openDWARF reads the DWARF of the ELF, Mach-O or PE file at path.
+func openDWARF(path string) (*dwarf.Data, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer func() { _ = f.Close() }()
+		return f.DWARF()
+	}
+	if f, closer, err := openMacho(path); err == nil {
+		defer func() { _ = closer.Close() }()
+		return f.DWARF()
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer func() { _ = f.Close() }()
+		return f.DWARF()
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unsupported file format: %s", path)
+}
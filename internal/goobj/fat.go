@@ -0,0 +1,107 @@
+package goobj
+
+import (
+	"debug/macho"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/go/src/objfile"
+)
+
+// Arch selects the slice of universal Mach-O binaries by GOARCH.
+// Empty selects the slice of the host architecture, or the first slice
+// when the binary wasn't built for it.
+var Arch = ""
+
+// Slices returns the GOARCH of each slice of a universal Mach-O binary,
+// nil for other files. It implements disasm.SlicedFile.
+func (file *File) Slices() []string { return file.slices }
+
+// openFatMacho opens the slice chosen by sliceArch of the universal Mach-O
+// binary at path and returns the GOARCH of each slice. It fails with
+// macho.ErrNotFat for other Mach-O files.
+func openFatMacho(path string) (*objfile.File, []string, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	fat, err := macho.NewFatFile(r)
+	if err != nil {
+		_ = r.Close()
+		return nil, nil, err
+	}
+
+	goarches := fatGOARCHes(fat)
+	goarch, err := sliceArch(goarches)
+	if err != nil {
+		_ = r.Close()
+		return nil, nil, err
+	}
+	return objfile.OpenMacho(r, fat.Arches[slices.Index(goarches, goarch)].File), goarches, nil
+}
+
+// sliceArch returns the slice of goarches to load, see Arch.
+func sliceArch(goarches []string) (string, error) {
+	switch {
+	case Arch != "":
+		if !slices.Contains(goarches, Arch) {
+			return "", fmt.Errorf("universal binary has no %s slice, only %s", Arch, strings.Join(goarches, ", "))
+		}
+		return Arch, nil
+	case slices.Contains(goarches, runtime.GOARCH):
+		return runtime.GOARCH, nil
+	}
+	return goarches[0], nil
+}
+
+// fatGOARCHes returns the GOARCH of each slice of fat.
+func fatGOARCHes(fat *macho.FatFile) []string {
+	goarches := make([]string, len(fat.Arches))
+	for i, arch := range fat.Arches {
+		goarches[i] = machoGOARCH(arch.Cpu)
+	}
+	return goarches
+}
+
+// machoGOARCH returns the GOARCH of a Mach-O CPU type, "" when it's unknown.
+func machoGOARCH(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.Cpu386:
+		return "386"
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.CpuArm:
+		return "arm"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.CpuPpc64:
+		return "ppc64"
+	}
+	return ""
+}
+
+// openMacho opens the Mach-O file at path, or the slice chosen by sliceArch
+// of a universal binary. The closer closes the file.
+func openMacho(path string) (*macho.File, io.Closer, error) {
+	fat, err := macho.OpenFat(path)
+	if errors.Is(err, macho.ErrNotFat) {
+		f, err := macho.Open(path)
+		return f, f, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	goarches := fatGOARCHes(fat)
+	goarch, err := sliceArch(goarches)
+	if err != nil {
+		_ = fat.Close()
+		return nil, nil, err
+	}
+	return fat.Arches[slices.Index(goarches, goarch)].File, fat, nil
+}
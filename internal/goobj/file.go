@@ -1,62 +1,308 @@
 package goobj
 
 import (
+	"context"
+	"debug/buildinfo"
+	"debug/macho"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gameformush/goasm-vscode/internal/disasm"
 	godisasm "github.com/gameformush/goasm-vscode/internal/go/src/disasm"
 	"github.com/gameformush/goasm-vscode/internal/go/src/objfile"
+	"github.com/gameformush/goasm-vscode/internal/objdumploader"
+	"github.com/gameformush/goasm-vscode/internal/wasmobj"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ disasm.File = (*File)(nil)
 var _ disasm.Func = (*Function)(nil)
 
+// tracer records the loading of files, it uses the provider set up by the caller.
+var tracer = otel.Tracer("lensm")
+
 // File contains information about the object file.
 type File struct {
+	path    string
 	objfile *objfile.File
 	disasm  *godisasm.Disasm
 	funcs   []disasm.Func
+	test    bool
+	plugin  *pluginInfo
+	buildID string
+
+	// slices are the GOARCH of each slice of a universal Mach-O binary,
+	// nil for other files
+	slices []string
+
+	// symbols maps symbol names to their index in the symbol table,
+	// built on first use, see FindSymbol
+	symbolsOnce sync.Once
+	symbols     map[string]int
+
+	// buildInfo is the embedded build info, read on first use,
+	// nil when the binary has none
+	buildInfoOnce sync.Once
+	buildInfo     *buildinfo.BuildInfo
+
+	// frames maps func entry addresses to pclntab details, loaded on first use
+	framesOnce sync.Once
+	frames     map[uint64]funcInfo
+	pcQuantum  uint64
+
+	// constraints caches the build constraints of the source files
+	constraints disasm.ConstraintCache
+
+	// addr2line resolves instructions without line information,
+	// nil unless Addr2LineCommand is set
+	addr2line *Addr2LineResolver
+
+	// dwarfPath is the separate debug info, its line table is loaded on first use
+	dwarfPath string
+	dwarfOnce sync.Once
+	dwarf     *lineTable
+	dwarfErr  error
+
+	// mu guards cache and loading
+	mu sync.Mutex
+	// cache keeps the recently loaded code, LoadCode returns the same
+	// *disasm.Code for the same func and options without copying it,
+	// nil when CodeCacheSize is 0
+	cache *lru.Cache[codeKey, *disasm.Code]
+	// loading contains the disassemblies in progress, concurrent
+	// LoadCode calls for the same key wait for the first one
+	loading map[codeKey]*codeLoad
+}
+
+// CodeCacheSize is the number of disassembled funcs kept by each file,
+// 0 disables the cache.
+var CodeCacheSize = 256
+
+// codeKey identifies the disassembly of a function with specific options.
+type codeKey struct {
+	fn   *Function
+	opts disasm.Options
+}
 
-	cache map[*Function]*disasm.Code
+// codeLoad is a disassembly in progress, done is closed when code is set.
+type codeLoad struct {
+	done chan struct{}
+	code *disasm.Code
 }
 
 func (file *File) Funcs() []disasm.Func { return file.funcs }
 
+// PackageFuncs groups the funcs by package path.
+func (file *File) PackageFuncs() map[string][]disasm.Func {
+	return disasm.GroupByPackage(file.funcs)
+}
+
+// SymbolTable lists all symbols in the file.
+func (file *File) SymbolTable() []disasm.Symbol {
+	syms := file.disasm.Syms()
+	table := make([]disasm.Symbol, 0, len(syms))
+	for _, sym := range syms {
+		table = append(table, disasm.Symbol{
+			Name:     sym.Name,
+			Type:     string(sym.Code),
+			Size:     sym.Size,
+			Address:  sym.Addr,
+			Exported: disasm.IsExported(sym.Name),
+		})
+	}
+	return table
+}
+
+// ResolvePC returns the source position of pc,
+// ok is false when pc isn't inside a func.
+func (file *File) ResolvePC(pc uint64) (loc disasm.Location, ok bool) {
+	name, line, fn := file.disasm.PCLN().PCToLine(pc)
+	if fn == nil {
+		return disasm.Location{}, false
+	}
+	if name == "" {
+		if table, _ := file.dwarfLines(); table != nil {
+			name, line, _ = table.lookup(pc)
+		}
+	}
+	return disasm.Location{File: name, Line: line, Func: fn.Name}, true
+}
+
+// BuildID returns the build ID from the go:buildid section.
+func (file *File) BuildID() string { return file.buildID }
+
+// GOARCH returns the architecture of the instructions, e.g. "amd64".
+func (file *File) GOARCH() string { return file.objfile.GOARCH() }
+
+// GoVersion returns the version of the toolchain that built the file,
+// e.g. "go1.22.3", or "" when the file has no build info.
+func (file *File) GoVersion() string {
+	if info := file.readBuildInfo(); info != nil {
+		return info.GoVersion
+	}
+	return ""
+}
+
+// GoModPath returns the path of the main module from the build info.
+func (file *File) GoModPath() string {
+	if info := file.readBuildInfo(); info != nil {
+		return info.Main.Path
+	}
+	return ""
+}
+
+// readBuildInfo returns the build info the linker embedded in the binary,
+// nil when there's none.
+func (file *File) readBuildInfo() *buildinfo.BuildInfo {
+	file.buildInfoOnce.Do(func() {
+		file.buildInfo, _ = buildinfo.ReadFile(file.path)
+	})
+	return file.buildInfo
+}
+
+// IsTest returns whether the file was built with `go test -c`.
+func (file *File) IsTest() bool { return file.test }
+
+// IsPlugin returns whether the file was built with `-buildmode=plugin`.
+func (file *File) IsPlugin() bool { return file.plugin != nil }
+
 // Function contains information about the executable.
 type Function struct {
 	obj *File
 	sym objfile.Sym
 
 	sortName string
+	export   bool
 }
 
 func (fn *Function) Name() string { return fn.sym.Name }
 
+// Size returns the size of the func in bytes.
+func (fn *Function) Size() int64 { return fn.sym.Size }
+
+// Kind classifies the func by its name, see disasm.KindOf.
+func (fn *Function) Kind() disasm.FuncKind { return disasm.KindOf(fn.sym.Name) }
+
+// BuildConstraints returns the build constraints of the file
+// containing the entry of the func.
+func (fn *Function) BuildConstraints() []string {
+	file, _, _ := fn.obj.disasm.PCLN().PCToLine(fn.sym.Addr)
+	if file == "" {
+		return nil
+	}
+	return fn.obj.constraints.Get(file)
+}
+
+// IsPluginExport returns whether the func is accessible via `plugin.Lookup`.
+func (fn *Function) IsPluginExport() bool { return fn.export }
+
 func (file *File) Close() error {
 	return file.objfile.Close()
 }
 
-func Load(path string) (*File, error) {
-	f, err := objfile.Open(path)
+// Load loads the executable at path. The format is detected from its
+// magic number, see DetectFormat, and WebAssembly modules are loaded
+// by wasmobj.
+//
+// For architectures that the Go disassembler does not support,
+// it falls back to parsing the output of the system objdump.
+func Load(path string) (disasm.File, error) {
+	return LoadContext(context.Background(), path)
+}
+
+// LoadContext is like Load, but records the loading as a trace span.
+func LoadContext(ctx context.Context, path string) (_ disasm.File, err error) {
+	ctx, span := tracer.Start(ctx, "goobj.Load", trace.WithAttributes(attribute.String("file.path", path)))
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+	// the object file parsers trust offsets and sizes read from the
+	// binary, so a malformed input may panic instead of returning an error
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("malformed binary %q: %v", path, p)
+		}
+	}()
+
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("file.format", format.String()))
+	if format == FormatWasm {
+		module, err := wasmobj.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		return module, nil
+	}
+
+	file, err := load(ctx, path, format)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported architecture") {
+			if fallback, fallbackErr := objdumploader.Load(path); fallbackErr == nil {
+				span.SetAttributes(attribute.Bool("objdump", true))
+				return fallback, nil
+			}
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+func load(ctx context.Context, path string, format FileFormat) (*File, error) {
+	f, slices, err := openObjfile(path, format)
 	if err != nil {
 		return nil, err
 	}
+	// close f on errors and on panics, which are recovered by LoadContext
+	loaded := false
+	defer func() {
+		if !loaded {
+			_ = f.Close()
+		}
+	}()
 
 	dis, err := godisasm.DisasmForFile(f)
 	if err != nil {
-		_ = f.Close()
 		return nil, err
 	}
 
 	file := &File{
+		path:    path,
 		objfile: f,
+		slices:  slices,
 		disasm:  dis,
-		cache:   make(map[*Function]*disasm.Code),
+		loading: make(map[codeKey]*codeLoad),
+		test:    isTestBinary(dis.Syms()),
+		buildID: readBuildID(path),
+
+		dwarfPath: dSYMPath(path),
+	}
+	file.plugin = detectPlugin(file)
+	if CodeCacheSize > 0 {
+		// New only fails for non-positive sizes
+		file.cache, _ = lru.New[codeKey, *disasm.Code](CodeCacheSize)
 	}
+	if Addr2LineCommand != "" {
+		file.addr2line = NewAddr2LineResolver(Addr2LineCommand, path)
+	}
+
+	_, span := tracer.Start(ctx, "index.build")
+	defer span.End()
 
 	for _, sym := range dis.Syms() {
 		if sym.Code != 'T' && sym.Code != 't' || sym.Addr < dis.TextStart() {
@@ -66,6 +312,7 @@ func Load(path string) (*File, error) {
 			obj:      file,
 			sym:      sym,
 			sortName: sortingName(sym.Name),
+			export:   file.plugin.isExport(sym.Name),
 		}
 		file.funcs = append(file.funcs, sym)
 	}
@@ -73,26 +320,105 @@ func Load(path string) (*File, error) {
 	sort.SliceStable(file.funcs, func(i, k int) bool {
 		return sortingName(file.funcs[i].Name()) < sortingName(file.funcs[k].Name())
 	})
+	span.SetAttributes(attribute.Int("funcs", len(file.funcs)))
 
+	loaded = true
 	return file, nil
 }
 
+// openObjfile opens the object file at path of the format, see DetectFormat.
+// For universal Mach-O binaries it also returns the GOARCH of each slice.
+func openObjfile(path string, format FileFormat) (*objfile.File, []string, error) {
+	switch format {
+	case FormatMachO:
+		f, slices, err := openFatMacho(path)
+		if !errors.Is(err, macho.ErrNotFat) {
+			return f, slices, err
+		}
+	case FormatPE:
+		f, err := openPE(path)
+		return f, nil, err
+	}
+	f, err := objfile.Open(path)
+	return f, nil, err
+}
+
 func (fn *Function) Load(opts disasm.Options) *disasm.Code {
 	return fn.obj.LoadCode(fn, opts)
 }
 
+// LoadCode disassembles fn once for each options, the returned code
+// is shared and must not be modified, see disasm.Code.
+//
+// The code of the last CodeCacheSize funcs is kept, failed disassemblies
+// are retried by the next call.
 func (file *File) LoadCode(fn *Function, opts disasm.Options) *disasm.Code {
-	code, ok := file.cache[fn]
-	if !ok {
-		var err error
-		code, err = Disassemble(fn.obj.disasm, fn, opts)
-		file.cache[fn] = code
-		if err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, err)
+	key := codeKey{fn: fn, opts: opts}
+
+	file.mu.Lock()
+	if file.cache != nil {
+		if code, ok := file.cache.Get(key); ok {
+			file.mu.Unlock()
+			return code
+		}
+	}
+	if load, ok := file.loading[key]; ok {
+		file.mu.Unlock()
+		<-load.done
+		return load.code
+	}
+	load := &codeLoad{done: make(chan struct{})}
+	file.loading[key] = load
+	file.mu.Unlock()
+
+	var err error
+	defer func() {
+		file.mu.Lock()
+		delete(file.loading, key)
+		if err == nil && load.code != nil && file.cache != nil {
+			file.cache.Add(key, load.code)
 		}
+		file.mu.Unlock()
+		close(load.done)
+	}()
+
+	load.code, err = Disassemble(fn.obj.disasm, fn, opts)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
 	}
+	return load.code
+}
 
-	return code
+// ClearCache forgets the loaded code, so that the next LoadCode
+// disassembles again, e.g. when benchmarking.
+func (file *File) ClearCache() {
+	if file.cache != nil {
+		file.cache.Purge()
+	}
+}
+
+// IsTestBinary checks whether the executable at path was built with `go test -c`.
+func IsTestBinary(path string) bool {
+	f, err := objfile.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+
+	syms, err := f.Symbols()
+	if err != nil {
+		return false
+	}
+	return isTestBinary(syms)
+}
+
+func isTestBinary(syms []objfile.Sym) bool {
+	for _, sym := range syms {
+		if sym.Name == "testing.tRunner" || strings.HasSuffix(sym.Name, ".TestMain") {
+			return true
+		}
+	}
+	return false
 }
 
 var rxCodeDelimiter = regexp.MustCompile(`[ *().]+`)
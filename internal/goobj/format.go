@@ -0,0 +1,81 @@
+package goobj
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// FileFormat is the container format of a binary.
+type FileFormat int
+
+const (
+	// FormatUnknown is any other file, e.g. a Go object file or archive,
+	// which is left to the object file parsers.
+	FormatUnknown FileFormat = iota
+	FormatELF
+	FormatMachO
+	FormatPE
+	FormatWasm
+)
+
+// String returns the name of the format, e.g. "Mach-O".
+func (format FileFormat) String() string {
+	switch format {
+	case FormatELF:
+		return "ELF"
+	case FormatMachO:
+		return "Mach-O"
+	case FormatPE:
+		return "PE"
+	case FormatWasm:
+		return "Wasm"
+	default:
+		return "unknown"
+	}
+}
+
+// magics maps the first bytes of the files to their format.
+var magics = []struct {
+	magic  []byte
+	format FileFormat
+}{
+	{[]byte{0x7f, 'E', 'L', 'F'}, FormatELF},
+	// 32 and 64 bit Mach-O in both byte orders
+	{[]byte{0xfe, 0xed, 0xfa, 0xce}, FormatMachO},
+	{[]byte{0xfe, 0xed, 0xfa, 0xcf}, FormatMachO},
+	{[]byte{0xce, 0xfa, 0xed, 0xfe}, FormatMachO},
+	{[]byte{0xcf, 0xfa, 0xed, 0xfe}, FormatMachO},
+	// universal binaries
+	{[]byte{0xca, 0xfe, 0xba, 0xbe}, FormatMachO},
+	{[]byte{'M', 'Z'}, FormatPE},
+	{[]byte{0x00, 'a', 's', 'm'}, FormatWasm},
+}
+
+// DetectFormat returns the format of the file at path from its magic
+// number, regardless of its extension.
+func DetectFormat(path string) (FileFormat, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FormatUnknown, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return FormatUnknown, err
+	}
+	return detectFormat(header[:n]), nil
+}
+
+// detectFormat returns the format of the file starting with header.
+func detectFormat(header []byte) FileFormat {
+	for _, m := range magics {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.format
+		}
+	}
+	return FormatUnknown
+}
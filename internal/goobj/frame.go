@@ -0,0 +1,220 @@
+package goobj
+
+import (
+	"encoding/binary"
+)
+
+// Magic numbers of the pclntab formats with an offset based functab.
+const (
+	pclntab118 = 0xfffffff0
+	pclntab120 = 0xfffffff1
+)
+
+// pcdataStackMapIndex is the index of the pcdata table,
+// which selects the stack map of the call sites.
+const pcdataStackMapIndex = 1
+
+// funcInfo contains the pclntab details of a func.
+type funcInfo struct {
+	// frameSize is the largest SP delta of the func.
+	frameSize int
+	// stackMaps is the pc-value table of the stack map indexes.
+	stackMaps []byte
+}
+
+// FrameSize returns the stack frame size of the func in bytes,
+// or 0 when it can't be determined.
+func (fn *Function) FrameSize() int {
+	fn.obj.framesOnce.Do(fn.obj.loadFuncInfos)
+	return fn.obj.frames[fn.sym.Addr].frameSize
+}
+
+// StackMapPCs returns the PCs where the stack map of a call site takes effect,
+// which is usually at the call instruction. It returns nil when the pclntab
+// has no stack maps for the func.
+func (fn *Function) StackMapPCs() []uint64 {
+	fn.obj.framesOnce.Do(fn.obj.loadFuncInfos)
+	info, ok := fn.obj.frames[fn.sym.Addr]
+	if !ok || info.stackMaps == nil {
+		return nil
+	}
+
+	pcs := []uint64{}
+	pc := fn.sym.Addr
+	walkPCValue(info.stackMaps, fn.obj.pcQuantum, func(value int64, size uint64) {
+		if value >= 0 {
+			pcs = append(pcs, pc)
+		}
+		pc += size
+	})
+	return pcs
+}
+
+// loadFuncInfos reads the details of all funcs from the pclntab.
+func (file *File) loadFuncInfos() {
+	textStart, pclntab, err := file.pclnTab()
+	if err != nil {
+		return
+	}
+	file.frames, file.pcQuantum = parseFuncInfos(textStart, pclntab)
+}
+
+// pclnTab returns the pclntab and the address its entries are relative to.
+func (file *File) pclnTab() (textStart uint64, pclntab []byte, err error) {
+	textStart, pclntab, err = file.objfile.PCLNTab()
+	if err != nil {
+		return 0, nil, err
+	}
+	// Entries are relative to runtime.text, which may follow non-Go code.
+	if sym, ok := file.FindSymbol("runtime.text"); ok {
+		textStart = sym.Addr
+	}
+	return textStart, pclntab, nil
+}
+
+// pclnHeader describes a Go 1.18 or newer pclntab.
+type pclnHeader struct {
+	pclntab []byte
+	order   binary.ByteOrder
+	magic   uint32
+	quantum uint64
+
+	nfunc     int
+	textStart uint64
+	// offsets of the tables in pclntab
+	cutab, filetab, pctab, functab uint64
+}
+
+// readPCLNHeader parses the header of pclntab. The quantum
+// is set even when the rest of the header isn't supported.
+func readPCLNHeader(textStart uint64, pclntab []byte) (hdr pclnHeader, ok bool) {
+	hdr = pclnHeader{pclntab: pclntab, quantum: 1}
+	if len(pclntab) < 8 {
+		return hdr, false
+	}
+
+	hdr.order = binary.LittleEndian
+	hdr.magic = hdr.order.Uint32(pclntab)
+	if hdr.magic != pclntab118 && hdr.magic != pclntab120 {
+		hdr.order = binary.BigEndian
+		hdr.magic = hdr.order.Uint32(pclntab)
+		if hdr.magic != pclntab118 && hdr.magic != pclntab120 {
+			return hdr, false
+		}
+	}
+	hdr.quantum = max(uint64(pclntab[6]), 1)
+	ptrSize := int(pclntab[7])
+	if ptrSize != 4 && ptrSize != 8 || len(pclntab) < 8+8*ptrSize {
+		return hdr, false
+	}
+	word := func(i int) uint64 {
+		b := pclntab[8+i*ptrSize:]
+		if ptrSize == 4 {
+			return uint64(hdr.order.Uint32(b))
+		}
+		return hdr.order.Uint64(b)
+	}
+
+	hdr.nfunc = int(word(0))
+	hdr.textStart = textStart
+	if start := word(2); start != 0 {
+		hdr.textStart = start
+	}
+	hdr.cutab = word(4)
+	hdr.filetab = word(5)
+	hdr.pctab = word(6)
+	hdr.functab = word(7)
+	if hdr.functab+uint64(hdr.nfunc)*8 > uint64(len(pclntab)) {
+		return hdr, false
+	}
+	return hdr, true
+}
+
+// pcdataOffset returns the offset of the pcdata offsets in _func,
+// Go 1.20 added startLine.
+func (hdr *pclnHeader) pcdataOffset() uint64 {
+	if hdr.magic == pclntab120 {
+		return 44
+	}
+	return 40
+}
+
+// funcs calls fn with the entry address and the offset of the _func of each func,
+// skipping the funcs whose _func is truncated.
+func (hdr *pclnHeader) funcs(fn func(entry, funcoff uint64)) {
+	functab := hdr.pclntab[hdr.functab:]
+	for i := range hdr.nfunc {
+		entry := hdr.textStart + uint64(hdr.order.Uint32(functab[i*8:]))
+		funcoff := hdr.functab + uint64(hdr.order.Uint32(functab[i*8+4:]))
+		if funcoff+hdr.pcdataOffset() > uint64(len(hdr.pclntab)) {
+			continue
+		}
+		fn(entry, funcoff)
+	}
+}
+
+// field returns the 32-bit field of the _func at funcoff.
+func (hdr *pclnHeader) field(funcoff, offset uint64) uint32 {
+	return hdr.order.Uint32(hdr.pclntab[funcoff+offset:])
+}
+
+// table returns the pc-value table at off in pctab, nil when there's none.
+func (hdr *pclnHeader) table(off uint64) []byte {
+	if off == 0 || hdr.pctab+off >= uint64(len(hdr.pclntab)) {
+		return nil
+	}
+	return hdr.pclntab[hdr.pctab+off:]
+}
+
+// parseFuncInfos returns the details of each func by entry address and
+// the pc quantum of the tables. Only Go 1.18 and newer tables are supported.
+func parseFuncInfos(textStart uint64, pclntab []byte) (map[uint64]funcInfo, uint64) {
+	hdr, ok := readPCLNHeader(textStart, pclntab)
+	if !ok {
+		return nil, hdr.quantum
+	}
+
+	infos := make(map[uint64]funcInfo, hdr.nfunc)
+	hdr.funcs(func(entry, funcoff uint64) {
+		var info funcInfo
+		// _func.pcsp follows entryOff, nameOff, args and deferreturn
+		if pcsp := hdr.table(uint64(hdr.field(funcoff, 16))); pcsp != nil {
+			walkPCValue(pcsp, hdr.quantum, func(value int64, _ uint64) {
+				info.frameSize = max(info.frameSize, int(value))
+			})
+		}
+		npcdata := uint64(hdr.field(funcoff, 28))
+		if off := funcoff + hdr.pcdataOffset() + 4*pcdataStackMapIndex; npcdata > pcdataStackMapIndex && off+4 <= uint64(len(pclntab)) {
+			info.stackMaps = hdr.table(uint64(hdr.order.Uint32(pclntab[off:])))
+		}
+		if info.frameSize > 0 || info.stackMaps != nil {
+			infos[entry] = info
+		}
+	})
+	return infos, hdr.quantum
+}
+
+// walkPCValue calls fn with each value of an encoded pc-value table
+// and the size of the pc range it covers.
+func walkPCValue(table []byte, quantum uint64, fn func(value int64, size uint64)) {
+	value := int64(-1)
+	for first := true; ; first = false {
+		uvdelta, n := binary.Uvarint(table)
+		if n <= 0 || uvdelta == 0 && !first {
+			break
+		}
+		table = table[n:]
+		if uvdelta&1 != 0 {
+			value += int64(^(uvdelta >> 1))
+		} else {
+			value += int64(uvdelta >> 1)
+		}
+
+		pcdelta, n := binary.Uvarint(table)
+		if n <= 0 {
+			break
+		}
+		table = table[n:]
+		fn(value, pcdelta*quantum)
+	}
+}
@@ -0,0 +1,105 @@
+package goobj
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// FuzzLoad checks that malformed binaries fail to load with an error
+// instead of a panic. Run it with `go test -fuzz=FuzzLoad ./internal/goobj`.
+func FuzzLoad(f *testing.F) {
+	f.Add(minimalELF())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(t.TempDir(), "binary")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		defer func() {
+			if p := recover(); p != nil {
+				t.Fatalf("panic: %v", p)
+			}
+		}()
+		file, err := Load(path)
+		if err != nil {
+			if file != nil {
+				t.Fatalf("Load returned a file with the error %v", err)
+			}
+			return
+		}
+		defer func() { _ = file.Close() }()
+		for _, fn := range file.Funcs() {
+			_ = fn.Load(disasm.Options{})
+		}
+	})
+}
+
+// minimalELF returns an amd64 executable with a single func main.main,
+// which consists of a RET instruction.
+func minimalELF() []byte {
+	const (
+		textAddr   = 0x401000
+		headerSize = 64
+		sectSize   = 64
+		symSize    = 24
+	)
+	text := []byte{0xc3}
+	shstrtab := []byte("\x00.text\x00.symtab\x00.strtab\x00.shstrtab\x00")
+	strtab := []byte("\x00main.main\x00")
+	var symtab bytes.Buffer
+	must(binary.Write(&symtab, binary.LittleEndian, elf.Sym64{}))
+	must(binary.Write(&symtab, binary.LittleEndian, elf.Sym64{
+		Name:  1,
+		Info:  elf.ST_INFO(elf.STB_GLOBAL, elf.STT_FUNC),
+		Shndx: 1,
+		Value: textAddr,
+		Size:  uint64(len(text)),
+	}))
+
+	// the sections follow the header, the section headers follow the sections
+	var data bytes.Buffer
+	offsets := make([]uint64, 4)
+	for i, section := range [][]byte{text, symtab.Bytes(), strtab, shstrtab} {
+		offsets[i] = uint64(headerSize + data.Len())
+		data.Write(section)
+	}
+
+	var out bytes.Buffer
+	must(binary.Write(&out, binary.LittleEndian, elf.Header64{
+		Ident:     [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(elf.ELFCLASS64), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)},
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Entry:     textAddr,
+		Shoff:     uint64(headerSize + data.Len()),
+		Ehsize:    headerSize,
+		Shentsize: sectSize,
+		Shnum:     5,
+		Shstrndx:  4,
+	}))
+	out.Write(data.Bytes())
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(elf.SHT_PROGBITS), Flags: uint64(elf.SHF_ALLOC | elf.SHF_EXECINSTR), Addr: textAddr, Off: offsets[0], Size: uint64(len(text)), Addralign: 16},
+		{Name: 7, Type: uint32(elf.SHT_SYMTAB), Off: offsets[1], Size: uint64(symtab.Len()), Link: 3, Info: 1, Addralign: 8, Entsize: symSize},
+		{Name: 15, Type: uint32(elf.SHT_STRTAB), Off: offsets[2], Size: uint64(len(strtab)), Addralign: 1},
+		{Name: 23, Type: uint32(elf.SHT_STRTAB), Off: offsets[3], Size: uint64(len(shstrtab)), Addralign: 1},
+	}
+	for _, section := range sections {
+		must(binary.Write(&out, binary.LittleEndian, section))
+	}
+	return out.Bytes()
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,216 @@
+package goobj
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// ItabInfo describes the method table of a concrete type
+// used as an interface type.
+type ItabInfo struct {
+	// InterfaceType is the name of the interface, e.g. "io.Writer".
+	InterfaceType string
+	// ConcreteType is the name of the implementing type, e.g. "*os.File".
+	ConcreteType string
+	// Methods are the names of the funcs implementing the interface methods,
+	// e.g. "os.(*File).Write", in the order of the interface methods.
+	// Addresses that don't belong to a func are formatted as hex.
+	Methods []string
+}
+
+// itabPrefixes are the symbol name prefixes of itabs,
+// "go.itab." was renamed to "go:itab." in Go 1.20.
+var itabPrefixes = []string{"go:itab.", "go.itab."}
+
+// ParseItabs lists the itabs found in the symbol table of file.
+//
+// Newer linkers don't write itab symbols to the symbol table,
+// for such binaries the result is empty.
+func ParseItabs(file disasm.File) ([]ItabInfo, error) {
+	obj, ok := file.(*File)
+	if !ok {
+		return nil, errors.New("itabs are only available for Go executables")
+	}
+
+	var syms []disasm.Symbol
+	for _, sym := range obj.SymbolTable() {
+		if _, _, ok := parseItabName(sym.Name); ok && sym.Size > 0 {
+			syms = append(syms, sym)
+		}
+	}
+	if len(syms) == 0 {
+		return []ItabInfo{}, nil
+	}
+
+	data, err := openData(obj.path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = data.Close() }()
+
+	funcs := make(map[uint64]string, len(obj.funcs))
+	for _, fn := range obj.funcs {
+		funcs[fn.(*Function).sym.Addr] = fn.Name()
+	}
+
+	// itab is {inter, _type *T; hash uint32; _ [4]byte; fun [...]uintptr}
+	funOffset := 2*data.ptrSize + 8
+	itabs := make([]ItabInfo, 0, len(syms))
+	for _, sym := range syms {
+		concrete, iface, _ := parseItabName(sym.Name)
+		itab := ItabInfo{InterfaceType: iface, ConcreteType: concrete, Methods: []string{}}
+
+		if sym.Size > int64(funOffset) {
+			raw, err := data.read(sym.Address, uint64(sym.Size))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", sym.Name, err)
+			}
+			for off := funOffset; off+data.ptrSize <= len(raw); off += data.ptrSize {
+				pc := data.ptr(raw[off:])
+				if name, ok := funcs[pc]; ok {
+					itab.Methods = append(itab.Methods, name)
+				} else {
+					itab.Methods = append(itab.Methods, fmt.Sprintf("0x%x", pc))
+				}
+			}
+		}
+		itabs = append(itabs, itab)
+	}
+
+	sort.Slice(itabs, func(i, k int) bool {
+		if itabs[i].InterfaceType != itabs[k].InterfaceType {
+			return itabs[i].InterfaceType < itabs[k].InterfaceType
+		}
+		return itabs[i].ConcreteType < itabs[k].ConcreteType
+	})
+	return itabs, nil
+}
+
+// parseItabName splits "go:itab.*os.File,io.Writer" into
+// the concrete type "*os.File" and the interface "io.Writer".
+func parseItabName(name string) (concrete, iface string, ok bool) {
+	for _, prefix := range itabPrefixes {
+		rest, found := strings.CutPrefix(name, prefix)
+		if !found {
+			continue
+		}
+		// type arguments may contain commas, e.g. "main.Pair[int,string]"
+		depth := 0
+		for i, r := range rest {
+			switch r {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			case ',':
+				if depth == 0 {
+					return rest[:i], rest[i+1:], true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
+
+// dataFile reads initialized data by virtual address.
+type dataFile struct {
+	closer    interface{ Close() error }
+	sections  []dataSection
+	ptrSize   int
+	byteOrder binary.ByteOrder
+}
+
+// dataSection is a loaded section of the executable.
+type dataSection struct {
+	addr uint64
+	size uint64
+	data func() ([]byte, error)
+	// loaded is the result of data, read on first use
+	loaded []byte
+}
+
+// openData opens the ELF, Mach-O or PE file at path for reading data.
+func openData(path string) (*dataFile, error) {
+	if f, err := elf.Open(path); err == nil {
+		data := &dataFile{closer: f, ptrSize: 8, byteOrder: f.ByteOrder}
+		if f.Class == elf.ELFCLASS32 {
+			data.ptrSize = 4
+		}
+		for _, s := range f.Sections {
+			if s.Type == elf.SHT_PROGBITS && s.Flags&elf.SHF_ALLOC != 0 {
+				data.sections = append(data.sections, dataSection{addr: s.Addr, size: s.Size, data: s.Data})
+			}
+		}
+		return data, nil
+	}
+	if f, closer, err := openMacho(path); err == nil {
+		data := &dataFile{closer: closer, ptrSize: 8, byteOrder: f.ByteOrder}
+		if f.Magic == macho.Magic32 {
+			data.ptrSize = 4
+		}
+		for _, s := range f.Sections {
+			data.sections = append(data.sections, dataSection{addr: s.Addr, size: s.Size, data: s.Data})
+		}
+		return data, nil
+	}
+	if f, err := pe.Open(path); err == nil {
+		data := &dataFile{closer: f, ptrSize: 8, byteOrder: binary.LittleEndian}
+		var base uint64
+		switch header := f.OptionalHeader.(type) {
+		case *pe.OptionalHeader32:
+			base, data.ptrSize = uint64(header.ImageBase), 4
+		case *pe.OptionalHeader64:
+			base = header.ImageBase
+		}
+		for _, s := range f.Sections {
+			data.sections = append(data.sections, dataSection{addr: base + uint64(s.VirtualAddress), size: uint64(s.VirtualSize), data: s.Data})
+		}
+		return data, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unsupported file format: %s", path)
+}
+
+// read returns size bytes of data starting at addr.
+func (f *dataFile) read(addr, size uint64) ([]byte, error) {
+	for i := range f.sections {
+		s := &f.sections[i]
+		if addr < s.addr || addr+size > s.addr+s.size {
+			continue
+		}
+		if s.loaded == nil {
+			data, err := s.data()
+			if err != nil {
+				return nil, err
+			}
+			s.loaded = data
+		}
+		off := addr - s.addr
+		if off+size > uint64(len(s.loaded)) {
+			return nil, fmt.Errorf("address 0x%x is not initialized", addr)
+		}
+		return s.loaded[off : off+size], nil
+	}
+	return nil, fmt.Errorf("address 0x%x is not in a section", addr)
+}
+
+// ptr decodes a pointer from the start of b.
+func (f *dataFile) ptr(b []byte) uint64 {
+	if f.ptrSize == 4 {
+		return uint64(f.byteOrder.Uint32(b))
+	}
+	return f.byteOrder.Uint64(b)
+}
+
+func (f *dataFile) Close() error { return f.closer.Close() }
@@ -0,0 +1,155 @@
+package goobj
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// FindFuncByLine returns the funcs with instructions compiled from line of
+// the source file path, see disasm.SourceFileMatches. There can be several,
+// when the line has been inlined into other funcs.
+func (file *File) FindFuncByLine(path string, line int) []disasm.Func {
+	textStart, pclntab, err := file.pclnTab()
+	if err != nil {
+		return nil
+	}
+	hdr, ok := readPCLNHeader(textStart, pclntab)
+	if !ok {
+		return nil
+	}
+
+	byEntry := file.funcsByEntry()
+	fileMatches := hdr.fileMatcher(pclntab, func(name string) bool {
+		return disasm.SourceFileMatches(name, path)
+	})
+
+	var found []disasm.Func
+	hdr.funcs(func(entry, funcoff uint64) {
+		fn, ok := byEntry[entry]
+		if !ok {
+			return
+		}
+		// _func.pcfile and pcln follow pcsp, cuOffset follows npcdata
+		pcfile := hdr.table(uint64(hdr.field(funcoff, 20)))
+		pcln := hdr.table(uint64(hdr.field(funcoff, 24)))
+		cuOffset := hdr.field(funcoff, 32)
+		if pcfile == nil || pcln == nil {
+			return
+		}
+
+		var lines []uint64
+		pc := uint64(0)
+		walkPCValue(pcln, hdr.quantum, func(value int64, size uint64) {
+			if value == int64(line) {
+				lines = append(lines, pc)
+			}
+			pc += size
+		})
+		if len(lines) == 0 {
+			return
+		}
+
+		pc = 0
+		matched := false
+		walkPCValue(pcfile, hdr.quantum, func(value int64, size uint64) {
+			for _, linePC := range lines {
+				if !matched && pc <= linePC && linePC < pc+size && fileMatches(cuOffset, value) {
+					matched = true
+				}
+			}
+			pc += size
+		})
+		if matched {
+			found = append(found, fn)
+		}
+	})
+	return found
+}
+
+// FuncsBySourceFile returns the funcs with an instruction compiled from
+// a source file matching pattern, see disasm.SourcePatternMatches.
+// It implements disasm.SourceFileFinder.
+func (file *File) FuncsBySourceFile(pattern string) ([]disasm.Func, error) {
+	if err := disasm.CheckSourcePattern(pattern); err != nil {
+		return nil, err
+	}
+	textStart, pclntab, err := file.pclnTab()
+	if err != nil {
+		return nil, err
+	}
+	hdr, ok := readPCLNHeader(textStart, pclntab)
+	if !ok {
+		return nil, errors.New("unsupported pclntab")
+	}
+
+	byEntry := file.funcsByEntry()
+	fileMatches := hdr.fileMatcher(pclntab, func(name string) bool {
+		return disasm.SourcePatternMatches(name, pattern)
+	})
+
+	matched := map[disasm.Func]bool{}
+	hdr.funcs(func(entry, funcoff uint64) {
+		fn, ok := byEntry[entry]
+		if !ok {
+			return
+		}
+		pcfile := hdr.table(uint64(hdr.field(funcoff, 20)))
+		cuOffset := hdr.field(funcoff, 32)
+		if pcfile == nil {
+			return
+		}
+		walkPCValue(pcfile, hdr.quantum, func(value int64, size uint64) {
+			if !matched[fn] && fileMatches(cuOffset, value) {
+				matched[fn] = true
+			}
+		})
+	})
+
+	// keep the order of Funcs
+	var found []disasm.Func
+	for _, fn := range file.funcs {
+		if matched[fn] {
+			found = append(found, fn)
+		}
+	}
+	return found, nil
+}
+
+// funcsByEntry maps the entry PCs to the funcs.
+func (file *File) funcsByEntry() map[uint64]disasm.Func {
+	byEntry := make(map[uint64]disasm.Func, len(file.funcs))
+	for _, fn := range file.funcs {
+		byEntry[fn.(*Function).sym.Addr] = fn
+	}
+	return byEntry
+}
+
+// fileMatcher returns a func reporting whether the file fileno of the
+// compilation unit at cuOffset matches, which caches match by file name.
+func (hdr *pclnHeader) fileMatcher(pclntab []byte, match func(name string) bool) func(cuOffset uint32, fileno int64) bool {
+	// matches caches whether the file names, by offset in filetab, match
+	matches := map[uint32]bool{}
+	return func(cuOffset uint32, fileno int64) bool {
+		index := hdr.cutab + (uint64(cuOffset)+uint64(fileno))*4
+		if fileno < 0 || index+4 > uint64(len(pclntab)) {
+			return false
+		}
+		off := hdr.order.Uint32(pclntab[index:])
+		if off == math.MaxUint32 {
+			return false
+		}
+		matched, ok := matches[off]
+		if !ok {
+			name := pclntab[min(hdr.filetab+uint64(off), uint64(len(pclntab))):]
+			if end := bytes.IndexByte(name, 0); end >= 0 {
+				name = name[:end]
+			}
+			matched = match(string(name))
+			matches[off] = matched
+		}
+		return matched
+	}
+}
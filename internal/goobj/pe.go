@@ -0,0 +1,94 @@
+package goobj
+
+import (
+	"debug/pe"
+	"fmt"
+	"os"
+
+	"github.com/gameformush/goasm-vscode/internal/go/src/objfile"
+)
+
+// openPE opens the PE file at path.
+//
+// The PE parser of objfile requires runtime.symtab, which Go 1.23 and
+// later don't write anymore, so the tables are loaded by peTables.
+func openPE(path string) (*objfile.File, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := pe.NewFile(r)
+	if err != nil {
+		_ = r.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return objfile.OpenPE(r, f, func() (uint64, []byte, []byte, error) {
+		return peTables(f)
+	}), nil
+}
+
+// peTables returns the start of the text section, the symtab and the
+// pclntab of f. The symtab is optional as for ELF and Mach-O.
+func peTables(f *pe.File) (textStart uint64, symtab, pclntab []byte, err error) {
+	var imageBase uint64
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		imageBase = uint64(header.ImageBase)
+	case *pe.OptionalHeader64:
+		imageBase = header.ImageBase
+	default:
+		return 0, nil, nil, fmt.Errorf("pe file format not recognized")
+	}
+
+	if sect := f.Section(".text"); sect != nil {
+		textStart = imageBase + uint64(sect.VirtualAddress)
+	}
+	if pclntab, err = peTable(f, "runtime.pclntab", "runtime.epclntab"); err != nil {
+		// the names used by Go 1.3 and earlier
+		var err2 error
+		if pclntab, err2 = peTable(f, "pclntab", "epclntab"); err2 != nil {
+			return 0, nil, nil, err
+		}
+	}
+	if symtab, err = peTable(f, "runtime.symtab", "runtime.esymtab"); err != nil {
+		symtab, _ = peTable(f, "symtab", "esymtab")
+	}
+	return textStart, symtab, pclntab, nil
+}
+
+// peTable returns the data between the symbols start and end.
+func peTable(f *pe.File, start, end string) ([]byte, error) {
+	ssym, err := peSymbol(f, start)
+	if err != nil {
+		return nil, err
+	}
+	esym, err := peSymbol(f, end)
+	if err != nil {
+		return nil, err
+	}
+	if ssym.SectionNumber != esym.SectionNumber {
+		return nil, fmt.Errorf("%s and %s symbols must be in the same section", start, end)
+	}
+	data, err := f.Sections[ssym.SectionNumber-1].Data()
+	if err != nil {
+		return nil, err
+	}
+	if ssym.Value > esym.Value || uint64(esym.Value) > uint64(len(data)) {
+		return nil, fmt.Errorf("%s and %s symbols are outside of their section", start, end)
+	}
+	return data[ssym.Value:esym.Value], nil
+}
+
+// peSymbol returns the symbol with the name, which must be in a section.
+func peSymbol(f *pe.File, name string) (*pe.Symbol, error) {
+	for _, s := range f.Symbols {
+		if s.Name != name {
+			continue
+		}
+		if s.SectionNumber <= 0 || int(s.SectionNumber) > len(f.Sections) {
+			return nil, fmt.Errorf("symbol %s: invalid section number %d", name, s.SectionNumber)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("no %s symbol found", name)
+}
@@ -0,0 +1,52 @@
+package goobj
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pluginInfo describes a shared object built with `-buildmode=plugin`.
+type pluginInfo struct {
+	// path is the package path of the plugin main package,
+	// e.g. "plugin/unnamed-4a3f..." or "example.com/plug".
+	path string
+}
+
+// detectPlugin checks whether file is a Go plugin.
+// It returns nil for executables and shared libraries that aren't plugins.
+func detectPlugin(file *File) *pluginInfo {
+	if _, ok := file.FindSymbol("go:plugin.tabs"); !ok {
+		return nil
+	}
+	plugin := &pluginInfo{}
+
+	// The linker stores the plugin path as raw bytes in a separate symbol.
+	sym, ok := file.FindSymbol("go:link.thispluginpath")
+	if !ok || sym.Size <= 0 {
+		return plugin
+	}
+	data, err := openData(file.path)
+	if err != nil {
+		return plugin
+	}
+	defer func() { _ = data.Close() }()
+	if raw, err := data.read(sym.Addr, uint64(sym.Size)); err == nil {
+		plugin.path = string(raw)
+	}
+
+	return plugin
+}
+
+// isExport checks whether the symbol can be found with `plugin.Lookup`.
+func (plugin *pluginInfo) isExport(sym string) bool {
+	if plugin == nil || plugin.path == "" {
+		return false
+	}
+	name, ok := strings.CutPrefix(sym, plugin.path+".")
+	if !ok || strings.ContainsAny(name, ".()*") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,67 @@
+package goobj
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+func TestLoadPlugin(t *testing.T) {
+	switch runtime.GOOS {
+	case "linux", "darwin", "freebsd":
+	default:
+		t.Skipf("plugins aren't supported on %s", runtime.GOOS)
+	}
+	// the plugin build mode links with the C toolchain
+	requireCgo(t)
+
+	so := buildProgram(t, map[string]string{
+		"plugin.go": `package main
+
+var Greeting = "hello"
+
+func Greet() string { return Greeting + suffix() }
+
+//go:noinline
+func suffix() string { return "!" }
+`,
+	}, "-buildmode=plugin")
+
+	loaded, err := Load(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = loaded.Close() }()
+	file := loaded.(*File)
+	if !file.IsPlugin() {
+		t.Fatal("IsPlugin = false for a -buildmode=plugin binary")
+	}
+
+	exports := map[string]bool{}
+	for _, fn := range file.Funcs() {
+		exports[fn.Name()] = fn.(*Function).IsPluginExport()
+		if fn.Name() != "example.com/test.Greet" {
+			continue
+		}
+		code := fn.Load(disasm.Options{})
+		if code == nil || len(code.Insts) == 0 {
+			t.Fatalf("failed to load %s", fn.Name())
+		}
+		if filepath.Base(code.Insts[0].File) != "plugin.go" {
+			t.Errorf("%s is compiled from %s, want plugin.go", fn.Name(), code.Insts[0].File)
+		}
+	}
+	for name, want := range map[string]bool{
+		"example.com/test.Greet":  true,
+		"example.com/test.suffix": false,
+	} {
+		export, ok := exports[name]
+		if !ok {
+			t.Errorf("%s not found", name)
+		} else if export != want {
+			t.Errorf("%s IsPluginExport = %v, want %v", name, export, want)
+		}
+	}
+}
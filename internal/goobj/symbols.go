@@ -0,0 +1,21 @@
+package goobj
+
+import "github.com/gameformush/goasm-vscode/internal/go/src/objfile"
+
+// FindSymbol returns the symbol with the name, the first by address
+// when there are several. The index is built on the first call.
+func (file *File) FindSymbol(name string) (*objfile.Sym, bool) {
+	syms := file.disasm.Syms()
+	file.symbolsOnce.Do(func() {
+		file.symbols = make(map[string]int, len(syms))
+		for i := len(syms) - 1; i >= 0; i-- {
+			file.symbols[syms[i].Name] = i
+		}
+	})
+
+	i, ok := file.symbols[name]
+	if !ok {
+		return nil, false
+	}
+	return &syms[i], true
+}
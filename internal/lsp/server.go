@@ -0,0 +1,365 @@
+// Package lsp serves the disassembly to editors with the Language Server
+// Protocol, so that editors without a lensm extension can show it.
+//
+// Only hover and go to definition are implemented: hovering a source line
+// shows the instructions compiled from it, and go to definition opens the
+// disassembly of the func containing it. The messages are JSON-RPC 2.0 with
+// the LSP base protocol framing, see
+// https://microsoft.github.io/language-server-protocol/specifications/base/0.9/specification/.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// JSON-RPC error codes, see the LSP specification.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// maxHoverInsts limits the instructions shown when hovering a line.
+const maxHoverInsts = 40
+
+// Server answers the requests of an editor about the source of File.
+type Server struct {
+	// File is the binary compiled from the edited source.
+	File disasm.File
+	// Options are used for loading the code.
+	Options disasm.Options
+	// Logger logs failed requests, nil uses slog.Default().
+	Logger *slog.Logger
+
+	// asmDir contains the disassembly written for go to definition,
+	// created on first use.
+	asmDir     string
+	asmDirOnce sync.Once
+	asmDirErr  error
+
+	out sync.Mutex
+	w   io.Writer
+}
+
+// request is a JSON-RPC request or notification, notifications have no ID.
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response, with either a result or an error.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Position is a zero based line and UTF-16 character offset.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range of a document, End is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in the document at URI.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// textDocumentPositionParams are the params of hover and definition.
+type textDocumentPositionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position Position `json:"position"`
+}
+
+// Hover is the result of textDocument/hover.
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// MarkupContent is text in the format of Kind, "plaintext" or "markdown".
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Serve answers the requests read from r on w until the editor sends exit
+// or closes r. It returns nil after exit.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	in := bufio.NewReader(r)
+	for {
+		body, err := readMessage(in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		result, rerr := s.handle(req)
+		if req.ID == nil {
+			// notifications aren't answered
+			continue
+		}
+		if rerr != nil {
+			s.logger().Error("lsp request failed", "method", req.Method, "err", rerr.Message)
+		}
+		s.reply(req.ID, result, rerr)
+	}
+}
+
+// handle dispatches req to its method.
+func (s *Server) handle(req request) (any, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "lensm"},
+		}, nil
+	case "initialized", "shutdown", "$/cancelRequest", "$/setTrace",
+		"textDocument/didOpen", "textDocument/didChange", "textDocument/didClose", "textDocument/didSave":
+		return nil, nil
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		hover, err := s.hover(params)
+		if err != nil {
+			return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		return hover, nil
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		loc, err := s.definition(params)
+		if err != nil {
+			return nil, &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		return loc, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// hover returns the instructions compiled from the line at the position,
+// nil when there are none.
+func (s *Server) hover(params textDocumentPositionParams) (*Hover, error) {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	line := params.Position.Line + 1
+	fn, code, index := disasm.FindFuncByLine(s.File, path, line, s.Options)
+	if fn == nil {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s`\n\n```asm\n", fn.Name())
+	shown := 0
+	for _, ix := range code.Insts[index:] {
+		if ix.Text == "" || ix.Line != line || !disasm.SourceFileMatches(ix.File, path) {
+			continue
+		}
+		if shown == maxHoverInsts {
+			b.WriteString("...\n")
+			break
+		}
+		fmt.Fprintf(&b, "0x%x\t%s\n", ix.PC, ix.Text)
+		shown++
+	}
+	b.WriteString("```\n")
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: b.String()},
+		Range: &Range{
+			Start: Position{Line: params.Position.Line},
+			End:   Position{Line: params.Position.Line + 1},
+		},
+	}, nil
+}
+
+// definition writes the disassembly of the func containing the line at
+// the position to a file and returns the location of the first instruction
+// of the line in it, nil when no func contains the line.
+func (s *Server) definition(params textDocumentPositionParams) (*Location, error) {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	fn, code, index := disasm.FindFuncByLine(s.File, path, params.Position.Line+1, s.Options)
+	if fn == nil {
+		return nil, nil
+	}
+
+	s.asmDirOnce.Do(func() {
+		s.asmDir, s.asmDirErr = os.MkdirTemp("", "lensm-lsp-")
+	})
+	if s.asmDirErr != nil {
+		return nil, s.asmDirErr
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "TEXT %s(SB) %s\n", code.Name, code.File)
+	target := 0
+	lines := 1
+	for i, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		if i == index {
+			target = lines
+		}
+		fmt.Fprintf(&b, "  %s:%d\t0x%x\t%s\n", ix.File, ix.Line, ix.PC, ix.Text)
+		lines++
+	}
+
+	asmPath := filepath.Join(s.asmDir, asmFileName(fn.Name()))
+	if err := os.WriteFile(asmPath, []byte(b.String()), 0o644); err != nil {
+		return nil, err
+	}
+	return &Location{
+		URI:   pathToURI(asmPath),
+		Range: Range{Start: Position{Line: target}, End: Position{Line: target}},
+	}, nil
+}
+
+// Close removes the disassembly written for go to definition.
+func (s *Server) Close() error {
+	if s.asmDir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.asmDir)
+}
+
+// reply writes the response to the request with id.
+func (s *Server) reply(id *json.RawMessage, result any, rerr *responseError) {
+	resp := response{JSONRPC: "2.0", ID: id, Result: result, Error: rerr}
+	if rerr != nil {
+		resp.Result = nil
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		s.logger().Error("lsp response", "err", err)
+		return
+	}
+
+	s.out.Lock()
+	defer s.out.Unlock()
+	if _, err := fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		s.logger().Error("lsp response", "err", err)
+	}
+}
+
+func (s *Server) logger() *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return slog.Default()
+}
+
+// readMessage reads the body of the next message framed by its headers.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	return body, nil
+}
+
+// uriToPath returns the path of a file URI.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI %q, only file URIs are supported", uri)
+	}
+	path := u.Path
+	// file:///C:/dir/file.go has the path /C:/dir/file.go
+	if runtime.GOOS == "windows" && len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// pathToURI returns the file URI of the absolute path.
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// asmFileName returns a file name for the disassembly of the func name,
+// replacing the characters that aren't allowed in file names.
+func asmFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+	return name + ".s"
+}
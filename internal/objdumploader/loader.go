@@ -0,0 +1,203 @@
+// Package objdumploader implements disasm.File by parsing the output of
+// the system `objdump`.
+//
+// It's used as a fallback for architectures that the Go disassembler
+// does not support. `go tool objdump` shares the architecture support
+// with goobj, hence it's not useful for this purpose.
+package objdumploader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// Command is the objdump executable that is used for disassembling.
+var Command = "objdump"
+
+var _ disasm.File = (*File)(nil)
+var _ disasm.Func = (*Func)(nil)
+
+// File contains the functions parsed from objdump output.
+type File struct {
+	funcs       []disasm.Func
+	constraints disasm.ConstraintCache
+}
+
+func (file *File) Funcs() []disasm.Func { return file.funcs }
+
+// PackageFuncs groups the funcs by package path.
+func (file *File) PackageFuncs() map[string][]disasm.Func {
+	return disasm.GroupByPackage(file.funcs)
+}
+
+func (file *File) Close() error { return nil }
+
+// BuildID returns "", the objdump output doesn't include the build ID.
+func (file *File) BuildID() string { return "" }
+
+// GoModPath returns "", the objdump output doesn't include the build info.
+func (file *File) GoModPath() string { return "" }
+
+// SymbolTable lists the functions found in the objdump output.
+func (file *File) SymbolTable() []disasm.Symbol {
+	table := make([]disasm.Symbol, 0, len(file.funcs))
+	for _, fn := range file.funcs {
+		fn := fn.(*Func)
+		sym := disasm.Symbol{
+			Name:     fn.name,
+			Type:     "T",
+			Exported: disasm.IsExported(fn.name),
+		}
+		if len(fn.insts) > 0 {
+			sym.Address = fn.insts[0].PC
+			sym.Size = int64(fn.end - sym.Address)
+		}
+		table = append(table, sym)
+	}
+	return table
+}
+
+// Func contains a single disassembled function.
+type Func struct {
+	obj   *File
+	name  string
+	file  string
+	insts []disasm.Inst
+	// end is the address after the last instruction.
+	end uint64
+}
+
+func (fn *Func) Name() string { return fn.name }
+
+// BuildConstraints returns the build constraints of the source file
+// of the first instruction.
+func (fn *Func) BuildConstraints() []string {
+	if fn.file == "" {
+		return nil
+	}
+	return fn.obj.constraints.Get(fn.file)
+}
+
+// Load returns the parsed instructions.
+// Source code and jump lines are not available for objdump output.
+func (fn *Func) Load(opts disasm.Options) *disasm.Code {
+	return &disasm.Code{
+		Name:  fn.name,
+		File:  fn.file,
+		Insts: fn.insts,
+	}
+}
+
+// Load disassembles the executable at path using objdump.
+func Load(path string) (*File, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(Command, "--disassemble", "--line-numbers", path)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", Command, err, strings.TrimSpace(stderr.String()))
+	}
+	return Parse(bytes.NewReader(out))
+}
+
+var (
+	rxFunc   = regexp.MustCompile(`^[0-9a-fA-F]+ <(.+)>:$`)
+	rxLine   = regexp.MustCompile(`^(/.+|[A-Za-z]:\\.+):(\d+)(?: \(discriminator \d+\))?$`)
+	rxInst   = regexp.MustCompile(`^\s+([0-9a-fA-F]+):\t(.*)$`)
+	rxTarget = regexp.MustCompile(`^\S+\s+[0-9a-fA-F]+ <([^+>]+)>$`)
+)
+
+// Parse parses the output of `objdump --disassemble --line-numbers`,
+// which must include the raw instruction bytes for the function sizes.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{}
+
+	var fn *Func
+	var srcFile string
+	var srcLine int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := rxFunc.FindStringSubmatch(line); match != nil {
+			fn = &Func{obj: file, name: match[1]}
+			file.funcs = append(file.funcs, fn)
+			srcFile, srcLine = "", 0
+			continue
+		}
+		if fn == nil {
+			continue
+		}
+		if match := rxLine.FindStringSubmatch(line); match != nil {
+			srcFile = match[1]
+			srcLine, _ = strconv.Atoi(match[2])
+			if fn.file == "" {
+				fn.file = srcFile
+			}
+			continue
+		}
+		if match := rxInst.FindStringSubmatch(line); match != nil {
+			pc, err := strconv.ParseUint(match[1], 16, 64)
+			if err != nil {
+				continue
+			}
+			raw, text, ok := strings.Cut(match[2], "\t")
+			fn.end = max(fn.end, pc+uint64(rawLen(raw)))
+			if !ok {
+				// the bytes of a long instruction continue without the text
+				continue
+			}
+			text = strings.TrimSpace(text)
+			// drop objdump comments, e.g. "# 301af0 <__gmon_start__@Base>"
+			if comment := strings.Index(text, " # "); comment >= 0 {
+				text = strings.TrimSpace(text[:comment])
+			}
+
+			var call string
+			if strings.HasPrefix(text, "call") || strings.HasPrefix(text, "bl") || strings.HasPrefix(text, "jal") {
+				if target := rxTarget.FindStringSubmatch(text); target != nil {
+					call = target[1]
+				}
+			}
+
+			fn.insts = append(fn.insts, disasm.Inst{
+				PC:   pc,
+				Text: text,
+				File: srcFile,
+				Line: srcLine,
+				Call: call,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(file.funcs, func(i, k int) bool {
+		return strings.ToLower(file.funcs[i].Name()) < strings.ToLower(file.funcs[k].Name())
+	})
+
+	return file, nil
+}
+
+// rawLen returns the number of bytes of the raw instruction bytes shown
+// by objdump, e.g. "48 83 ec 08" or the word "fe010113".
+func rawLen(raw string) int {
+	digits := 0
+	for _, c := range raw {
+		if c != ' ' {
+			digits++
+		}
+	}
+	return digits / 2
+}
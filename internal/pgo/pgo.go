@@ -0,0 +1,386 @@
+// Package pgo reads the CPU profiles used for profile-guided optimization,
+// to show which instructions the compiler considers hot.
+package pgo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// Profile contains the sampled addresses of a pprof CPU profile.
+type Profile struct {
+	// Weights maps the sampled addresses to the fraction of the samples
+	// whose stack contains them, from 0 to 1.
+	Weights map[uint64]float64
+	// Self maps the addresses sampled as the innermost frame to the
+	// fraction of the samples spent there.
+	Self map[uint64]float64
+
+	// addrs are the keys of Weights, sorted.
+	addrs []uint64
+}
+
+// Load reads the profile at path, e.g. default.pgo, of the executable at
+// exePath. The sampled addresses are relocated to the addresses of the
+// executable, see ReadSegments. An empty exePath keeps them unchanged.
+func Load(path, exePath string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var segments []Segment
+	if exePath != "" {
+		segments, err = ReadSegments(exePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return Parse(data, segments)
+}
+
+// Parse parses a pprof profile, which may be gzip compressed.
+//
+// The profile contains the addresses where the executable was loaded in
+// the sampled process, which differ from the addresses in the executable
+// for position independent executables and with ASLR. They are relocated
+// by their mapping to the segments of the executable. Without segments,
+// the addresses are kept unchanged.
+func Parse(data []byte, segments []Segment) (*Profile, error) {
+	if bytes.HasPrefix(data, []byte("GO PREPROFILE")) {
+		return nil, errors.New("preprocessed profiles don't contain addresses, use the pprof profile")
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		data, err = io.ReadAll(bufio.NewReader(r))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := parseRaw(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pprof profile: %w", err)
+	}
+
+	// pprof selects the last sample type by default, cpu/nanoseconds for
+	// CPU profiles
+	index := raw.sampleTypes - 1
+	cum := map[uint64]int64{}
+	self := map[uint64]int64{}
+	var total int64
+	seen := map[uint64]bool{}
+	for _, sample := range raw.samples {
+		if index < 0 || index >= len(sample.values) {
+			continue
+		}
+		value := sample.values[index]
+		if value <= 0 {
+			continue
+		}
+		total += value
+		clear(seen)
+		for i, id := range sample.locations {
+			loc, ok := raw.locations[id]
+			if !ok || loc.addr == 0 {
+				continue
+			}
+			addr := relocate(loc.addr, raw.mappings[loc.mapping], segments)
+			if i == 0 {
+				self[addr] += value
+			}
+			// recursive calls are counted once per sample
+			if !seen[addr] {
+				seen[addr] = true
+				cum[addr] += value
+			}
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("profile has no samples")
+	}
+
+	p := &Profile{
+		Weights: make(map[uint64]float64, len(cum)),
+		Self:    make(map[uint64]float64, len(self)),
+	}
+	for addr, value := range cum {
+		p.Weights[addr] = float64(value) / float64(total)
+		p.addrs = append(p.addrs, addr)
+	}
+	for addr, value := range self {
+		p.Self[addr] = float64(value) / float64(total)
+	}
+	slices.Sort(p.addrs)
+	return p, nil
+}
+
+// InstWeights returns the weight of each instruction of code, nil when
+// none of them were sampled.
+//
+// The profiles store the return addresses of the frames, which follow the
+// call instructions, so an address is attributed to the instruction
+// containing the byte before it.
+func (p *Profile) InstWeights(code *disasm.Code) []float64 {
+	var weights []float64
+	for i, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		end := instEnd(code, i)
+		k, _ := slices.BinarySearch(p.addrs, ix.PC+1)
+		weight := 0.0
+		for ; k < len(p.addrs) && p.addrs[k] <= end; k++ {
+			weight += p.Weights[p.addrs[k]]
+		}
+		if weight == 0 {
+			continue
+		}
+		if weights == nil {
+			weights = make([]float64, len(code.Insts))
+		}
+		weights[i] = min(weight, 1)
+	}
+	return weights
+}
+
+// Percent returns the percentage of the samples spent in the instructions
+// of code itself, ok is false when none of them were sampled.
+func (p *Profile) Percent(code *disasm.Code) (percent float64, ok bool) {
+	total := 0.0
+	for i, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		end := instEnd(code, i)
+		for addr := ix.PC + 1; addr <= end; addr++ {
+			total += p.Self[addr]
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * total, true
+}
+
+// instEnd returns the address after the instruction at index i,
+// from its encoding or the next instruction.
+func instEnd(code *disasm.Code, i int) uint64 {
+	ix := &code.Insts[i]
+	if len(ix.Bytes) > 0 {
+		return ix.PC + uint64(len(ix.Bytes))
+	}
+	for _, next := range code.Insts[i+1:] {
+		if next.Text != "" && next.PC > ix.PC {
+			return next.PC
+		}
+	}
+	return ix.PC + 1
+}
+
+// relocate returns the address in the executable of the address addr,
+// which was sampled in the memory mapping m. Addresses outside of the
+// segments are returned unchanged.
+func relocate(addr uint64, m rawMapping, segments []Segment) uint64 {
+	if m.start == 0 || addr < m.start {
+		return addr
+	}
+	offset := addr - m.start + m.offset
+	for _, seg := range segments {
+		if offset >= seg.Offset && offset-seg.Offset < seg.Size {
+			return offset - seg.Offset + seg.Addr
+		}
+	}
+	return addr
+}
+
+// rawProfile contains the fields of the profile.proto message that are
+// needed for attributing the samples to addresses.
+type rawProfile struct {
+	sampleTypes int
+	samples     []rawSample
+	// locations maps the location IDs to their addresses.
+	locations map[uint64]rawLocation
+	// mappings maps the mapping IDs to the memory mappings.
+	mappings map[uint64]rawMapping
+}
+
+type rawLocation struct {
+	mapping uint64
+	addr    uint64
+}
+
+// rawMapping is a file mapped into the memory of the sampled process.
+type rawMapping struct {
+	// start is the address where the mapping starts.
+	start uint64
+	// offset is the file offset of the start.
+	offset uint64
+}
+
+type rawSample struct {
+	// locations are the location IDs of the stack, innermost first.
+	locations []uint64
+	values    []int64
+}
+
+// Field numbers of profile.proto, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto.
+const (
+	profileSampleType = 1
+	profileSample     = 2
+	profileMapping    = 3
+	profileLocation   = 4
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	mappingID          = 1
+	mappingMemoryStart = 2
+	mappingFileOffset  = 4
+
+	locationID        = 1
+	locationMappingID = 2
+	locationAddress   = 3
+)
+
+// parseRaw decodes the Profile message.
+func parseRaw(data []byte) (*rawProfile, error) {
+	raw := &rawProfile{locations: map[uint64]rawLocation{}, mappings: map[uint64]rawMapping{}}
+	err := walkFields(data, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case profileSampleType:
+			raw.sampleTypes++
+		case profileSample:
+			var sample rawSample
+			err := walkFields(value, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+				switch num {
+				case sampleLocationID:
+					return appendVarints(&sample.locations, typ, value, scalar, func(v uint64) uint64 { return v })
+				case sampleValue:
+					return appendVarints(&sample.values, typ, value, scalar, func(v uint64) int64 { return int64(v) })
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			raw.samples = append(raw.samples, sample)
+		case profileMapping:
+			var id uint64
+			var mapping rawMapping
+			err := walkFields(value, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+				if typ != protowire.VarintType {
+					return nil
+				}
+				switch num {
+				case mappingID:
+					id = scalar
+				case mappingMemoryStart:
+					mapping.start = scalar
+				case mappingFileOffset:
+					mapping.offset = scalar
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			raw.mappings[id] = mapping
+		case profileLocation:
+			var id uint64
+			var loc rawLocation
+			err := walkFields(value, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+				if typ != protowire.VarintType {
+					return nil
+				}
+				switch num {
+				case locationID:
+					id = scalar
+				case locationMappingID:
+					loc.mapping = scalar
+				case locationAddress:
+					loc.addr = scalar
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			raw.locations[id] = loc
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// walkFields calls fn for each field of the message in data, with the
+// content of length delimited fields or the value of varint fields.
+func walkFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		var scalar uint64
+		switch typ {
+		case protowire.VarintType:
+			scalar, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value, scalar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendVarints appends a repeated varint field, which is either packed
+// into value or a single scalar.
+func appendVarints[T any](values *[]T, typ protowire.Type, value []byte, scalar uint64, convert func(uint64) T) error {
+	if typ == protowire.VarintType {
+		*values = append(*values, convert(scalar))
+		return nil
+	}
+	if typ != protowire.BytesType {
+		return nil
+	}
+	for len(value) > 0 {
+		v, n := protowire.ConsumeVarint(value)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		*values = append(*values, convert(v))
+		value = value[n:]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package pgo
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"errors"
+)
+
+// Segment is a part of the executable that is mapped into memory.
+type Segment struct {
+	// Addr is the address of the segment in the executable.
+	Addr uint64
+	// Offset is the file offset of the segment.
+	Offset uint64
+	// Size is the size of the segment in the file.
+	Size uint64
+}
+
+// ReadSegments returns the loadable segments of the ELF or Mach-O
+// executable at path. It returns no segments for other formats, whose
+// profile addresses are not relocated.
+func ReadSegments(path string) ([]Segment, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		var segments []Segment
+		for _, prog := range f.Progs {
+			if prog.Type == elf.PT_LOAD && prog.Filesz > 0 {
+				segments = append(segments, Segment{Addr: prog.Vaddr, Offset: prog.Off, Size: prog.Filesz})
+			}
+		}
+		return segments, nil
+	}
+
+	f, err := macho.Open(path)
+	if err != nil {
+		var formatErr *macho.FormatError
+		if errors.As(err, &formatErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var segments []Segment
+	for _, load := range f.Loads {
+		if seg, ok := load.(*macho.Segment); ok && seg.Filesz > 0 {
+			segments = append(segments, Segment{Addr: seg.Addr, Offset: seg.Offset, Size: seg.Filesz})
+		}
+	}
+	return segments, nil
+}
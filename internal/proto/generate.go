@@ -0,0 +1,4 @@
+// Package lensmpb contains the gRPC API of the server, generated from lensm.proto.
+package lensmpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative lensm.proto
@@ -0,0 +1,97 @@
+// Package recent keeps track of recently opened binaries.
+package recent
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the name of the recent files list in the config directory.
+const FileName = "recent.json"
+
+// MaxEntries is the number of remembered files.
+const MaxEntries = 10
+
+// Entry is a single recently opened file.
+type Entry struct {
+	Path   string    `json:"path"`
+	Opened time.Time `json:"opened"`
+}
+
+// RecentFiles lists the recently opened files, most recent first.
+type RecentFiles struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Add moves path to the front of the list.
+func (r *RecentFiles) Add(path string) {
+	r.remove(path)
+	r.Entries = append([]Entry{{Path: path, Opened: time.Now()}}, r.Entries...)
+	if len(r.Entries) > MaxEntries {
+		r.Entries = r.Entries[:MaxEntries]
+	}
+}
+
+func (r *RecentFiles) remove(path string) {
+	entries := r.Entries[:0]
+	for _, entry := range r.Entries {
+		if entry.Path != path {
+			entries = append(entries, entry)
+		}
+	}
+	r.Entries = entries
+}
+
+// Prune removes duplicates and the files that no longer exist.
+func (r *RecentFiles) Prune() {
+	seen := map[string]bool{}
+	entries := r.Entries[:0]
+	for _, entry := range r.Entries {
+		if seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+		if _, err := os.Stat(entry.Path); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	r.Entries = entries
+	if len(r.Entries) > MaxEntries {
+		r.Entries = r.Entries[:MaxEntries]
+	}
+}
+
+// Load reads the list from configDir and prunes it.
+// A missing file results in an empty list.
+func (r *RecentFiles) Load(configDir string) error {
+	data, err := os.ReadFile(filepath.Join(configDir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		r.Entries = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return err
+	}
+	r.Prune()
+	return nil
+}
+
+// Save writes the list to configDir, creating the directory when needed.
+func (r *RecentFiles) Save(configDir string) error {
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(configDir, FileName), data, 0o644)
+}
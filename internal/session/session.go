@@ -0,0 +1,66 @@
+// Package session persists the files that were open in the UI,
+// so that they can be restored on the next start.
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the session file in the config directory.
+const FileName = "session.json"
+
+// Session contains the state that is restored on start.
+type Session struct {
+	// OpenFiles lists the paths of the open binaries in tab order.
+	OpenFiles []string `json:"openFiles"`
+	// SelectedFuncs maps the PathKey of a binary to its selected func.
+	SelectedFuncs map[string]string `json:"selectedFuncs,omitempty"`
+	// PinnedFuncs maps the PathKey of a binary to its pinned funcs in pin order.
+	PinnedFuncs map[string][]string `json:"pinnedFuncs,omitempty"`
+}
+
+// PathKey returns the key of the binary at path in SelectedFuncs,
+// a hash of the absolute path.
+func PathKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Load reads the session from configDir.
+// A missing session file results in an empty session.
+func Load(configDir string) (*Session, error) {
+	data, err := os.ReadFile(filepath.Join(configDir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save writes the session to configDir, creating the directory when needed.
+func (s *Session) Save(configDir string) error {
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(configDir, FileName), data, 0o644)
+}
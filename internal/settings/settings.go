@@ -0,0 +1,75 @@
+// Package settings persists the preferences of the UI.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the settings file in the config directory.
+const FileName = "settings.json"
+
+// Settings contains the user preferences.
+type Settings struct {
+	// FuncSort is the field the function list is sorted by.
+	FuncSort string `json:"funcSort,omitempty"`
+	// FuncSortDescending reverses the function list.
+	FuncSortDescending bool `json:"funcSortDescending,omitempty"`
+	// CodeViewMode arranges the source and the assembly of the code view.
+	CodeViewMode string `json:"codeViewMode,omitempty"`
+	// ShowTimings annotates the instructions with their latency and throughput.
+	ShowTimings bool `json:"showTimings,omitempty"`
+	// ShowHex prefixes the instructions with their encoding.
+	ShowHex bool `json:"showHex,omitempty"`
+	// SyntaxTheme is the name of the theme coloring the instruction categories,
+	// a built-in theme or a file in the themes directory, see themes.LoadSyntaxTheme.
+	SyntaxTheme string `json:"syntaxTheme,omitempty"`
+	// TextSize is the text size in sp chosen in the UI, 0 uses the -text-size flag.
+	TextSize float32 `json:"textSize,omitempty"`
+	// Window is the main window when it was last closed, nil uses the defaults.
+	Window *Window `json:"window,omitempty"`
+	// FuncListWidth is the width in dp of the function list, 0 uses the default.
+	FuncListWidth float32 `json:"funcListWidth,omitempty"`
+}
+
+// Window is the geometry of a window.
+type Window struct {
+	// Width and Height are the size in dp when the window isn't maximized.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Maximized is set when the window was maximized.
+	Maximized bool `json:"maximized,omitempty"`
+}
+
+// Load reads the settings from configDir.
+// A missing settings file results in the defaults.
+func Load(configDir string) (*Settings, error) {
+	data, err := os.ReadFile(filepath.Join(configDir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save writes the settings to configDir, creating the directory when needed.
+func (s *Settings) Save(configDir string) error {
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(configDir, FileName), data, 0o644)
+}
@@ -0,0 +1,219 @@
+// Package themes provides the colors of the instruction categories.
+//
+// A theme is a TOML file with a [syntax] section that assigns a color
+// to each category, e.g. branch = "#E57B19". Only this flat subset of
+// TOML is supported. Categories missing from a theme use the default colors.
+package themes
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// DefaultName is the name of the theme used when none is configured.
+const DefaultName = "default"
+
+// SyntaxTheme contains the colors of the instruction categories.
+type SyntaxTheme struct {
+	Branch     color.NRGBA
+	Call       color.NRGBA
+	Return     color.NRGBA
+	Load       color.NRGBA
+	Store      color.NRGBA
+	Arithmetic color.NRGBA
+	Vector     color.NRGBA
+	Other      color.NRGBA
+}
+
+// Color returns the color of an instruction category.
+func (theme SyntaxTheme) Color(cat disasm.InstCategory) color.NRGBA {
+	switch cat {
+	case disasm.CategoryBranch:
+		return theme.Branch
+	case disasm.CategoryCall:
+		return theme.Call
+	case disasm.CategoryReturn:
+		return theme.Return
+	case disasm.CategoryLoad:
+		return theme.Load
+	case disasm.CategoryStore:
+		return theme.Store
+	case disasm.CategoryArithmetic:
+		return theme.Arithmetic
+	case disasm.CategoryVector:
+		return theme.Vector
+	default:
+		return theme.Other
+	}
+}
+
+//go:embed *.toml
+var builtinFiles embed.FS
+
+// builtins are the themes shipped with lensm by name.
+var builtins = map[string]SyntaxTheme{}
+
+func init() {
+	entries, err := builtinFiles.ReadDir(".")
+	if err != nil {
+		panic(err)
+	}
+	// the default theme fills in the other themes
+	names := []string{DefaultName + ".toml"}
+	for _, entry := range entries {
+		if entry.Name() != names[0] {
+			names = append(names, entry.Name())
+		}
+	}
+	for _, file := range names {
+		data, err := builtinFiles.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		theme, err := parse(bytes.NewReader(data), builtins[DefaultName])
+		if err != nil {
+			panic(fmt.Errorf("theme %s: %w", file, err))
+		}
+		builtins[strings.TrimSuffix(file, ".toml")] = theme
+	}
+}
+
+// Default returns the default theme.
+func Default() SyntaxTheme { return builtins[DefaultName] }
+
+// Builtins returns the names of the built-in themes, sorted.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dir returns the directory of the user themes, ~/.config/lensm/themes on Linux.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lensm", "themes"), nil
+}
+
+// LoadSyntaxTheme returns the built-in theme with the name,
+// otherwise it reads <name>.toml from Dir.
+func LoadSyntaxTheme(name string) (SyntaxTheme, error) {
+	if theme, ok := builtins[name]; ok {
+		return theme, nil
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return SyntaxTheme{}, fmt.Errorf("invalid theme name %q", name)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return SyntaxTheme{}, err
+	}
+	f, err := os.Open(filepath.Join(dir, name+".toml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return SyntaxTheme{}, fmt.Errorf("unknown theme %q, the built-in themes are %s", name, strings.Join(Builtins(), ", "))
+	}
+	if err != nil {
+		return SyntaxTheme{}, err
+	}
+	defer f.Close()
+
+	theme, err := parse(f, Default())
+	if err != nil {
+		return SyntaxTheme{}, fmt.Errorf("theme %s: %w", f.Name(), err)
+	}
+	return theme, nil
+}
+
+// parse reads the [syntax] section of a theme, starting from base.
+// Keys outside of any section are treated as part of it.
+func parse(r io.Reader, base SyntaxTheme) (SyntaxTheme, error) {
+	theme := base
+	fields := map[string]*color.NRGBA{
+		"branch":     &theme.Branch,
+		"call":       &theme.Call,
+		"return":     &theme.Return,
+		"load":       &theme.Load,
+		"store":      &theme.Store,
+		"arithmetic": &theme.Arithmetic,
+		"vector":     &theme.Vector,
+		"other":      &theme.Other,
+	}
+
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != "" && section != "syntax" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return theme, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		field, ok := fields[key]
+		if !ok {
+			return theme, fmt.Errorf("line %d: unknown category %q", lineNumber, key)
+		}
+		c, err := parseColor(stripComment(value))
+		if err != nil {
+			return theme, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		*field = c
+	}
+	return theme, scanner.Err()
+}
+
+// stripComment removes a trailing comment after a quoted value.
+func stripComment(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) {
+		if end := strings.IndexByte(value[1:], '"'); end >= 0 {
+			return value[:end+2]
+		}
+	}
+	return value
+}
+
+// parseColor parses a quoted "#RRGGBB" or "#RRGGBBAA" color.
+func parseColor(value string) (color.NRGBA, error) {
+	s, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(s, "#") || (len(s) != 7 && len(s) != 9) {
+		return color.NRGBA{}, fmt.Errorf("invalid color %s, expected \"#RRGGBB\"", value)
+	}
+	if len(s) == 7 {
+		s += "FF"
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %s, expected \"#RRGGBB\"", value)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
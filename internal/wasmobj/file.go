@@ -26,6 +26,11 @@ type File struct {
 
 func (file *File) Funcs() []disasm.Func { return file.funcs }
 
+// PackageFuncs groups the funcs by package path.
+func (file *File) PackageFuncs() map[string][]disasm.Func {
+	return disasm.GroupByPackage(file.funcs)
+}
+
 // Func contains information about the executable.
 type Func struct {
 	obj      *File
@@ -37,10 +42,42 @@ type Func struct {
 
 func (fn *Func) Name() string { return fn.name }
 
+// Size returns the size of the func body in bytes.
+func (fn *Func) Size() int64 { return int64(len(fn.code.Body)) }
+
+// SymbolTable lists the named functions in the module.
+func (file *File) SymbolTable() []disasm.Symbol {
+	table := make([]disasm.Symbol, 0, len(file.funcs))
+	for _, fn := range file.funcs {
+		fn := fn.(*Func)
+		table = append(table, disasm.Symbol{
+			Name:     fn.name,
+			Type:     "T",
+			Size:     int64(len(fn.code.Body)),
+			Address:  uint64(fn.index),
+			Exported: disasm.IsExported(fn.name),
+		})
+	}
+	return table
+}
+
 func (file *File) Close() error {
 	return nil
 }
 
+// BuildID returns the content of the go:buildid custom section.
+func (file *File) BuildID() string {
+	for _, section := range file.module.CustomSections {
+		if section.Name == "go:buildid" {
+			return string(section.Data)
+		}
+	}
+	return ""
+}
+
+// GoModPath returns "", the build info of WebAssembly modules isn't read.
+func (file *File) GoModPath() string { return "" }
+
 func Load(path string) (*File, error) {
 	obj := &File{}
 
@@ -79,6 +116,9 @@ func Load(path string) (*File, error) {
 	return obj, nil
 }
 
+// BuildConstraints returns nil, WebAssembly modules have no source mapping.
+func (fn *Func) BuildConstraints() []string { return nil }
+
 func (fn *Func) Load(opts disasm.Options) *disasm.Code {
 	return fn.obj.LoadCode(fn, opts)
 }
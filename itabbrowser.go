@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"regexp"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/goobj"
+)
+
+// ItabBrowserUI lists the interface method tables of a file,
+// each followed by the funcs implementing the interface methods.
+type ItabBrowserUI struct {
+	All         []goobj.ItabInfo
+	Filter      widget.Editor
+	FilterError string
+	// LoadError is shown instead of the list when the itabs can't be read.
+	LoadError error
+
+	// rows are the filtered itabs and their methods.
+	rows     []itabRow
+	filtered int
+
+	List SelectList
+}
+
+// itabRow is a single row of the itab browser.
+type itabRow struct {
+	itab *goobj.ItabInfo
+	// method is the implementing func, empty for the itab header.
+	method string
+}
+
+// NewItabBrowser creates a new itab browser with the specified theme.
+func NewItabBrowser(theme *material.Theme) *ItabBrowserUI {
+	ui := &ItabBrowserUI{}
+	ui.Filter.SingleLine = true
+	ui.List = NewVerticalSelectList(unit.Dp(theme.TextSize) + 4)
+	ui.List.Selected = -1
+	return ui
+}
+
+// SetItabs updates the full list of itabs.
+func (ui *ItabBrowserUI) SetItabs(all []goobj.ItabInfo, err error) {
+	ui.All = all
+	ui.LoadError = err
+	ui.updateFiltered()
+}
+
+// updateFiltered keeps the itabs where the interface, the concrete type
+// or one of the methods matches the filter.
+func (ui *ItabBrowserUI) updateFiltered() {
+	rx, err := regexp.Compile("(?i)" + ui.Filter.Text())
+	ui.FilterError = ""
+	if err != nil {
+		ui.FilterError = err.Error()
+		return
+	}
+
+	ui.rows = ui.rows[:0]
+	ui.filtered = 0
+	for i := range ui.All {
+		itab := &ui.All[i]
+		match := rx.MatchString(itab.InterfaceType) || rx.MatchString(itab.ConcreteType)
+		for _, method := range itab.Methods {
+			match = match || rx.MatchString(method)
+		}
+		if !match {
+			continue
+		}
+		ui.filtered++
+		ui.rows = append(ui.rows, itabRow{itab: itab})
+		for _, method := range itab.Methods {
+			ui.rows = append(ui.rows, itabRow{itab: itab, method: method})
+		}
+	}
+	ui.List.Selected = -1
+}
+
+// Layout draws the itabs. open is called when a method is selected.
+func (ui *ItabBrowserUI) Layout(th *material.Theme, gtx layout.Context, open func(gtx layout.Context, name string)) layout.Dimensions {
+	for {
+		ev, ok := ui.Filter.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.ChangeEvent); ok {
+			ui.updateFiltered()
+			gtx.Execute(op.InvalidateCmd{})
+		}
+	}
+
+	if ui.LoadError != nil {
+		return material.Body1(th, ui.LoadError.Error()).Layout(gtx)
+	}
+	if len(ui.All) == 0 {
+		return layout.Inset{Top: 8, Left: 8}.Layout(gtx,
+			material.Body1(th, "No itabs in the symbol table, newer linkers don't list them").Layout)
+	}
+
+	lineHeight := gtx.Metric.Sp(th.TextSize)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return FocusBorder(th, gtx.Focused(&ui.Filter)).Layout(gtx,
+				material.Editor(th, &ui.Filter, "Filter itabs (regexp)").Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if ui.FilterError == "" {
+				return layout.Dimensions{}
+			}
+			return material.Body1(th, ui.FilterError).Layout(gtx)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			previous := ui.List.Selected
+			dims := ui.List.Layout(th, gtx, len(ui.rows), func(gtx layout.Context, index int) layout.Dimensions {
+				row := ui.rows[index]
+				if row.method == "" {
+					paint.Fill(gtx.Ops, secondaryBackground)
+					SourceLine{
+						TopLeft:    image.Pt(0, 0),
+						Text:       row.itab.ConcreteType + " → " + row.itab.InterfaceType,
+						TextHeight: th.TextSize * 8 / 10,
+						Bold:       true,
+						Color:      th.Fg,
+					}.Layout(th, gtx)
+					return layout.Dimensions{Size: gtx.Constraints.Max}
+				}
+				if ui.List.Selected == index || ui.List.Hovered == index {
+					bg := th.ContrastBg
+					bg.A /= 4
+					paint.Fill(gtx.Ops, bg)
+				}
+				SourceLine{
+					TopLeft:    image.Pt(lineHeight*2, 0),
+					Text:       row.method,
+					TextHeight: th.TextSize * 8 / 10,
+					Bold:       ui.List.Hovered == index,
+					Color:      th.Fg,
+				}.Layout(th, gtx)
+				return layout.Dimensions{Size: gtx.Constraints.Max}
+			})
+			if ui.List.Selected != previous && InRange(ui.List.Selected, len(ui.rows)) {
+				if row := ui.rows[ui.List.Selected]; row.method != "" && open != nil {
+					open(gtx, row.method)
+				}
+			}
+			return dims
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			body := material.Body1(th, fmt.Sprintf("%d / %d", ui.filtered, len(ui.All)))
+			body.TextSize *= 0.8
+			return layout.Center.Layout(gtx, body.Layout)
+		}),
+	)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/lsp"
+)
+
+// serveLSP answers the Language Server Protocol requests of an editor
+// on stdin and stdout about the executable at path, until the editor exits.
+func serveLSP(path, dwarfPath string, context int, logger *slog.Logger) error {
+	file, err := loadDumpFile(path, dwarfPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	server := &lsp.Server{
+		File:    file,
+		Options: disasm.Options{Context: context},
+		Logger:  logger,
+	}
+	defer server.Close()
+	return server.Serve(os.Stdin, os.Stdout)
+}
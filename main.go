@@ -4,9 +4,9 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"image"
 	"image/color"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"runtime/pprof"
@@ -18,35 +18,103 @@ import (
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget/material"
+	"github.com/gameformush/goasm-vscode/internal/coverage"
 	"github.com/gameformush/goasm-vscode/internal/goobj"
+	"github.com/gameformush/goasm-vscode/internal/pgo"
 )
 
 func main() {
 	cpuprofile := flag.String("cpuprofile", "", "enable cpu profiling")
 	textSize := flag.Int("text-size", 12, "default font size")
-	filter := flag.String("filter", "", "filter the functions by regexp")
+	var filters, filterPackages, corsOrigins stringList
+	flag.Var(&filters, "filter", "filter the functions by regexp, may be repeated to match any of the patterns")
+	flag.Var(&filterPackages, "filter-package", "with -dump-asm, select all functions of a package, may be repeated")
 	watch := flag.Bool("watch", false, "auto reload executable")
+	watchHash := flag.Bool("watch-hash", false, "with -watch, compare the SHA-256 of the executable to detect changes on filesystems with coarse modification times, and to ignore touched but unchanged files")
 	lineContext := flag.Int("context", 3, "source line context")
+	warnFrame := flag.Int("warn-frame", 4096, "warn about functions with a stack frame larger than this many bytes, 0 disables")
+	maxComplexity := flag.Int("max-complexity", 0, "highlight functions with a cyclomatic complexity (loops + 1) larger than this, 0 disables")
+	maxFuncs := flag.Int("max-funcs", 0, "list at most this many functions matching the filter, for huge binaries, 0 is unlimited")
+	dwarfPath := flag.String("dwarf", "", "read line information of the executable from this separate debug info file (e.g. from a -dbg package)")
+	arch := flag.String("arch", "", "load this GOARCH slice of universal (fat) Mach-O binaries (e.g. arm64 or amd64), defaults to the host architecture")
+	addr2line := flag.String("addr2line", "", "resolve the source of instructions without line information (e.g. stripped binaries with separate DWARF) with this addr2line executable")
+	delveTarget := flag.String("delve", "", "view the live process with this pid, attaching with dlv, or of the headless Delve server at this address (format: host:port)")
+	asmFile := flag.String("asm-file", "", "view the functions of a JSON export (e.g. of -dump-asm -output json) instead of an executable")
+	coverProfile := flag.String("coverage", "", "show the line coverage of a `go test -coverprofile` output")
+	pgoProfile := flag.String("pgo", "", "show the instructions sampled in this CPU profile used for profile-guided optimization (e.g. default.pgo) as a heatmap")
+	minContext := flag.Int("min-context", 0, "minimum source line context clients may request in server mode")
+	maxContext := flag.Int("max-context", 50, "maximum source line context clients may request in server mode")
 	font := flag.String("font", "", "user font")
+	fontDir := flag.String("font-dir", "", "directory of user fonts, e.g. the weights and styles of a family")
 	darkMode := flag.Bool("dark", false, "use dark theme")
 
+	// Dump options
+	dump := flag.Bool("dump-asm", false, "print the disassembly of the functions matching any -filter or -filter-package and exit")
+	output := flag.String("output", "text", "output format of -dump-asm (text, json, objdump for the format of go tool objdump, or svg for the call graph, rendered with Graphviz dot)")
+	root := flag.String("root", "", "with -output svg, limit the call graph to the functions reachable from this function")
+	benchLoads := flag.Int("benchmark-load", 0, "load the functions matching any -filter or -filter-package this many times, print the min/avg/p95/max latencies and exit")
+
+	// Editor integration
+	lspMode := flag.Bool("lsp", false, "serve the Language Server Protocol on stdin/stdout, hovering a source line shows its instructions and go to definition opens the disassembly")
+
 	// HTTP server/client options
 	serverMode := flag.Bool("server", false, "run in server mode (HTTP API only)")
 	clientMode := flag.Bool("client", false, "run in client mode (connect to HTTP server)")
 	serverAddr := flag.String("addr", "localhost:8080", "HTTP server address (format: host:port)")
-
-	workInProgressWASM = os.Getenv("LENSM_EXPERIMENT_WASM") != ""
+	grpcAddr := flag.String("grpc-addr", "", "also serve the gRPC API on this address in server mode (format: host:port)")
+	tlsCert := flag.String("tls-cert", "", "serve https with this certificate in server mode, requires -tls-key")
+	tlsKey := flag.String("tls-key", "", "private key of -tls-cert")
+	flag.Var(&corsOrigins, "cors-origin", "allow browser requests from this origin in server mode (e.g. vscode-webview://<id> or https://example.com, * matches any part), may be repeated, defaults to any origin")
+	corsNoCredentials := flag.Bool("cors-no-credentials", false, "don't allow cross-origin requests with credentials in server mode")
+	tlsClientCA := flag.String("tls-client-ca", "", "require client certificates signed by the CAs in this file in server mode")
+	tlsClientCert := flag.String("tls-client-cert", "", "present this certificate to the server in client mode, requires -tls-client-key")
+	tlsClientKey := flag.String("tls-client-key", "", "private key of -tls-client-cert")
+	tlsCA := flag.String("tls-ca", "", "verify the server certificate with the CAs in this file in client mode")
+	maxUploadBytes := flag.Int64("max-upload-bytes", 100<<20, "maximum size of any request body in server mode, 0 disables the limit")
+	maxRequestBytes := flag.Int64("max-request-bytes", 4<<10, "maximum size of JSON request bodies in server mode, 0 disables the limit")
+	maxConcurrent := flag.Int("max-concurrent", 8, "maximum functions disassembled at the same time in server mode, 0 disables the limit")
+	queueTimeout := flag.Duration("queue-timeout", 30*time.Second, "how long requests wait for a disassembly slot in server mode before failing with 503")
+	fileTTL := flag.Duration("file-ttl", time.Hour, "unload files loaded by clients that weren't accessed for this long in server mode, 0 disables")
+	cacheSize := flag.Int("cache-size", 500, "number of disassembled functions cached by the server, 0 disables")
+	logFormat := flag.String("log-format", "text", "log output format (text or json)")
+	otelEndpoint := flag.String("otel-endpoint", "", "send traces to an OTLP/HTTP endpoint (e.g. http://localhost:4318)")
 
 	flag.Parse()
 	exePath := flag.Arg(0)
+	goobj.Addr2LineCommand = *addr2line
+	goobj.Arch = *arch
+	if *asmFile != "" {
+		if exePath != "" || *delveTarget != "" {
+			fmt.Fprintln(os.Stderr, "Error: -asm-file can't be used with an executable or -delve")
+			os.Exit(1)
+		}
+		exePath = *asmFile
+	}
 
-	if exePath == "" && !*serverMode && !*clientMode {
+	if exePath == "" && !*serverMode && !*clientMode && *delveTarget == "" {
 		fmt.Fprintln(os.Stderr, "lensm <exePath>")
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// Debug code removed
+	var logHandler slog.Handler
+	switch *logFormat {
+	case "text":
+		logHandler = slog.NewTextHandler(os.Stderr, nil)
+	case "json":
+		logHandler = slog.NewJSONHandler(os.Stderr, nil)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown -log-format %q\n", *logFormat)
+		os.Exit(1)
+	}
+	logger := slog.New(logHandler)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := setupTracing(context.Background(), *otelEndpoint, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Check for incompatible modes
 	if *serverMode && *clientMode {
@@ -54,19 +122,92 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *delveTarget != "" && (*serverMode || *clientMode) {
+		fmt.Fprintln(os.Stderr, "Error: -delve can't be used with -server or -client")
+		os.Exit(1)
+	}
+
+	if *lspMode && (*serverMode || *clientMode || *delveTarget != "" || *asmFile != "" || *dump || *benchLoads != 0) {
+		fmt.Fprintln(os.Stderr, "Error: -lsp can't be used with -server, -client, -delve, -asm-file, -dump-asm or -benchmark-load")
+		os.Exit(1)
+	}
+
+	if *asmFile != "" && (*serverMode || *clientMode || *dump || *benchLoads != 0) {
+		fmt.Fprintln(os.Stderr, "Error: -asm-file can't be used with -server, -client, -dump-asm or -benchmark-load")
+		os.Exit(1)
+	}
+
+	if *minContext > *maxContext || *lineContext < *minContext || *lineContext > *maxContext {
+		fmt.Fprintln(os.Stderr, "Error: -context must be between -min-context and -max-context")
+		os.Exit(1)
+	}
+
+	if *dump || *benchLoads != 0 {
+		if exePath == "" {
+			fmt.Fprintln(os.Stderr, "Error: -dump-asm and -benchmark-load require an executable")
+			os.Exit(1)
+		}
+		filter, err := newFilterFunc(filters, filterPackages)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *benchLoads != 0 {
+			err = benchmarkLoad(exePath, *dwarfPath, filter, *benchLoads, *lineContext)
+		} else {
+			err = dumpAsm(exePath, *dwarfPath, filter, *output, *root, *lineContext)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *lspMode {
+		if err := serveLSP(exePath, *dwarfPath, *lineContext, logger); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var server *Server
 	// Start in server mode if requested
 	if *serverMode {
 		fmt.Printf("Starting lensm in server mode on %s\n", *serverAddr)
-		server = StartServer(*serverAddr, *lineContext)
+		// the server keeps the code in its own cache, see ServerConfig.CacheSize
+		goobj.CodeCacheSize = 0
+		server = StartServer(*serverAddr, ServerConfig{
+			Context:    *lineContext,
+			MinContext: *minContext,
+			MaxContext: *maxContext,
+			CacheSize:  *cacheSize,
+			FileTTL:    *fileTTL,
+			Logger:     logger,
+
+			MaxUploadBytes:  *maxUploadBytes,
+			MaxRequestBytes: *maxRequestBytes,
+			MaxConcurrent:   *maxConcurrent,
+			QueueTimeout:    *queueTimeout,
+
+			TLSCertFile:     *tlsCert,
+			TLSKeyFile:      *tlsKey,
+			TLSClientCAFile: *tlsClientCA,
+
+			GRPCAddr: *grpcAddr,
+
+			CORSOrigins:       corsOrigins,
+			CORSNoCredentials: *corsNoCredentials,
+		})
 
 		if exePath != "" {
 			fmt.Printf("Loading file: %s\n", exePath)
-			file, err := goobj.Load(exePath)
+			file, err := goobj.LoadWithDWARF(exePath, *dwarfPath)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to load %s: %v\n", exePath, err)
 			} else {
-				server.addFile(exePath, file)
+				server.addFile(exePath, file, true)
 			}
 		}
 
@@ -93,6 +234,7 @@ func main() {
 		} else {
 			fmt.Println("Server gracefully stopped")
 		}
+		_ = shutdownTracing(ctx)
 
 		// Signal received, close the done channel
 		close(done)
@@ -102,19 +244,57 @@ func main() {
 	// Set the server URL if in client mode
 	var serverURL string
 	if *clientMode {
-		// Check if the address starts with http://
-		if !strings.HasPrefix(*serverAddr, "http://") {
-			serverURL = "http://" + *serverAddr
-		} else {
+		// Use https for addresses without a scheme when TLS is configured
+		switch {
+		case strings.HasPrefix(*serverAddr, "http://"), strings.HasPrefix(*serverAddr, "https://"):
 			serverURL = *serverAddr
+		case *tlsClientCert != "" || *tlsCA != "":
+			serverURL = "https://" + *serverAddr
+		default:
+			serverURL = "http://" + *serverAddr
+		}
+		if (*tlsClientCert == "") != (*tlsClientKey == "") {
+			fmt.Fprintln(os.Stderr, "Error: -tls-client-cert and -tls-client-key must be used together")
+			os.Exit(1)
 		}
 		fmt.Printf("Running in client mode, connecting to %s\n", serverURL)
 	}
 
+	var coverageProfile *coverage.Profile
+	var coveragePath string
+	switch {
+	case *coverProfile != "" && serverURL != "":
+		// the server parses the profile in client mode
+		coveragePath = *coverProfile
+	case *coverProfile != "":
+		var err error
+		coverageProfile, err = coverage.Load(*coverProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load coverage %s: %v\n", *coverProfile, err)
+			os.Exit(1)
+		}
+	}
+
+	var pgoData *pgo.Profile
+	if *pgoProfile != "" {
+		// the profile is relocated to the local executable, in client
+		// mode the path refers to the server
+		localPath := exePath
+		if serverURL != "" {
+			localPath = ""
+		}
+		var err error
+		pgoData, err = pgo.Load(*pgoProfile, localPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load PGO profile %s: %v\n", *pgoProfile, err)
+			os.Exit(1)
+		}
+	}
+
 	windows := &Windows{}
 
 	theme := material.NewTheme()
-	theme.Shaper = text.NewShaper(text.WithCollection(LoadFonts(*font)))
+	theme.Shaper = text.NewShaper(text.WithCollection(LoadFonts(*font, *fontDir)))
 	theme.TextSize = unit.Sp(*textSize)
 
 	// Apply dark theme if requested
@@ -135,17 +315,34 @@ func main() {
 
 	ui := NewExeUI(windows, theme)
 	ui.Config = FileUIConfig{
-		Path:      exePath,
-		Watch:     *watch,
-		Context:   *lineContext,
-		ServerURL: serverURL,
+		Path:          exePath,
+		Watch:         *watch,
+		WatchHash:     *watchHash,
+		Context:       *lineContext,
+		Filter:        filters.Pattern(),
+		ServerURL:     serverURL,
+		ClientCert:    *tlsClientCert,
+		ClientKey:     *tlsClientKey,
+		ServerCA:      *tlsCA,
+		WarnFrame:     *warnFrame,
+		MaxComplexity: *maxComplexity,
+		MaxFuncs:      *maxFuncs,
+		Coverage:      coverageProfile,
+		CoveragePath:  coveragePath,
+		PGO:           pgoData,
+		DWARF:         *dwarfPath,
+		Delve:         *delveTarget,
+		TextSize:      theme.TextSize,
 	}
-	ui.Funcs.SetFilter(*filter)
 
-	windows.Open("lensm", image.Pt(1400, 900), ui.Run)
+	ui.LoadSettings()
+	windows.Open(ui.WindowConfig(), ui.Run)
 
 	go func() {
 		profile(*cpuprofile, windows.Wait)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = shutdownTracing(ctx)
+		cancel()
 		os.Exit(0)
 	}()
 
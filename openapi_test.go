@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// TestOpenAPIDocumentsRoutes checks that openapi.json is a valid OpenAPI
+// document and that it describes every route of the API.
+func TestOpenAPIDocumentsRoutes(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromFile("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+
+	config := ServerConfig{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	router := NewServer(config).newRouter(config)
+	// the patterns of the path variables aren't part of the OpenAPI path
+	pattern := regexp.MustCompile(`\{(\w+):[^}]+\}`)
+	routes := 0
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// path prefixes of subrouters
+			return nil
+		}
+		if path == "/ui" || strings.HasPrefix(path, "/ui/") {
+			// the web UI isn't part of the API
+			return nil
+		}
+		// v2 serves the v1 paths with paginated lists, see apiVersion
+		path = strings.Replace(pattern.ReplaceAllString(path, "{$1}"), "/api/v2/", "/api/", 1)
+
+		routes++
+		item := doc.Paths.Value(path)
+		if item == nil {
+			t.Errorf("%s isn't documented", path)
+			return nil
+		}
+		for _, method := range methods {
+			if item.GetOperation(method) == nil {
+				t.Errorf("%s %s isn't documented", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes == 0 {
+		t.Fatal("no routes found")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/f32color"
+)
+
+// PackageSizesUI lists the code size of each package, largest first.
+type PackageSizesUI struct {
+	Sizes []disasm.PackageSize
+	Total int64
+
+	List SelectList
+}
+
+// NewPackageSizes creates a new package size table with the specified theme.
+func NewPackageSizes(theme *material.Theme) *PackageSizesUI {
+	ui := &PackageSizesUI{}
+	ui.List = NewVerticalSelectList(unit.Dp(theme.TextSize) + 4)
+	ui.List.Selected = -1
+	return ui
+}
+
+// SetFile computes the package sizes of file. Files that can provide
+// the sizes themselves, such as a file loaded from a server, are asked.
+func (ui *PackageSizesUI) SetFile(file disasm.File) {
+	if sizer, ok := file.(interface {
+		PackageSizes() ([]disasm.PackageSize, int64)
+	}); ok {
+		ui.Sizes, ui.Total = sizer.PackageSizes()
+	} else {
+		ui.Sizes, ui.Total = disasm.PackageSizes(file)
+	}
+	ui.List.Selected = -1
+}
+
+// Layout draws the table. open is called with the package of the selected row.
+func (ui *PackageSizesUI) Layout(th *material.Theme, gtx layout.Context, open func(pkg string)) layout.Dimensions {
+	lineHeight := gtx.Metric.Sp(th.TextSize)
+	sizeWidth := lineHeight * 6
+	countWidth := lineHeight * 5
+	shareWidth := lineHeight * 8
+	textHeight := th.TextSize * 8 / 10
+
+	header := func(gtx layout.Context) layout.Dimensions {
+		height := lineHeight * 3 / 2
+		paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, height)}.Op())
+		for _, column := range []struct {
+			x    int
+			text string
+		}{
+			{0, "Size"},
+			{sizeWidth, "Functions"},
+			{sizeWidth + countWidth, "Share"},
+			{sizeWidth + countWidth + shareWidth, "Package"},
+		} {
+			SourceLine{
+				TopLeft:    image.Pt(column.x, lineHeight/4),
+				Text:       column.text,
+				TextHeight: textHeight,
+				Color:      th.Fg,
+			}.Layout(th, gtx)
+		}
+		return layout.Dimensions{Size: image.Pt(gtx.Constraints.Max.X, height)}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(header),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			previous := ui.List.Selected
+			dims := ui.List.Layout(th, gtx, len(ui.Sizes), func(gtx layout.Context, index int) layout.Dimensions {
+				pkg := &ui.Sizes[index]
+				if ui.List.Selected == index || ui.List.Hovered == index {
+					bg := th.ContrastBg
+					bg.A /= 4
+					paint.Fill(gtx.Ops, bg)
+				}
+
+				share := 0.0
+				if ui.Total > 0 {
+					share = float64(pkg.Size) / float64(ui.Total)
+				}
+				paint.FillShape(gtx.Ops, f32color.HSLA(0.6, 0.6, 0.6, 0.6), clip.Rect{
+					Min: image.Pt(sizeWidth+countWidth, lineHeight/6),
+					Max: image.Pt(sizeWidth+countWidth+int(float64(shareWidth-lineHeight)*share), lineHeight*5/6),
+				}.Op())
+
+				name := pkg.Name
+				if name == "" {
+					name = "(no package)"
+				}
+				for _, column := range []struct {
+					x    int
+					text string
+				}{
+					{0, fmt.Sprintf("%10d", pkg.Size)},
+					{sizeWidth, fmt.Sprintf("%8d", pkg.Funcs)},
+					{sizeWidth + countWidth + shareWidth - lineHeight*3, fmt.Sprintf("%4.1f%%", share*100)},
+					{sizeWidth + countWidth + shareWidth, name},
+				} {
+					SourceLine{
+						TopLeft:    image.Pt(column.x, 0),
+						Text:       column.text,
+						TextHeight: textHeight,
+						Bold:       ui.List.Hovered == index && column.text == name,
+						Color:      th.Fg,
+					}.Layout(th, gtx)
+				}
+				return layout.Dimensions{Size: gtx.Constraints.Max}
+			})
+			if ui.List.Selected != previous && InRange(ui.List.Selected, len(ui.Sizes)) && open != nil {
+				open(ui.Sizes[ui.List.Selected].Name)
+			}
+			return dims
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			body := material.Body1(th, fmt.Sprintf("%d packages, %d bytes", len(ui.Sizes), ui.Total))
+			body.TextSize *= 0.8
+			return layout.Center.Layout(gtx, body.Layout)
+		}),
+	)
+}
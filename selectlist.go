@@ -3,6 +3,7 @@ package main
 import (
 	"image"
 	"image/color"
+	"time"
 
 	"gioui.org/f32"
 	"gioui.org/font"
@@ -38,6 +39,26 @@ type SelectList struct {
 	Hovered  int
 
 	ItemHeight unit.Dp
+
+	// scroll animates the list toward an item requested by ScrollTo.
+	scroll struct {
+		pending bool
+		target  int
+		// last is the offset set by the animation in the previous frame,
+		// a different offset means the list was scrolled meanwhile.
+		last int
+		anim ScrollAnimation
+	}
+}
+
+// scrollDuration is how long ScrollTo takes to bring an item into view.
+const scrollDuration = 200 * time.Millisecond
+
+// ScrollTo smoothly scrolls the list until the item at index is visible,
+// starting on the next frame. It overrides a scroll that's still running.
+func (list *SelectList) ScrollTo(index int) {
+	list.scroll.pending = true
+	list.scroll.target = index
 }
 
 // Layout draws the list.
@@ -151,6 +172,8 @@ func (list *SelectList) Layout(th *material.Theme, gtx layout.Context, length in
 		}
 
 		if changed {
+			list.scroll.pending = false
+			list.scroll.anim.Stop()
 			pos := &list.List.Position
 			switch {
 			case list.Selected < pos.First+1:
@@ -160,6 +183,8 @@ func (list *SelectList) Layout(th *material.Theme, gtx layout.Context, length in
 			}
 		}
 
+		list.updateScroll(gtx, length, itemHeight)
+
 		style := material.List(th, &list.List)
 		style.AnchorStrategy = material.Overlay
 		return style.Layout(gtx, length,
@@ -173,6 +198,36 @@ func (list *SelectList) Layout(th *material.Theme, gtx layout.Context, length in
 	})
 }
 
+// updateScroll starts the animation requested by ScrollTo and
+// moves the list along it.
+func (list *SelectList) updateScroll(gtx layout.Context, length, itemHeight int) {
+	pos := &list.List.Position
+	offset := pos.First*itemHeight + pos.Offset
+	if list.scroll.pending {
+		list.scroll.pending = false
+		height := gtx.Constraints.Max.Y
+		target := offset
+		switch top := list.scroll.target * itemHeight; {
+		case top < offset:
+			target = top - itemHeight
+		case top+itemHeight > offset+height:
+			target = top + 2*itemHeight - height
+		}
+		target = max(min(target, length*itemHeight-height), 0)
+		if target != offset {
+			list.scroll.anim.Start(gtx, float32(offset), float32(target), scrollDuration)
+			list.scroll.last = offset
+		}
+	}
+	if offset != list.scroll.last {
+		list.scroll.anim.Stop()
+	}
+	if scroll, ok := list.scroll.anim.Update(gtx); ok {
+		list.scroll.last = int(scroll)
+		*pos = layout.Position{First: list.scroll.last / itemHeight, Offset: list.scroll.last % itemHeight}
+	}
+}
+
 // StringListItem creates a string item drawer that reacts to hover and selection.
 func StringListItem(th *material.Theme, state *SelectList, item func(int) string) layout.ListElement {
 	return func(gtx layout.Context, index int) layout.Dimensions {
@@ -209,3 +264,58 @@ func StringListItem(th *material.Theme, state *SelectList, item func(int) string
 		})
 	}
 }
+
+// Badge is a label drawn on a colored background.
+type Badge struct {
+	Label string
+	Color color.NRGBA
+}
+
+// BadgeListItem is like StringListItem, but draws colored badges after the text.
+func BadgeListItem(th *material.Theme, state *SelectList, item func(int) string, badges func(int) []Badge) layout.ListElement {
+	text := StringListItem(th, state, item)
+	return func(gtx layout.Context, index int) layout.Dimensions {
+		type recorded struct {
+			call op.CallOp
+			dims layout.Dimensions
+			bg   color.NRGBA
+		}
+		var labels []recorded
+		width := 0
+		for _, badge := range badges(index) {
+			if badge.Label == "" {
+				continue
+			}
+			// record the badge to know its width
+			macro := op.Record(gtx.Ops)
+			txt := material.Body2(th, badge.Label)
+			txt.TextSize = th.TextSize * 6 / 10
+			txt.Color = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+			badgeGtx := gtx
+			badgeGtx.Constraints.Min = image.Point{}
+			dims := layout.Inset{Left: 3, Right: 3}.Layout(badgeGtx, txt.Layout)
+			labels = append(labels, recorded{call: macro.Stop(), dims: dims, bg: badge.Color})
+			width += dims.Size.X + gtx.Dp(4)
+		}
+		if len(labels) == 0 {
+			return text(gtx, index)
+		}
+
+		gtx.Constraints.Max.X = max(gtx.Constraints.Max.X-width, 0)
+		textDims := text(gtx, index)
+
+		left := gtx.Constraints.Max.X
+		radius := gtx.Dp(3)
+		for _, label := range labels {
+			top := (textDims.Size.Y - label.dims.Size.Y) / 2
+			stack := op.Offset(image.Pt(left, top)).Push(gtx.Ops)
+			paint.FillShape(gtx.Ops, label.bg, clip.UniformRRect(image.Rectangle{Max: label.dims.Size}, radius).Op(gtx.Ops))
+			label.call.Add(gtx.Ops)
+			stack.Pop()
+			left += label.dims.Size.X + gtx.Dp(4)
+		}
+
+		textDims.Size.X = gtx.Constraints.Max.X + width
+		return textDims
+	}
+}
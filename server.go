@@ -1,68 +1,233 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	_ "embed"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
-	"regexp"
+	"os"
+	"runtime/debug"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/gameformush/goasm-vscode/internal/coverage"
 	"github.com/gameformush/goasm-vscode/internal/disasm"
 	"github.com/gameformush/goasm-vscode/internal/goobj"
-	"github.com/gameformush/goasm-vscode/internal/wasmobj"
 	"github.com/gorilla/mux"
+	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/rs/cors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
 // Server handles HTTP requests for disassembly operations
 type Server struct {
 	// activeFiles maps file paths to loaded disasm.File instances
-	activeFiles      map[string]disasm.File
+	activeFiles      map[string]*activeFile
 	activeFilesMutex sync.RWMutex
+	// fileTTL unloads files that weren't accessed for this long, 0 disables
+	fileTTL      time.Duration
+	filesEvicted atomic.Int64
+	// stop ends the background work of the server
+	stop     chan struct{}
+	stopOnce sync.Once
 
 	// Options for disassembly
 	options disasm.Options
 
+	// minContext and maxContext limit the context requested by clients
+	minContext int
+	maxContext int
+
 	// HTTP server
 	httpServer *http.Server
+	// grpcServer serves the gRPC API, nil when disabled
+	grpcServer *grpc.Server
+
+	// service implements the operations of the REST and the gRPC API
+	service *serviceImpl
+
+	// logger is used for all server log output
+	logger *slog.Logger
+
+	// funcCache contains recently disassembled functions, nil when disabled
+	funcCache   *lru.Cache[string, *disasm.Code]
+	cacheSize   int
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+
+	// callIndex contains the callers of each function per file, built in the background
+	callIndex callIndexes
+
+	// watches reload the files of WatchFunctions streams when they change
+	watches fileWatches
+
+	// uploads are the temporary files of uploaded binaries, removed when closed
+	uploads uploads
+
+	// maxRequestBytes limits the body of JSON requests and WebSocket messages, 0 disables the limit
+	maxRequestBytes int64
+
+	// corsOrigins are the origins allowed to access the API from a browser
+	corsOrigins []string
+
+	// inFlight counts the handlers and background work using the loaded files,
+	// Shutdown waits for them before closing the files
+	inFlight sync.WaitGroup
+
+	// semaphore limits concurrent disassemblies, nil when unlimited
+	semaphore      chan struct{}
+	queueTimeout   time.Duration
+	queueDepth     atomic.Int64
+	busyRejections atomic.Int64
+}
+
+// ServerConfig configures the server
+type ServerConfig struct {
+	// Context is the default number of source context lines
+	Context int
+	// MinContext and MaxContext limit the context requested by clients
+	MinContext int
+	MaxContext int
+
+	// CacheSize is the number of disassembled functions to keep, 0 disables the cache
+	CacheSize int
+
+	// FileTTL unloads files loaded by clients that weren't accessed for this long, 0 disables
+	FileTTL time.Duration
+
+	// Logger is used for all log output, nil uses slog.Default()
+	Logger *slog.Logger
+
+	// MaxUploadBytes limits the body of every request, MaxRequestBytes the body
+	// of requests with a JSON body, 0 disables the limit
+	MaxUploadBytes  int64
+	MaxRequestBytes int64
+
+	// MaxConcurrent limits the functions disassembled at the same time, 0 disables the limit
+	MaxConcurrent int
+	// QueueTimeout is how long a request waits for a free slot before failing with 503
+	QueueTimeout time.Duration
+
+	// TLSCertFile and TLSKeyFile enable https with the certificate and its private key
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile requires clients to present a certificate signed by one of its CAs
+	TLSClientCAFile string
+
+	// GRPCAddr serves the gRPC API on this address with the same TLS config, empty disables
+	GRPCAddr string
+
+	// CORSOrigins are the origins allowed to access the API from a browser,
+	// nil allows every origin, see defaultCORSOrigins
+	CORSOrigins []string
+	// CORSNoCredentials disables sending cookies and client certificates cross-origin
+	CORSNoCredentials bool
+}
+
+// defaultCORSOrigins allows every origin, including VS Code webviews
+var defaultCORSOrigins = []string{"*", "vscode-webview://*"}
+
+// tlsConfig creates the TLS config of the server, nil when TLS is disabled
+func (config ServerConfig) tlsConfig() (*tls.Config, error) {
+	if config.TLSCertFile == "" && config.TLSClientCAFile == "" {
+		return nil, nil
+	}
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, fmt.Errorf("TLS requires both a certificate and a key")
+	}
+	cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if config.TLSClientCAFile != "" {
+		data, err := os.ReadFile(config.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
 }
 
 // NewServer creates a new HTTP server for disassembly operations
-func NewServer(context int) *Server {
-	return &Server{
-		activeFiles: make(map[string]disasm.File),
+func NewServer(config ServerConfig) *Server {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	server := &Server{
+		activeFiles: make(map[string]*activeFile),
+		fileTTL:     config.FileTTL,
+		stop:        make(chan struct{}),
 		options: disasm.Options{
-			Context: context,
+			Context: config.Context,
 		},
+		minContext: config.MinContext,
+		maxContext: config.MaxContext,
+		logger:     logger,
+		cacheSize:  config.CacheSize,
+
+		maxRequestBytes: config.MaxRequestBytes,
+		corsOrigins:     config.CORSOrigins,
+		queueTimeout:    config.QueueTimeout,
+	}
+	if server.corsOrigins == nil {
+		server.corsOrigins = defaultCORSOrigins
 	}
+	server.service = &serviceImpl{server: server}
+	if config.MaxConcurrent > 0 {
+		server.semaphore = make(chan struct{}, config.MaxConcurrent)
+	}
+	if config.CacheSize > 0 {
+		// New only fails for non-positive sizes
+		server.funcCache, _ = lru.New[string, *disasm.Code](config.CacheSize)
+	}
+	return server
 }
 
 // StartServer starts the HTTP server on the specified address and returns the server instance
 // The server runs in a goroutine and gracefully shuts down on SIGTERM
-func StartServer(addr string, lineContext int) *Server {
-	server := NewServer(lineContext)
-
-	// Create a new router using Gorilla Mux
-	r := mux.NewRouter()
-
-	// Set up middleware
-	r.Use(loggingMiddleware)
-
-	// API routes
-	r.HandleFunc("/api/files", server.handleFiles).Methods("GET", "POST")
-	r.HandleFunc("/api/files/{path:.+}", server.handleFileOperations).Methods("DELETE")
-	r.HandleFunc("/api/functions", server.handleFunctions).Methods("GET")
-	r.HandleFunc("/api/functions/{name:.+}", server.handleFunctionOperations).Methods("GET")
+func StartServer(addr string, config ServerConfig) *Server {
+	server := NewServer(config)
+	r := server.newRouter(config)
 
 	// Create a CORS handler with the rs/cors package
+	origins := server.corsOrigins
+	server.logger.Info("cors", "origins", origins, "credentials", !config.CORSNoCredentials)
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*", "vscode-webview://*"}, // All origins including VS Code webviews
+		AllowedOrigins:   origins,
 		AllowedMethods:   []string{"GET", "POST", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Accept", "Authorization", "X-Requested-With", "Origin"},
-		AllowCredentials: true,
+		AllowCredentials: !config.CORSNoCredentials,
 		MaxAge:           86400, // Maximum value not ignored by any major browser (1 day)
 		Debug:            true,  // Enable debugging for troubleshooting
 		// Set the Vary header to tell browsers to cache responses based on Origin header
@@ -73,10 +238,17 @@ func StartServer(addr string, lineContext int) *Server {
 	// Wrap the router with the CORS handler
 	handler := c.Handler(r)
 
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		server.logger.Error("invalid TLS configuration", "err", err)
+		os.Exit(1)
+	}
+
 	// Create HTTP server
 	server.httpServer = &http.Server{
-		Addr:    addr,
-		Handler: handler,
+		Addr:      addr,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
 	}
 
 	// Channel to signal when server is ready
@@ -84,40 +256,433 @@ func StartServer(addr string, lineContext int) *Server {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on %s", addr)
+		server.logger.Info("starting server", "addr", addr, "tls", tlsConfig != nil)
 		serverReady <- struct{}{} // Signal that server is starting
 
-		if err := server.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+		var err error
+		if tlsConfig != nil {
+			// the certificates are already in TLSConfig
+			err = server.httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = server.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			server.logger.Error("server error", "err", err)
+			os.Exit(1)
 		}
 	}()
 
 	// Wait for server to start
 	<-serverReady
 
+	if config.GRPCAddr != "" {
+		if err := server.startGRPC(config.GRPCAddr, tlsConfig); err != nil {
+			server.logger.Error("gRPC server error", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	if server.fileTTL > 0 {
+		go server.evictIdleFiles()
+	}
+
 	return server
 }
 
-// loggingMiddleware logs all requests with their paths and methods
-func loggingMiddleware(next http.Handler) http.Handler {
+// newRouter creates the router of all endpoints with their middleware
+func (s *Server) newRouter(config ServerConfig) *mux.Router {
+	// Create a new router using Gorilla Mux
+	// Keep the double slash of absolute paths in /files/{path}, cleaning it redirects the request
+	r := mux.NewRouter().SkipClean(true)
+
+	// Set up middleware
+	r.Use(s.inFlightMiddleware)
+	r.Use(s.loggingMiddleware)
+	r.Use(s.recoveryMiddleware)
+	r.Use(maxBodyMiddleware(config.MaxUploadBytes))
+	r.Use(tracingMiddleware)
+
+	// API routes, v2 is registered first since /api is a prefix of /api/v2
+	s.registerRoutes(r.PathPrefix("/api/v2").Subrouter())
+	s.registerRoutes(r.PathPrefix("/api").Subrouter())
+	r.HandleFunc("/api/version", handleVersion).Methods("GET")
+	r.HandleFunc("/api/cache/stats", s.handleCacheStats).Methods("GET")
+	r.HandleFunc("/api/openapi.json", handleOpenAPI).Methods("GET")
+	r.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
+	registerWebUI(r)
+	return r
+}
+
+// registerRoutes adds the API handlers to r
+// The same handlers serve all versions, see apiVersion
+func (s *Server) registerRoutes(r *mux.Router) {
+	limitJSON := maxBodyMiddleware(s.maxRequestBytes)
+	r.Handle("/files", limitJSON(http.HandlerFunc(s.handleFiles))).Methods("GET", "POST")
+	r.HandleFunc("/files/{path:.+}", s.handleFileOperations).Methods("DELETE")
+	r.HandleFunc("/upload", s.handleUpload).Methods("POST")
+	r.HandleFunc("/functions", s.handleFunctions).Methods("GET")
+	r.Handle("/functions/bulk", maxBodyMiddleware(s.bulkRequestBytes())(http.HandlerFunc(s.handleFunctionsBulk))).Methods("POST")
+	r.HandleFunc("/functions/{name:.+}/meta", s.handleFunctionMeta).Methods("GET")
+	r.HandleFunc("/functions/{name:.+}/callers", s.handleFunctionCallers).Methods("GET")
+	r.HandleFunc("/functions/{name:.+}/timing", s.handleFunctionTiming).Methods("GET")
+	r.HandleFunc("/functions/{name:.+}", s.handleFunctionOperations).Methods("GET")
+	r.HandleFunc("/symbols", s.handleSymbols).Methods("GET")
+	r.HandleFunc("/packages", s.handlePackages).Methods("GET")
+	r.HandleFunc("/packages/sizes", s.handlePackageSizes).Methods("GET")
+	r.HandleFunc("/itabs", s.handleItabs).Methods("GET")
+	r.HandleFunc("/resolve", s.handleResolve).Methods("GET")
+	// coverage profiles aren't JSON, they are only limited like uploads
+	r.HandleFunc("/coverage", s.handleCoverage).Methods("GET", "POST")
+	r.HandleFunc("/ws", s.handleWebSocket).Methods("GET")
+}
+
+// apiVersions lists the supported API versions, oldest first
+var apiVersions = []string{"v1", "v2"}
+
+// apiVersion returns the API version used by the request
+func apiVersion(r *http.Request) int {
+	if strings.HasPrefix(r.URL.Path, "/api/v2/") {
+		return 2
+	}
+	return 1
+}
+
+// handleVersion lists the supported API versions
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, http.StatusOK, map[string]interface{}{
+		"versions": apiVersions,
+	})
+}
+
+// ListMeta describes the page returned by a v2 list endpoint
+type ListMeta struct {
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// writeList encodes items under key
+// For v2 requests the items are paginated with the offset and limit
+// query parameters and the response contains ListMeta under "meta"
+func writeList[T any](w http.ResponseWriter, r *http.Request, key string, items []T) {
+	response := map[string]interface{}{}
+	if apiVersion(r) >= 2 {
+		query := r.URL.Query()
+		meta := ListMeta{Total: len(items)}
+		for param, value := range map[string]*int{"offset": &meta.Offset, "limit": &meta.Limit} {
+			if str := query.Get(param); str != "" {
+				v, err := strconv.Atoi(str)
+				if err != nil || v < 0 {
+					writeError(w, fmt.Sprintf("Invalid %s value", param), http.StatusBadRequest)
+					return
+				}
+				*value = v
+			}
+		}
+
+		from := min(meta.Offset, len(items))
+		to := len(items)
+		if meta.Limit > 0 {
+			to = min(from+meta.Limit, len(items))
+		}
+		items = items[from:to]
+		response["meta"] = meta
+	}
+	response[key] = items
+
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+// loggingMiddleware logs all requests with their method, path, status and duration
+// The request ID is taken from the X-Request-ID header or generated
+func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s", r.Method, r.RequestURI)
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		}
+		s.logger.Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start),
+			"requestID", requestID,
+		)
+	})
+}
+
+// inFlightMiddleware tracks running handlers for Shutdown
+func (s *Server) inFlightMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recoveryMiddleware turns a panic in a handler into a 500 response instead of crashing the server
+// The response contains the request ID of the logged stack trace
+func (s *Server) recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				// aborting the response is intended, see http.ErrAbortHandler
+				panic(err)
+			}
+			requestID := w.Header().Get("X-Request-ID")
+			s.logger.Error("panic in handler",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"requestID", requestID,
+				"err", err,
+				"stack", string(debug.Stack()),
+			)
+
+			writeError(w, "internal server error", http.StatusInternalServerError)
+		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
-func (s *Server) addFile(path string, file disasm.File) {
+// maxBodyMiddleware rejects request bodies larger than maxBytes with 413
+// Bodies without a Content-Length are cut off at the limit, see decodeJSONBody
+// A limit of 0 disables the middleware
+func maxBodyMiddleware(maxBytes int64) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				writeBodyTooLarge(w, maxBytes)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ServerError is the JSON body of all error responses
+// RequestID is the X-Request-ID of the logged request
+type ServerError struct {
+	Code      int    `json:"code"`
+	Message   string `json:"error"`
+	RequestID string `json:"id,omitempty"`
+}
+
+func (err *ServerError) Error() string {
+	if err.Code == 0 {
+		return "server error: " + err.Message
+	}
+	return fmt.Sprintf("server error (status %d): %s", err.Code, err.Message)
+}
+
+// writeJSON responds with status and v encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with status and a ServerError containing message
+// It replaces http.Error, with the same arguments
+func writeError(w http.ResponseWriter, message string, status int) {
+	writeJSON(w, status, newServerError(w, message, status))
+}
+
+// newServerError creates the error response with the request ID of w
+func newServerError(w http.ResponseWriter, message string, status int) ServerError {
+	return ServerError{Code: status, Message: message, RequestID: w.Header().Get("X-Request-ID")}
+}
+
+// writeBodyTooLarge responds with 413 and the exceeded limit
+func writeBodyTooLarge(w http.ResponseWriter, limit int64) {
+	writeJSON(w, http.StatusRequestEntityTooLarge, struct {
+		ServerError
+		Limit int64 `json:"limit"`
+	}{newServerError(w, "request too large", http.StatusRequestEntityTooLarge), limit})
+}
+
+// decodeJSONBody decodes the request body into v and reports errors to the client
+// It returns false when the request was answered with an error
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeBodyTooLarge(w, tooLarge.Limit)
+		return false
+	}
+	writeError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	return false
+}
+
+// tracingMiddleware creates a span for every request, continuing the
+// trace from the traceparent header when present
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tmpl, err := route.GetPathTemplate(); err == nil {
+				name = tmpl
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.target", r.URL.RequestURI()),
+			),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.status_code", rec.status))
+		if rec.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack allows upgrading the connection, e.g. for WebSockets
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rec.status = http.StatusSwitchingProtocols
+	return http.NewResponseController(rec.ResponseWriter).Hijack()
+}
+
+// newRequestID returns a random identifier for correlating log lines
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// activeFile is a loaded file and when it was last used
+type activeFile struct {
+	file         disasm.File
+	lastAccessed time.Time
+	// loaded is when the file was loaded
+	loaded time.Time
+	// pinned files are never evicted, e.g. the file given on the command line
+	pinned bool
+	// coverage is the uploaded coverage profile, nil when there's none
+	coverage *coverage.Profile
+}
+
+// addFile stores the file loaded from path, replacing a previous one
+// Pinned files aren't evicted after the file TTL. The coverage of a replaced
+// file is kept, since it's by source line
+func (s *Server) addFile(path string, file disasm.File, pinned bool) {
 	s.activeFilesMutex.Lock()
-	s.activeFiles[path] = file
+	var profile *coverage.Profile
+	if previous, ok := s.activeFiles[path]; ok {
+		profile = previous.coverage
+	}
+	now := time.Now()
+	s.activeFiles[path] = &activeFile{file: file, lastAccessed: now, loaded: now, pinned: pinned, coverage: profile}
 	s.activeFilesMutex.Unlock()
+	s.invalidateCache(path)
+	s.startCallIndex(path, file)
+}
+
+// getFile returns the loaded file at path and marks it as accessed
+func (s *Server) getFile(path string) (disasm.File, bool) {
+	s.activeFilesMutex.Lock()
+	defer s.activeFilesMutex.Unlock()
+	active, ok := s.activeFiles[path]
+	if !ok {
+		return nil, false
+	}
+	active.lastAccessed = time.Now()
+	return active.file, true
+}
+
+// unloadFile releases everything of the file at path after it was removed from activeFiles
+func (s *Server) unloadFile(path string, file disasm.File) {
+	s.invalidateCache(path)
+	s.dropCallIndex(path)
+	if err := file.Close(); err != nil {
+		s.logger.Warn("error closing file", "path", path, "err", err)
+	}
+	s.removeUpload(path)
 }
 
 // Shutdown gracefully shuts down the server
+// It stops accepting requests, waits for the running handlers, including
+// WebSocket connections, and then closes the loaded files
+// The files stay open when ctx is done before the handlers finished
 func (s *Server) Shutdown(ctx context.Context) error {
+	var err error
 	if s.httpServer != nil {
-		return s.httpServer.Shutdown(ctx)
+		err = s.httpServer.Shutdown(ctx)
 	}
-	return nil
+
+	s.stopOnce.Do(func() { close(s.stop) })
+	// streams end when stop is closed
+	s.stopGRPC(ctx)
+
+	s.callIndex.mu.Lock()
+	for path, index := range s.callIndex.byPath {
+		close(index.stop)
+		delete(s.callIndex.byPath, path)
+	}
+	s.callIndex.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for in-flight requests: %w", ctx.Err())
+	}
+
+	s.activeFilesMutex.Lock()
+	defer s.activeFilesMutex.Unlock()
+	for path, active := range s.activeFiles {
+		if closeErr := active.file.Close(); closeErr != nil {
+			s.logger.Warn("error closing file", "path", path, "err", closeErr)
+		}
+		delete(s.activeFiles, path)
+		s.removeUpload(path)
+	}
+	return err
 }
 
 // handleFiles handles operations on the collection of files
@@ -130,65 +695,92 @@ func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
 			Path string `json:"path"`
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		if !decodeJSONBody(w, r, &req) {
 			return
 		}
 
-		if req.Path == "" {
-			http.Error(w, "Path is required", http.StatusBadRequest)
+		created, err := s.service.LoadFile(r.Context(), req.Path)
+		if err != nil {
+			writeServiceError(w, err)
 			return
 		}
-
-		// Check if we already have this file loaded
-		s.activeFilesMutex.RLock()
-		_, exists := s.activeFiles[req.Path]
-		s.activeFilesMutex.RUnlock()
-
-		if exists {
+		if !created {
 			// File already loaded
 			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		// Load the file
-		var file disasm.File
-		var err error
+		w.WriteHeader(http.StatusCreated)
 
-		if workInProgressWASM && isWasmFile(req.Path) {
-			file, err = wasmobj.Load(req.Path)
-		} else {
-			file, err = goobj.Load(req.Path)
-		}
+	case http.MethodGet:
+		// List all loaded files
+		writeList(w, r, "files", s.service.ListFiles())
 
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to load file: %v", err), http.StatusInternalServerError)
-			return
-		}
+	default:
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
 
-		// Store the file
-		s.activeFilesMutex.Lock()
-		s.activeFiles[req.Path] = file
-		s.activeFilesMutex.Unlock()
+// loadFile loads the file at path unless it's already loaded
+// It returns whether the file was newly loaded
+func (s *Server) loadFile(ctx context.Context, path string) (bool, error) {
+	// Check if we already have this file loaded
+	_, exists := s.getFile(path)
 
-		w.WriteHeader(http.StatusCreated)
+	if exists {
+		return false, nil
+	}
 
-	case http.MethodGet:
-		// List all loaded files
-		s.activeFilesMutex.RLock()
-		files := make([]string, 0, len(s.activeFiles))
-		for path := range s.activeFiles {
-			files = append(files, path)
-		}
-		s.activeFilesMutex.RUnlock()
+	file, err := openFile(ctx, path)
+	if err != nil {
+		return false, err
+	}
 
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"files": files,
-		})
+	// Store the file
+	s.addFile(path, file, false)
+	return true, nil
+}
 
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// errFileUnloaded is returned by reloadFile for files that aren't loaded anymore
+var errFileUnloaded = errors.New("file was unloaded")
+
+// reloadFile loads the file at path again after it changed on disk
+// The loaded file is replaced and closed
+func (s *Server) reloadFile(ctx context.Context, path string) (disasm.File, error) {
+	file, err := openFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activeFilesMutex.Lock()
+	active, ok := s.activeFiles[path]
+	var previous disasm.File
+	if ok {
+		previous = active.file
+		active.file = file
+		active.lastAccessed = time.Now()
 	}
+	s.activeFilesMutex.Unlock()
+	if !ok {
+		_ = file.Close()
+		return nil, errFileUnloaded
+	}
+
+	s.invalidateCache(path)
+	s.startCallIndex(path, file)
+	if err := previous.Close(); err != nil {
+		s.logger.Warn("error closing file", "path", path, "err", err)
+	}
+	return file, nil
+}
+
+// openFile loads the binary at path
+func openFile(ctx context.Context, path string) (disasm.File, error) {
+	ctx, span := tracer.Start(ctx, "file.load", trace.WithAttributes(attribute.String("file.path", path)))
+	file, err := goobj.LoadContext(ctx, path)
+	setSpanError(span, err)
+	span.End()
+	return file, err
 }
 
 // handleFileOperations handles operations on a specific file
@@ -199,27 +791,24 @@ func (s *Server) handleFileOperations(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	path := vars["path"]
 	if path == "" {
-		http.Error(w, "File path is required", http.StatusBadRequest)
+		writeError(w, "File path is required", http.StatusBadRequest)
 		return
 	}
 
 	// Only handle DELETE method (others are configured in the router)
 	// Close and remove a file
 	s.activeFilesMutex.Lock()
-	file, exists := s.activeFiles[path]
+	active, exists := s.activeFiles[path]
 	if exists {
 		delete(s.activeFiles, path)
 	}
 	s.activeFilesMutex.Unlock()
 
 	if !exists {
-		http.Error(w, "File not found", http.StatusNotFound)
+		writeError(w, "File not found", http.StatusNotFound)
 		return
 	}
-
-	if err := file.Close(); err != nil {
-		log.Printf("Error closing file %s: %v", path, err)
-	}
+	s.unloadFile(path, active.file)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -229,59 +818,163 @@ func (s *Server) handleFunctions(w http.ResponseWriter, r *http.Request) {
 	// OPTIONS requests should be handled before this function is called
 
 	// Get query parameters
+	query := r.URL.Query()
+	funcs, err := s.service.ListFunctions(query.Get("file"), query.Get("filter"), query.Get("kind"), query.Get("source"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeList(w, r, "functions", funcs)
+}
+
+// handleSymbols lists the symbol table of a file
+func (s *Server) handleSymbols(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	path := query.Get("file")
-	filter := query.Get("filter")
+	symType := query.Get("type")
+	exportedStr := query.Get("exported")
 
 	if path == "" {
-		http.Error(w, "File path is required", http.StatusBadRequest)
+		writeError(w, "File path is required", http.StatusBadRequest)
 		return
 	}
 
+	var exportedOnly bool
+	if exportedStr != "" {
+		var err error
+		exportedOnly, err = strconv.ParseBool(exportedStr)
+		if err != nil {
+			writeError(w, "Invalid exported value", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Get the file
-	s.activeFilesMutex.RLock()
-	file, exists := s.activeFiles[path]
-	s.activeFilesMutex.RUnlock()
+	file, exists := s.getFile(path)
 
 	if !exists {
-		http.Error(w, "File not found", http.StatusNotFound)
+		writeError(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	// Get all functions
-	funcs := file.Funcs()
-
-	// Filter functions if a filter is provided
-	var filteredFuncs []FunctionInfo
-	if filter != "" {
-		rx, err := regexp.Compile(filter)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Invalid filter regex: %v", err), http.StatusBadRequest)
-			return
+	symbols := []SymbolInfo{}
+	for _, sym := range file.SymbolTable() {
+		if symType != "" && sym.Type != symType {
+			continue
 		}
-
-		for _, fn := range funcs {
-			if rx.MatchString(fn.Name()) {
-				filteredFuncs = append(filteredFuncs, FunctionInfo{
-					Name: fn.Name(),
-				})
-			}
+		if exportedOnly && !sym.Exported {
+			continue
 		}
-	} else {
-		// No filter, return all functions
-		filteredFuncs = make([]FunctionInfo, len(funcs))
-		for i, fn := range funcs {
-			filteredFuncs[i] = FunctionInfo{
-				Name: fn.Name(),
-			}
+		symbols = append(symbols, SymbolInfo{
+			Name:     sym.Name,
+			Type:     sym.Type,
+			Size:     sym.Size,
+			Address:  sym.Address,
+			Exported: sym.Exported,
+		})
+	}
+
+	writeList(w, r, "symbols", symbols)
+}
+
+// handleItabs lists the interface method tables of a file
+func (s *Server) handleItabs(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get the file
+	file, exists := s.getFile(path)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	if _, ok := file.(*goobj.File); !ok {
+		writeError(w, "Itabs are not supported for this file", http.StatusNotImplemented)
+		return
+	}
+	itabs, err := goobj.ParseItabs(file)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to read itabs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]ItabInfo, len(itabs))
+	for i, itab := range itabs {
+		infos[i] = ItabInfo{
+			InterfaceType: itab.InterfaceType,
+			ConcreteType:  itab.ConcreteType,
+			Methods:       itab.Methods,
 		}
 	}
+	writeList(w, r, "itabs", infos)
+}
 
-	// Set content type and encode the response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"functions": filteredFuncs,
+// handlePackages lists the packages of the functions in a file
+func (s *Server) handlePackages(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get the file
+	file, exists := s.getFile(path)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	packages := []PackageInfo{}
+	for name, funcs := range file.PackageFuncs() {
+		packages = append(packages, PackageInfo{
+			Name:      name,
+			Functions: len(funcs),
+		})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
 	})
+
+	writeList(w, r, "packages", packages)
+}
+
+// handlePackageSizes sums the code size of the functions per package, largest first
+func (s *Server) handlePackageSizes(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get the file
+	file, exists := s.getFile(path)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	sizes, total := disasm.PackageSizes(file)
+	breakdown := SizeBreakdown{
+		Packages: make([]PackageSize, len(sizes)),
+		Total:    total,
+	}
+	for i, pkg := range sizes {
+		breakdown.Packages[i] = PackageSize{
+			Name:          pkg.Name,
+			ByteSize:      pkg.Size,
+			FunctionCount: pkg.Funcs,
+		}
+	}
+
+	writeResponse(w, r, http.StatusOK, breakdown)
 }
 
 // handleFunctionOperations handles operations on a specific function
@@ -291,74 +984,277 @@ func (s *Server) handleFunctionOperations(w http.ResponseWriter, r *http.Request
 	// Extract the function name from the URL using Gorilla Mux vars
 	vars := mux.Vars(r)
 	functionName := vars["name"]
-	if functionName == "" {
-		http.Error(w, "Function name is required", http.StatusBadRequest)
-		return
-	}
 
 	// Get query parameters
 	query := r.URL.Query()
 	path := query.Get("file")
 	contextStr := query.Get("context")
 
+	// Override the server default context if provided
+	var context *int
+	if contextStr != "" {
+		value, err := strconv.Atoi(contextStr)
+		if err != nil {
+			writeError(w, "Invalid context value", http.StatusBadRequest)
+			return
+		}
+		context = &value
+	}
+
+	code, err := s.service.DisassembleFunction(r.Context(), path, functionName, context)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Encode the response as requested by the Accept header
+	writeResponse(w, r, http.StatusOK, newCodeResponse(code, s.fileCoverage(path)))
+}
+
+// handleFunctionMeta returns the metadata and complexity metrics of a function
+func (s *Server) handleFunctionMeta(w http.ResponseWriter, r *http.Request) {
+	functionName := mux.Vars(r)["name"]
+	path := r.URL.Query().Get("file")
 	if path == "" {
-		http.Error(w, "File path is required", http.StatusBadRequest)
+		writeError(w, "File path is required", http.StatusBadRequest)
 		return
 	}
 
 	// Get the file
-	s.activeFilesMutex.RLock()
-	file, exists := s.activeFiles[path]
-	s.activeFilesMutex.RUnlock()
+	file, exists := s.getFile(path)
 
 	if !exists {
-		http.Error(w, "File not found", http.StatusNotFound)
+		writeError(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	// Find the function
-	funcs := file.Funcs()
 	var targetFunc disasm.Func
-	for _, fn := range funcs {
+	for _, fn := range file.Funcs() {
 		if fn.Name() == functionName {
 			targetFunc = fn
 			break
 		}
 	}
-
 	if targetFunc == nil {
-		http.Error(w, "Function not found", http.StatusNotFound)
+		writeError(w, "Function not found", http.StatusNotFound)
+		return
+	}
+
+	meta := FunctionMeta{
+		Name:    targetFunc.Name(),
+		Kind:    disasm.KindOf(targetFunc.Name()).String(),
+		Package: disasm.PackageOf(targetFunc.Name()),
+	}
+	if sized, ok := targetFunc.(interface{ Size() int64 }); ok {
+		meta.Size = sized.Size()
+	}
+	if frame, ok := targetFunc.(interface{ FrameSize() int }); ok {
+		meta.FrameSize = frame.FrameSize()
+	}
+	if err := s.acquireSlot(r.Context()); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	defer s.releaseSlot()
+	if code := s.loadCode(r.Context(), path, targetFunc, s.options); code != nil {
+		meta.InstructionCount = code.InstructionCount
+		meta.CallCount = code.CallCount
+		meta.CyclomaticComplexity = code.CyclomaticComplexity
+		for _, directive := range goobj.ParseDirectives(code.File, targetFunc.Name()) {
+			meta.Directives = append(meta.Directives, DirectiveInfo{
+				Kind:  directive.Kind.String(),
+				Value: directive.Value,
+			})
+		}
+	}
+
+	writeResponse(w, r, http.StatusOK, meta)
+}
+
+// handleResolve maps a program counter to its source position
+func (s *Server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	path := query.Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+	// base 0 accepts both 0x10a3f2 and decimal addresses
+	pc, err := strconv.ParseUint(query.Get("pc"), 0, 64)
+	if err != nil {
+		writeError(w, "Invalid pc value", http.StatusBadRequest)
+		return
+	}
+
+	// Get the file
+	file, exists := s.getFile(path)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	resolver, ok := file.(interface {
+		ResolvePC(pc uint64) (disasm.Location, bool)
+	})
+	if !ok {
+		writeError(w, "Resolving addresses is not supported for this file", http.StatusNotImplemented)
+		return
+	}
+	loc, ok := resolver.ResolvePC(pc)
+	if !ok {
+		writeError(w, "No function at pc", http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, PCResolution{
+		SourceFile: loc.File,
+		Line:       loc.Line,
+		FuncName:   loc.Func,
+	})
+}
+
+// checkContext verifies that the requested context is within the server limits
+func (s *Server) checkContext(context int) error {
+	if context < s.minContext || context > s.maxContext {
+		return fmt.Errorf("context must be between %d and %d", s.minContext, s.maxContext)
+	}
+	return nil
+}
+
+const (
+	// maxBulkFunctions limits the number of functions in a bulk request
+	maxBulkFunctions = 50
+	// maxBulkRequestBytes is the smallest body limit of bulk requests,
+	// enough for maxBulkFunctions long function names, see bulkRequestBytes
+	maxBulkRequestBytes = 64 << 10
+	// bulkConcurrency limits the number of functions disassembled in parallel
+	bulkConcurrency = 8
+)
+
+// BulkResult is the result for a single function of a bulk request
+type BulkResult struct {
+	Name  string        `json:"name"`
+	Code  *CodeResponse `json:"code,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// bulkRequestBytes returns the body limit of bulk requests, 0 when
+// the limit is disabled
+func (s *Server) bulkRequestBytes() int64 {
+	if s.maxRequestBytes <= 0 {
+		return 0
+	}
+	return max(s.maxRequestBytes, maxBulkRequestBytes)
+}
+
+// handleFunctionsBulk disassembles multiple functions with a single request
+func (s *Server) handleFunctionsBulk(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		File    string   `json:"file"`
+		Names   []string `json:"names"`
+		Context *int     `json:"context"`
+	}
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.File == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Names) > maxBulkFunctions {
+		writeError(w, fmt.Sprintf("Too many functions, at most %d are allowed", maxBulkFunctions), http.StatusBadRequest)
+		return
+	}
+
+	// Get the file
+	file, exists := s.getFile(req.File)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	// Set context if provided
 	options := s.options
-	if contextStr != "" {
-		context, err := strconv.Atoi(contextStr)
-		if err != nil {
-			http.Error(w, "Invalid context value", http.StatusBadRequest)
+	if req.Context != nil {
+		if err := s.checkContext(*req.Context); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		options.Context = context
+		options.Context = *req.Context
 	}
 
-	// Load the function code
-	code := targetFunc.Load(options)
-	if code == nil {
-		http.Error(w, "Failed to load function code", http.StatusInternalServerError)
-		return
+	funcs := make(map[string]disasm.Func, len(req.Names))
+	for _, fn := range file.Funcs() {
+		funcs[fn.Name()] = fn
+	}
+
+	results := make([]BulkResult, len(req.Names))
+	var g errgroup.Group
+	g.SetLimit(bulkConcurrency)
+	for i, name := range req.Names {
+		results[i].Name = name
+		fn, ok := funcs[name]
+		if !ok {
+			results[i].Error = "function not found"
+			continue
+		}
+		g.Go(func() error {
+			// the recovery middleware doesn't cover the goroutines of the group
+			defer func() {
+				if err := recover(); err != nil {
+					s.logger.Error("panic in bulk disassembly",
+						"path", req.File,
+						"func", name,
+						"requestID", w.Header().Get("X-Request-ID"),
+						"err", err,
+						"stack", string(debug.Stack()),
+					)
+					results[i].Code = nil
+					results[i].Error = "internal server error"
+				}
+			}()
+
+			if err := s.acquireSlot(r.Context()); err != nil {
+				results[i].Error = err.Error()
+				return nil
+			}
+			defer s.releaseSlot()
+			code := s.loadCode(r.Context(), req.File, fn, options)
+			if code == nil {
+				results[i].Error = "failed to load function code"
+				return nil
+			}
+			response := newCodeResponse(code, s.fileCoverage(req.File))
+			results[i].Code = &response
+			return nil
+		})
 	}
+	_ = g.Wait()
 
-	// Convert to response format
+	writeResponse(w, r, http.StatusOK, map[string]interface{}{
+		"results": results,
+	})
+}
+
+// newCodeResponse converts the disassembled code to the API response format
+// The instructions are marked covered with profile, when not nil
+func newCodeResponse(code *disasm.Code, profile *coverage.Profile) CodeResponse {
 	response := CodeResponse{
 		Name:         code.Name,
 		File:         code.File,
 		Instructions: make([]InstructionInfo, len(code.Insts)),
 		Sources:      make([]SourceInfo, len(code.Source)),
 		MaxJump:      code.MaxJump,
+		FrameSize:    code.FrameSize,
+		Arch:         code.Arch,
+		StackMapPCs:  code.StackMapPCs,
 	}
 
-	// Convert instructions
+	// Convert instructions with their loop annotations
+	loops := disasm.DetectLoops(code)
+	depths := disasm.LoopDepths(loops, len(code.Insts))
 	for i, inst := range code.Insts {
 		response.Instructions[i] = InstructionInfo{
 			PC:        inst.PC,
@@ -369,13 +1265,34 @@ func (s *Server) handleFunctionOperations(w http.ResponseWriter, r *http.Request
 			RefOffset: inst.RefOffset,
 			RefStack:  inst.RefStack,
 			Call:      inst.Call,
+			Category:  disasm.ClassifyArch(code.Arch, inst.Text).String(),
+			Bytes:     inst.Bytes,
+			LoopDepth: depths[i],
+		}
+		if profile != nil && inst.Text != "" {
+			if covered, ok := profile.Line(inst.File, inst.Line); ok {
+				response.Instructions[i].Covered = &covered
+			}
 		}
 	}
+	for _, loop := range loops {
+		response.Instructions[loop.Header].LoopHeader = true
+	}
+	for i, alignment := range disasm.AlignmentAnnotations(code) {
+		response.Instructions[i].Alignment = alignment
+	}
+	for _, site := range disasm.DetectDefers(code) {
+		response.Defers = append(response.Defers, DeferSiteInfo{
+			PushPC:    site.PushPC,
+			ReturnPCs: site.ReturnPCs,
+		})
+	}
 
 	// Convert sources
 	for i, src := range code.Source {
 		sourceInfo := SourceInfo{
 			File:   src.File,
+			Lang:   disasm.DetectLanguage(src.File).String(),
 			Blocks: make([]SourceBlockInfo, len(src.Blocks)),
 		}
 
@@ -403,16 +1320,105 @@ func (s *Server) handleFunctionOperations(w http.ResponseWriter, r *http.Request
 		response.Sources[i] = sourceInfo
 	}
 
-	// Set content type and encode the response
+	return response
+}
+
+// openAPISpec describes the API, keep it in sync with the handlers
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// handleOpenAPI serves the OpenAPI 3.0 specification of the API
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	_, _ = w.Write(openAPISpec)
 }
 
 // Response types for the API
 
+// FileInfo represents a loaded file
+// Arch and GoVersion are empty when they can't be determined
+type FileInfo struct {
+	Path      string    `json:"path"`
+	BuildID   string    `json:"buildId,omitempty"`
+	GoModPath string    `json:"goModPath,omitempty"`
+	FuncCount int       `json:"funcCount"`
+	Arch      string    `json:"arch,omitempty"`
+	GoVersion string    `json:"goVersion,omitempty"`
+	Loaded    time.Time `json:"loaded"`
+}
+
 // FunctionInfo represents a function in an object file
 type FunctionInfo struct {
-	Name string `json:"name"`
+	Name             string   `json:"name"`
+	Kind             string   `json:"kind"`
+	Package          string   `json:"package"`
+	BuildConstraints []string `json:"buildConstraints,omitempty"`
+}
+
+// PCResolution is the source position of a program counter
+// Column is 0 when unknown, which is always the case for Go binaries
+type PCResolution struct {
+	SourceFile string `json:"sourceFile"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	FuncName   string `json:"funcName"`
+}
+
+// SymbolInfo represents an entry in the symbol table of an object file
+type SymbolInfo struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Size     int64  `json:"size"`
+	Address  uint64 `json:"address"`
+	Exported bool   `json:"exported"`
+}
+
+// SizeBreakdown contains the code size of each package and of the whole file
+type SizeBreakdown struct {
+	Packages []PackageSize `json:"packages"`
+	Total    int64         `json:"total"`
+}
+
+// PackageSize is the code size of the functions in a package
+type PackageSize struct {
+	Name          string `json:"name"`
+	ByteSize      int64  `json:"byteSize"`
+	FunctionCount int    `json:"functionCount"`
+}
+
+// ItabInfo describes the method table of a concrete type used as an interface
+type ItabInfo struct {
+	InterfaceType string   `json:"interfaceType"`
+	ConcreteType  string   `json:"concreteType"`
+	Methods       []string `json:"methods"`
+}
+
+// FunctionMeta contains the metadata of a function
+type FunctionMeta struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Package   string `json:"package"`
+	Size      int64  `json:"size"`
+	FrameSize int    `json:"frameSize"`
+
+	InstructionCount     int `json:"instructionCount"`
+	CallCount            int `json:"callCount"`
+	CyclomaticComplexity int `json:"cyclomaticComplexity"`
+
+	Directives []DirectiveInfo `json:"directives,omitempty"`
+}
+
+// DirectiveInfo is a compiler directive of a function, e.g. //go:nosplit
+type DirectiveInfo struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value,omitempty"`
+}
+
+// PackageInfo represents a package with functions in an object file
+type PackageInfo struct {
+	Name      string `json:"name"`
+	Functions int    `json:"functions"`
 }
 
 // CodeResponse represents the disassembled code of a function
@@ -422,6 +1428,16 @@ type CodeResponse struct {
 	Instructions []InstructionInfo `json:"instructions"`
 	Sources      []SourceInfo      `json:"sources"`
 	MaxJump      int               `json:"maxJump"`
+	FrameSize    int               `json:"frameSize,omitempty"`
+	Arch         string            `json:"arch,omitempty"`
+	StackMapPCs  []uint64          `json:"stackMapPcs"`
+	Defers       []DeferSiteInfo   `json:"defers,omitempty"`
+}
+
+// DeferSiteInfo is a deferred call and the returns that may run it
+type DeferSiteInfo struct {
+	PushPC    uint64   `json:"pushPc"`
+	ReturnPCs []uint64 `json:"returnPcs"`
 }
 
 // InstructionInfo represents a single assembly instruction
@@ -434,11 +1450,25 @@ type InstructionInfo struct {
 	RefOffset int    `json:"refOffset"`
 	RefStack  int    `json:"refStack"`
 	Call      string `json:"call"`
+	Category  string `json:"category,omitempty"`
+	// Bytes is the encoding of the instruction, omitted when unknown
+	Bytes []byte `json:"bytes,omitempty"`
+	// Covered is whether the line of the instruction was run by the tests,
+	// omitted without coverage data for the line, see handleCoverage
+	Covered *bool `json:"covered,omitempty"`
+
+	LoopHeader bool `json:"loopHeader,omitempty"`
+	LoopDepth  int  `json:"loopDepth,omitempty"`
+	// Alignment is the boundary in bytes the instruction was aligned to,
+	// see disasm.AlignmentAnnotations
+	Alignment int `json:"alignment,omitempty"`
 }
 
 // SourceInfo represents source code from a single file
 type SourceInfo struct {
-	File   string            `json:"file"`
+	File string `json:"file"`
+	// Lang is the language of File, see disasm.SourceLang
+	Lang   string            `json:"lang,omitempty"`
 	Blocks []SourceBlockInfo `json:"blocks"`
 }
 
@@ -455,8 +1485,3 @@ type LineRangeInfo struct {
 	From int `json:"from"`
 	To   int `json:"to"`
 }
-
-// Helper function to check if a file is a WebAssembly file
-func isWasmFile(path string) bool {
-	return regexp.MustCompile(`\.wasm$`).MatchString(path)
-}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/goobj"
+)
+
+// benchFuncs are the funcs of testdata/bench by their approximate number of instructions
+var benchFuncs = []struct {
+	size string
+	name string
+}{
+	{"small", "main.small"},
+	{"medium", "main.medium"},
+	{"large", "main.large"},
+}
+
+// buildBench builds testdata/bench and returns the path of the binary
+// The test is skipped without testdata or a go toolchain
+func buildBench(tb testing.TB) string {
+	tb.Helper()
+	src := filepath.Join("testdata", "bench", "main.go")
+	if _, err := os.Stat(src); err != nil {
+		tb.Skip("no test binary source in testdata")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		tb.Skip("no go toolchain to build the test binary")
+	}
+
+	path := filepath.Join(tb.TempDir(), "bench")
+	out, err := exec.Command(goTool, "build", "-o", path, src).CombinedOutput()
+	if err != nil {
+		tb.Fatalf("build test binary: %v\n%s", err, out)
+	}
+	return path
+}
+
+// benchServer builds testdata/bench and returns a server with the binary loaded
+func benchServer(b *testing.B) (*Server, http.Handler, string) {
+	b.Helper()
+	path := buildBench(b)
+
+	// Disable both code caches so every request disassembles the function
+	cacheSize := goobj.CodeCacheSize
+	goobj.CodeCacheSize = 0
+	b.Cleanup(func() { goobj.CodeCacheSize = cacheSize })
+
+	config := ServerConfig{
+		Context: 3,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	server := NewServer(config)
+	if _, err := server.loadFile(context.Background(), path); err != nil {
+		b.Fatalf("load test binary: %v", err)
+	}
+	return server, server.newRouter(config), path
+}
+
+func functionURL(name, path string) string {
+	return "/api/functions/" + url.PathEscape(name) + "?file=" + url.QueryEscape(path)
+}
+
+func BenchmarkHandleFunctionOperations(b *testing.B) {
+	_, handler, path := benchServer(b)
+
+	b.Run("recorder", func(b *testing.B) {
+		for _, fn := range benchFuncs {
+			b.Run(fn.size, func(b *testing.B) {
+				target := functionURL(fn.name, path)
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					rec := httptest.NewRecorder()
+					handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+					if rec.Code != http.StatusOK {
+						b.Fatalf("GET %s: %d %s", target, rec.Code, rec.Body)
+					}
+				}
+			})
+		}
+	})
+
+	b.Run("server", func(b *testing.B) {
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+		client := ts.Client()
+
+		for _, fn := range benchFuncs {
+			b.Run(fn.size, func(b *testing.B) {
+				target := ts.URL + functionURL(fn.name, path)
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					resp, err := client.Get(target)
+					if err != nil {
+						b.Fatal(err)
+					}
+					_, err = io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+					if err != nil {
+						b.Fatal(err)
+					}
+					if resp.StatusCode != http.StatusOK {
+						b.Fatalf("GET %s: %s", target, resp.Status)
+					}
+				}
+			})
+		}
+	})
+}
+
+func BenchmarkEncodeFunctionCode(b *testing.B) {
+	file, err := goobj.Load(buildBench(b))
+	if err != nil {
+		b.Fatalf("load test binary: %v", err)
+	}
+	defer file.Close()
+
+	var response CodeResponse
+	for _, fn := range file.Funcs() {
+		if fn.Name() == "main.large" {
+			response = newCodeResponse(fn.Load(disasm.Options{Context: 3}), nil)
+		}
+	}
+	if len(response.Instructions) == 0 {
+		b.Fatal("main.large not found")
+	}
+
+	for _, enc := range []struct {
+		name     string
+		encoding Encoding
+	}{
+		{"json", EncodingJSON},
+		{"msgpack", EncodingMsgpack},
+	} {
+		var encoded bytes.Buffer
+		if err := enc.encoding.Encode(&encoded, &response); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(enc.name+"/encode", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ReportMetric(float64(encoded.Len()), "bytes")
+			var buf bytes.Buffer
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := enc.encoding.Encode(&buf, &response); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(enc.name+"/decode", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var decoded CodeResponse
+				if err := enc.encoding.Decode(bytes.NewReader(encoded.Bytes()), &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// funcCacheKey identifies the disassembly of a function with specific options
+func funcCacheKey(prefix, funcName string, context int) string {
+	return prefix + ":" + funcName + ":" + strconv.Itoa(context)
+}
+
+// cacheKeyPrefix returns the build ID of the file at path, or the path when it has none
+// Keys with the build ID survive reloading the file, as its content is the same
+func (s *Server) cacheKeyPrefix(path string) string {
+	file, ok := s.getFile(path)
+	if ok {
+		if id := file.BuildID(); id != "" {
+			return "buildid:" + id
+		}
+	}
+	return path
+}
+
+// loadCode disassembles fn from the file at path, using the cache when enabled
+func (s *Server) loadCode(ctx context.Context, path string, fn disasm.Func, options disasm.Options) *disasm.Code {
+	key := funcCacheKey(s.cacheKeyPrefix(path), fn.Name(), options.Context)
+	if s.funcCache != nil {
+		if code, ok := s.funcCache.Get(key); ok {
+			s.cacheHits.Add(1)
+			return code
+		}
+		s.cacheMisses.Add(1)
+	}
+
+	_, span := tracer.Start(ctx, "func.disassemble", trace.WithAttributes(
+		attribute.String("file.path", path),
+		attribute.String("func.name", fn.Name()),
+	))
+	code := fn.Load(options)
+	if code != nil {
+		span.SetAttributes(attribute.Int("func.insts", len(code.Insts)))
+	}
+	span.End()
+
+	if code != nil && s.funcCache != nil {
+		s.funcCache.Add(key, code)
+	}
+	return code
+}
+
+// invalidateCache removes all cached functions of the file at path
+// Functions of files with a build ID are kept, see cacheKeyPrefix
+func (s *Server) invalidateCache(path string) {
+	if s.funcCache == nil {
+		return
+	}
+	prefix := path + ":"
+	for _, key := range s.funcCache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			s.funcCache.Remove(key)
+		}
+	}
+}
+
+// CacheStats describes the state of the function cache
+type CacheStats struct {
+	Enabled  bool  `json:"enabled"`
+	Size     int   `json:"size"`
+	Capacity int   `json:"capacity"`
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+}
+
+// handleCacheStats reports the function cache statistics for debugging
+func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	stats := CacheStats{
+		Enabled: s.funcCache != nil,
+		Hits:    s.cacheHits.Load(),
+		Misses:  s.cacheMisses.Load(),
+	}
+	if s.funcCache != nil {
+		stats.Size = s.funcCache.Len()
+		stats.Capacity = s.cacheSize
+	}
+
+	writeResponse(w, r, http.StatusOK, stats)
+}
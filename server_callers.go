@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// maxCallerDepth limits the depth of caller lookups
+const maxCallerDepth = 10
+
+// callIndex maps callees to the set of their direct callers
+type callIndex struct {
+	// ready is closed when callers has been built
+	ready chan struct{}
+	// stop is closed when the file is removed
+	stop chan struct{}
+
+	callers map[string]map[string]bool
+}
+
+// callIndexes contains the call indexes by file path
+type callIndexes struct {
+	mu     sync.Mutex
+	byPath map[string]*callIndex
+}
+
+// CallerInfo describes a function calling the requested function
+type CallerInfo struct {
+	Name  string `json:"name"`
+	Depth int    `json:"depth"`
+}
+
+// startCallIndex builds the call index of file in the background,
+// replacing the index of a previously loaded file at path
+func (s *Server) startCallIndex(path string, file disasm.File) *callIndex {
+	index := &callIndex{
+		ready: make(chan struct{}),
+		stop:  make(chan struct{}),
+	}
+
+	s.callIndex.mu.Lock()
+	if s.callIndex.byPath == nil {
+		s.callIndex.byPath = map[string]*callIndex{}
+	}
+	if previous, ok := s.callIndex.byPath[path]; ok {
+		close(previous.stop)
+	}
+	s.callIndex.byPath[path] = index
+	s.callIndex.mu.Unlock()
+
+	s.inFlight.Add(1)
+	go func() {
+		defer s.inFlight.Done()
+
+		// acquireSlot gives up when the file is removed
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-index.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		callers := map[string]map[string]bool{}
+		for _, fn := range file.Funcs() {
+			// acquireSlot doesn't check ctx without a limit of concurrent requests
+			if ctx.Err() != nil {
+				return
+			}
+			if err := s.acquireSlot(ctx); err != nil {
+				if errors.Is(err, errServerBusy) {
+					// the next request for callers starts over
+					s.logger.Warn("call index abandoned", "path", path, "err", err)
+					s.forgetCallIndex(path, index)
+				}
+				return
+			}
+			calls := s.funcCalls(path, fn)
+			s.releaseSlot()
+
+			for _, call := range calls {
+				if callers[call] == nil {
+					callers[call] = map[string]bool{}
+				}
+				callers[call][fn.Name()] = true
+			}
+		}
+		index.callers = callers
+		close(index.ready)
+		s.logger.Debug("call index built", "path", path, "callees", len(callers))
+	}()
+	return index
+}
+
+// funcCalls returns the names of the funcs called by fn
+// Funcs of Go binaries are decoded without caching their code,
+// a panic of a malformed func is logged and leaves it without calls
+func (s *Server) funcCalls(path string, fn disasm.Func) (calls []string) {
+	defer func() {
+		if err := recover(); err != nil {
+			s.logger.Error("panic in call index",
+				"path", path,
+				"func", fn.Name(),
+				"err", err,
+				"stack", string(debug.Stack()),
+			)
+			calls = nil
+		}
+	}()
+
+	if lister, ok := fn.(interface{ Calls() []string }); ok {
+		return lister.Calls()
+	}
+	code := fn.Load(disasm.Options{})
+	if code == nil {
+		return nil
+	}
+	for _, ix := range code.Insts {
+		if ix.Call != "" {
+			calls = append(calls, ix.Call)
+		}
+	}
+	return calls
+}
+
+// forgetCallIndex removes index of path, unless it was already replaced
+func (s *Server) forgetCallIndex(path string, index *callIndex) {
+	s.callIndex.mu.Lock()
+	defer s.callIndex.mu.Unlock()
+	if s.callIndex.byPath[path] == index {
+		delete(s.callIndex.byPath, path)
+	}
+}
+
+// dropCallIndex stops building and forgets the call index of path
+func (s *Server) dropCallIndex(path string) {
+	s.callIndex.mu.Lock()
+	defer s.callIndex.mu.Unlock()
+	if index, ok := s.callIndex.byPath[path]; ok {
+		close(index.stop)
+		delete(s.callIndex.byPath, path)
+	}
+}
+
+// callIndexFor returns the call index of path, starting to build it when needed
+func (s *Server) callIndexFor(path string, file disasm.File) *callIndex {
+	s.callIndex.mu.Lock()
+	index, ok := s.callIndex.byPath[path]
+	s.callIndex.mu.Unlock()
+	if ok {
+		return index
+	}
+	return s.startCallIndex(path, file)
+}
+
+// handleFunctionCallers lists the callers of a function using the call index
+// It responds with 202 Accepted while the index is being built
+func (s *Server) handleFunctionCallers(w http.ResponseWriter, r *http.Request) {
+	functionName := mux.Vars(r)["name"]
+	query := r.URL.Query()
+	path := query.Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+
+	depth := 1
+	if str := query.Get("depth"); str != "" {
+		v, err := strconv.Atoi(str)
+		if err != nil || v < 1 || v > maxCallerDepth {
+			writeError(w, "depth must be between 1 and "+strconv.Itoa(maxCallerDepth), http.StatusBadRequest)
+			return
+		}
+		depth = v
+	}
+
+	// Get the file
+	file, exists := s.getFile(path)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	found := false
+	for _, fn := range file.Funcs() {
+		if fn.Name() == functionName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		writeError(w, "Function not found", http.StatusNotFound)
+		return
+	}
+
+	index := s.callIndexFor(path, file)
+	select {
+	case <-index.ready:
+	default:
+		w.Header().Set("Retry-After", "1")
+		writeResponse(w, r, http.StatusAccepted, map[string]string{"status": "building"})
+		return
+	}
+
+	// breadth first, so each caller is reported at its smallest depth
+	seen := map[string]bool{functionName: true}
+	callers := []CallerInfo{}
+	level := []string{functionName}
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		var next []string
+		for _, callee := range level {
+			for caller := range index.callers[callee] {
+				if seen[caller] {
+					continue
+				}
+				seen[caller] = true
+				next = append(next, caller)
+				callers = append(callers, CallerInfo{Name: caller, Depth: d})
+			}
+		}
+		level = next
+	}
+	sort.Slice(callers, func(i, k int) bool {
+		if callers[i].Depth != callers[k].Depth {
+			return callers[i].Depth < callers[k].Depth
+		}
+		return callers[i].Name < callers[k].Name
+	})
+
+	writeList(w, r, "callers", callers)
+}
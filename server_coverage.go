@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/gameformush/goasm-vscode/internal/coverage"
+)
+
+// CoverageResponse contains the line coverage stored for a file
+type CoverageResponse struct {
+	Files []FileCoverage `json:"files"`
+}
+
+// FileCoverage lists the covered and uncovered lines of a source file,
+// which is named by import path as in the profile
+type FileCoverage struct {
+	File      string `json:"file"`
+	Covered   []int  `json:"covered"`
+	Uncovered []int  `json:"uncovered"`
+}
+
+// CoverageUploadResponse summarizes an uploaded coverage profile
+type CoverageUploadResponse struct {
+	Files        int `json:"files"`
+	Lines        int `json:"lines"`
+	CoveredLines int `json:"coveredLines"`
+}
+
+// fileCoverage returns the coverage profile uploaded for the file at path,
+// nil when there's none
+func (s *Server) fileCoverage(path string) *coverage.Profile {
+	s.activeFilesMutex.RLock()
+	defer s.activeFilesMutex.RUnlock()
+	if active, ok := s.activeFiles[path]; ok {
+		return active.coverage
+	}
+	return nil
+}
+
+// handleCoverage stores a `go test -coverprofile` output for a file with POST,
+// which marks the covered instructions in the code responses, and returns
+// the stored line coverage with GET
+func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		profile := s.fileCoverage(path)
+		if profile == nil {
+			writeError(w, "No coverage for file", http.StatusNotFound)
+			return
+		}
+		resp := CoverageResponse{Files: make([]FileCoverage, 0, len(profile.Lines))}
+		for _, file := range slices.Sorted(maps.Keys(profile.Lines)) {
+			covered, uncovered := profile.SplitLines(file)
+			resp.Files = append(resp.Files, FileCoverage{File: file, Covered: covered, Uncovered: uncovered})
+		}
+		writeResponse(w, r, http.StatusOK, resp)
+		return
+	}
+
+	profile, err := coverage.Parse(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeBodyTooLarge(w, tooLarge.Limit)
+		return
+	}
+	if err != nil {
+		writeError(w, "Invalid coverage profile: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.activeFilesMutex.Lock()
+	active, ok := s.activeFiles[path]
+	if ok {
+		active.coverage = profile
+	}
+	s.activeFilesMutex.Unlock()
+	if !ok {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	resp := CoverageUploadResponse{Files: len(profile.Lines)}
+	for _, lines := range profile.Lines {
+		resp.Lines += len(lines)
+		for _, covered := range lines {
+			if covered {
+				resp.CoveredLines++
+			}
+		}
+	}
+	writeResponse(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	lensmpb "github.com/gameformush/goasm-vscode/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService implements the gRPC API with the operations of the REST API
+type grpcService struct {
+	lensmpb.UnimplementedLensmServiceServer
+	service *serviceImpl
+}
+
+// startGRPC serves the gRPC API on addr in a goroutine
+// tlsConfig is used for the connections, nil serves them unencrypted
+func (s *Server) startGRPC(addr string, tlsConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	var options []grpc.ServerOption
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	options = append(options,
+		grpc.ChainUnaryInterceptor(s.grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(s.grpcStreamInterceptor),
+	)
+	s.grpcServer = grpc.NewServer(options...)
+	lensmpb.RegisterLensmServiceServer(s.grpcServer, &grpcService{service: s.service})
+
+	go func() {
+		s.logger.Info("starting gRPC server", "addr", addr, "tls", tlsConfig != nil)
+		if err := s.grpcServer.Serve(listener); err != nil {
+			s.logger.Error("gRPC server error", "err", err)
+		}
+	}()
+	return nil
+}
+
+// stopGRPC stops the gRPC server, waiting for the running calls until ctx is done
+func (s *Server) stopGRPC(ctx context.Context) {
+	if s.grpcServer == nil {
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
+// grpcUnaryInterceptor tracks, logs and recovers calls like the HTTP middleware
+func (s *Server) grpcUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer s.trackGRPC(ctx, info.FullMethod, &err)()
+	return handler(ctx, req)
+}
+
+// grpcStreamInterceptor tracks, logs and recovers streams like the HTTP middleware
+func (s *Server) grpcStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer s.trackGRPC(stream.Context(), info.FullMethod, &err)()
+	return handler(srv, stream)
+}
+
+// trackGRPC counts the call as in flight for Shutdown, and returns the
+// function that logs it when deferred, turning a panic into an Internal error
+func (s *Server) trackGRPC(ctx context.Context, method string, err *error) func() {
+	s.inFlight.Add(1)
+	start := time.Now()
+	return func() {
+		defer s.inFlight.Done()
+		if p := recover(); p != nil {
+			s.logger.Error("panic in gRPC handler",
+				"method", method,
+				"err", p,
+				"stack", string(debug.Stack()),
+			)
+			*err = status.Error(codes.Internal, "internal server error")
+		}
+
+		code := status.Code(*err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK, codes.Canceled:
+		case codes.Internal, codes.Unknown:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+		s.logger.Log(ctx, level, "gRPC request",
+			"method", method,
+			"code", code.String(),
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// grpcError converts an error of serviceImpl to a gRPC status
+func grpcError(err error) error {
+	var serverErr *ServerError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &serverErr):
+		code := codes.Internal
+		switch serverErr.Code {
+		case http.StatusBadRequest:
+			code = codes.InvalidArgument
+		case http.StatusNotFound:
+			code = codes.NotFound
+		case http.StatusServiceUnavailable:
+			code = codes.Unavailable
+		}
+		return status.Error(code, serverErr.Message)
+	case errors.Is(err, errServerBusy):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// LoadFile implements lensmpb.LensmServiceServer
+func (g *grpcService) LoadFile(ctx context.Context, req *lensmpb.LoadFileRequest) (*lensmpb.LoadFileResponse, error) {
+	created, err := g.service.LoadFile(ctx, req.GetPath())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &lensmpb.LoadFileResponse{Created: created}, nil
+}
+
+// ListFiles implements lensmpb.LensmServiceServer
+func (g *grpcService) ListFiles(ctx context.Context, req *lensmpb.ListFilesRequest) (*lensmpb.ListFilesResponse, error) {
+	files := g.service.ListFiles()
+	resp := &lensmpb.ListFilesResponse{Files: make([]*lensmpb.FileInfo, len(files))}
+	for i, file := range files {
+		resp.Files[i] = &lensmpb.FileInfo{Path: file.Path, BuildId: file.BuildID}
+	}
+	return resp, nil
+}
+
+// ListFunctions implements lensmpb.LensmServiceServer
+func (g *grpcService) ListFunctions(ctx context.Context, req *lensmpb.ListFunctionsRequest) (*lensmpb.ListFunctionsResponse, error) {
+	funcs, err := g.service.ListFunctions(req.GetFile(), req.GetFilter(), req.GetKind(), req.GetSource())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &lensmpb.ListFunctionsResponse{Functions: functionInfosToProto(funcs)}, nil
+}
+
+// DisassembleFunction implements lensmpb.LensmServiceServer
+func (g *grpcService) DisassembleFunction(ctx context.Context, req *lensmpb.DisassembleFunctionRequest) (*lensmpb.Code, error) {
+	var context *int
+	if req.Context != nil {
+		value := int(req.GetContext())
+		context = &value
+	}
+	code, err := g.service.DisassembleFunction(ctx, req.GetFile(), req.GetName(), context)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return codeToProto(newCodeResponse(code, nil)), nil
+}
+
+// StreamFunctionUpdates implements lensmpb.LensmServiceServer
+func (g *grpcService) StreamFunctionUpdates(req *lensmpb.StreamFunctionUpdatesRequest, stream grpc.ServerStreamingServer[lensmpb.FunctionUpdate]) error {
+	err := g.service.WatchFunctions(stream.Context(), req.GetFile(), func(buildID string, funcs []FunctionInfo) error {
+		return stream.Send(&lensmpb.FunctionUpdate{BuildId: buildID, Functions: functionInfosToProto(funcs)})
+	})
+	return grpcError(err)
+}
+
+// functionInfosToProto converts the functions to their messages
+func functionInfosToProto(funcs []FunctionInfo) []*lensmpb.FunctionInfo {
+	infos := make([]*lensmpb.FunctionInfo, len(funcs))
+	for i, fn := range funcs {
+		infos[i] = &lensmpb.FunctionInfo{
+			Name:             fn.Name,
+			Kind:             fn.Kind,
+			Package:          fn.Package,
+			BuildConstraints: fn.BuildConstraints,
+		}
+	}
+	return infos
+}
+
+// codeToProto converts the REST response of a function to its message
+func codeToProto(code CodeResponse) *lensmpb.Code {
+	msg := &lensmpb.Code{
+		Name:         code.Name,
+		File:         code.File,
+		Instructions: make([]*lensmpb.Instruction, len(code.Instructions)),
+		Sources:      make([]*lensmpb.Source, len(code.Sources)),
+		MaxJump:      int32(code.MaxJump),
+		FrameSize:    int32(code.FrameSize),
+	}
+	for i, inst := range code.Instructions {
+		msg.Instructions[i] = &lensmpb.Instruction{
+			Pc:         inst.PC,
+			Text:       inst.Text,
+			File:       inst.File,
+			Line:       int32(inst.Line),
+			RefPc:      inst.RefPC,
+			RefOffset:  int32(inst.RefOffset),
+			RefStack:   int32(inst.RefStack),
+			Call:       inst.Call,
+			Category:   inst.Category,
+			LoopHeader: inst.LoopHeader,
+			LoopDepth:  int32(inst.LoopDepth),
+		}
+	}
+	for i, src := range code.Sources {
+		source := &lensmpb.Source{File: src.File, Lang: src.Lang, Blocks: make([]*lensmpb.SourceBlock, len(src.Blocks))}
+		for k, block := range src.Blocks {
+			related := make([]*lensmpb.LineRanges, len(block.Related))
+			for line, ranges := range block.Related {
+				related[line] = &lensmpb.LineRanges{Ranges: make([]*lensmpb.LineRange, len(ranges))}
+				for r, lineRange := range ranges {
+					related[line].Ranges[r] = &lensmpb.LineRange{From: int32(lineRange.From), To: int32(lineRange.To)}
+				}
+			}
+			source.Blocks[k] = &lensmpb.SourceBlock{
+				From:    int32(block.From),
+				To:      int32(block.To),
+				Lines:   block.Lines,
+				Related: related,
+			}
+		}
+		msg.Sources[i] = source
+	}
+	for _, site := range code.Defers {
+		msg.Defers = append(msg.Defers, &lensmpb.DeferSite{PushPc: site.PushPC, ReturnPcs: site.ReturnPCs})
+	}
+	return msg
+}
+
+// codeFromProto converts a function message to the REST response, see CodeResponse.toCode
+func codeFromProto(msg *lensmpb.Code) *CodeResponse {
+	code := &CodeResponse{
+		Name:         msg.GetName(),
+		File:         msg.GetFile(),
+		Instructions: make([]InstructionInfo, len(msg.GetInstructions())),
+		Sources:      make([]SourceInfo, len(msg.GetSources())),
+		MaxJump:      int(msg.GetMaxJump()),
+		FrameSize:    int(msg.GetFrameSize()),
+	}
+	for i, inst := range msg.GetInstructions() {
+		code.Instructions[i] = InstructionInfo{
+			PC:         inst.GetPc(),
+			Text:       inst.GetText(),
+			File:       inst.GetFile(),
+			Line:       int(inst.GetLine()),
+			RefPC:      inst.GetRefPc(),
+			RefOffset:  int(inst.GetRefOffset()),
+			RefStack:   int(inst.GetRefStack()),
+			Call:       inst.GetCall(),
+			Category:   inst.GetCategory(),
+			LoopHeader: inst.GetLoopHeader(),
+			LoopDepth:  int(inst.GetLoopDepth()),
+		}
+	}
+	for i, src := range msg.GetSources() {
+		source := SourceInfo{File: src.GetFile(), Lang: src.GetLang(), Blocks: make([]SourceBlockInfo, len(src.GetBlocks()))}
+		for k, block := range src.GetBlocks() {
+			related := make([][]LineRangeInfo, len(block.GetRelated()))
+			for line, ranges := range block.GetRelated() {
+				for _, lineRange := range ranges.GetRanges() {
+					related[line] = append(related[line], LineRangeInfo{From: int(lineRange.GetFrom()), To: int(lineRange.GetTo())})
+				}
+			}
+			source.Blocks[k] = SourceBlockInfo{
+				From:    int(block.GetFrom()),
+				To:      int(block.GetTo()),
+				Lines:   block.GetLines(),
+				Related: related,
+			}
+		}
+		code.Sources[i] = source
+	}
+	for _, site := range msg.GetDefers() {
+		code.Defers = append(code.Defers, DeferSiteInfo{PushPC: site.GetPushPc(), ReturnPCs: site.GetReturnPcs()})
+	}
+	return code
+}
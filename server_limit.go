@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// errServerBusy is returned when no disassembly slot was free within the queue timeout
+var errServerBusy = errors.New("server busy")
+
+// acquireSlot waits for one of the disassembly slots
+// It fails with errServerBusy after the queue timeout or when ctx is done
+func (s *Server) acquireSlot(ctx context.Context) error {
+	if s.semaphore == nil {
+		return nil
+	}
+
+	s.queueDepth.Add(1)
+	defer s.queueDepth.Add(-1)
+
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case s.semaphore <- struct{}{}:
+		return nil
+	case <-timer.C:
+		s.busyRejections.Add(1)
+		return errServerBusy
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseSlot frees a slot taken by acquireSlot
+func (s *Server) releaseSlot() {
+	if s.semaphore != nil {
+		<-s.semaphore
+	}
+}
+
+// writeServerBusy responds with 503 when no disassembly slot is available
+func writeServerBusy(w http.ResponseWriter) {
+	writeError(w, errServerBusy.Error(), http.StatusServiceUnavailable)
+}
+
+// handleMetrics reports the disassembly queue and cache in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	metric := func(name, typ, help string, value int64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, typ, name, value)
+	}
+	metric("lensm_disassembly_queue_depth", "gauge", "Requests waiting for a disassembly slot.", s.queueDepth.Load())
+	metric("lensm_disassembly_in_flight", "gauge", "Disassemblies in progress.", int64(len(s.semaphore)))
+	metric("lensm_disassembly_slots", "gauge", "Maximum concurrent disassemblies, 0 when unlimited.", int64(cap(s.semaphore)))
+	metric("lensm_disassembly_busy_total", "counter", "Requests rejected because the queue timeout elapsed.", s.busyRejections.Load())
+	metric("lensm_func_cache_hits_total", "counter", "Function cache hits.", s.cacheHits.Load())
+	metric("lensm_func_cache_misses_total", "counter", "Function cache misses.", s.cacheMisses.Load())
+	s.activeFilesMutex.RLock()
+	loaded := len(s.activeFiles)
+	s.activeFilesMutex.RUnlock()
+	metric("lensm_files_loaded", "gauge", "Files loaded by the server.", int64(loaded))
+	metric("lensm_files_evicted_total", "counter", "Files unloaded because they weren't accessed within the file TTL.", s.filesEvicted.Load())
+}
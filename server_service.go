@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// serviceImpl implements the operations shared by the REST and the gRPC API
+// Failures are *ServerError with the HTTP status, errServerBusy, or the
+// error of the request context
+type serviceImpl struct {
+	server *Server
+}
+
+// serviceError creates the error of a failed operation
+func serviceError(status int, format string, args ...any) error {
+	return &ServerError{Code: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// writeServiceError responds with the error of a serviceImpl operation
+// The error of the request context is written too, the client still waits
+// when a middleware set a deadline
+func writeServiceError(w http.ResponseWriter, err error) {
+	var serverErr *ServerError
+	switch {
+	case errors.As(err, &serverErr):
+		writeError(w, serverErr.Message, serverErr.Code)
+	case errors.Is(err, errServerBusy):
+		writeServerBusy(w)
+	default:
+		writeError(w, err.Error(), http.StatusServiceUnavailable)
+	}
+}
+
+// LoadFile loads the file at path unless it's already loaded
+// It returns whether the file was newly loaded
+func (svc *serviceImpl) LoadFile(ctx context.Context, path string) (bool, error) {
+	if path == "" {
+		return false, serviceError(http.StatusBadRequest, "Path is required")
+	}
+	created, err := svc.server.loadFile(ctx, path)
+	if err != nil {
+		return false, serviceError(http.StatusInternalServerError, "Failed to load file: %v", err)
+	}
+	return created, nil
+}
+
+// ListFiles lists the loaded files sorted by path
+// The details are read after releasing the lock, since the Go version
+// is read from the binary on first use
+func (svc *serviceImpl) ListFiles() []FileInfo {
+	s := svc.server
+	s.activeFilesMutex.RLock()
+	actives := make(map[string]*activeFile, len(s.activeFiles))
+	for path, active := range s.activeFiles {
+		actives[path] = active
+	}
+	s.activeFilesMutex.RUnlock()
+
+	files := make([]FileInfo, 0, len(actives))
+	for path, active := range actives {
+		info := FileInfo{
+			Path:      path,
+			BuildID:   active.file.BuildID(),
+			GoModPath: active.file.GoModPath(),
+			FuncCount: len(active.file.Funcs()),
+			Loaded:    active.loaded,
+		}
+		if arch, ok := active.file.(interface{ GOARCH() string }); ok {
+			info.Arch = arch.GOARCH()
+		}
+		if version, ok := active.file.(interface{ GoVersion() string }); ok {
+			info.GoVersion = version.GoVersion()
+		}
+		files = append(files, info)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// file returns the loaded file at path
+func (svc *serviceImpl) file(path string) (disasm.File, error) {
+	if path == "" {
+		return nil, serviceError(http.StatusBadRequest, "File path is required")
+	}
+	file, exists := svc.server.getFile(path)
+	if !exists {
+		return nil, serviceError(http.StatusNotFound, "File not found")
+	}
+	return file, nil
+}
+
+// ListFunctions lists the functions of the file at path whose names match
+// the filter regular expression, that are of kind and that have code from
+// a source file matching source, see disasm.SourcePatternMatches. Empty
+// matches all
+func (svc *serviceImpl) ListFunctions(path, filter, kindStr, source string) ([]FunctionInfo, error) {
+	file, err := svc.file(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rx *regexp.Regexp
+	if filter != "" {
+		rx, err = regexp.Compile(filter)
+		if err != nil {
+			return nil, serviceError(http.StatusBadRequest, "Invalid filter regex: %v", err)
+		}
+	}
+
+	var kind disasm.FuncKind
+	if kindStr != "" {
+		var ok bool
+		kind, ok = disasm.ParseFuncKind(kindStr)
+		if !ok {
+			return nil, serviceError(http.StatusBadRequest, "Invalid kind %q", kindStr)
+		}
+	}
+
+	funcs := file.Funcs()
+	if source != "" {
+		if err := disasm.CheckSourcePattern(source); err != nil {
+			return nil, serviceError(http.StatusBadRequest, "Invalid source pattern: %v", err)
+		}
+		funcs, err = disasm.FuncsBySourceFile(file, source)
+		if err != nil {
+			return nil, serviceError(http.StatusInternalServerError, "Failed to match source files: %v", err)
+		}
+	}
+
+	// Filter functions by name and kind
+	filteredFuncs := []FunctionInfo{}
+	for _, fn := range funcs {
+		if rx != nil && !rx.MatchString(fn.Name()) {
+			continue
+		}
+		fnKind := disasm.KindOf(fn.Name())
+		if kindStr != "" && fnKind != kind {
+			continue
+		}
+		filteredFuncs = append(filteredFuncs, functionInfo(fn))
+	}
+	return filteredFuncs, nil
+}
+
+// functionInfo describes fn
+func functionInfo(fn disasm.Func) FunctionInfo {
+	return FunctionInfo{
+		Name:             fn.Name(),
+		Kind:             disasm.KindOf(fn.Name()).String(),
+		Package:          disasm.PackageOf(fn.Name()),
+		BuildConstraints: fn.BuildConstraints(),
+	}
+}
+
+// DisassembleFunction disassembles the named function of the file at path,
+// waiting for a free slot. context overrides the server default when not nil
+func (svc *serviceImpl) DisassembleFunction(ctx context.Context, path, name string, context *int) (*disasm.Code, error) {
+	s := svc.server
+	if name == "" {
+		return nil, serviceError(http.StatusBadRequest, "Function name is required")
+	}
+	file, err := svc.file(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var targetFunc disasm.Func
+	for _, fn := range file.Funcs() {
+		if fn.Name() == name {
+			targetFunc = fn
+			break
+		}
+	}
+	if targetFunc == nil {
+		return nil, serviceError(http.StatusNotFound, "Function not found")
+	}
+
+	options := s.options
+	if context != nil {
+		if err := s.checkContext(*context); err != nil {
+			return nil, serviceError(http.StatusBadRequest, "%v", err)
+		}
+		options.Context = *context
+	}
+
+	if err := s.acquireSlot(ctx); err != nil {
+		return nil, err
+	}
+	defer s.releaseSlot()
+	code := s.loadCode(ctx, path, targetFunc, options)
+	if code == nil {
+		return nil, serviceError(http.StatusInternalServerError, "Failed to load function code")
+	}
+	return code, nil
+}
+
+// WatchFunctions calls send with the functions of the file at path, and
+// again after the binary changed on disk and was reloaded, until ctx is
+// done, the server stops or send fails
+func (svc *serviceImpl) WatchFunctions(ctx context.Context, path string, send func(buildID string, funcs []FunctionInfo) error) error {
+	s := svc.server
+	// subscribe before sending the file, so that no reload is missed
+	watch := s.subscribeWatch(path)
+	defer s.unsubscribeWatch(path, watch)
+	updated, _, _ := watch.result()
+
+	file, err := svc.file(path)
+	if err != nil {
+		return err
+	}
+	sendFile := func(file disasm.File) error {
+		funcs := make([]FunctionInfo, 0, len(file.Funcs()))
+		for _, fn := range file.Funcs() {
+			funcs = append(funcs, functionInfo(fn))
+		}
+		return send(file.BuildID(), funcs)
+	}
+	if err := sendFile(file); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.stop:
+			return nil
+		case <-updated:
+		}
+
+		updated, file, err = watch.result()
+		if err != nil {
+			// the watch only ends with errFileUnloaded
+			return serviceError(http.StatusNotFound, "File not found")
+		}
+		if err := sendFile(file); err != nil {
+			return err
+		}
+	}
+}
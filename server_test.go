@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/gameformush/goasm-vscode/internal/goobj"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	server := NewServer(ServerConfig{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var insts []int
+		_ = insts[3] // index out of range, like a malformed binary
+	})
+	handler := server.loggingMiddleware(server.recoveryMiddleware(panicking))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/functions/main.main", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", rec.Body, err)
+	}
+	if body["error"] != "internal server error" {
+		t.Errorf("error = %v, want internal server error", body["error"])
+	}
+	requestID := rec.Header().Get("X-Request-ID")
+	if requestID == "" || body["id"] != requestID {
+		t.Errorf("id = %v, want the X-Request-ID %q", body["id"], requestID)
+	}
+}
+
+func TestShutdownClosesWebSockets(t *testing.T) {
+	config := ServerConfig{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	server := NewServer(config)
+	ts := httptest.NewServer(server.newRouter(config))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown with an open WebSocket: %v", err)
+	}
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("read after Shutdown = %v, want close going away", err)
+	}
+}
+
+func TestMutualTLSRejectsClientWithoutCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	writeTestCert(t, dir, "server", ca, caKey)
+	writeTestCert(t, dir, "client", ca, caKey)
+
+	config := ServerConfig{
+		Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		TLSCertFile:     filepath.Join(dir, "server.pem"),
+		TLSKeyFile:      filepath.Join(dir, "server-key.pem"),
+		TLSClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(config)
+	ts := httptest.NewUnstartedServer(server.newRouter(config))
+	ts.TLS = tlsConfig
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	anonymous := NewClient(ts.URL).WithRootCA(filepath.Join(dir, "ca.pem"))
+	if _, err := anonymous.GetFiles(); err == nil {
+		t.Error("request without a client certificate succeeded")
+	}
+
+	authenticated := NewClient(ts.URL).
+		WithRootCA(filepath.Join(dir, "ca.pem")).
+		WithClientCert(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem"))
+	if _, err := authenticated.GetFiles(); err != nil {
+		t.Errorf("request with a client certificate: %v", err)
+	}
+}
+
+// writeTestCert writes the certificate name.pem and the key name-key.pem to dir,
+// signed by parent, or a self-signed CA when parent is nil
+func writeTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestResolvePC(t *testing.T) {
+	path := buildBench(t)
+	file, err := goobj.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string]uint64{}
+	for _, sym := range file.SymbolTable() {
+		entries[sym.Name] = sym.Address
+	}
+	_ = file.Close()
+
+	config := ServerConfig{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	server := NewServer(config)
+	if _, err := server.loadFile(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.newRouter(config))
+	defer ts.Close()
+	client := NewClient(ts.URL)
+
+	// the stack check at the entry of main.main is at the line of its
+	// declaration in testdata/bench/main.go
+	const name, line = "main.main", 1587
+	pc, ok := entries[name]
+	if !ok {
+		t.Fatalf("%s not in the symbol table", name)
+	}
+	loc, err := client.ResolvePC(path, pc)
+	if err != nil {
+		t.Fatalf("resolve %#x: %v", pc, err)
+	}
+	if !strings.HasSuffix(filepath.ToSlash(loc.SourceFile), "testdata/bench/main.go") || loc.Line != line || loc.FuncName != name {
+		t.Errorf("resolve %#x = %s:%d in %s, want testdata/bench/main.go:%d in %s", pc, loc.SourceFile, loc.Line, loc.FuncName, line, name)
+	}
+
+	if _, err := client.ResolvePC(path, 0); err == nil {
+		t.Error("resolving pc 0 succeeded")
+	}
+}
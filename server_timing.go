@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gameformush/goasm-vscode/internal/disasm/timing"
+)
+
+// TimingResponse contains the estimated cycles of the instructions of a function
+type TimingResponse struct {
+	Name  string `json:"name"`
+	Arch  string `json:"arch"`
+	Model string `json:"model"`
+	// CriticalPath is the latency of the longest chain of dependent instructions
+	CriticalPath float64             `json:"criticalPath"`
+	Instructions []InstructionTiming `json:"instructions"`
+}
+
+// InstructionTiming is the latency and reciprocal throughput of an instruction
+// Known is false for instructions missing from the timing table
+type InstructionTiming struct {
+	PC         uint64  `json:"pc"`
+	Text       string  `json:"text"`
+	Known      bool    `json:"known"`
+	Latency    float64 `json:"latency"`
+	Throughput float64 `json:"throughput"`
+}
+
+// fileArch returns the GOARCH of file, amd64 when it's unknown
+func fileArch(file disasm.File) string {
+	if arch, ok := file.(interface{ GOARCH() string }); ok && arch.GOARCH() != "" {
+		return arch.GOARCH()
+	}
+	return "amd64"
+}
+
+// handleFunctionTiming annotates the instructions of a function with their timings
+func (s *Server) handleFunctionTiming(w http.ResponseWriter, r *http.Request) {
+	functionName := mux.Vars(r)["name"]
+	query := r.URL.Query()
+	path := query.Get("file")
+	if path == "" {
+		writeError(w, "File path is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get the file
+	file, exists := s.getFile(path)
+
+	if !exists {
+		writeError(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	arch := query.Get("arch")
+	if arch == "" {
+		arch = fileArch(file)
+	}
+	table, ok := timing.ForArch(arch)
+	if !ok {
+		writeError(w, "No timing table for arch "+arch, http.StatusBadRequest)
+		return
+	}
+
+	var targetFunc disasm.Func
+	for _, fn := range file.Funcs() {
+		if fn.Name() == functionName {
+			targetFunc = fn
+			break
+		}
+	}
+	if targetFunc == nil {
+		writeError(w, "Function not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.acquireSlot(r.Context()); err != nil {
+		if errors.Is(err, errServerBusy) {
+			writeServerBusy(w)
+		}
+		return
+	}
+	defer s.releaseSlot()
+	code := s.loadCode(r.Context(), path, targetFunc, s.options)
+	if code == nil {
+		writeError(w, "Failed to load function code", http.StatusInternalServerError)
+		return
+	}
+
+	resp := TimingResponse{
+		Name:         code.Name,
+		Arch:         table.Arch,
+		Model:        table.Model,
+		CriticalPath: table.CriticalPath(code.Insts),
+		Instructions: make([]InstructionTiming, 0, len(code.Insts)),
+	}
+	for _, ix := range code.Insts {
+		if ix.Text == "" {
+			continue
+		}
+		t, known := table.Lookup(ix.Text)
+		resp.Instructions = append(resp.Instructions, InstructionTiming{
+			PC:         ix.PC,
+			Text:       ix.Text,
+			Known:      known,
+			Latency:    t.Latency,
+			Throughput: t.Throughput,
+		})
+	}
+
+	writeResponse(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// fileEvictionInterval is how often idle files are looked for
+const fileEvictionInterval = 5 * time.Minute
+
+// evictIdleFiles periodically unloads the files that weren't accessed
+// within the file TTL, until the server is shut down
+func (s *Server) evictIdleFiles() {
+	ticker := time.NewTicker(fileEvictionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.evictFiles(now)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// evictFiles unloads the files last accessed before now minus the file TTL
+// Pinned files are kept
+func (s *Server) evictFiles(now time.Time) {
+	evicted := map[string]disasm.File{}
+	s.activeFilesMutex.Lock()
+	for path, active := range s.activeFiles {
+		if !active.pinned && now.Sub(active.lastAccessed) > s.fileTTL {
+			evicted[path] = active.file
+			delete(s.activeFiles, path)
+		}
+	}
+	s.activeFilesMutex.Unlock()
+
+	for path, file := range evicted {
+		s.logger.Info("evicting idle file", "path", path, "ttl", s.fileTTL)
+		s.unloadFile(path, file)
+		s.filesEvicted.Add(1)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// uploads tracks the temporary files created for uploaded binaries
+type uploads struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// UploadResponse describes an uploaded and loaded binary
+type UploadResponse struct {
+	// Path identifies the file in the other endpoints
+	Path string `json:"path"`
+	// SHA256 is the hex encoded hash of the received content
+	SHA256    string `json:"sha256"`
+	Size      int64  `json:"size"`
+	BuildID   string `json:"buildId,omitempty"`
+	GoModPath string `json:"goModPath,omitempty"`
+}
+
+// handleUpload stores the request body in a temporary file and loads it
+// The body is hashed while it's written, so it's never held in memory
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	name := filepath.Base(r.URL.Query().Get("filename"))
+	if name == "." || name == string(filepath.Separator) {
+		name = "binary"
+	}
+
+	tmp, err := os.CreateTemp("", "lensm-upload-*-"+name)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Failed to store upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+	path := tmp.Name()
+
+	hash := sha256.New()
+	size, err := io.Copy(tmp, io.TeeReader(r.Body, hash))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeBodyTooLarge(w, tooLarge.Limit)
+			return
+		}
+		writeError(w, fmt.Sprintf("Failed to store upload: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := s.loadFile(r.Context(), path); err != nil {
+		_ = os.Remove(path)
+		writeError(w, fmt.Sprintf("Failed to load file: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.uploads.mu.Lock()
+	if s.uploads.paths == nil {
+		s.uploads.paths = map[string]bool{}
+	}
+	s.uploads.paths[path] = true
+	s.uploads.mu.Unlock()
+
+	var buildID, goModPath string
+	if file, ok := s.getFile(path); ok {
+		buildID, goModPath = file.BuildID(), file.GoModPath()
+	}
+
+	writeResponse(w, r, http.StatusCreated, UploadResponse{
+		Path:      path,
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+		Size:      size,
+		BuildID:   buildID,
+		GoModPath: goModPath,
+	})
+}
+
+// removeUpload deletes the temporary file of an uploaded binary at path
+// Other files are left alone
+func (s *Server) removeUpload(path string) {
+	s.uploads.mu.Lock()
+	uploaded := s.uploads.paths[path]
+	delete(s.uploads.paths, path)
+	s.uploads.mu.Unlock()
+
+	if uploaded {
+		if err := os.Remove(path); err != nil {
+			s.logger.Warn("error removing upload", "path", path, "err", err)
+		}
+	}
+}
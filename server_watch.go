@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// watchPollInterval is how often a file watch checks the file when
+// change notifications are unavailable
+const watchPollInterval = time.Second
+
+// fileWatch reloads a file when it changes on disk, the reloaded file is
+// shared by all WatchFunctions streams of the file
+type fileWatch struct {
+	// streams is the number of streams using the watch, guarded by fileWatches.mu
+	streams int
+	// stop is closed when the last stream ends
+	stop chan struct{}
+
+	mu   sync.Mutex
+	file disasm.File
+	// err ends the watch, e.g. errFileUnloaded
+	err error
+	// updated is closed and replaced when file or err changes
+	updated chan struct{}
+}
+
+// fileWatches contains the running file watches by file path
+type fileWatches struct {
+	mu     sync.Mutex
+	byPath map[string]*fileWatch
+}
+
+// subscribeWatch returns the watch of the file at path for a new stream,
+// the first stream starts the watch
+// The stream must call unsubscribeWatch when it ends
+func (s *Server) subscribeWatch(path string) *fileWatch {
+	s.watches.mu.Lock()
+	defer s.watches.mu.Unlock()
+
+	watch, ok := s.watches.byPath[path]
+	if ok {
+		if _, _, err := watch.result(); err == nil {
+			watch.streams++
+			return watch
+		}
+		// the watch ended, the file was loaded again since
+		delete(s.watches.byPath, path)
+	}
+
+	watch = &fileWatch{
+		streams: 1,
+		stop:    make(chan struct{}),
+		updated: make(chan struct{}),
+	}
+	if s.watches.byPath == nil {
+		s.watches.byPath = map[string]*fileWatch{}
+	}
+	s.watches.byPath[path] = watch
+
+	s.inFlight.Add(1)
+	go func() {
+		defer s.inFlight.Done()
+		s.runWatch(path, watch)
+	}()
+	return watch
+}
+
+// unsubscribeWatch ends a stream of watch, the last stream stops the watch
+func (s *Server) unsubscribeWatch(path string, watch *fileWatch) {
+	s.watches.mu.Lock()
+	defer s.watches.mu.Unlock()
+
+	watch.streams--
+	if watch.streams > 0 {
+		return
+	}
+	close(watch.stop)
+	if s.watches.byPath[path] == watch {
+		delete(s.watches.byPath, path)
+	}
+}
+
+// runWatch reloads the file at path after every change until the watch
+// stops, the server stops or the file is unloaded
+func (s *Server) runWatch(path string, watch *fileWatch) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-watch.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var changed <-chan struct{}
+	var ticks <-chan time.Time
+	if watcher, err := watchFile(path); err == nil {
+		defer watcher.Close()
+		changed = watcher.changed
+	} else {
+		tick := time.NewTicker(watchPollInterval)
+		defer tick.Stop()
+		ticks = tick.C
+	}
+
+	var lastModTime time.Time
+	if stat, err := os.Stat(path); err == nil {
+		lastModTime = stat.ModTime()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-changed:
+		case <-ticks:
+		}
+
+		stat, err := os.Stat(path)
+		if err != nil || stat.ModTime().Equal(lastModTime) {
+			continue
+		}
+		file, err := s.reloadFile(ctx, path)
+		if errors.Is(err, errFileUnloaded) {
+			watch.publish(nil, err)
+			return
+		}
+		if err != nil {
+			// the binary may still be written, wait for the next change
+			s.logger.Warn("error reloading file", "path", path, "err", err)
+			continue
+		}
+		lastModTime = stat.ModTime()
+		watch.publish(file, nil)
+	}
+}
+
+// publish notifies the streams about the reloaded file or the error ending the watch
+func (watch *fileWatch) publish(file disasm.File, err error) {
+	watch.mu.Lock()
+	defer watch.mu.Unlock()
+	watch.file, watch.err = file, err
+	close(watch.updated)
+	watch.updated = make(chan struct{})
+}
+
+// result returns the last reloaded file, nil before the first reload,
+// or the error ending the watch, and a channel closed by the next update
+func (watch *fileWatch) result() (<-chan struct{}, disasm.File, error) {
+	watch.mu.Lock()
+	defer watch.mu.Unlock()
+	return watch.updated, watch.file, watch.err
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// webUIFiles is a single page application using the REST API, for
+// browsing the disassembly without the native UI
+//
+//go:embed webui
+var webUIFiles embed.FS
+
+// registerWebUI serves the web UI under /ui/
+func registerWebUI(r *mux.Router) {
+	files, err := fs.Sub(webUIFiles, "webui")
+	if err != nil {
+		panic(err)
+	}
+	r.Handle("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently)).Methods("GET")
+	r.PathPrefix("/ui/").Handler(http.StripPrefix("/ui/", http.FileServerFS(files))).Methods("GET")
+}
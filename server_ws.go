@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+	"github.com/gorilla/websocket"
+)
+
+// WSMessage is a message sent over the /api/ws connection
+//
+// Requests use the types "load" and "disasm", responses use
+// "loaded", "code" and "error"
+type WSMessage struct {
+	Type string `json:"type"`
+	// ID is copied from the request to the response, when set
+	ID string `json:"id,omitempty"`
+
+	// Path is the file to load for "load" and "loaded"
+	Path string `json:"path,omitempty"`
+
+	// File, Func and Context select the function for "disasm",
+	// a nil Context uses the server default
+	File    string `json:"file,omitempty"`
+	Func    string `json:"func,omitempty"`
+	Context *int   `json:"context,omitempty"`
+
+	// Error describes the failure for "error"
+	Error string `json:"error,omitempty"`
+
+	// Code contains the disassembly for "code"
+	Code *CodeResponse `json:"code,omitempty"`
+}
+
+// handleWebSocket handles a long-lived connection for loading files and
+// disassembling functions without the overhead of separate requests
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// rs/cors doesn't handle the upgrade, so the origin is checked here
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkWSOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error
+		s.logger.Warn("websocket upgrade failed", "err", err)
+		return
+	}
+	defer conn.Close()
+	if s.maxRequestBytes > 0 {
+		conn.SetReadLimit(s.maxRequestBytes)
+	}
+
+	// http.Server.Shutdown doesn't close hijacked connections, so the
+	// connection is closed when the server stops to end ReadJSON
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.stop:
+			closing := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server stopping")
+			_ = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(time.Second))
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var req WSMessage
+		if err := conn.ReadJSON(&req); err != nil {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.logger.Warn("websocket read failed", "err", err)
+			}
+			return
+		}
+
+		var resp WSMessage
+		switch req.Type {
+		case "load":
+			resp = s.wsLoad(r, &req)
+		case "disasm":
+			resp = s.wsDisasm(r, &req)
+		default:
+			resp = WSMessage{Type: "error", Error: fmt.Sprintf("unknown message type %q", req.Type)}
+		}
+		resp.ID = req.ID
+
+		if err := conn.WriteJSON(&resp); err != nil {
+			s.logger.Warn("websocket write failed", "err", err)
+			return
+		}
+	}
+}
+
+// checkWSOrigin allows upgrades from the CORS origins of the server
+// Requests without an Origin header don't come from a browser and are allowed
+func (s *Server) checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.corsOrigins {
+		if originMatches(allowed, origin) {
+			return true
+		}
+	}
+	s.logger.Warn("websocket origin not allowed", "origin", origin)
+	return false
+}
+
+// originMatches reports whether origin matches the allowed origin
+// Like rs/cors, allowed may contain one * wildcard
+func originMatches(allowed, origin string) bool {
+	allowed = strings.ToLower(allowed)
+	origin = strings.ToLower(origin)
+	prefix, suffix, wildcard := strings.Cut(allowed, "*")
+	if !wildcard {
+		return allowed == origin
+	}
+	return len(origin) >= len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// wsLoad handles the "load" message
+func (s *Server) wsLoad(r *http.Request, req *WSMessage) WSMessage {
+	if req.Path == "" {
+		return WSMessage{Type: "error", Error: "path is required"}
+	}
+	if _, err := s.loadFile(r.Context(), req.Path); err != nil {
+		return WSMessage{Type: "error", Error: fmt.Sprintf("failed to load file: %v", err)}
+	}
+	return WSMessage{Type: "loaded", Path: req.Path}
+}
+
+// wsDisasm handles the "disasm" message
+func (s *Server) wsDisasm(r *http.Request, req *WSMessage) WSMessage {
+	if req.File == "" || req.Func == "" {
+		return WSMessage{Type: "error", Error: "file and func are required"}
+	}
+
+	file, exists := s.getFile(req.File)
+	if !exists {
+		return WSMessage{Type: "error", Error: "file not found"}
+	}
+
+	var targetFunc disasm.Func
+	for _, fn := range file.Funcs() {
+		if fn.Name() == req.Func {
+			targetFunc = fn
+			break
+		}
+	}
+	if targetFunc == nil {
+		return WSMessage{Type: "error", Error: "function not found"}
+	}
+
+	options := s.options
+	if req.Context != nil {
+		if err := s.checkContext(*req.Context); err != nil {
+			return WSMessage{Type: "error", Error: err.Error()}
+		}
+		options.Context = *req.Context
+	}
+
+	if err := s.acquireSlot(r.Context()); err != nil {
+		return WSMessage{Type: "error", Error: err.Error()}
+	}
+	defer s.releaseSlot()
+	code := s.loadCode(r.Context(), req.File, targetFunc, options)
+	if code == nil {
+		return WSMessage{Type: "error", Error: "failed to load function code"}
+	}
+
+	response := newCodeResponse(code, s.fileCoverage(req.File))
+	return WSMessage{Type: "code", Code: &response}
+}
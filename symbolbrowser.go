@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/gameformush/goasm-vscode/internal/disasm"
+)
+
+// SymbolColumn is a column in the symbol browser.
+type SymbolColumn int
+
+const (
+	SymbolAddress SymbolColumn = iota
+	SymbolSize
+	SymbolType
+	SymbolName
+
+	symbolColumnCount
+)
+
+var symbolColumnNames = [symbolColumnCount]string{"Address", "Size", "Type", "Name"}
+
+// SymbolBrowserUI lists the symbol table of a file, similar to `nm`.
+type SymbolBrowserUI struct {
+	All         []disasm.Symbol
+	Filtered    []disasm.Symbol
+	Filter      widget.Editor
+	FilterError string
+
+	SortBy  SymbolColumn
+	Reverse bool
+	headers [symbolColumnCount]widget.Clickable
+
+	List SelectList
+}
+
+// NewSymbolBrowser creates a new symbol browser with the specified theme.
+func NewSymbolBrowser(theme *material.Theme) *SymbolBrowserUI {
+	ui := &SymbolBrowserUI{SortBy: SymbolAddress}
+	ui.Filter.SingleLine = true
+	ui.List = NewVerticalSelectList(unit.Dp(theme.TextSize) + 4)
+	ui.List.Selected = -1
+	return ui
+}
+
+// SetSymbols updates the full symbol table.
+func (ui *SymbolBrowserUI) SetSymbols(all []disasm.Symbol) {
+	ui.All = all
+	ui.updateFiltered()
+}
+
+// updateFiltered filters and sorts the symbol table.
+func (ui *SymbolBrowserUI) updateFiltered() {
+	rx, err := regexp.Compile("(?i)" + ui.Filter.Text())
+	ui.FilterError = ""
+	if err != nil {
+		ui.FilterError = err.Error()
+		return
+	}
+
+	ui.Filtered = ui.Filtered[:0]
+	for _, sym := range ui.All {
+		if rx.MatchString(sym.Name) {
+			ui.Filtered = append(ui.Filtered, sym)
+		}
+	}
+
+	less := func(a, b *disasm.Symbol) bool {
+		switch ui.SortBy {
+		case SymbolSize:
+			return a.Size < b.Size
+		case SymbolType:
+			return a.Type < b.Type
+		case SymbolName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		default:
+			return a.Address < b.Address
+		}
+	}
+	sort.SliceStable(ui.Filtered, func(i, k int) bool {
+		if ui.Reverse {
+			return less(&ui.Filtered[k], &ui.Filtered[i])
+		}
+		return less(&ui.Filtered[i], &ui.Filtered[k])
+	})
+	ui.List.Selected = -1
+}
+
+// Layout draws the symbol table. open is called when a func symbol is selected.
+func (ui *SymbolBrowserUI) Layout(th *material.Theme, gtx layout.Context, open func(gtx layout.Context, name string)) layout.Dimensions {
+	changed := false
+	for {
+		ev, ok := ui.Filter.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.ChangeEvent); ok {
+			changed = true
+		}
+	}
+	for i := range ui.headers {
+		for ui.headers[i].Clicked(gtx) {
+			column := SymbolColumn(i)
+			if ui.SortBy == column {
+				ui.Reverse = !ui.Reverse
+			} else {
+				ui.SortBy, ui.Reverse = column, false
+			}
+			changed = true
+		}
+	}
+	if changed {
+		ui.updateFiltered()
+		gtx.Execute(op.InvalidateCmd{})
+	}
+
+	lineHeight := gtx.Metric.Sp(th.TextSize)
+	columns := [symbolColumnCount]int{
+		SymbolAddress: lineHeight * 9,
+		SymbolSize:    lineHeight * 5,
+		SymbolType:    lineHeight * 3,
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return FocusBorder(th, gtx.Focused(&ui.Filter)).Layout(gtx,
+				material.Editor(th, &ui.Filter, "Filter symbols (regexp)").Layout)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if ui.FilterError == "" {
+				return layout.Dimensions{}
+			}
+			return material.Body1(th, ui.FilterError).Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			paint.FillShape(gtx.Ops, secondaryBackground, clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, lineHeight*3/2)}.Op())
+			children := make([]layout.FlexChild, 0, symbolColumnCount)
+			for i := range ui.headers {
+				i := i
+				label := func(gtx layout.Context) layout.Dimensions {
+					text := symbolColumnNames[i]
+					if ui.SortBy == SymbolColumn(i) {
+						if ui.Reverse {
+							text += " ▲"
+						} else {
+							text += " ▼"
+						}
+					}
+					return material.Clickable(gtx, &ui.headers[i], func(gtx layout.Context) layout.Dimensions {
+						if columns[i] > 0 {
+							gtx.Constraints = layout.Exact(image.Pt(columns[i], lineHeight*3/2))
+						}
+						body := material.Body1(th, text)
+						body.TextSize = th.TextSize * 8 / 10
+						return layout.W.Layout(gtx, body.Layout)
+					})
+				}
+				if columns[i] > 0 {
+					children = append(children, layout.Rigid(label))
+				} else {
+					children = append(children, layout.Flexed(1, label))
+				}
+			}
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			previous := ui.List.Selected
+			dims := ui.List.Layout(th, gtx, len(ui.Filtered), func(gtx layout.Context, index int) layout.Dimensions {
+				sym := &ui.Filtered[index]
+				if ui.List.Selected == index || ui.List.Hovered == index {
+					bg := th.ContrastBg
+					bg.A /= 4
+					paint.Fill(gtx.Ops, bg)
+				}
+				color := th.Fg
+				SourceLine{
+					TopLeft:    image.Pt(0, 0),
+					Text:       fmt.Sprintf("%08x", sym.Address),
+					TextHeight: th.TextSize * 8 / 10,
+					Color:      color,
+				}.Layout(th, gtx)
+				SourceLine{
+					TopLeft:    image.Pt(columns[SymbolAddress], 0),
+					Text:       fmt.Sprintf("%8d", sym.Size),
+					TextHeight: th.TextSize * 8 / 10,
+					Color:      color,
+				}.Layout(th, gtx)
+				SourceLine{
+					TopLeft:    image.Pt(columns[SymbolAddress]+columns[SymbolSize], 0),
+					Text:       sym.Type,
+					TextHeight: th.TextSize * 8 / 10,
+					Color:      color,
+				}.Layout(th, gtx)
+				SourceLine{
+					TopLeft:    image.Pt(columns[SymbolAddress]+columns[SymbolSize]+columns[SymbolType], 0),
+					Text:       sym.Name,
+					TextHeight: th.TextSize * 8 / 10,
+					Italic:     !sym.Exported,
+					Bold:       sym.IsFunc() && ui.List.Hovered == index,
+					Color:      color,
+				}.Layout(th, gtx)
+				return layout.Dimensions{Size: gtx.Constraints.Max}
+			})
+			if ui.List.Selected != previous && InRange(ui.List.Selected, len(ui.Filtered)) {
+				if sym := &ui.Filtered[ui.List.Selected]; sym.IsFunc() && open != nil {
+					open(gtx, sym.Name)
+				}
+			}
+			return dims
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			body := material.Body1(th, fmt.Sprintf("%d / %d", len(ui.Filtered), len(ui.All)))
+			body.TextSize *= 0.8
+			return layout.Center.Layout(gtx, body.Layout)
+		}),
+	)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Tab is a single entry in a TabBar.
+type Tab struct {
+	Label string
+	// Dirty shows an indicator next to the label, e.g. while reloading.
+	Dirty bool
+
+	Select *widget.Clickable
+	Close  *widget.Clickable
+}
+
+// TabBar shows a row of tabs with close buttons and a button for adding a tab.
+type TabBar struct {
+	Add widget.Clickable
+}
+
+// Layout draws the tabs, highlighting the one at index active.
+// The caller handles the clicks of the tab, close and add buttons.
+func (bar *TabBar) Layout(th *material.Theme, gtx layout.Context, tabs []Tab, active int) layout.Dimensions {
+	children := make([]layout.FlexChild, 0, len(tabs)+1)
+	for i := range tabs {
+		tab := &tabs[i]
+		selected := i == active
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Clickable(gtx, tab.Select, func(gtx layout.Context) layout.Dimensions {
+						label := tab.Label
+						if tab.Dirty {
+							label = "● " + label
+						}
+						txt := material.Body1(th, label)
+						if selected {
+							txt.Font.Weight = font.Bold
+						}
+						return layout.Inset{Top: 4, Left: 8, Right: 4, Bottom: 4}.Layout(gtx, txt.Layout)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Clickable(gtx, tab.Close, func(gtx layout.Context) layout.Dimensions {
+						txt := material.Body2(th, "×")
+						return layout.Inset{Top: 4, Left: 4, Right: 8, Bottom: 4}.Layout(gtx, txt.Layout)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					// separator between tabs
+					size := image.Pt(gtx.Dp(1), gtx.Dp(16))
+					paint.FillShape(gtx.Ops, splitterColor, clip.Rect{Max: size}.Op())
+					return layout.Dimensions{Size: size}
+				}),
+			)
+		}))
+	}
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return material.Clickable(gtx, &bar.Add, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: 4, Left: 8, Right: 8, Bottom: 4}.Layout(gtx, material.Body1(th, "+").Layout)
+		})
+	}))
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+}
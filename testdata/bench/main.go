@@ -0,0 +1,1593 @@
+// Command bench is disassembled by the server benchmarks, see
+// server_bench_test.go. Its funcs have about 50, 500 and 5000 instructions.
+package main
+
+// small sums x in a loop.
+//
+//go:noinline
+func small(x *[64]uint64) uint64 {
+	var s uint64
+	for i, v := range x {
+		if v%3 == 0 {
+			s += v * uint64(i)
+		} else {
+			s ^= v >> 2
+		}
+	}
+	for i := range x {
+		x[i] = s - x[i]
+		if x[i] > s {
+			s += x[i] / 7
+		}
+	}
+	return s
+}
+
+// medium mixes the elements of x.
+//
+//go:noinline
+func medium(x *[64]uint64) uint64 {
+	var s uint64
+	s = s*3 ^ x[0]
+	s = s*10 ^ x[13]
+	s = s*17 ^ x[26]
+	s = s*24 ^ x[39]
+	s = s*31 ^ x[52]
+	s = s*9 ^ x[1]
+	s = s*16 ^ x[14]
+	s = s*23 ^ x[27]
+	s = s*30 ^ x[40]
+	s = s*8 ^ x[53]
+	s = s*15 ^ x[2]
+	s = s*22 ^ x[15]
+	s = s*29 ^ x[28]
+	s = s*7 ^ x[41]
+	s = s*14 ^ x[54]
+	s = s*21 ^ x[3]
+	s = s*28 ^ x[16]
+	s = s*6 ^ x[29]
+	s = s*13 ^ x[42]
+	s = s*20 ^ x[55]
+	s = s*27 ^ x[4]
+	s = s*5 ^ x[17]
+	s = s*12 ^ x[30]
+	s = s*19 ^ x[43]
+	s = s*26 ^ x[56]
+	s = s*4 ^ x[5]
+	s = s*11 ^ x[18]
+	s = s*18 ^ x[31]
+	s = s*25 ^ x[44]
+	s = s*3 ^ x[57]
+	s = s*10 ^ x[6]
+	s = s*17 ^ x[19]
+	s = s*24 ^ x[32]
+	s = s*31 ^ x[45]
+	s = s*9 ^ x[58]
+	s = s*16 ^ x[7]
+	s = s*23 ^ x[20]
+	s = s*30 ^ x[33]
+	s = s*8 ^ x[46]
+	s = s*15 ^ x[59]
+	s = s*22 ^ x[8]
+	s = s*29 ^ x[21]
+	s = s*7 ^ x[34]
+	s = s*14 ^ x[47]
+	s = s*21 ^ x[60]
+	s = s*28 ^ x[9]
+	s = s*6 ^ x[22]
+	s = s*13 ^ x[35]
+	s = s*20 ^ x[48]
+	s = s*27 ^ x[61]
+	s = s*5 ^ x[10]
+	s = s*12 ^ x[23]
+	s = s*19 ^ x[36]
+	s = s*26 ^ x[49]
+	s = s*4 ^ x[62]
+	s = s*11 ^ x[11]
+	s = s*18 ^ x[24]
+	s = s*25 ^ x[37]
+	s = s*3 ^ x[50]
+	s = s*10 ^ x[63]
+	s = s*17 ^ x[12]
+	s = s*24 ^ x[25]
+	s = s*31 ^ x[38]
+	s = s*9 ^ x[51]
+	s = s*16 ^ x[0]
+	s = s*23 ^ x[13]
+	s = s*30 ^ x[26]
+	s = s*8 ^ x[39]
+	s = s*15 ^ x[52]
+	s = s*22 ^ x[1]
+	s = s*29 ^ x[14]
+	s = s*7 ^ x[27]
+	s = s*14 ^ x[40]
+	s = s*21 ^ x[53]
+	s = s*28 ^ x[2]
+	s = s*6 ^ x[15]
+	s = s*13 ^ x[28]
+	s = s*20 ^ x[41]
+	s = s*27 ^ x[54]
+	s = s*5 ^ x[3]
+	s = s*12 ^ x[16]
+	s = s*19 ^ x[29]
+	s = s*26 ^ x[42]
+	s = s*4 ^ x[55]
+	s = s*11 ^ x[4]
+	s = s*18 ^ x[17]
+	s = s*25 ^ x[30]
+	s = s*3 ^ x[43]
+	s = s*10 ^ x[56]
+	s = s*17 ^ x[5]
+	s = s*24 ^ x[18]
+	s = s*31 ^ x[31]
+	s = s*9 ^ x[44]
+	s = s*16 ^ x[57]
+	s = s*23 ^ x[6]
+	s = s*30 ^ x[19]
+	s = s*8 ^ x[32]
+	s = s*15 ^ x[45]
+	s = s*22 ^ x[58]
+	s = s*29 ^ x[7]
+	s = s*7 ^ x[20]
+	s = s*14 ^ x[33]
+	s = s*21 ^ x[46]
+	s = s*28 ^ x[59]
+	s = s*6 ^ x[8]
+	s = s*13 ^ x[21]
+	s = s*20 ^ x[34]
+	s = s*27 ^ x[47]
+	s = s*5 ^ x[60]
+	s = s*12 ^ x[9]
+	s = s*19 ^ x[22]
+	s = s*26 ^ x[35]
+	s = s*4 ^ x[48]
+	s = s*11 ^ x[61]
+	s = s*18 ^ x[10]
+	s = s*25 ^ x[23]
+	s = s*3 ^ x[36]
+	s = s*10 ^ x[49]
+	s = s*17 ^ x[62]
+	s = s*24 ^ x[11]
+	s = s*31 ^ x[24]
+	s = s*9 ^ x[37]
+	s = s*16 ^ x[50]
+	s = s*23 ^ x[63]
+	s = s*30 ^ x[12]
+	return s
+}
+
+// large mixes the elements of x.
+//
+//go:noinline
+func large(x *[64]uint64) uint64 {
+	var s uint64
+	s = s*3 ^ x[0]
+	s = s*10 ^ x[13]
+	s = s*17 ^ x[26]
+	s = s*24 ^ x[39]
+	s = s*31 ^ x[52]
+	s = s*9 ^ x[1]
+	s = s*16 ^ x[14]
+	s = s*23 ^ x[27]
+	s = s*30 ^ x[40]
+	s = s*8 ^ x[53]
+	s = s*15 ^ x[2]
+	s = s*22 ^ x[15]
+	s = s*29 ^ x[28]
+	s = s*7 ^ x[41]
+	s = s*14 ^ x[54]
+	s = s*21 ^ x[3]
+	s = s*28 ^ x[16]
+	s = s*6 ^ x[29]
+	s = s*13 ^ x[42]
+	s = s*20 ^ x[55]
+	s = s*27 ^ x[4]
+	s = s*5 ^ x[17]
+	s = s*12 ^ x[30]
+	s = s*19 ^ x[43]
+	s = s*26 ^ x[56]
+	s = s*4 ^ x[5]
+	s = s*11 ^ x[18]
+	s = s*18 ^ x[31]
+	s = s*25 ^ x[44]
+	s = s*3 ^ x[57]
+	s = s*10 ^ x[6]
+	s = s*17 ^ x[19]
+	s = s*24 ^ x[32]
+	s = s*31 ^ x[45]
+	s = s*9 ^ x[58]
+	s = s*16 ^ x[7]
+	s = s*23 ^ x[20]
+	s = s*30 ^ x[33]
+	s = s*8 ^ x[46]
+	s = s*15 ^ x[59]
+	s = s*22 ^ x[8]
+	s = s*29 ^ x[21]
+	s = s*7 ^ x[34]
+	s = s*14 ^ x[47]
+	s = s*21 ^ x[60]
+	s = s*28 ^ x[9]
+	s = s*6 ^ x[22]
+	s = s*13 ^ x[35]
+	s = s*20 ^ x[48]
+	s = s*27 ^ x[61]
+	s = s*5 ^ x[10]
+	s = s*12 ^ x[23]
+	s = s*19 ^ x[36]
+	s = s*26 ^ x[49]
+	s = s*4 ^ x[62]
+	s = s*11 ^ x[11]
+	s = s*18 ^ x[24]
+	s = s*25 ^ x[37]
+	s = s*3 ^ x[50]
+	s = s*10 ^ x[63]
+	s = s*17 ^ x[12]
+	s = s*24 ^ x[25]
+	s = s*31 ^ x[38]
+	s = s*9 ^ x[51]
+	s = s*16 ^ x[0]
+	s = s*23 ^ x[13]
+	s = s*30 ^ x[26]
+	s = s*8 ^ x[39]
+	s = s*15 ^ x[52]
+	s = s*22 ^ x[1]
+	s = s*29 ^ x[14]
+	s = s*7 ^ x[27]
+	s = s*14 ^ x[40]
+	s = s*21 ^ x[53]
+	s = s*28 ^ x[2]
+	s = s*6 ^ x[15]
+	s = s*13 ^ x[28]
+	s = s*20 ^ x[41]
+	s = s*27 ^ x[54]
+	s = s*5 ^ x[3]
+	s = s*12 ^ x[16]
+	s = s*19 ^ x[29]
+	s = s*26 ^ x[42]
+	s = s*4 ^ x[55]
+	s = s*11 ^ x[4]
+	s = s*18 ^ x[17]
+	s = s*25 ^ x[30]
+	s = s*3 ^ x[43]
+	s = s*10 ^ x[56]
+	s = s*17 ^ x[5]
+	s = s*24 ^ x[18]
+	s = s*31 ^ x[31]
+	s = s*9 ^ x[44]
+	s = s*16 ^ x[57]
+	s = s*23 ^ x[6]
+	s = s*30 ^ x[19]
+	s = s*8 ^ x[32]
+	s = s*15 ^ x[45]
+	s = s*22 ^ x[58]
+	s = s*29 ^ x[7]
+	s = s*7 ^ x[20]
+	s = s*14 ^ x[33]
+	s = s*21 ^ x[46]
+	s = s*28 ^ x[59]
+	s = s*6 ^ x[8]
+	s = s*13 ^ x[21]
+	s = s*20 ^ x[34]
+	s = s*27 ^ x[47]
+	s = s*5 ^ x[60]
+	s = s*12 ^ x[9]
+	s = s*19 ^ x[22]
+	s = s*26 ^ x[35]
+	s = s*4 ^ x[48]
+	s = s*11 ^ x[61]
+	s = s*18 ^ x[10]
+	s = s*25 ^ x[23]
+	s = s*3 ^ x[36]
+	s = s*10 ^ x[49]
+	s = s*17 ^ x[62]
+	s = s*24 ^ x[11]
+	s = s*31 ^ x[24]
+	s = s*9 ^ x[37]
+	s = s*16 ^ x[50]
+	s = s*23 ^ x[63]
+	s = s*30 ^ x[12]
+	s = s*8 ^ x[25]
+	s = s*15 ^ x[38]
+	s = s*22 ^ x[51]
+	s = s*29 ^ x[0]
+	s = s*7 ^ x[13]
+	s = s*14 ^ x[26]
+	s = s*21 ^ x[39]
+	s = s*28 ^ x[52]
+	s = s*6 ^ x[1]
+	s = s*13 ^ x[14]
+	s = s*20 ^ x[27]
+	s = s*27 ^ x[40]
+	s = s*5 ^ x[53]
+	s = s*12 ^ x[2]
+	s = s*19 ^ x[15]
+	s = s*26 ^ x[28]
+	s = s*4 ^ x[41]
+	s = s*11 ^ x[54]
+	s = s*18 ^ x[3]
+	s = s*25 ^ x[16]
+	s = s*3 ^ x[29]
+	s = s*10 ^ x[42]
+	s = s*17 ^ x[55]
+	s = s*24 ^ x[4]
+	s = s*31 ^ x[17]
+	s = s*9 ^ x[30]
+	s = s*16 ^ x[43]
+	s = s*23 ^ x[56]
+	s = s*30 ^ x[5]
+	s = s*8 ^ x[18]
+	s = s*15 ^ x[31]
+	s = s*22 ^ x[44]
+	s = s*29 ^ x[57]
+	s = s*7 ^ x[6]
+	s = s*14 ^ x[19]
+	s = s*21 ^ x[32]
+	s = s*28 ^ x[45]
+	s = s*6 ^ x[58]
+	s = s*13 ^ x[7]
+	s = s*20 ^ x[20]
+	s = s*27 ^ x[33]
+	s = s*5 ^ x[46]
+	s = s*12 ^ x[59]
+	s = s*19 ^ x[8]
+	s = s*26 ^ x[21]
+	s = s*4 ^ x[34]
+	s = s*11 ^ x[47]
+	s = s*18 ^ x[60]
+	s = s*25 ^ x[9]
+	s = s*3 ^ x[22]
+	s = s*10 ^ x[35]
+	s = s*17 ^ x[48]
+	s = s*24 ^ x[61]
+	s = s*31 ^ x[10]
+	s = s*9 ^ x[23]
+	s = s*16 ^ x[36]
+	s = s*23 ^ x[49]
+	s = s*30 ^ x[62]
+	s = s*8 ^ x[11]
+	s = s*15 ^ x[24]
+	s = s*22 ^ x[37]
+	s = s*29 ^ x[50]
+	s = s*7 ^ x[63]
+	s = s*14 ^ x[12]
+	s = s*21 ^ x[25]
+	s = s*28 ^ x[38]
+	s = s*6 ^ x[51]
+	s = s*13 ^ x[0]
+	s = s*20 ^ x[13]
+	s = s*27 ^ x[26]
+	s = s*5 ^ x[39]
+	s = s*12 ^ x[52]
+	s = s*19 ^ x[1]
+	s = s*26 ^ x[14]
+	s = s*4 ^ x[27]
+	s = s*11 ^ x[40]
+	s = s*18 ^ x[53]
+	s = s*25 ^ x[2]
+	s = s*3 ^ x[15]
+	s = s*10 ^ x[28]
+	s = s*17 ^ x[41]
+	s = s*24 ^ x[54]
+	s = s*31 ^ x[3]
+	s = s*9 ^ x[16]
+	s = s*16 ^ x[29]
+	s = s*23 ^ x[42]
+	s = s*30 ^ x[55]
+	s = s*8 ^ x[4]
+	s = s*15 ^ x[17]
+	s = s*22 ^ x[30]
+	s = s*29 ^ x[43]
+	s = s*7 ^ x[56]
+	s = s*14 ^ x[5]
+	s = s*21 ^ x[18]
+	s = s*28 ^ x[31]
+	s = s*6 ^ x[44]
+	s = s*13 ^ x[57]
+	s = s*20 ^ x[6]
+	s = s*27 ^ x[19]
+	s = s*5 ^ x[32]
+	s = s*12 ^ x[45]
+	s = s*19 ^ x[58]
+	s = s*26 ^ x[7]
+	s = s*4 ^ x[20]
+	s = s*11 ^ x[33]
+	s = s*18 ^ x[46]
+	s = s*25 ^ x[59]
+	s = s*3 ^ x[8]
+	s = s*10 ^ x[21]
+	s = s*17 ^ x[34]
+	s = s*24 ^ x[47]
+	s = s*31 ^ x[60]
+	s = s*9 ^ x[9]
+	s = s*16 ^ x[22]
+	s = s*23 ^ x[35]
+	s = s*30 ^ x[48]
+	s = s*8 ^ x[61]
+	s = s*15 ^ x[10]
+	s = s*22 ^ x[23]
+	s = s*29 ^ x[36]
+	s = s*7 ^ x[49]
+	s = s*14 ^ x[62]
+	s = s*21 ^ x[11]
+	s = s*28 ^ x[24]
+	s = s*6 ^ x[37]
+	s = s*13 ^ x[50]
+	s = s*20 ^ x[63]
+	s = s*27 ^ x[12]
+	s = s*5 ^ x[25]
+	s = s*12 ^ x[38]
+	s = s*19 ^ x[51]
+	s = s*26 ^ x[0]
+	s = s*4 ^ x[13]
+	s = s*11 ^ x[26]
+	s = s*18 ^ x[39]
+	s = s*25 ^ x[52]
+	s = s*3 ^ x[1]
+	s = s*10 ^ x[14]
+	s = s*17 ^ x[27]
+	s = s*24 ^ x[40]
+	s = s*31 ^ x[53]
+	s = s*9 ^ x[2]
+	s = s*16 ^ x[15]
+	s = s*23 ^ x[28]
+	s = s*30 ^ x[41]
+	s = s*8 ^ x[54]
+	s = s*15 ^ x[3]
+	s = s*22 ^ x[16]
+	s = s*29 ^ x[29]
+	s = s*7 ^ x[42]
+	s = s*14 ^ x[55]
+	s = s*21 ^ x[4]
+	s = s*28 ^ x[17]
+	s = s*6 ^ x[30]
+	s = s*13 ^ x[43]
+	s = s*20 ^ x[56]
+	s = s*27 ^ x[5]
+	s = s*5 ^ x[18]
+	s = s*12 ^ x[31]
+	s = s*19 ^ x[44]
+	s = s*26 ^ x[57]
+	s = s*4 ^ x[6]
+	s = s*11 ^ x[19]
+	s = s*18 ^ x[32]
+	s = s*25 ^ x[45]
+	s = s*3 ^ x[58]
+	s = s*10 ^ x[7]
+	s = s*17 ^ x[20]
+	s = s*24 ^ x[33]
+	s = s*31 ^ x[46]
+	s = s*9 ^ x[59]
+	s = s*16 ^ x[8]
+	s = s*23 ^ x[21]
+	s = s*30 ^ x[34]
+	s = s*8 ^ x[47]
+	s = s*15 ^ x[60]
+	s = s*22 ^ x[9]
+	s = s*29 ^ x[22]
+	s = s*7 ^ x[35]
+	s = s*14 ^ x[48]
+	s = s*21 ^ x[61]
+	s = s*28 ^ x[10]
+	s = s*6 ^ x[23]
+	s = s*13 ^ x[36]
+	s = s*20 ^ x[49]
+	s = s*27 ^ x[62]
+	s = s*5 ^ x[11]
+	s = s*12 ^ x[24]
+	s = s*19 ^ x[37]
+	s = s*26 ^ x[50]
+	s = s*4 ^ x[63]
+	s = s*11 ^ x[12]
+	s = s*18 ^ x[25]
+	s = s*25 ^ x[38]
+	s = s*3 ^ x[51]
+	s = s*10 ^ x[0]
+	s = s*17 ^ x[13]
+	s = s*24 ^ x[26]
+	s = s*31 ^ x[39]
+	s = s*9 ^ x[52]
+	s = s*16 ^ x[1]
+	s = s*23 ^ x[14]
+	s = s*30 ^ x[27]
+	s = s*8 ^ x[40]
+	s = s*15 ^ x[53]
+	s = s*22 ^ x[2]
+	s = s*29 ^ x[15]
+	s = s*7 ^ x[28]
+	s = s*14 ^ x[41]
+	s = s*21 ^ x[54]
+	s = s*28 ^ x[3]
+	s = s*6 ^ x[16]
+	s = s*13 ^ x[29]
+	s = s*20 ^ x[42]
+	s = s*27 ^ x[55]
+	s = s*5 ^ x[4]
+	s = s*12 ^ x[17]
+	s = s*19 ^ x[30]
+	s = s*26 ^ x[43]
+	s = s*4 ^ x[56]
+	s = s*11 ^ x[5]
+	s = s*18 ^ x[18]
+	s = s*25 ^ x[31]
+	s = s*3 ^ x[44]
+	s = s*10 ^ x[57]
+	s = s*17 ^ x[6]
+	s = s*24 ^ x[19]
+	s = s*31 ^ x[32]
+	s = s*9 ^ x[45]
+	s = s*16 ^ x[58]
+	s = s*23 ^ x[7]
+	s = s*30 ^ x[20]
+	s = s*8 ^ x[33]
+	s = s*15 ^ x[46]
+	s = s*22 ^ x[59]
+	s = s*29 ^ x[8]
+	s = s*7 ^ x[21]
+	s = s*14 ^ x[34]
+	s = s*21 ^ x[47]
+	s = s*28 ^ x[60]
+	s = s*6 ^ x[9]
+	s = s*13 ^ x[22]
+	s = s*20 ^ x[35]
+	s = s*27 ^ x[48]
+	s = s*5 ^ x[61]
+	s = s*12 ^ x[10]
+	s = s*19 ^ x[23]
+	s = s*26 ^ x[36]
+	s = s*4 ^ x[49]
+	s = s*11 ^ x[62]
+	s = s*18 ^ x[11]
+	s = s*25 ^ x[24]
+	s = s*3 ^ x[37]
+	s = s*10 ^ x[50]
+	s = s*17 ^ x[63]
+	s = s*24 ^ x[12]
+	s = s*31 ^ x[25]
+	s = s*9 ^ x[38]
+	s = s*16 ^ x[51]
+	s = s*23 ^ x[0]
+	s = s*30 ^ x[13]
+	s = s*8 ^ x[26]
+	s = s*15 ^ x[39]
+	s = s*22 ^ x[52]
+	s = s*29 ^ x[1]
+	s = s*7 ^ x[14]
+	s = s*14 ^ x[27]
+	s = s*21 ^ x[40]
+	s = s*28 ^ x[53]
+	s = s*6 ^ x[2]
+	s = s*13 ^ x[15]
+	s = s*20 ^ x[28]
+	s = s*27 ^ x[41]
+	s = s*5 ^ x[54]
+	s = s*12 ^ x[3]
+	s = s*19 ^ x[16]
+	s = s*26 ^ x[29]
+	s = s*4 ^ x[42]
+	s = s*11 ^ x[55]
+	s = s*18 ^ x[4]
+	s = s*25 ^ x[17]
+	s = s*3 ^ x[30]
+	s = s*10 ^ x[43]
+	s = s*17 ^ x[56]
+	s = s*24 ^ x[5]
+	s = s*31 ^ x[18]
+	s = s*9 ^ x[31]
+	s = s*16 ^ x[44]
+	s = s*23 ^ x[57]
+	s = s*30 ^ x[6]
+	s = s*8 ^ x[19]
+	s = s*15 ^ x[32]
+	s = s*22 ^ x[45]
+	s = s*29 ^ x[58]
+	s = s*7 ^ x[7]
+	s = s*14 ^ x[20]
+	s = s*21 ^ x[33]
+	s = s*28 ^ x[46]
+	s = s*6 ^ x[59]
+	s = s*13 ^ x[8]
+	s = s*20 ^ x[21]
+	s = s*27 ^ x[34]
+	s = s*5 ^ x[47]
+	s = s*12 ^ x[60]
+	s = s*19 ^ x[9]
+	s = s*26 ^ x[22]
+	s = s*4 ^ x[35]
+	s = s*11 ^ x[48]
+	s = s*18 ^ x[61]
+	s = s*25 ^ x[10]
+	s = s*3 ^ x[23]
+	s = s*10 ^ x[36]
+	s = s*17 ^ x[49]
+	s = s*24 ^ x[62]
+	s = s*31 ^ x[11]
+	s = s*9 ^ x[24]
+	s = s*16 ^ x[37]
+	s = s*23 ^ x[50]
+	s = s*30 ^ x[63]
+	s = s*8 ^ x[12]
+	s = s*15 ^ x[25]
+	s = s*22 ^ x[38]
+	s = s*29 ^ x[51]
+	s = s*7 ^ x[0]
+	s = s*14 ^ x[13]
+	s = s*21 ^ x[26]
+	s = s*28 ^ x[39]
+	s = s*6 ^ x[52]
+	s = s*13 ^ x[1]
+	s = s*20 ^ x[14]
+	s = s*27 ^ x[27]
+	s = s*5 ^ x[40]
+	s = s*12 ^ x[53]
+	s = s*19 ^ x[2]
+	s = s*26 ^ x[15]
+	s = s*4 ^ x[28]
+	s = s*11 ^ x[41]
+	s = s*18 ^ x[54]
+	s = s*25 ^ x[3]
+	s = s*3 ^ x[16]
+	s = s*10 ^ x[29]
+	s = s*17 ^ x[42]
+	s = s*24 ^ x[55]
+	s = s*31 ^ x[4]
+	s = s*9 ^ x[17]
+	s = s*16 ^ x[30]
+	s = s*23 ^ x[43]
+	s = s*30 ^ x[56]
+	s = s*8 ^ x[5]
+	s = s*15 ^ x[18]
+	s = s*22 ^ x[31]
+	s = s*29 ^ x[44]
+	s = s*7 ^ x[57]
+	s = s*14 ^ x[6]
+	s = s*21 ^ x[19]
+	s = s*28 ^ x[32]
+	s = s*6 ^ x[45]
+	s = s*13 ^ x[58]
+	s = s*20 ^ x[7]
+	s = s*27 ^ x[20]
+	s = s*5 ^ x[33]
+	s = s*12 ^ x[46]
+	s = s*19 ^ x[59]
+	s = s*26 ^ x[8]
+	s = s*4 ^ x[21]
+	s = s*11 ^ x[34]
+	s = s*18 ^ x[47]
+	s = s*25 ^ x[60]
+	s = s*3 ^ x[9]
+	s = s*10 ^ x[22]
+	s = s*17 ^ x[35]
+	s = s*24 ^ x[48]
+	s = s*31 ^ x[61]
+	s = s*9 ^ x[10]
+	s = s*16 ^ x[23]
+	s = s*23 ^ x[36]
+	s = s*30 ^ x[49]
+	s = s*8 ^ x[62]
+	s = s*15 ^ x[11]
+	s = s*22 ^ x[24]
+	s = s*29 ^ x[37]
+	s = s*7 ^ x[50]
+	s = s*14 ^ x[63]
+	s = s*21 ^ x[12]
+	s = s*28 ^ x[25]
+	s = s*6 ^ x[38]
+	s = s*13 ^ x[51]
+	s = s*20 ^ x[0]
+	s = s*27 ^ x[13]
+	s = s*5 ^ x[26]
+	s = s*12 ^ x[39]
+	s = s*19 ^ x[52]
+	s = s*26 ^ x[1]
+	s = s*4 ^ x[14]
+	s = s*11 ^ x[27]
+	s = s*18 ^ x[40]
+	s = s*25 ^ x[53]
+	s = s*3 ^ x[2]
+	s = s*10 ^ x[15]
+	s = s*17 ^ x[28]
+	s = s*24 ^ x[41]
+	s = s*31 ^ x[54]
+	s = s*9 ^ x[3]
+	s = s*16 ^ x[16]
+	s = s*23 ^ x[29]
+	s = s*30 ^ x[42]
+	s = s*8 ^ x[55]
+	s = s*15 ^ x[4]
+	s = s*22 ^ x[17]
+	s = s*29 ^ x[30]
+	s = s*7 ^ x[43]
+	s = s*14 ^ x[56]
+	s = s*21 ^ x[5]
+	s = s*28 ^ x[18]
+	s = s*6 ^ x[31]
+	s = s*13 ^ x[44]
+	s = s*20 ^ x[57]
+	s = s*27 ^ x[6]
+	s = s*5 ^ x[19]
+	s = s*12 ^ x[32]
+	s = s*19 ^ x[45]
+	s = s*26 ^ x[58]
+	s = s*4 ^ x[7]
+	s = s*11 ^ x[20]
+	s = s*18 ^ x[33]
+	s = s*25 ^ x[46]
+	s = s*3 ^ x[59]
+	s = s*10 ^ x[8]
+	s = s*17 ^ x[21]
+	s = s*24 ^ x[34]
+	s = s*31 ^ x[47]
+	s = s*9 ^ x[60]
+	s = s*16 ^ x[9]
+	s = s*23 ^ x[22]
+	s = s*30 ^ x[35]
+	s = s*8 ^ x[48]
+	s = s*15 ^ x[61]
+	s = s*22 ^ x[10]
+	s = s*29 ^ x[23]
+	s = s*7 ^ x[36]
+	s = s*14 ^ x[49]
+	s = s*21 ^ x[62]
+	s = s*28 ^ x[11]
+	s = s*6 ^ x[24]
+	s = s*13 ^ x[37]
+	s = s*20 ^ x[50]
+	s = s*27 ^ x[63]
+	s = s*5 ^ x[12]
+	s = s*12 ^ x[25]
+	s = s*19 ^ x[38]
+	s = s*26 ^ x[51]
+	s = s*4 ^ x[0]
+	s = s*11 ^ x[13]
+	s = s*18 ^ x[26]
+	s = s*25 ^ x[39]
+	s = s*3 ^ x[52]
+	s = s*10 ^ x[1]
+	s = s*17 ^ x[14]
+	s = s*24 ^ x[27]
+	s = s*31 ^ x[40]
+	s = s*9 ^ x[53]
+	s = s*16 ^ x[2]
+	s = s*23 ^ x[15]
+	s = s*30 ^ x[28]
+	s = s*8 ^ x[41]
+	s = s*15 ^ x[54]
+	s = s*22 ^ x[3]
+	s = s*29 ^ x[16]
+	s = s*7 ^ x[29]
+	s = s*14 ^ x[42]
+	s = s*21 ^ x[55]
+	s = s*28 ^ x[4]
+	s = s*6 ^ x[17]
+	s = s*13 ^ x[30]
+	s = s*20 ^ x[43]
+	s = s*27 ^ x[56]
+	s = s*5 ^ x[5]
+	s = s*12 ^ x[18]
+	s = s*19 ^ x[31]
+	s = s*26 ^ x[44]
+	s = s*4 ^ x[57]
+	s = s*11 ^ x[6]
+	s = s*18 ^ x[19]
+	s = s*25 ^ x[32]
+	s = s*3 ^ x[45]
+	s = s*10 ^ x[58]
+	s = s*17 ^ x[7]
+	s = s*24 ^ x[20]
+	s = s*31 ^ x[33]
+	s = s*9 ^ x[46]
+	s = s*16 ^ x[59]
+	s = s*23 ^ x[8]
+	s = s*30 ^ x[21]
+	s = s*8 ^ x[34]
+	s = s*15 ^ x[47]
+	s = s*22 ^ x[60]
+	s = s*29 ^ x[9]
+	s = s*7 ^ x[22]
+	s = s*14 ^ x[35]
+	s = s*21 ^ x[48]
+	s = s*28 ^ x[61]
+	s = s*6 ^ x[10]
+	s = s*13 ^ x[23]
+	s = s*20 ^ x[36]
+	s = s*27 ^ x[49]
+	s = s*5 ^ x[62]
+	s = s*12 ^ x[11]
+	s = s*19 ^ x[24]
+	s = s*26 ^ x[37]
+	s = s*4 ^ x[50]
+	s = s*11 ^ x[63]
+	s = s*18 ^ x[12]
+	s = s*25 ^ x[25]
+	s = s*3 ^ x[38]
+	s = s*10 ^ x[51]
+	s = s*17 ^ x[0]
+	s = s*24 ^ x[13]
+	s = s*31 ^ x[26]
+	s = s*9 ^ x[39]
+	s = s*16 ^ x[52]
+	s = s*23 ^ x[1]
+	s = s*30 ^ x[14]
+	s = s*8 ^ x[27]
+	s = s*15 ^ x[40]
+	s = s*22 ^ x[53]
+	s = s*29 ^ x[2]
+	s = s*7 ^ x[15]
+	s = s*14 ^ x[28]
+	s = s*21 ^ x[41]
+	s = s*28 ^ x[54]
+	s = s*6 ^ x[3]
+	s = s*13 ^ x[16]
+	s = s*20 ^ x[29]
+	s = s*27 ^ x[42]
+	s = s*5 ^ x[55]
+	s = s*12 ^ x[4]
+	s = s*19 ^ x[17]
+	s = s*26 ^ x[30]
+	s = s*4 ^ x[43]
+	s = s*11 ^ x[56]
+	s = s*18 ^ x[5]
+	s = s*25 ^ x[18]
+	s = s*3 ^ x[31]
+	s = s*10 ^ x[44]
+	s = s*17 ^ x[57]
+	s = s*24 ^ x[6]
+	s = s*31 ^ x[19]
+	s = s*9 ^ x[32]
+	s = s*16 ^ x[45]
+	s = s*23 ^ x[58]
+	s = s*30 ^ x[7]
+	s = s*8 ^ x[20]
+	s = s*15 ^ x[33]
+	s = s*22 ^ x[46]
+	s = s*29 ^ x[59]
+	s = s*7 ^ x[8]
+	s = s*14 ^ x[21]
+	s = s*21 ^ x[34]
+	s = s*28 ^ x[47]
+	s = s*6 ^ x[60]
+	s = s*13 ^ x[9]
+	s = s*20 ^ x[22]
+	s = s*27 ^ x[35]
+	s = s*5 ^ x[48]
+	s = s*12 ^ x[61]
+	s = s*19 ^ x[10]
+	s = s*26 ^ x[23]
+	s = s*4 ^ x[36]
+	s = s*11 ^ x[49]
+	s = s*18 ^ x[62]
+	s = s*25 ^ x[11]
+	s = s*3 ^ x[24]
+	s = s*10 ^ x[37]
+	s = s*17 ^ x[50]
+	s = s*24 ^ x[63]
+	s = s*31 ^ x[12]
+	s = s*9 ^ x[25]
+	s = s*16 ^ x[38]
+	s = s*23 ^ x[51]
+	s = s*30 ^ x[0]
+	s = s*8 ^ x[13]
+	s = s*15 ^ x[26]
+	s = s*22 ^ x[39]
+	s = s*29 ^ x[52]
+	s = s*7 ^ x[1]
+	s = s*14 ^ x[14]
+	s = s*21 ^ x[27]
+	s = s*28 ^ x[40]
+	s = s*6 ^ x[53]
+	s = s*13 ^ x[2]
+	s = s*20 ^ x[15]
+	s = s*27 ^ x[28]
+	s = s*5 ^ x[41]
+	s = s*12 ^ x[54]
+	s = s*19 ^ x[3]
+	s = s*26 ^ x[16]
+	s = s*4 ^ x[29]
+	s = s*11 ^ x[42]
+	s = s*18 ^ x[55]
+	s = s*25 ^ x[4]
+	s = s*3 ^ x[17]
+	s = s*10 ^ x[30]
+	s = s*17 ^ x[43]
+	s = s*24 ^ x[56]
+	s = s*31 ^ x[5]
+	s = s*9 ^ x[18]
+	s = s*16 ^ x[31]
+	s = s*23 ^ x[44]
+	s = s*30 ^ x[57]
+	s = s*8 ^ x[6]
+	s = s*15 ^ x[19]
+	s = s*22 ^ x[32]
+	s = s*29 ^ x[45]
+	s = s*7 ^ x[58]
+	s = s*14 ^ x[7]
+	s = s*21 ^ x[20]
+	s = s*28 ^ x[33]
+	s = s*6 ^ x[46]
+	s = s*13 ^ x[59]
+	s = s*20 ^ x[8]
+	s = s*27 ^ x[21]
+	s = s*5 ^ x[34]
+	s = s*12 ^ x[47]
+	s = s*19 ^ x[60]
+	s = s*26 ^ x[9]
+	s = s*4 ^ x[22]
+	s = s*11 ^ x[35]
+	s = s*18 ^ x[48]
+	s = s*25 ^ x[61]
+	s = s*3 ^ x[10]
+	s = s*10 ^ x[23]
+	s = s*17 ^ x[36]
+	s = s*24 ^ x[49]
+	s = s*31 ^ x[62]
+	s = s*9 ^ x[11]
+	s = s*16 ^ x[24]
+	s = s*23 ^ x[37]
+	s = s*30 ^ x[50]
+	s = s*8 ^ x[63]
+	s = s*15 ^ x[12]
+	s = s*22 ^ x[25]
+	s = s*29 ^ x[38]
+	s = s*7 ^ x[51]
+	s = s*14 ^ x[0]
+	s = s*21 ^ x[13]
+	s = s*28 ^ x[26]
+	s = s*6 ^ x[39]
+	s = s*13 ^ x[52]
+	s = s*20 ^ x[1]
+	s = s*27 ^ x[14]
+	s = s*5 ^ x[27]
+	s = s*12 ^ x[40]
+	s = s*19 ^ x[53]
+	s = s*26 ^ x[2]
+	s = s*4 ^ x[15]
+	s = s*11 ^ x[28]
+	s = s*18 ^ x[41]
+	s = s*25 ^ x[54]
+	s = s*3 ^ x[3]
+	s = s*10 ^ x[16]
+	s = s*17 ^ x[29]
+	s = s*24 ^ x[42]
+	s = s*31 ^ x[55]
+	s = s*9 ^ x[4]
+	s = s*16 ^ x[17]
+	s = s*23 ^ x[30]
+	s = s*30 ^ x[43]
+	s = s*8 ^ x[56]
+	s = s*15 ^ x[5]
+	s = s*22 ^ x[18]
+	s = s*29 ^ x[31]
+	s = s*7 ^ x[44]
+	s = s*14 ^ x[57]
+	s = s*21 ^ x[6]
+	s = s*28 ^ x[19]
+	s = s*6 ^ x[32]
+	s = s*13 ^ x[45]
+	s = s*20 ^ x[58]
+	s = s*27 ^ x[7]
+	s = s*5 ^ x[20]
+	s = s*12 ^ x[33]
+	s = s*19 ^ x[46]
+	s = s*26 ^ x[59]
+	s = s*4 ^ x[8]
+	s = s*11 ^ x[21]
+	s = s*18 ^ x[34]
+	s = s*25 ^ x[47]
+	s = s*3 ^ x[60]
+	s = s*10 ^ x[9]
+	s = s*17 ^ x[22]
+	s = s*24 ^ x[35]
+	s = s*31 ^ x[48]
+	s = s*9 ^ x[61]
+	s = s*16 ^ x[10]
+	s = s*23 ^ x[23]
+	s = s*30 ^ x[36]
+	s = s*8 ^ x[49]
+	s = s*15 ^ x[62]
+	s = s*22 ^ x[11]
+	s = s*29 ^ x[24]
+	s = s*7 ^ x[37]
+	s = s*14 ^ x[50]
+	s = s*21 ^ x[63]
+	s = s*28 ^ x[12]
+	s = s*6 ^ x[25]
+	s = s*13 ^ x[38]
+	s = s*20 ^ x[51]
+	s = s*27 ^ x[0]
+	s = s*5 ^ x[13]
+	s = s*12 ^ x[26]
+	s = s*19 ^ x[39]
+	s = s*26 ^ x[52]
+	s = s*4 ^ x[1]
+	s = s*11 ^ x[14]
+	s = s*18 ^ x[27]
+	s = s*25 ^ x[40]
+	s = s*3 ^ x[53]
+	s = s*10 ^ x[2]
+	s = s*17 ^ x[15]
+	s = s*24 ^ x[28]
+	s = s*31 ^ x[41]
+	s = s*9 ^ x[54]
+	s = s*16 ^ x[3]
+	s = s*23 ^ x[16]
+	s = s*30 ^ x[29]
+	s = s*8 ^ x[42]
+	s = s*15 ^ x[55]
+	s = s*22 ^ x[4]
+	s = s*29 ^ x[17]
+	s = s*7 ^ x[30]
+	s = s*14 ^ x[43]
+	s = s*21 ^ x[56]
+	s = s*28 ^ x[5]
+	s = s*6 ^ x[18]
+	s = s*13 ^ x[31]
+	s = s*20 ^ x[44]
+	s = s*27 ^ x[57]
+	s = s*5 ^ x[6]
+	s = s*12 ^ x[19]
+	s = s*19 ^ x[32]
+	s = s*26 ^ x[45]
+	s = s*4 ^ x[58]
+	s = s*11 ^ x[7]
+	s = s*18 ^ x[20]
+	s = s*25 ^ x[33]
+	s = s*3 ^ x[46]
+	s = s*10 ^ x[59]
+	s = s*17 ^ x[8]
+	s = s*24 ^ x[21]
+	s = s*31 ^ x[34]
+	s = s*9 ^ x[47]
+	s = s*16 ^ x[60]
+	s = s*23 ^ x[9]
+	s = s*30 ^ x[22]
+	s = s*8 ^ x[35]
+	s = s*15 ^ x[48]
+	s = s*22 ^ x[61]
+	s = s*29 ^ x[10]
+	s = s*7 ^ x[23]
+	s = s*14 ^ x[36]
+	s = s*21 ^ x[49]
+	s = s*28 ^ x[62]
+	s = s*6 ^ x[11]
+	s = s*13 ^ x[24]
+	s = s*20 ^ x[37]
+	s = s*27 ^ x[50]
+	s = s*5 ^ x[63]
+	s = s*12 ^ x[12]
+	s = s*19 ^ x[25]
+	s = s*26 ^ x[38]
+	s = s*4 ^ x[51]
+	s = s*11 ^ x[0]
+	s = s*18 ^ x[13]
+	s = s*25 ^ x[26]
+	s = s*3 ^ x[39]
+	s = s*10 ^ x[52]
+	s = s*17 ^ x[1]
+	s = s*24 ^ x[14]
+	s = s*31 ^ x[27]
+	s = s*9 ^ x[40]
+	s = s*16 ^ x[53]
+	s = s*23 ^ x[2]
+	s = s*30 ^ x[15]
+	s = s*8 ^ x[28]
+	s = s*15 ^ x[41]
+	s = s*22 ^ x[54]
+	s = s*29 ^ x[3]
+	s = s*7 ^ x[16]
+	s = s*14 ^ x[29]
+	s = s*21 ^ x[42]
+	s = s*28 ^ x[55]
+	s = s*6 ^ x[4]
+	s = s*13 ^ x[17]
+	s = s*20 ^ x[30]
+	s = s*27 ^ x[43]
+	s = s*5 ^ x[56]
+	s = s*12 ^ x[5]
+	s = s*19 ^ x[18]
+	s = s*26 ^ x[31]
+	s = s*4 ^ x[44]
+	s = s*11 ^ x[57]
+	s = s*18 ^ x[6]
+	s = s*25 ^ x[19]
+	s = s*3 ^ x[32]
+	s = s*10 ^ x[45]
+	s = s*17 ^ x[58]
+	s = s*24 ^ x[7]
+	s = s*31 ^ x[20]
+	s = s*9 ^ x[33]
+	s = s*16 ^ x[46]
+	s = s*23 ^ x[59]
+	s = s*30 ^ x[8]
+	s = s*8 ^ x[21]
+	s = s*15 ^ x[34]
+	s = s*22 ^ x[47]
+	s = s*29 ^ x[60]
+	s = s*7 ^ x[9]
+	s = s*14 ^ x[22]
+	s = s*21 ^ x[35]
+	s = s*28 ^ x[48]
+	s = s*6 ^ x[61]
+	s = s*13 ^ x[10]
+	s = s*20 ^ x[23]
+	s = s*27 ^ x[36]
+	s = s*5 ^ x[49]
+	s = s*12 ^ x[62]
+	s = s*19 ^ x[11]
+	s = s*26 ^ x[24]
+	s = s*4 ^ x[37]
+	s = s*11 ^ x[50]
+	s = s*18 ^ x[63]
+	s = s*25 ^ x[12]
+	s = s*3 ^ x[25]
+	s = s*10 ^ x[38]
+	s = s*17 ^ x[51]
+	s = s*24 ^ x[0]
+	s = s*31 ^ x[13]
+	s = s*9 ^ x[26]
+	s = s*16 ^ x[39]
+	s = s*23 ^ x[52]
+	s = s*30 ^ x[1]
+	s = s*8 ^ x[14]
+	s = s*15 ^ x[27]
+	s = s*22 ^ x[40]
+	s = s*29 ^ x[53]
+	s = s*7 ^ x[2]
+	s = s*14 ^ x[15]
+	s = s*21 ^ x[28]
+	s = s*28 ^ x[41]
+	s = s*6 ^ x[54]
+	s = s*13 ^ x[3]
+	s = s*20 ^ x[16]
+	s = s*27 ^ x[29]
+	s = s*5 ^ x[42]
+	s = s*12 ^ x[55]
+	s = s*19 ^ x[4]
+	s = s*26 ^ x[17]
+	s = s*4 ^ x[30]
+	s = s*11 ^ x[43]
+	s = s*18 ^ x[56]
+	s = s*25 ^ x[5]
+	s = s*3 ^ x[18]
+	s = s*10 ^ x[31]
+	s = s*17 ^ x[44]
+	s = s*24 ^ x[57]
+	s = s*31 ^ x[6]
+	s = s*9 ^ x[19]
+	s = s*16 ^ x[32]
+	s = s*23 ^ x[45]
+	s = s*30 ^ x[58]
+	s = s*8 ^ x[7]
+	s = s*15 ^ x[20]
+	s = s*22 ^ x[33]
+	s = s*29 ^ x[46]
+	s = s*7 ^ x[59]
+	s = s*14 ^ x[8]
+	s = s*21 ^ x[21]
+	s = s*28 ^ x[34]
+	s = s*6 ^ x[47]
+	s = s*13 ^ x[60]
+	s = s*20 ^ x[9]
+	s = s*27 ^ x[22]
+	s = s*5 ^ x[35]
+	s = s*12 ^ x[48]
+	s = s*19 ^ x[61]
+	s = s*26 ^ x[10]
+	s = s*4 ^ x[23]
+	s = s*11 ^ x[36]
+	s = s*18 ^ x[49]
+	s = s*25 ^ x[62]
+	s = s*3 ^ x[11]
+	s = s*10 ^ x[24]
+	s = s*17 ^ x[37]
+	s = s*24 ^ x[50]
+	s = s*31 ^ x[63]
+	s = s*9 ^ x[12]
+	s = s*16 ^ x[25]
+	s = s*23 ^ x[38]
+	s = s*30 ^ x[51]
+	s = s*8 ^ x[0]
+	s = s*15 ^ x[13]
+	s = s*22 ^ x[26]
+	s = s*29 ^ x[39]
+	s = s*7 ^ x[52]
+	s = s*14 ^ x[1]
+	s = s*21 ^ x[14]
+	s = s*28 ^ x[27]
+	s = s*6 ^ x[40]
+	s = s*13 ^ x[53]
+	s = s*20 ^ x[2]
+	s = s*27 ^ x[15]
+	s = s*5 ^ x[28]
+	s = s*12 ^ x[41]
+	s = s*19 ^ x[54]
+	s = s*26 ^ x[3]
+	s = s*4 ^ x[16]
+	s = s*11 ^ x[29]
+	s = s*18 ^ x[42]
+	s = s*25 ^ x[55]
+	s = s*3 ^ x[4]
+	s = s*10 ^ x[17]
+	s = s*17 ^ x[30]
+	s = s*24 ^ x[43]
+	s = s*31 ^ x[56]
+	s = s*9 ^ x[5]
+	s = s*16 ^ x[18]
+	s = s*23 ^ x[31]
+	s = s*30 ^ x[44]
+	s = s*8 ^ x[57]
+	s = s*15 ^ x[6]
+	s = s*22 ^ x[19]
+	s = s*29 ^ x[32]
+	s = s*7 ^ x[45]
+	s = s*14 ^ x[58]
+	s = s*21 ^ x[7]
+	s = s*28 ^ x[20]
+	s = s*6 ^ x[33]
+	s = s*13 ^ x[46]
+	s = s*20 ^ x[59]
+	s = s*27 ^ x[8]
+	s = s*5 ^ x[21]
+	s = s*12 ^ x[34]
+	s = s*19 ^ x[47]
+	s = s*26 ^ x[60]
+	s = s*4 ^ x[9]
+	s = s*11 ^ x[22]
+	s = s*18 ^ x[35]
+	s = s*25 ^ x[48]
+	s = s*3 ^ x[61]
+	s = s*10 ^ x[10]
+	s = s*17 ^ x[23]
+	s = s*24 ^ x[36]
+	s = s*31 ^ x[49]
+	s = s*9 ^ x[62]
+	s = s*16 ^ x[11]
+	s = s*23 ^ x[24]
+	s = s*30 ^ x[37]
+	s = s*8 ^ x[50]
+	s = s*15 ^ x[63]
+	s = s*22 ^ x[12]
+	s = s*29 ^ x[25]
+	s = s*7 ^ x[38]
+	s = s*14 ^ x[51]
+	s = s*21 ^ x[0]
+	s = s*28 ^ x[13]
+	s = s*6 ^ x[26]
+	s = s*13 ^ x[39]
+	s = s*20 ^ x[52]
+	s = s*27 ^ x[1]
+	s = s*5 ^ x[14]
+	s = s*12 ^ x[27]
+	s = s*19 ^ x[40]
+	s = s*26 ^ x[53]
+	s = s*4 ^ x[2]
+	s = s*11 ^ x[15]
+	s = s*18 ^ x[28]
+	s = s*25 ^ x[41]
+	s = s*3 ^ x[54]
+	s = s*10 ^ x[3]
+	s = s*17 ^ x[16]
+	s = s*24 ^ x[29]
+	s = s*31 ^ x[42]
+	s = s*9 ^ x[55]
+	s = s*16 ^ x[4]
+	s = s*23 ^ x[17]
+	s = s*30 ^ x[30]
+	s = s*8 ^ x[43]
+	s = s*15 ^ x[56]
+	s = s*22 ^ x[5]
+	s = s*29 ^ x[18]
+	s = s*7 ^ x[31]
+	s = s*14 ^ x[44]
+	s = s*21 ^ x[57]
+	s = s*28 ^ x[6]
+	s = s*6 ^ x[19]
+	s = s*13 ^ x[32]
+	s = s*20 ^ x[45]
+	s = s*27 ^ x[58]
+	s = s*5 ^ x[7]
+	s = s*12 ^ x[20]
+	s = s*19 ^ x[33]
+	s = s*26 ^ x[46]
+	s = s*4 ^ x[59]
+	s = s*11 ^ x[8]
+	s = s*18 ^ x[21]
+	s = s*25 ^ x[34]
+	s = s*3 ^ x[47]
+	s = s*10 ^ x[60]
+	s = s*17 ^ x[9]
+	s = s*24 ^ x[22]
+	s = s*31 ^ x[35]
+	s = s*9 ^ x[48]
+	s = s*16 ^ x[61]
+	s = s*23 ^ x[10]
+	s = s*30 ^ x[23]
+	s = s*8 ^ x[36]
+	s = s*15 ^ x[49]
+	s = s*22 ^ x[62]
+	s = s*29 ^ x[11]
+	s = s*7 ^ x[24]
+	s = s*14 ^ x[37]
+	s = s*21 ^ x[50]
+	s = s*28 ^ x[63]
+	s = s*6 ^ x[12]
+	s = s*13 ^ x[25]
+	s = s*20 ^ x[38]
+	s = s*27 ^ x[51]
+	s = s*5 ^ x[0]
+	s = s*12 ^ x[13]
+	s = s*19 ^ x[26]
+	s = s*26 ^ x[39]
+	s = s*4 ^ x[52]
+	s = s*11 ^ x[1]
+	s = s*18 ^ x[14]
+	s = s*25 ^ x[27]
+	s = s*3 ^ x[40]
+	s = s*10 ^ x[53]
+	s = s*17 ^ x[2]
+	s = s*24 ^ x[15]
+	s = s*31 ^ x[28]
+	s = s*9 ^ x[41]
+	s = s*16 ^ x[54]
+	s = s*23 ^ x[3]
+	s = s*30 ^ x[16]
+	s = s*8 ^ x[29]
+	s = s*15 ^ x[42]
+	s = s*22 ^ x[55]
+	s = s*29 ^ x[4]
+	s = s*7 ^ x[17]
+	s = s*14 ^ x[30]
+	s = s*21 ^ x[43]
+	s = s*28 ^ x[56]
+	s = s*6 ^ x[5]
+	s = s*13 ^ x[18]
+	s = s*20 ^ x[31]
+	s = s*27 ^ x[44]
+	s = s*5 ^ x[57]
+	s = s*12 ^ x[6]
+	s = s*19 ^ x[19]
+	s = s*26 ^ x[32]
+	s = s*4 ^ x[45]
+	s = s*11 ^ x[58]
+	s = s*18 ^ x[7]
+	s = s*25 ^ x[20]
+	s = s*3 ^ x[33]
+	s = s*10 ^ x[46]
+	s = s*17 ^ x[59]
+	s = s*24 ^ x[8]
+	s = s*31 ^ x[21]
+	s = s*9 ^ x[34]
+	s = s*16 ^ x[47]
+	s = s*23 ^ x[60]
+	s = s*30 ^ x[9]
+	s = s*8 ^ x[22]
+	s = s*15 ^ x[35]
+	s = s*22 ^ x[48]
+	s = s*29 ^ x[61]
+	s = s*7 ^ x[10]
+	s = s*14 ^ x[23]
+	s = s*21 ^ x[36]
+	s = s*28 ^ x[49]
+	s = s*6 ^ x[62]
+	s = s*13 ^ x[11]
+	s = s*20 ^ x[24]
+	s = s*27 ^ x[37]
+	s = s*5 ^ x[50]
+	s = s*12 ^ x[63]
+	s = s*19 ^ x[12]
+	s = s*26 ^ x[25]
+	s = s*4 ^ x[38]
+	s = s*11 ^ x[51]
+	s = s*18 ^ x[0]
+	s = s*25 ^ x[13]
+	s = s*3 ^ x[26]
+	s = s*10 ^ x[39]
+	s = s*17 ^ x[52]
+	s = s*24 ^ x[1]
+	s = s*31 ^ x[14]
+	s = s*9 ^ x[27]
+	s = s*16 ^ x[40]
+	s = s*23 ^ x[53]
+	s = s*30 ^ x[2]
+	s = s*8 ^ x[15]
+	s = s*15 ^ x[28]
+	s = s*22 ^ x[41]
+	s = s*29 ^ x[54]
+	s = s*7 ^ x[3]
+	s = s*14 ^ x[16]
+	s = s*21 ^ x[29]
+	s = s*28 ^ x[42]
+	s = s*6 ^ x[55]
+	s = s*13 ^ x[4]
+	s = s*20 ^ x[17]
+	s = s*27 ^ x[30]
+	s = s*5 ^ x[43]
+	s = s*12 ^ x[56]
+	s = s*19 ^ x[5]
+	s = s*26 ^ x[18]
+	s = s*4 ^ x[31]
+	s = s*11 ^ x[44]
+	s = s*18 ^ x[57]
+	s = s*25 ^ x[6]
+	s = s*3 ^ x[19]
+	s = s*10 ^ x[32]
+	s = s*17 ^ x[45]
+	s = s*24 ^ x[58]
+	s = s*31 ^ x[7]
+	s = s*9 ^ x[20]
+	s = s*16 ^ x[33]
+	s = s*23 ^ x[46]
+	s = s*30 ^ x[59]
+	s = s*8 ^ x[8]
+	s = s*15 ^ x[21]
+	s = s*22 ^ x[34]
+	s = s*29 ^ x[47]
+	s = s*7 ^ x[60]
+	s = s*14 ^ x[9]
+	s = s*21 ^ x[22]
+	s = s*28 ^ x[35]
+	s = s*6 ^ x[48]
+	s = s*13 ^ x[61]
+	s = s*20 ^ x[10]
+	s = s*27 ^ x[23]
+	s = s*5 ^ x[36]
+	s = s*12 ^ x[49]
+	s = s*19 ^ x[62]
+	s = s*26 ^ x[11]
+	s = s*4 ^ x[24]
+	s = s*11 ^ x[37]
+	s = s*18 ^ x[50]
+	s = s*25 ^ x[63]
+	s = s*3 ^ x[12]
+	s = s*10 ^ x[25]
+	s = s*17 ^ x[38]
+	s = s*24 ^ x[51]
+	s = s*31 ^ x[0]
+	s = s*9 ^ x[13]
+	s = s*16 ^ x[26]
+	s = s*23 ^ x[39]
+	s = s*30 ^ x[52]
+	s = s*8 ^ x[1]
+	s = s*15 ^ x[14]
+	s = s*22 ^ x[27]
+	s = s*29 ^ x[40]
+	s = s*7 ^ x[53]
+	s = s*14 ^ x[2]
+	s = s*21 ^ x[15]
+	s = s*28 ^ x[28]
+	s = s*6 ^ x[41]
+	s = s*13 ^ x[54]
+	s = s*20 ^ x[3]
+	s = s*27 ^ x[16]
+	s = s*5 ^ x[29]
+	s = s*12 ^ x[42]
+	s = s*19 ^ x[55]
+	s = s*26 ^ x[4]
+	s = s*4 ^ x[17]
+	s = s*11 ^ x[30]
+	s = s*18 ^ x[43]
+	s = s*25 ^ x[56]
+	s = s*3 ^ x[5]
+	s = s*10 ^ x[18]
+	s = s*17 ^ x[31]
+	s = s*24 ^ x[44]
+	s = s*31 ^ x[57]
+	s = s*9 ^ x[6]
+	s = s*16 ^ x[19]
+	s = s*23 ^ x[32]
+	s = s*30 ^ x[45]
+	s = s*8 ^ x[58]
+	s = s*15 ^ x[7]
+	s = s*22 ^ x[20]
+	s = s*29 ^ x[33]
+	s = s*7 ^ x[46]
+	s = s*14 ^ x[59]
+	s = s*21 ^ x[8]
+	s = s*28 ^ x[21]
+	s = s*6 ^ x[34]
+	s = s*13 ^ x[47]
+	s = s*20 ^ x[60]
+	s = s*27 ^ x[9]
+	s = s*5 ^ x[22]
+	s = s*12 ^ x[35]
+	s = s*19 ^ x[48]
+	s = s*26 ^ x[61]
+	s = s*4 ^ x[10]
+	s = s*11 ^ x[23]
+	s = s*18 ^ x[36]
+	s = s*25 ^ x[49]
+	s = s*3 ^ x[62]
+	s = s*10 ^ x[11]
+	s = s*17 ^ x[24]
+	s = s*24 ^ x[37]
+	s = s*31 ^ x[50]
+	s = s*9 ^ x[63]
+	s = s*16 ^ x[12]
+	s = s*23 ^ x[25]
+	s = s*30 ^ x[38]
+	s = s*8 ^ x[51]
+	s = s*15 ^ x[0]
+	s = s*22 ^ x[13]
+	s = s*29 ^ x[26]
+	s = s*7 ^ x[39]
+	s = s*14 ^ x[52]
+	s = s*21 ^ x[1]
+	s = s*28 ^ x[14]
+	s = s*6 ^ x[27]
+	s = s*13 ^ x[40]
+	s = s*20 ^ x[53]
+	s = s*27 ^ x[2]
+	s = s*5 ^ x[15]
+	s = s*12 ^ x[28]
+	s = s*19 ^ x[41]
+	s = s*26 ^ x[54]
+	s = s*4 ^ x[3]
+	s = s*11 ^ x[16]
+	s = s*18 ^ x[29]
+	s = s*25 ^ x[42]
+	s = s*3 ^ x[55]
+	s = s*10 ^ x[4]
+	s = s*17 ^ x[17]
+	s = s*24 ^ x[30]
+	s = s*31 ^ x[43]
+	s = s*9 ^ x[56]
+	s = s*16 ^ x[5]
+	s = s*23 ^ x[18]
+	s = s*30 ^ x[31]
+	s = s*8 ^ x[44]
+	s = s*15 ^ x[57]
+	s = s*22 ^ x[6]
+	s = s*29 ^ x[19]
+	s = s*7 ^ x[32]
+	s = s*14 ^ x[45]
+	s = s*21 ^ x[58]
+	s = s*28 ^ x[7]
+	s = s*6 ^ x[20]
+	s = s*13 ^ x[33]
+	s = s*20 ^ x[46]
+	s = s*27 ^ x[59]
+	s = s*5 ^ x[8]
+	s = s*12 ^ x[21]
+	s = s*19 ^ x[34]
+	s = s*26 ^ x[47]
+	s = s*4 ^ x[60]
+	s = s*11 ^ x[9]
+	s = s*18 ^ x[22]
+	s = s*25 ^ x[35]
+	s = s*3 ^ x[48]
+	s = s*10 ^ x[61]
+	s = s*17 ^ x[10]
+	s = s*24 ^ x[23]
+	s = s*31 ^ x[36]
+	s = s*9 ^ x[49]
+	s = s*16 ^ x[62]
+	s = s*23 ^ x[11]
+	s = s*30 ^ x[24]
+	s = s*8 ^ x[37]
+	s = s*15 ^ x[50]
+	s = s*22 ^ x[63]
+	s = s*29 ^ x[12]
+	s = s*7 ^ x[25]
+	s = s*14 ^ x[38]
+	s = s*21 ^ x[51]
+	s = s*28 ^ x[0]
+	s = s*6 ^ x[13]
+	s = s*13 ^ x[26]
+	s = s*20 ^ x[39]
+	s = s*27 ^ x[52]
+	s = s*5 ^ x[1]
+	s = s*12 ^ x[14]
+	s = s*19 ^ x[27]
+	s = s*26 ^ x[40]
+	s = s*4 ^ x[53]
+	s = s*11 ^ x[2]
+	s = s*18 ^ x[15]
+	return s
+}
+
+func main() {
+	var x [64]uint64
+	for i := range x {
+		x[i] = uint64(i)
+	}
+	println(small(&x), medium(&x), large(&x))
+}
@@ -0,0 +1,27 @@
+package main
+
+// fileWatcher notifies about changes of a single file
+// It's created by the platform specific watchFile
+type fileWatcher struct {
+	// changed receives a value when the file may have changed,
+	// changes before the value is received are coalesced
+	changed chan struct{}
+	stop    func()
+}
+
+func newFileWatcher() *fileWatcher {
+	return &fileWatcher{changed: make(chan struct{}, 1)}
+}
+
+// notify signals a change without blocking
+func (w *fileWatcher) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops watching the file
+func (w *fileWatcher) Close() {
+	w.stop()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchFile watches the file at path with inotify
+// The directory is watched, as builds usually replace the file
+func watchFile(path string) (*fileWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	wd, err := unix.InotifyAddWatch(fd, dir, unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO|unix.IN_ATTRIB)
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+
+	w := newFileWatcher()
+	var once sync.Once
+	w.stop = func() {
+		// removing the watch queues IN_IGNORED, which ends the blocking read
+		once.Do(func() { _, _ = unix.InotifyRmWatch(fd, uint32(wd)) })
+	}
+
+	go func() {
+		defer func() { _ = unix.Close(fd) }()
+		buf := make([]byte, 64<<10)
+		for {
+			n, err := unix.Read(fd, buf)
+			if err == unix.EINTR {
+				continue
+			}
+			if err != nil || n <= 0 {
+				return
+			}
+			for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+				event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				nameStart := offset + unix.SizeofInotifyEvent
+				offset = nameStart + int(event.Len)
+				if event.Mask&unix.IN_IGNORED != 0 {
+					return
+				}
+				if strings.TrimRight(string(buf[nameStart:offset]), "\x00") == name {
+					w.notify()
+				}
+			}
+		}
+	}()
+	return w, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package main
+
+import "errors"
+
+// watchFile isn't supported on this platform, the caller polls instead
+func watchFile(path string) (*fileWatcher, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// watchFile watches the file at path with ReadDirectoryChangesW
+// The directory is watched, as builds usually replace the file
+func watchFile(path string) (*fileWatcher, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	dir, name := filepath.Dir(abs), filepath.Base(abs)
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := windows.CreateFile(dirPtr, windows.FILE_LIST_DIRECTORY,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OVERLAPPED, 0)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := windows.CreateEvent(nil, 0, 0, nil)
+	if err != nil {
+		_ = windows.CloseHandle(handle)
+		return nil, err
+	}
+	stop, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		_ = windows.CloseHandle(ready)
+		_ = windows.CloseHandle(handle)
+		return nil, err
+	}
+
+	w := newFileWatcher()
+	var once sync.Once
+	w.stop = func() {
+		once.Do(func() { _ = windows.SetEvent(stop) })
+	}
+
+	const mask = windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_LAST_WRITE | windows.FILE_NOTIFY_CHANGE_SIZE
+	go func() {
+		defer func() {
+			_ = windows.CloseHandle(stop)
+			_ = windows.CloseHandle(ready)
+			_ = windows.CloseHandle(handle)
+		}()
+		// FILE_NOTIFY_INFORMATION entries must be DWORD aligned
+		buf := make([]uint32, 16<<10)
+		bufBytes := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*4)
+		for {
+			overlapped := windows.Overlapped{HEvent: ready}
+			err := windows.ReadDirectoryChanges(handle, &bufBytes[0], uint32(len(bufBytes)), false, mask, nil, &overlapped, 0)
+			if err != nil {
+				return
+			}
+
+			var n uint32
+			event, err := windows.WaitForMultipleObjects([]windows.Handle{ready, stop}, false, windows.INFINITE)
+			if err != nil || event != windows.WAIT_OBJECT_0 {
+				_ = windows.CancelIoEx(handle, &overlapped)
+				_ = windows.GetOverlappedResult(handle, &overlapped, &n, true)
+				return
+			}
+			if err := windows.GetOverlappedResult(handle, &overlapped, &n, false); err != nil {
+				return
+			}
+			if n == 0 {
+				// the buffer overflowed and the changes were lost
+				w.notify()
+				continue
+			}
+
+			for offset := uint32(0); ; {
+				info := (*windows.FileNotifyInformation)(unsafe.Pointer(&bufBytes[offset]))
+				fileName := unsafe.Slice(&info.FileName, info.FileNameLength/2)
+				if strings.EqualFold(windows.UTF16ToString(fileName), name) {
+					w.notify()
+				}
+				if info.NextEntryOffset == 0 {
+					break
+				}
+				offset += info.NextEntryOffset
+			}
+		}
+	}()
+	return w, nil
+}
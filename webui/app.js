@@ -0,0 +1,257 @@
+// The web UI lists the functions of a loaded file and shows the
+// disassembly of the selected one next to its source, like the Gio UI.
+// All data comes from the REST API, see API_DOCUMENTATION.md.
+"use strict";
+
+(() => {
+	// maxFuncs limits the rendered functions, refine the filter to see others
+	const maxFuncs = 2000;
+
+	const $ = (id) => document.getElementById(id);
+	const state = {
+		file: "",
+		funcs: [],
+		selected: "",
+		code: null,
+	};
+
+	// api requests a v2 endpoint and decodes the JSON response,
+	// failures throw the message of the ServerError
+	async function api(path, params) {
+		const query = new URLSearchParams(params).toString();
+		const resp = await fetch("../api/v2" + path + (query ? "?" + query : ""), {
+			headers: { Accept: "application/json" },
+		});
+		const body = await resp.json().catch(() => null);
+		if (!resp.ok) {
+			throw new Error(body && body.error ? body.error : resp.status + " " + resp.statusText);
+		}
+		return body;
+	}
+
+	function showError(err) {
+		$("error").textContent = err ? String(err.message || err) : "";
+	}
+
+	function element(tag, className, text) {
+		const el = document.createElement(tag);
+		if (className) {
+			el.className = className;
+		}
+		if (text !== undefined) {
+			el.textContent = text;
+		}
+		return el;
+	}
+
+	// fromHash returns the file and the function of a deep link,
+	// e.g. #file=/path/to/binary&func=main.main
+	function fromHash() {
+		const params = new URLSearchParams(location.hash.slice(1));
+		return { file: params.get("file") || "", func: params.get("func") || "" };
+	}
+
+	// selectionHash returns the deep link of the current selection
+	function selectionHash() {
+		const params = { file: state.file };
+		if (state.selected) {
+			params.func = state.selected;
+		}
+		return "#" + new URLSearchParams(params).toString();
+	}
+
+	// pushHash adds the selection to the history, so that it can be
+	// shared as a link and the back button returns to the previous one
+	function pushHash() {
+		const hash = selectionHash();
+		if (location.hash !== hash) {
+			history.pushState(null, "", hash);
+		}
+	}
+
+	async function loadFiles() {
+		const { files } = await api("/files");
+		const select = $("files");
+		select.replaceChildren();
+		for (const file of files) {
+			select.append(new Option(file.path, file.path));
+		}
+		if (files.length === 0) {
+			showError("No files are loaded, start the server with a binary: lensm -server /path/to/binary");
+			return;
+		}
+		const link = fromHash();
+		const file = files.some((f) => f.path === link.file) ? link.file : files[0].path;
+		select.value = file;
+		await openFile(file, link.func, false);
+		// the initial selection replaces the entry of the page instead of adding one
+		history.replaceState(null, "", selectionHash());
+	}
+
+	// openFile lists the functions of file and selects func when it exists,
+	// push adds the selection to the history
+	async function openFile(file, func, push) {
+		state.file = file;
+		state.selected = "";
+		state.code = null;
+		renderCode();
+		const { functions } = await api("/functions", { file });
+		state.funcs = functions;
+		renderFuncs();
+		if (func && functions.some((fn) => fn.name === func)) {
+			await openFunc(func, push);
+		} else if (push) {
+			pushHash();
+		}
+	}
+
+	// restoreHash shows the selection of the history entry navigated to
+	async function restoreHash() {
+		const link = fromHash();
+		if (link.file && link.file !== state.file) {
+			if (![...$("files").options].some((option) => option.value === link.file)) {
+				return;
+			}
+			$("files").value = link.file;
+			await openFile(link.file, link.func, false);
+			return;
+		}
+		if (link.func && link.func !== state.selected && state.funcs.some((fn) => fn.name === link.func)) {
+			await openFunc(link.func, false);
+		} else if (!link.func && state.selected) {
+			state.selected = "";
+			state.code = null;
+			renderFuncs();
+			renderCode();
+		}
+	}
+
+	function renderFuncs() {
+		let rx = null;
+		try {
+			rx = new RegExp($("filter").value, "i");
+		} catch {
+			// keep the list while the expression is incomplete
+			return;
+		}
+		const matched = state.funcs.filter((fn) => rx.test(fn.name));
+		const list = $("funcs");
+		list.replaceChildren();
+		for (const fn of matched.slice(0, maxFuncs)) {
+			const item = element("li", fn.name === state.selected ? "selected" : "", fn.name);
+			item.title = fn.package;
+			item.onclick = () => openFunc(fn.name, true).catch(showError);
+			list.append(item);
+		}
+		$("count").textContent = matched.length > maxFuncs
+			? `Showing first ${maxFuncs} of ${matched.length} functions — refine your filter`
+			: `${matched.length} functions`;
+	}
+
+	// openFunc shows the disassembly of name, push adds it to the history
+	async function openFunc(name, push) {
+		showError(null);
+		state.selected = name;
+		for (const item of $("funcs").children) {
+			const selected = item.textContent === name;
+			item.classList.toggle("selected", selected);
+			if (selected && !push) {
+				item.scrollIntoView({ block: "nearest" });
+			}
+		}
+		if (push) {
+			pushHash();
+		}
+
+		const code = await api("/functions/" + encodeURIComponent(name), {
+			file: state.file,
+			context: $("context").value,
+		});
+		if (state.selected === name) {
+			state.code = code;
+			renderCode();
+		}
+	}
+
+	function renderCode() {
+		const code = state.code;
+		$("name").textContent = code ? code.name : "";
+		$("file").textContent = code ? "file: " + code.file : "";
+		const asm = $("asm");
+		const source = $("source");
+		asm.replaceChildren();
+		source.replaceChildren();
+		if (!code) {
+			return;
+		}
+
+		const rows = code.instructions.map((inst) => {
+			const row = element("tr", inst.category || "other");
+			row.append(
+				element("td", "pc", inst.text ? "0x" + inst.pc.toString(16) : ""),
+				element("td", "text", inst.text),
+			);
+			if (inst.call) {
+				row.title = inst.call;
+				row.onclick = () => {
+					if (state.funcs.some((fn) => fn.name === inst.call)) {
+						openFunc(inst.call, true).catch(showError);
+					}
+				};
+			}
+			asm.append(row);
+			return row;
+		});
+
+		// related maps the instructions and the source lines to the rows
+		// to highlight together, from the ranges of the source blocks
+		const related = new Map();
+		const relate = (a, b) => {
+			if (!related.has(a)) {
+				related.set(a, new Set());
+			}
+			related.get(a).add(b);
+		};
+		for (const src of code.sources) {
+			source.append(element("div", "path", src.file));
+			for (const block of src.blocks) {
+				const div = element("div", "block");
+				block.lines.forEach((text, i) => {
+					const line = element("div");
+					line.append(element("span", "line", String(block.from + i)), text);
+					relate(line, line);
+					for (const r of (block.related && block.related[i]) || []) {
+						for (let k = r.from; k < r.to && k < rows.length; k++) {
+							relate(line, rows[k]);
+							relate(rows[k], line);
+							relate(rows[k], rows[k]);
+						}
+					}
+					div.append(line);
+				});
+				source.append(div);
+			}
+		}
+
+		let highlighted = [];
+		const highlight = (el) => {
+			highlighted.forEach((h) => h.classList.remove("related"));
+			highlighted = [...(related.get(el) || [])];
+			highlighted.forEach((h) => h.classList.add("related"));
+		};
+		for (const el of related.keys()) {
+			el.onmouseenter = () => highlight(el);
+		}
+	}
+
+	$("files").onchange = () => openFile($("files").value, "", true).catch(showError);
+	$("filter").oninput = renderFuncs;
+	$("context").onchange = () => {
+		if (state.selected) {
+			openFunc(state.selected, false).catch(showError);
+		}
+	};
+	window.onpopstate = () => restoreHash().catch(showError);
+
+	loadFiles().catch(showError);
+})();
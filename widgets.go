@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"gioui.org/font"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
@@ -39,7 +41,7 @@ func (line SourceLine) Layout(th *material.Theme, gtx layout.Context) {
 		defer clip.Rect{Max: maxSize}.Push(gtx.Ops).Pop()
 	}
 
-	f := font.Font{Typeface: "override-monospace,Go,monospace", Weight: font.Normal}
+	f := sourceFont
 	if line.Italic {
 		f.Style = font.Italic
 	}
@@ -50,6 +52,19 @@ func (line SourceLine) Layout(th *material.Theme, gtx layout.Context) {
 	widget.Label{MaxLines: 1}.Layout(gtx, th.Shaper, f, line.TextHeight, line.Text, op.CallOp{})
 }
 
+// sourceFont is the monospace font of SourceLine.
+var sourceFont = font.Font{Typeface: "override-monospace,Go,monospace", Weight: font.Normal}
+
+// monoCharWidth returns the width of a character of SourceLine at size.
+func monoCharWidth(th *material.Theme, gtx layout.Context, size unit.Sp) int {
+	const sample = "0123456789"
+	gtx.Constraints = layout.Constraints{Max: image.Pt(maxLineWidth, maxLineWidth)}
+	macro := op.Record(gtx.Ops)
+	dims := widget.Label{MaxLines: 1}.Layout(gtx, th.Shaper, sourceFont, size, sample, op.CallOp{})
+	macro.Stop()
+	return max(dims.Size.X/len(sample), 1)
+}
+
 type VerticalLine struct {
 	Width unit.Dp
 	Color color.NRGBA
@@ -66,6 +81,62 @@ func (line VerticalLine) Layout(gtx layout.Context) layout.Dimensions {
 	}
 }
 
+// Splitter is a vertical line that resizes the panel before it when dragged.
+type Splitter struct {
+	Color color.NRGBA
+
+	// pressX is where the drag started, relative to the line.
+	pressX   float32
+	dragging bool
+}
+
+// splitterHitWidth is the width of the area around the line that can be dragged.
+const splitterHitWidth = 6
+
+// Layout draws the line and updates width, the width of the panel in dp,
+// while it's dragged, starting from current. It reports whether a drag ended.
+func (split *Splitter) Layout(gtx layout.Context, width *unit.Dp, current unit.Dp) (layout.Dimensions, bool) {
+	released := false
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target: split,
+			Kinds:  pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel,
+		})
+		if !ok {
+			break
+		}
+		press, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+		switch press.Kind {
+		case pointer.Press:
+			split.pressX = press.Position.X
+			split.dragging = true
+			*width = current
+		case pointer.Drag:
+			if split.dragging {
+				*width += unit.Dp((press.Position.X - split.pressX) / gtx.Metric.PxPerDp)
+				gtx.Execute(op.InvalidateCmd{})
+			}
+		case pointer.Release, pointer.Cancel:
+			released = split.dragging
+			split.dragging = false
+		}
+	}
+
+	size := image.Point{X: gtx.Dp(1), Y: gtx.Constraints.Min.Y}
+	paint.FillShape(gtx.Ops, split.Color, clip.Rect{Max: size}.Op())
+
+	hit := gtx.Dp(splitterHitWidth)
+	area := clip.Rect{Min: image.Pt(-hit/2, 0), Max: image.Pt(hit/2, size.Y)}.Push(gtx.Ops)
+	pointer.CursorColResize.Add(gtx.Ops)
+	event.Op(gtx.Ops, split)
+	area.Pop()
+
+	return layout.Dimensions{Size: size}, released
+}
+
 type HorizontalLine struct {
 	Height unit.Dp
 	Color  color.NRGBA
@@ -5,6 +5,8 @@ import (
 	"image"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"gioui.org/app"
@@ -20,16 +22,36 @@ type Windows struct {
 	active sync.WaitGroup
 }
 
-func (windows *Windows) Open(title string, sizeDp image.Point, run func(*app.Window) error) {
+// WindowConfig is the initial state of a window.
+type WindowConfig struct {
+	Title string
+	// Size is the size in dp, it's at least MinSize.
+	Size image.Point
+	// MinSize is the minimum size in dp, zero doesn't limit the size.
+	MinSize image.Point
+	// Maximized opens the window maximized.
+	Maximized bool
+}
+
+func (windows *Windows) Open(config WindowConfig, run func(*app.Window) error) {
+	size := image.Pt(max(config.Size.X, config.MinSize.X), max(config.Size.Y, config.MinSize.Y))
+	options := []app.Option{
+		app.Title(config.Title),
+		app.Size(unit.Dp(size.X), unit.Dp(size.Y)),
+	}
+	if config.MinSize != (image.Point{}) {
+		options = append(options, app.MinSize(unit.Dp(config.MinSize.X), unit.Dp(config.MinSize.Y)))
+	}
+	if config.Maximized {
+		options = append(options, app.Maximized.Option())
+	}
+
 	windows.active.Add(1)
 	go func() {
 		defer windows.active.Done()
 
 		window := new(app.Window)
-		window.Option(
-			app.Title(title),
-			app.Size(unit.Dp(sizeDp.X), unit.Dp(sizeDp.Y)),
-		)
+		window.Option(options...)
 		if err := run(window); err != nil {
 			log.Println(err)
 		}
@@ -58,20 +80,64 @@ func WidgetWindow(widget layout.Widget) func(*app.Window) error {
 	}
 }
 
-func LoadFonts(userfont string) []font.FontFace {
+// userTypeface is the typeface of the user fonts, which takes precedence
+// over the monospace font of the collection.
+const userTypeface = "override-monospace,monospace"
+
+// LoadFonts returns the Go fonts together with the user font from fontFile
+// and all the *.ttf and *.otf fonts in fontDir. The fonts in fontDir keep
+// their style and weight, so that e.g. bold and italic variants are used.
+func LoadFonts(fontFile, fontDir string) []font.FontFace {
 	collection := gofont.Collection()
-	if userfont == "" {
-		return collection
+	if fontFile != "" {
+		b, err := os.ReadFile(fontFile)
+		if err != nil {
+			panic(fmt.Errorf("failed to parse font: %v", err))
+		}
+		face, err := opentype.Parse(b)
+		if err != nil {
+			panic(fmt.Errorf("failed to parse font: %v", err))
+		}
+		fnt := font.Font{Typeface: userTypeface, Weight: font.Normal}
+		collection = append(collection, font.FontFace{Font: fnt, Face: face})
 	}
-	b, err := os.ReadFile(userfont)
-	if err != nil {
-		panic(fmt.Errorf("failed to parse font: %v", err))
+	if fontDir != "" {
+		collection = append(collection, loadFontDir(fontDir)...)
 	}
-	face, err := opentype.Parse(b)
+	return collection
+}
+
+// loadFontDir parses the *.ttf and *.otf files in dir,
+// skipping the files that can't be parsed.
+func loadFontDir(dir string) []font.FontFace {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		panic(fmt.Errorf("failed to parse font: %v", err))
+		panic(fmt.Errorf("failed to read font dir: %v", err))
+	}
+	var faces []font.FontFace
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".ttf" && ext != ".otf") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			log.Println(fmt.Errorf("failed to read font: %w", err))
+			continue
+		}
+		parsed, err := opentype.ParseCollection(b)
+		if err != nil {
+			log.Println(fmt.Errorf("failed to parse font %s: %w", path, err))
+			continue
+		}
+		for _, face := range parsed {
+			face.Font.Typeface = userTypeface
+			faces = append(faces, face)
+		}
+	}
+	if len(faces) == 0 {
+		panic(fmt.Errorf("no fonts found in %s", dir))
 	}
-	fnt := font.Font{Typeface: "override-monospace,monospace", Weight: font.Normal}
-	fface := font.FontFace{Font: fnt, Face: face}
-	return append(collection, fface)
+	return faces
 }